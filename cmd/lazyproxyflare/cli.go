@@ -0,0 +1,828 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lazyproxyflare/internal/audit"
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/ui"
+)
+
+// Exit codes for headless subcommands
+const (
+	exitOK       = 0 // Operation succeeded
+	exitFailure  = 1 // Operation failed (Caddy/DNS error, rolled back where possible)
+	exitUsage    = 2 // Invalid arguments
+	exitNotFound = 3 // Entry not found
+	exitConfig   = 4 // Profile, token or data loading failed
+	exitChanges  = 5 // plan --exit-code: changes are pending
+)
+
+// subcommands maps headless subcommand names to their handlers
+var subcommands = map[string]func(args []string) int{
+	"list":   runList,
+	"add":    runAdd,
+	"edit":   runEdit,
+	"delete": runDelete,
+	"sync":   runSync,
+	"claim":  runClaim,
+	"plan":   runPlan,
+	"apply":  runApply,
+}
+
+// isSubcommand reports whether name is a headless subcommand
+func isSubcommand(name string) bool {
+	_, ok := subcommands[name]
+	return ok
+}
+
+// runSubcommand dispatches a headless subcommand and returns its exit code
+func runSubcommand(name string, args []string) int {
+	return subcommands[name](args)
+}
+
+// cliContext holds the loaded profile state shared by all subcommands
+type cliContext struct {
+	cfg         *config.Config
+	profileName string
+	apiToken    string
+	jsonOutput  bool
+	out         io.Writer
+	logger      *audit.Logger
+}
+
+// commonFlags registers the flags every subcommand accepts
+func commonFlags(fs *flag.FlagSet) (profile *string, jsonOutput *bool) {
+	profile = fs.String("profile", "", "Profile to use (default: last used or only profile)")
+	jsonOutput = fs.Bool("json", false, "Print machine-readable JSON output")
+	return profile, jsonOutput
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// newCLIContext resolves the profile and API token for a subcommand
+func newCLIContext(profileName string, jsonOutput bool) (*cliContext, error) {
+	if profileName == "" {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover profiles: %w", err)
+		}
+		switch len(profiles) {
+		case 0:
+			return nil, fmt.Errorf("no profiles found - run lazyproxyflare once to create one")
+		case 1:
+			profileName = profiles[0]
+		default:
+			lastUsed, _ := config.GetLastUsedProfile()
+			if lastUsed == "" {
+				return nil, fmt.Errorf("multiple profiles found - choose one with --profile")
+			}
+			profileName = lastUsed
+		}
+	}
+
+	profileConfig, err := config.LoadProfile(profileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile '%s': %w", profileName, err)
+	}
+	cfg := config.ProfileToLegacyConfig(profileConfig)
+	caddy.SetContainerPath(cfg.Caddy.CaddyfilePath, cfg.Caddy.CaddyfileContainerPath)
+
+	apiToken, err := cfg.GetAPIToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	// Audit logging is best-effort, matching the TUI
+	var logger *audit.Logger
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		logger, _ = audit.NewLogger(filepath.Join(homeDir, ".config", "lazyproxyflare"))
+	}
+
+	// Finish or undo operations interrupted by a crash before touching anything
+	recovered, err := ui.RecoverJournals(cfg, apiToken)
+	for _, description := range recovered {
+		fmt.Fprintf(os.Stderr, "rolled back interrupted operation: %s\n", description)
+		if logger != nil {
+			logger.Log(audit.LogEntry{
+				Operation:  audit.OperationRecover,
+				EntityType: audit.EntityBoth,
+				Domain:     description,
+				Details:    map[string]interface{}{"source": "cli"},
+				Result:     audit.ResultSuccess,
+			})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("crash recovery failed: %w", err)
+	}
+
+	return &cliContext{
+		cfg:         cfg,
+		profileName: profileName,
+		apiToken:    apiToken,
+		jsonOutput:  jsonOutput,
+		out:         os.Stdout,
+		logger:      logger,
+	}, nil
+}
+
+// setup parses common flags, loads the profile and reports usage/config errors
+func setup(fs *flag.FlagSet, args []string, profile *string, jsonOutput *bool) (*cliContext, []string, int) {
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, nil, exitOK
+	}
+	if err != nil {
+		return nil, nil, exitUsage
+	}
+	ctx, err := newCLIContext(*profile, *jsonOutput)
+	if err != nil {
+		printError(*jsonOutput, err)
+		return nil, nil, exitConfig
+	}
+	return ctx, positional, exitOK
+}
+
+// loadEntries loads entries, reporting failures in the selected output format
+func (c *cliContext) loadEntries() ([]diff.SyncedEntry, bool) {
+	entries, _, err := ui.LoadEntries(c.cfg)
+	if err != nil {
+		printError(c.jsonOutput, fmt.Errorf("failed to load data: %w", err))
+		return nil, false
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Domain < entries[j].Domain
+	})
+	return entries, true
+}
+
+// findEntry looks up a single entry by name, printing an error if missing
+func (c *cliContext) findEntry(name string) (diff.SyncedEntry, int) {
+	entries, ok := c.loadEntries()
+	if !ok {
+		return diff.SyncedEntry{}, exitConfig
+	}
+	entry, found := ui.FindEntry(c.cfg, entries, name)
+	if !found {
+		printError(c.jsonOutput, fmt.Errorf("entry %s not found", name))
+		return diff.SyncedEntry{}, exitNotFound
+	}
+	return entry, exitOK
+}
+
+// printError writes an error to stderr (or as JSON to stdout)
+func printError(jsonOutput bool, err error) {
+	if jsonOutput {
+		writeJSON(os.Stdout, map[string]interface{}{"success": false, "error": err.Error()})
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
+
+// writeJSON encodes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// operationJSON is the JSON shape of an add/edit/delete/sync result
+type operationJSON struct {
+	Operation  string `json:"operation"`
+	Success    bool   `json:"success"`
+	Domain     string `json:"domain"`
+	EntityType string `json:"entity_type,omitempty"`
+	SyncType   string `json:"sync_type,omitempty"`
+	BackupPath string `json:"backup_path,omitempty"`
+	ErrorStep  string `json:"error_step,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Reload     string `json:"reload_output,omitempty"`
+}
+
+// logResult records an operation result in the audit log
+func (c *cliContext) logResult(op audit.OperationType, result ui.OperationResult, details map[string]interface{}) {
+	if c.logger == nil {
+		return
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["source"] = "cli"
+	if result.ReloadOutput != "" {
+		details["reload_output"] = result.ReloadOutput
+	}
+
+	entry := audit.LogEntry{
+		Operation:  op,
+		EntityType: audit.EntityType(result.EntityType),
+		Domain:     result.Domain,
+		Details:    details,
+		Result:     audit.ResultSuccess,
+	}
+	if !result.Success {
+		entry.Result = audit.ResultFailure
+		entry.Error = fmt.Sprintf("%s: %v", result.ErrorStep, result.Err)
+	}
+	c.logger.Log(entry)
+}
+
+// toOperationJSON converts an operation result to its JSON shape
+func toOperationJSON(op audit.OperationType, result ui.OperationResult) operationJSON {
+	out := operationJSON{
+		Operation:  string(op),
+		Success:    result.Success,
+		Domain:     result.Domain,
+		EntityType: result.EntityType,
+		SyncType:   result.SyncType,
+		BackupPath: result.BackupPath,
+		ErrorStep:  result.ErrorStep,
+		Reload:     result.ReloadOutput,
+	}
+	if result.Err != nil {
+		out.Error = result.Err.Error()
+	}
+	return out
+}
+
+// printResult prints an operation result in human-readable form
+func (c *cliContext) printResult(op audit.OperationType, result ui.OperationResult) {
+	if result.Success {
+		fmt.Fprintf(c.out, "%s %s: ok\n", op, result.Domain)
+		if result.BackupPath != "" {
+			fmt.Fprintf(c.out, "  backup: %s\n", result.BackupPath)
+		}
+		if result.ReloadOutput != "" {
+			fmt.Fprintf(c.out, "  reload: %s\n", result.ReloadOutput)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s failed at %s: %v\n", op, result.Domain, result.ErrorStep, result.Err)
+}
+
+// report logs and prints a single operation result and returns the exit code
+func (c *cliContext) report(op audit.OperationType, result ui.OperationResult, details map[string]interface{}) int {
+	c.logResult(op, result, details)
+	if c.jsonOutput {
+		writeJSON(c.out, toOperationJSON(op, result))
+	} else {
+		c.printResult(op, result)
+	}
+
+	if !result.Success {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// entryJSON is the JSON shape of a listed entry
+type entryJSON struct {
+	Domain  string       `json:"domain"`
+	Status  string       `json:"status"`
+	Zone    string       `json:"zone,omitempty"`
+	DNS     *dnsJSON     `json:"dns,omitempty"`
+	AAAA    *dnsJSON     `json:"dns_aaaa,omitempty"` // Paired AAAA record of a dual-stack entry
+	Caddy   *caddyJSON   `json:"caddy,omitempty"`
+	Ingress *ingressJSON `json:"ingress,omitempty"` // Cloudflare Tunnel ingress rule
+	Drift   []driftJSON  `json:"drift,omitempty"`
+}
+
+type ingressJSON struct {
+	Service          string `json:"service"`
+	OriginServerName string `json:"origin_server_name,omitempty"`
+}
+
+type driftJSON struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Reason   string `json:"reason"`
+}
+
+type dnsJSON struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Proxied bool   `json:"proxied"`
+	TTL     int    `json:"ttl"`
+	Owned   bool   `json:"owned"` // Tagged as managed by LazyProxyFlare
+}
+
+type caddyJSON struct {
+	Domains []string    `json:"domains"`
+	Type    string      `json:"type"` // "reverse_proxy", "redirect" or "static"
+	Target  string      `json:"target"`
+	Port    int         `json:"port"`
+	SSL     bool        `json:"ssl"`
+	Imports []string    `json:"imports,omitempty"`
+	File    string      `json:"file"`
+	Routes  []routeJSON `json:"routes,omitempty"`
+
+	// Further upstreams and the load balancing subdirectives of the reverse_proxy
+	Upstreams     []string `json:"upstreams,omitempty"`
+	LoadBalancing []string `json:"load_balancing,omitempty"`
+
+	// Settings of redirects and static sites
+	RedirectTo   string `json:"redirect_to,omitempty"`
+	RedirectCode int    `json:"redirect_code,omitempty"`
+	PreservePath bool   `json:"preserve_path,omitempty"`
+	Root         string `json:"root,omitempty"`
+	Browse       bool   `json:"browse,omitempty"`
+}
+
+// routeJSON is the JSON shape of a path route
+type routeJSON struct {
+	Path        string   `json:"path"`
+	StripPrefix bool     `json:"strip_prefix"`
+	Upstream    string   `json:"upstream"`
+	Snippets    []string `json:"snippets,omitempty"`
+}
+
+// entryTypeKey returns the stable machine-readable name of an entry type
+func entryTypeKey(t caddy.EntryType) string {
+	switch t {
+	case caddy.EntryRedirect:
+		return "redirect"
+	case caddy.EntryStatic:
+		return "static"
+	default:
+		return "reverse_proxy"
+	}
+}
+
+// statusKey returns the stable machine-readable name of a sync status
+func statusKey(status diff.SyncStatus) string {
+	switch status {
+	case diff.StatusSynced:
+		return "synced"
+	case diff.StatusOrphanedDNS:
+		return "orphaned_dns"
+	case diff.StatusOrphanedCaddy:
+		return "orphaned_caddy"
+	case diff.StatusOrphanedIngress:
+		return "orphaned_ingress"
+	case diff.StatusDNSMismatch:
+		return "dns_mismatch"
+	case diff.StatusTargetMismatch:
+		return "target_mismatch"
+	case diff.StatusSettingsMismatch:
+		return "settings_mismatch"
+	case diff.StatusUnmanagedDNS:
+		return "unmanaged_dns"
+	case diff.StatusIgnored:
+		return "ignored"
+	default:
+		return "unknown"
+	}
+}
+
+// runList prints all entries with their sync status
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	status := fs.String("status", "", "Only show entries with this status (synced, orphaned_dns, orphaned_caddy, dns_mismatch, target_mismatch, settings_mismatch, unmanaged_dns, ignored)")
+	zone := fs.String("zone", "", "Only show entries in this zone (multi-zone profiles)")
+
+	ctx, _, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+
+	entries, ok := ctx.loadEntries()
+	if !ok {
+		return exitConfig
+	}
+
+	var filtered []diff.SyncedEntry
+	for _, entry := range entries {
+		if *status != "" && statusKey(entry.Status) != *status {
+			continue
+		}
+		// Ignored entries are only listed when asked for
+		if *status == "" && entry.Status == diff.StatusIgnored {
+			continue
+		}
+		if *zone != "" && !strings.EqualFold(entry.Zone, strings.TrimSuffix(*zone, ".")) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	if ctx.jsonOutput {
+		out := make([]entryJSON, 0, len(filtered))
+		for _, entry := range filtered {
+			item := entryJSON{Domain: entry.Domain, Status: statusKey(entry.Status), Zone: entry.Zone}
+			if entry.DNS != nil {
+				item.DNS = &dnsJSON{
+					ID:      entry.DNS.ID,
+					Type:    entry.DNS.Type,
+					Content: entry.DNS.Content,
+					Proxied: entry.DNS.Proxied,
+					TTL:     entry.DNS.TTL,
+					Owned:   entry.DNS.IsOwned(),
+				}
+			}
+			if entry.DNSAAAA != nil {
+				item.AAAA = &dnsJSON{
+					ID:      entry.DNSAAAA.ID,
+					Type:    entry.DNSAAAA.Type,
+					Content: entry.DNSAAAA.Content,
+					Proxied: entry.DNSAAAA.Proxied,
+					TTL:     entry.DNSAAAA.TTL,
+					Owned:   entry.DNSAAAA.IsOwned(),
+				}
+			}
+			if entry.Caddy != nil {
+				item.Caddy = &caddyJSON{
+					Domains: entry.Caddy.Domains,
+					Type:    entryTypeKey(entry.Caddy.Type),
+					Target:  entry.Caddy.Target,
+					Port:    entry.Caddy.Port,
+					SSL:     entry.Caddy.SSL,
+					Imports: entry.Caddy.Imports,
+					File:    entry.Caddy.File,
+
+					Upstreams:     entry.Caddy.Upstreams,
+					LoadBalancing: entry.Caddy.LoadBalancing.Directives(),
+
+					RedirectTo:   entry.Caddy.RedirectTo,
+					RedirectCode: entry.Caddy.RedirectCode,
+					PreservePath: entry.Caddy.RedirectPreservePath,
+					Root:         entry.Caddy.StaticRoot,
+					Browse:       entry.Caddy.StaticBrowse,
+				}
+				for _, route := range entry.Caddy.Routes {
+					item.Caddy.Routes = append(item.Caddy.Routes, routeJSON{
+						Path:        route.Path,
+						StripPrefix: route.StripPrefix,
+						Upstream:    route.Upstream(),
+						Snippets:    route.Snippets,
+					})
+				}
+			}
+			if entry.Ingress != nil {
+				item.Ingress = &ingressJSON{Service: entry.Ingress.Service, OriginServerName: entry.Ingress.OriginServerName}
+			}
+			for _, m := range entry.Mismatches {
+				item.Drift = append(item.Drift, driftJSON{Field: m.Field, Expected: m.Expected, Actual: m.Actual, Reason: m.Reason})
+			}
+			out = append(out, item)
+		}
+		writeJSON(ctx.out, out)
+		return exitOK
+	}
+
+	for _, entry := range filtered {
+		dnsInfo := "-"
+		if entry.DNS != nil {
+			dnsInfo = fmt.Sprintf("%s %s", entry.DNSType(), entry.DNSContent())
+			if entry.DNS.Proxied {
+				dnsInfo += " (proxied)"
+			}
+		}
+		caddyInfo := "-"
+		if entry.Caddy != nil {
+			caddyInfo = entry.Caddy.Type.Icon() + " " + entry.Caddy.Destination()
+		}
+		fmt.Fprintf(ctx.out, "%s %-40s %-45s %s\n", entry.Status.Icon(), entry.Domain, dnsInfo, caddyInfo)
+	}
+	return exitOK
+}
+
+// formFlags holds the add/edit flags that map onto AddFormData
+type formFlags struct {
+	dnsType   *string
+	target    *string
+	dnsOnly   *bool
+	upstream  *string
+	port      *int
+	proxied   *bool
+	ssl       *bool
+	websocket *bool
+	snippets  *string
+	custom    *string
+	routes    *string
+	upstreams *string
+	lb        *string
+
+	redirect     *string
+	redirectCode *int
+	preservePath *bool
+	static       *string
+	browse       *bool
+}
+
+// registerFormFlags registers the entry fields shared by add and edit
+func registerFormFlags(fs *flag.FlagSet) formFlags {
+	return formFlags{
+		dnsType:   fs.String("type", "", "DNS record type (CNAME, A, AAAA or A+AAAA)"),
+		target:    fs.String("target", "", "DNS target (CNAME hostname, record IP, or \"IPv4,IPv6\" for A+AAAA)"),
+		dnsOnly:   fs.Bool("dns-only", false, "Create DNS record only (no Caddy block)"),
+		upstream:  fs.String("upstream", "", "Reverse proxy target host"),
+		port:      fs.Int("port", 0, "Service port"),
+		proxied:   fs.Bool("proxied", false, "Proxy through Cloudflare"),
+		ssl:       fs.Bool("ssl", false, "Upstream uses HTTPS"),
+		websocket: fs.Bool("websocket", false, "Enable WebSocket support"),
+		snippets:  fs.String("snippets", "", "Comma-separated snippet names to import"),
+		custom:    fs.String("custom", "", "Custom Caddy directives"),
+		upstreams: fs.String("upstreams", "", "Further upstreams load balanced with --upstream (e.g. \"10.0.0.21,10.0.0.22:8080\")"),
+		lb:        fs.String("lb", "", "Load balancing settings separated by ';' (e.g. \"lb_policy least_conn; health_uri /healthz\")"),
+		routes:    fs.String("routes", "", "Path routes separated by ';' (e.g. \"/grafana/* grafana:3000 strip; /prometheus/* prom:9090\")"),
+
+		redirect:     fs.String("redirect", "", "Redirect to this URL instead of proxying (e.g. https://example.com)"),
+		redirectCode: fs.Int("redirect-code", 301, "Redirect status code (301, 302, 303, 307 or 308)"),
+		preservePath: fs.Bool("preserve-path", true, "Append the request path and query to the redirect URL"),
+		static:       fs.String("static", "", "Serve static files from this directory instead of proxying"),
+		browse:       fs.Bool("browse", false, "List directories without an index file (with --static)"),
+	}
+}
+
+// apply overrides form fields with flags that were set explicitly
+func (f formFlags) apply(fs *flag.FlagSet, form *ui.AddFormData) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "type":
+			form.DNSType = strings.ToUpper(*f.dnsType)
+		case "target":
+			form.DNSTarget = *f.target
+		case "dns-only":
+			form.DNSOnly = *f.dnsOnly
+		case "upstream":
+			form.EntryType = caddy.EntryProxy
+			form.ReverseProxyTarget = *f.upstream
+		case "port":
+			form.ServicePort = strconv.Itoa(*f.port)
+		case "proxied":
+			form.Proxied = *f.proxied
+		case "ssl":
+			form.SSL = *f.ssl
+		case "websocket":
+			form.WebSocket = *f.websocket
+		case "snippets":
+			form.SelectedSnippets = make(map[string]bool)
+			for _, name := range strings.Split(*f.snippets, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					form.SelectedSnippets[name] = true
+				}
+			}
+		case "custom":
+			form.CustomCaddyConfig = *f.custom
+		case "routes":
+			form.PathRoutes = strings.ReplaceAll(*f.routes, ";", "\n")
+		case "upstreams":
+			form.Upstreams = *f.upstreams
+		case "lb":
+			form.LoadBalancing = strings.ReplaceAll(*f.lb, ";", "\n")
+		case "redirect":
+			form.EntryType = caddy.EntryRedirect
+			form.RedirectTo = *f.redirect
+		case "redirect-code":
+			form.RedirectCode = strconv.Itoa(*f.redirectCode)
+		case "preserve-path":
+			form.RedirectPreservePath = *f.preservePath
+		case "static":
+			form.EntryType = caddy.EntryStatic
+			form.StaticRoot = *f.static
+		case "browse":
+			form.StaticBrowse = *f.browse
+		}
+	})
+}
+
+// formDetails builds audit log details from a form, matching the TUI
+func formDetails(form ui.AddFormData) map[string]interface{} {
+	details := map[string]interface{}{
+		"dns_type": form.DNSType,
+		"target":   form.DNSTarget,
+		"proxied":  form.Proxied,
+		"dns_only": form.DNSOnly,
+	}
+	if !form.DNSOnly {
+		switch form.EntryType {
+		case caddy.EntryRedirect:
+			details["redirect_to"] = form.RedirectTo
+			details["redirect_code"] = form.RedirectCode
+		case caddy.EntryStatic:
+			details["static_root"] = form.StaticRoot
+		default:
+			details["reverse_proxy"] = form.ReverseProxyTarget
+			details["port"] = form.ServicePort
+		}
+	}
+	return details
+}
+
+// runAdd creates a new entry (DNS record + Caddy block)
+func runAdd(args []string) int {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	ff := registerFormFlags(fs)
+
+	ctx, subdomains, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if len(subdomains) == 0 {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare add <subdomain>... [flags]"))
+		return exitUsage
+	}
+
+	form := ui.NewAddForm(ctx.cfg)
+	form.Subdomain = strings.Join(subdomains, "\n")
+	ff.apply(fs, &form)
+	if err := ui.ValidateForm(form); err != nil {
+		printError(ctx.jsonOutput, err)
+		return exitUsage
+	}
+
+	result := ui.RunCreateEntry(ctx.cfg, form, ctx.apiToken)
+	return ctx.report(audit.OperationCreate, result, formDetails(form))
+}
+
+// runEdit updates an existing entry; unset flags keep their current values
+func runEdit(args []string) int {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	ff := registerFormFlags(fs)
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if len(positional) != 1 {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare edit <domain> [flags]"))
+		return exitUsage
+	}
+
+	entry, code := ctx.findEntry(positional[0])
+	if code != exitOK {
+		return code
+	}
+
+	form := ui.EditFormFromEntry(ctx.cfg, entry)
+	ff.apply(fs, &form)
+	if err := ui.ValidateForm(form); err != nil {
+		printError(ctx.jsonOutput, err)
+		return exitUsage
+	}
+
+	result := ui.RunUpdateEntry(ctx.cfg, form, entry, ctx.apiToken)
+	return ctx.report(audit.OperationUpdate, result, formDetails(form))
+}
+
+// runDelete deletes an entry's DNS record and/or Caddy block
+func runDelete(args []string) int {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	scopeFlag := fs.String("scope", "all", "What to delete: all, dns, or caddy")
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if len(positional) != 1 {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare delete <domain> [--scope all|dns|caddy]"))
+		return exitUsage
+	}
+
+	var scope ui.DeleteScope
+	switch *scopeFlag {
+	case "all":
+		scope = ui.DeleteAll
+	case "dns":
+		scope = ui.DeleteDNSOnly
+	case "caddy":
+		scope = ui.DeleteCaddyOnly
+	default:
+		printError(ctx.jsonOutput, fmt.Errorf("invalid scope %q (expected all, dns, or caddy)", *scopeFlag))
+		return exitUsage
+	}
+
+	entry, code := ctx.findEntry(positional[0])
+	if code != exitOK {
+		return code
+	}
+
+	result := ui.RunDeleteEntry(ctx.cfg, entry, scope, ctx.apiToken)
+	return ctx.report(audit.OperationDelete, result, nil)
+}
+
+// runSync creates the missing half of orphaned entries
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	all := fs.Bool("all", false, "Sync every orphaned entry")
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if (*all && len(positional) != 0) || (!*all && len(positional) != 1) {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare sync <domain> | --all"))
+		return exitUsage
+	}
+
+	if !*all {
+		entry, code := ctx.findEntry(positional[0])
+		if code != exitOK {
+			return code
+		}
+		result := ui.RunSyncEntry(ctx.cfg, entry, ctx.apiToken)
+		return ctx.report(audit.OperationSync, result, map[string]interface{}{"sync_direction": result.SyncType})
+	}
+
+	entries, ok := ctx.loadEntries()
+	if !ok {
+		return exitConfig
+	}
+
+	// Sync each orphan in turn; report all results and fail if any failed
+	exitCode := exitOK
+	results := []operationJSON{}
+	for _, entry := range entries {
+		if entry.Status != diff.StatusOrphanedDNS && entry.Status != diff.StatusOrphanedCaddy {
+			continue
+		}
+		result := ui.RunSyncEntry(ctx.cfg, entry, ctx.apiToken)
+		ctx.logResult(audit.OperationSync, result, map[string]interface{}{"sync_direction": result.SyncType})
+		if ctx.jsonOutput {
+			results = append(results, toOperationJSON(audit.OperationSync, result))
+		} else {
+			ctx.printResult(audit.OperationSync, result)
+		}
+		if !result.Success {
+			exitCode = exitFailure
+		}
+	}
+
+	if ctx.jsonOutput {
+		writeJSON(ctx.out, results)
+	}
+	return exitCode
+}
+
+// runClaim tags existing DNS records as managed by LazyProxyFlare
+func runClaim(args []string) int {
+	fs := flag.NewFlagSet("claim", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	all := fs.Bool("all", false, "Claim the records of every entry with a Caddy block")
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if (*all && len(positional) != 0) || (!*all && len(positional) == 0) {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare claim <domain>... | --all"))
+		return exitUsage
+	}
+
+	entries, ok := ctx.loadEntries()
+	if !ok {
+		return exitConfig
+	}
+
+	// Records backing a Caddy site are the ones this profile manages;
+	// DNS-only records are only claimed by name
+	var claim []diff.SyncedEntry
+	for _, entry := range entries {
+		if *all && entry.Caddy != nil && entry.DNS != nil {
+			claim = append(claim, entry)
+		}
+	}
+	for _, name := range positional {
+		entry, found := ui.FindEntry(ctx.cfg, entries, name)
+		if !found {
+			printError(ctx.jsonOutput, fmt.Errorf("entry %s not found", name))
+			return exitNotFound
+		}
+		claim = append(claim, entry)
+	}
+
+	result := ui.RunClaimEntries(ctx.cfg, claim, ctx.apiToken)
+	return ctx.report(audit.OperationClaim, result, nil)
+}
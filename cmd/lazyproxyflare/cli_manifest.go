@@ -0,0 +1,251 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lazyproxyflare/internal/audit"
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/manifest"
+	"lazyproxyflare/internal/ui"
+)
+
+// changeJSON is the JSON shape of a planned change
+type changeJSON struct {
+	Action string                 `json:"action"`
+	Domain string                 `json:"domain"`
+	Fields []manifest.FieldChange `json:"fields,omitempty"`
+}
+
+// planManifest loads a manifest and plans it against the current entries
+func (c *cliContext) planManifest(path string) ([]manifest.Change, int) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		printError(c.jsonOutput, err)
+		return nil, exitUsage
+	}
+
+	entries, ok := c.loadEntries()
+	if !ok {
+		return nil, exitConfig
+	}
+
+	changes, err := manifest.Plan(m, c.cfg, entries)
+	if err != nil {
+		printError(c.jsonOutput, fmt.Errorf("invalid manifest: %w", err))
+		return nil, exitUsage
+	}
+	return changes, exitOK
+}
+
+// printPlan prints planned changes in human-readable form
+func (c *cliContext) printPlan(changes []manifest.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(c.out, "No changes. Cloudflare and Caddyfile match the manifest.")
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Fprintf(c.out, "%s %s %s\n", change.Action.Symbol(), change.Action, change.Domain)
+		for _, f := range change.Fields {
+			if f.Current == "" {
+				fmt.Fprintf(c.out, "    %s: %s\n", f.Field, f.Desired)
+			} else {
+				fmt.Fprintf(c.out, "    %s: %s -> %s\n", f.Field, f.Current, f.Desired)
+			}
+		}
+	}
+
+	create, update, del := manifest.Summary(changes)
+	fmt.Fprintf(c.out, "\nPlan: %d to create, %d to update, %d to delete.\n", create, update, del)
+}
+
+// runPlan shows what apply would change
+func runPlan(args []string) int {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+	detailedExit := fs.Bool("exit-code", false, "Exit with code 5 when changes are pending")
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if len(positional) != 1 {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare plan <manifest.yaml>"))
+		return exitUsage
+	}
+
+	changes, code := ctx.planManifest(positional[0])
+	if code != exitOK {
+		return code
+	}
+
+	if ctx.jsonOutput {
+		out := make([]changeJSON, 0, len(changes))
+		for _, change := range changes {
+			out = append(out, changeJSON{Action: string(change.Action), Domain: change.Domain, Fields: change.Fields})
+		}
+		writeJSON(ctx.out, out)
+	} else {
+		ctx.printPlan(changes)
+	}
+
+	if *detailedExit && len(changes) > 0 {
+		return exitChanges
+	}
+	return exitOK
+}
+
+// runApply makes the planned changes through the same create/update/delete paths as the TUI
+func runApply(args []string) int {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	profile, jsonOutput := commonFlags(fs)
+
+	ctx, positional, code := setup(fs, args, profile, jsonOutput)
+	if ctx == nil {
+		return code
+	}
+	if len(positional) != 1 {
+		printError(ctx.jsonOutput, fmt.Errorf("usage: lazyproxyflare apply <manifest.yaml>"))
+		return exitUsage
+	}
+
+	changes, code := ctx.planManifest(positional[0])
+	if code != exitOK {
+		return code
+	}
+	if !ctx.jsonOutput {
+		ctx.printPlan(changes)
+		if len(changes) > 0 {
+			fmt.Fprintln(ctx.out)
+		}
+	}
+
+	// Apply changes one by one; keep going so one failure doesn't block the rest
+	exitCode := exitOK
+	results := []operationJSON{}
+	for _, change := range changes {
+		op, result := ctx.applyChange(change)
+		var details map[string]interface{}
+		if change.Desired != nil {
+			details = desiredDetails(*change.Desired)
+		}
+		ctx.logResult(op, result, details)
+		if ctx.jsonOutput {
+			results = append(results, toOperationJSON(op, result))
+		} else {
+			ctx.printResult(op, result)
+		}
+		if !result.Success {
+			exitCode = exitFailure
+		}
+	}
+
+	if ctx.jsonOutput {
+		writeJSON(ctx.out, results)
+	}
+	return exitCode
+}
+
+// applyChange runs a single planned change
+func (c *cliContext) applyChange(change manifest.Change) (audit.OperationType, ui.OperationResult) {
+	switch change.Action {
+	case manifest.ActionCreate:
+		form := ui.NewAddForm(c.cfg)
+		form.Subdomain = change.Desired.Subdomain
+		applyDesired(&form, *change.Desired)
+		return audit.OperationCreate, ui.RunCreateEntry(c.cfg, form, c.apiToken)
+
+	case manifest.ActionUpdate:
+		entry := *change.Entry
+
+		// Update can't create a missing DNS record, so sync it first
+		// (CNAME with profile defaults), then converge the remaining fields
+		if entry.DNS == nil {
+			result := ui.RunSyncEntry(c.cfg, entry, c.apiToken)
+			if !result.Success {
+				return audit.OperationSync, result
+			}
+			entries, _, err := ui.LoadEntries(c.cfg)
+			if err != nil {
+				return audit.OperationUpdate, ui.OperationResult{Err: err, ErrorStep: "refresh", Domain: change.Domain}
+			}
+			synced, found := ui.FindEntry(c.cfg, entries, entry.Domain)
+			if !found {
+				return audit.OperationUpdate, ui.OperationResult{Err: fmt.Errorf("entry missing after sync"), ErrorStep: "refresh", Domain: change.Domain}
+			}
+			entry = synced
+			if len(manifest.CompareFields(entry, *change.Desired)) == 0 {
+				return audit.OperationSync, result
+			}
+		}
+
+		form := ui.EditFormFromEntry(c.cfg, entry)
+		applyDesired(&form, *change.Desired)
+		return audit.OperationUpdate, ui.RunUpdateEntry(c.cfg, form, entry, c.apiToken)
+
+	case manifest.ActionDelete:
+		return audit.OperationDelete, ui.RunDeleteEntry(c.cfg, *change.Entry, ui.DeleteAll, c.apiToken)
+	}
+
+	return audit.OperationType(change.Action), ui.OperationResult{
+		Err:       fmt.Errorf("unknown action %q", change.Action),
+		ErrorStep: "validation",
+		Domain:    change.Domain,
+	}
+}
+
+// applyDesired copies desired manifest values onto an add/edit form
+func applyDesired(form *ui.AddFormData, d manifest.Desired) {
+	form.DNSType = d.DNSType
+	form.DNSTarget = d.DNSTarget
+	form.Proxied = d.Proxied
+	form.DNSOnly = d.DNSOnly
+	form.EntryType = d.Type
+	switch d.Type {
+	case caddy.EntryRedirect:
+		form.RedirectTo = d.RedirectTo
+		form.RedirectCode = strconv.Itoa(d.RedirectCode)
+		form.RedirectPreservePath = d.PreservePath
+	case caddy.EntryStatic:
+		form.StaticRoot = d.StaticRoot
+		form.StaticBrowse = d.Browse
+	default:
+		form.ReverseProxyTarget = d.Target
+		form.ServicePort = strconv.Itoa(d.Port)
+		form.SSL = d.SSL
+		form.PathRoutes = caddy.FormatPathRoutes(d.Routes)
+		form.Upstreams = strings.Join(d.Upstreams, " ")
+		form.LoadBalancing = d.LoadBalancing.String()
+	}
+	form.SelectedSnippets = make(map[string]bool)
+	for _, name := range d.Snippets {
+		form.SelectedSnippets[name] = true
+	}
+}
+
+// desiredDetails builds audit log details for a manifest-driven change
+func desiredDetails(d manifest.Desired) map[string]interface{} {
+	details := map[string]interface{}{
+		"dns_type": d.DNSType,
+		"target":   d.DNSTarget,
+		"proxied":  d.Proxied,
+		"dns_only": d.DNSOnly,
+		"manifest": true,
+	}
+	if !d.DNSOnly {
+		switch d.Type {
+		case caddy.EntryRedirect:
+			details["redirect_to"] = d.RedirectTo
+			details["redirect_code"] = strconv.Itoa(d.RedirectCode)
+		case caddy.EntryStatic:
+			details["static_root"] = d.StaticRoot
+		default:
+			details["reverse_proxy"] = d.Target
+			details["port"] = strconv.Itoa(d.Port)
+		}
+	}
+	return details
+}
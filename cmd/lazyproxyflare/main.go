@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
 	"lazyproxyflare/internal/ui"
 )
 
@@ -19,16 +21,32 @@ import (
 var Version = "dev"
 
 func main() {
+	// Headless subcommands (list/add/edit/delete/sync) bypass the TUI
+	if len(os.Args) > 1 && isSubcommand(os.Args[1]) {
+		os.Exit(runSubcommand(os.Args[1], os.Args[2:]))
+	}
+
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	profileFlag := flag.String("profile", "", "Load a specific profile by name")
 
 	// Custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "LazyProxyFlare - Cloudflare DNS + Caddy reverse proxy manager\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: lazyproxyflare [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: lazyproxyflare [flags]\n")
+		fmt.Fprintf(os.Stderr, "       lazyproxyflare <command> [args] [--profile NAME] [--json]\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nWith no flags, launches the interactive TUI.\n")
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		fmt.Fprintf(os.Stderr, "  list                     List entries and their sync status\n")
+		fmt.Fprintf(os.Stderr, "  add <subdomain>...       Create DNS record(s) and Caddy block\n")
+		fmt.Fprintf(os.Stderr, "  edit <domain>            Update an existing entry\n")
+		fmt.Fprintf(os.Stderr, "  delete <domain>          Delete an entry (--scope all|dns|caddy)\n")
+		fmt.Fprintf(os.Stderr, "  sync <domain> | --all    Create the missing half of orphaned entries\n")
+		fmt.Fprintf(os.Stderr, "  claim <domain>... | --all  Tag existing DNS records as managed\n")
+		fmt.Fprintf(os.Stderr, "  plan <manifest.yaml>     Show changes needed to match a manifest\n")
+		fmt.Fprintf(os.Stderr, "  apply <manifest.yaml>    Make Cloudflare and the Caddyfile match a manifest\n")
+		fmt.Fprintf(os.Stderr, "\nRun 'lazyproxyflare <command> --help' for command flags.\n")
+		fmt.Fprintf(os.Stderr, "With no flags or command, launches the interactive TUI.\n")
 		fmt.Fprintf(os.Stderr, "Profiles are stored in ~/.config/lazyproxyflare/profiles/\n")
 	}
 
@@ -91,6 +109,10 @@ func autoLoadProfile(profileName string) {
 
 	// Convert to legacy config format
 	cfg := config.ProfileToLegacyConfig(profileConfig)
+	caddy.SetContainerPath(cfg.Caddy.CaddyfilePath, cfg.Caddy.CaddyfileContainerPath)
+
+	// Finish or undo operations interrupted by a crash
+	recoverInterrupted(cfg)
 
 	// Load data (Caddyfile + DNS records)
 	entries, snippets := loadData(cfg)
@@ -127,45 +149,62 @@ func launchProfileSelector(profiles []string) {
 	}
 }
 
+// recoverInterrupted rolls back operations on this profile left unfinished by a crash
+func recoverInterrupted(cfg *config.Config) {
+	apiToken, err := cfg.GetAPIToken()
+	if err != nil {
+		return // loadData reports the token error
+	}
+	recovered, err := ui.RecoverJournals(cfg, apiToken)
+	for _, description := range recovered {
+		log.Printf("Rolled back interrupted operation: %s", description)
+	}
+	if err != nil {
+		log.Printf("Warning: crash recovery failed: %v", err)
+	}
+}
+
 // loadData loads Caddyfile and DNS records for a given config
 func loadData(cfg *config.Config) ([]diff.SyncedEntry, []caddy.Snippet) {
-	// Parse Caddyfile
-	caddyContent, err := os.ReadFile(cfg.Caddy.CaddyfilePath)
+	// Parse the Caddyfile and the files it imports, with snippets
+	parsed, err := caddy.LoadCaddyfile(cfg.Caddy.CaddyfilePath)
 	if err != nil {
 		log.Printf("Warning: Failed to read Caddyfile: %v", err)
 		// Return empty data instead of crashing
 		return []diff.SyncedEntry{}, []caddy.Snippet{}
 	}
 
-	// Parse Caddyfile with snippets
-	parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
-
 	// Get API token
 	apiToken, err := cfg.GetAPIToken()
 	if err != nil {
 		log.Fatalf("Failed to get API token: %v", err)
 	}
 
-	// Fetch DNS records from Cloudflare
-	cfClient := cloudflare.NewClient(apiToken)
-
-	cnameRecords, err := cfClient.ListDNSRecords(cfg.Cloudflare.ZoneID, "CNAME")
-	if err != nil {
-		log.Printf("Warning: Failed to fetch CNAME records: %v", err)
-		cnameRecords = []cloudflare.DNSRecord{}
+	// Fetch DNS records from the profile's DNS provider
+	cfClient := dnsprovider.New(cfg, apiToken)
+	ctx := context.Background()
+
+	// Fetch CNAME, A and AAAA records from every zone in the profile
+	allDNS := []cloudflare.DNSRecord{}
+	for _, zone := range cfg.AllZones() {
+		records, err := ui.ListManagedRecords(ctx, cfClient, zone.ZoneID)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch DNS records for %s: %v", zone.Domain, err)
+			continue
+		}
+		allDNS = append(allDNS, records...)
 	}
 
-	aRecords, err := cfClient.ListDNSRecords(cfg.Cloudflare.ZoneID, "A")
+	// Read the tunnel ingress rules (tunnel profiles only)
+	opts := ui.CompareOptionsForConfig(cfg)
+	ingress, err := ui.LoadIngress(cfg)
 	if err != nil {
-		log.Printf("Warning: Failed to fetch A records: %v", err)
-		aRecords = []cloudflare.DNSRecord{}
+		log.Printf("Warning: Failed to read tunnel ingress rules: %v", err)
 	}
-
-	// Combine all DNS records
-	allDNS := append(cnameRecords, aRecords...)
+	opts.Ingress = ingress
 
 	// Run diff engine
-	syncedEntries := diff.Compare(allDNS, parsed.Entries)
+	syncedEntries := diff.CompareWithOptions(allDNS, parsed.Entries, opts)
 
 	return syncedEntries, parsed.Snippets
 }
@@ -19,6 +19,8 @@ const (
 	OperationBatchDelete OperationType = "batch_delete"
 	OperationBatchSync   OperationType = "batch_sync"
 	OperationRestore     OperationType = "restore"
+	OperationRecover     OperationType = "recover" // Interrupted operation undone on startup
+	OperationClaim       OperationType = "claim"   // Existing DNS records tagged as managed
 )
 
 // EntityType represents what entity was affected
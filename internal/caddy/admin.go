@@ -0,0 +1,264 @@
+package caddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultAdminAddress is the address Caddy's admin API listens on by default
+const DefaultAdminAddress = "localhost:2019"
+
+// AdminClient talks to Caddy's admin API (/load, /adapt, /config/).
+// Validation and reloads go through the running Caddy instance instead of
+// shelling out to docker or the caddy binary.
+type AdminClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// AdminError is an error response from the admin API
+type AdminError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *AdminError) Error() string {
+	return fmt.Sprintf("caddy admin API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// AdaptWarning is a warning emitted while adapting a Caddyfile
+type AdaptWarning struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Directive string `json:"directive"`
+	Message   string `json:"message"`
+}
+
+// AdaptResult is the JSON config produced by /adapt plus any warnings
+type AdaptResult struct {
+	Config   json.RawMessage `json:"result"`
+	Warnings []AdaptWarning  `json:"warnings"`
+}
+
+// NewAdminClient creates an admin API client.
+// address may be "host:port", an http(s):// URL, or a unix socket written
+// the way Caddy does ("unix//run/caddy/admin.sock") or as "unix:///path".
+// An empty address uses DefaultAdminAddress.
+func NewAdminClient(address string) (*AdminClient, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		address = DefaultAdminAddress
+	}
+
+	client := &AdminClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	switch {
+	case strings.HasPrefix(address, "unix://"), strings.HasPrefix(address, "unix/"):
+		socketPath := strings.TrimPrefix(strings.TrimPrefix(address, "unix://"), "unix/")
+		if socketPath == "" {
+			return nil, fmt.Errorf("invalid admin address %q: missing socket path", address)
+		}
+		client.httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		// Caddy only accepts loopback Host values on unix sockets
+		client.baseURL = "http://127.0.0.1"
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		client.baseURL = strings.TrimSuffix(address, "/")
+	default:
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid admin address %q: %w", address, err)
+		}
+		client.baseURL = "http://" + address
+	}
+
+	return client, nil
+}
+
+// do sends a request to the admin API and returns the response body
+func (c *AdminClient) do(method, path, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach caddy admin API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Errors come back as {"error": "..."}
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			message = errResp.Error
+		}
+		return nil, &AdminError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	return data, nil
+}
+
+// Adapt converts a Caddyfile to JSON without applying it.
+// A Caddyfile that fails to adapt is invalid.
+func (c *AdminClient) Adapt(caddyfile []byte) (*AdaptResult, error) {
+	data, err := c.do(http.MethodPost, "/adapt", "text/caddyfile", caddyfile)
+	if err != nil {
+		return nil, err
+	}
+
+	var result AdaptResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse adapt response: %w", err)
+	}
+	return &result, nil
+}
+
+// Load applies a Caddyfile as the new running config. Caddy swaps the
+// config gracefully and keeps the old one if the new one fails to load.
+func (c *AdminClient) Load(caddyfile []byte) error {
+	_, err := c.do(http.MethodPost, "/load", "text/caddyfile", caddyfile)
+	return err
+}
+
+// Config returns the running config (JSON) at the given path under /config/
+func (c *AdminClient) Config(path string) (json.RawMessage, error) {
+	data, err := c.do(http.MethodGet, "/config/"+strings.TrimPrefix(path, "/"), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// ValidateFile validates a Caddyfile on disk by adapting it
+func (c *AdminClient) ValidateFile(caddyfilePath string) error {
+	content, err := readForAdmin(caddyfilePath)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Adapt(content); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// LoadFile reads a Caddyfile from disk and loads it
+func (c *AdminClient) LoadFile(caddyfilePath string) error {
+	content, err := readForAdmin(caddyfilePath)
+	if err != nil {
+		return err
+	}
+	if err := c.Load(content); err != nil {
+		return fmt.Errorf("reload failed: %w", err)
+	}
+	return nil
+}
+
+// readForAdmin reads a Caddyfile to post to the admin API. The API adapts
+// the body without knowing where the file is, so Caddy would resolve
+// relative imports against its working directory; they are made absolute
+// under the directory Caddy reads the Caddyfile from (see SetContainerPath),
+// as "caddy adapt --config" would resolve them.
+func readForAdmin(caddyfilePath string) ([]byte, error) {
+	content, err := os.ReadFile(caddyfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Caddyfile: %w", err)
+	}
+
+	// Snippets are imported by name, wherever in the tree they are defined
+	snippets := map[string]bool{}
+	if tree, _ := LoadTree(caddyfilePath); tree != nil {
+		for _, src := range tree.Sources {
+			for _, block := range src.File.Blocks {
+				if block.Kind == BlockSnippet {
+					snippets[block.Keys[0]] = true
+				}
+			}
+		}
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(caddyfilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve Caddyfile directory: %w", err)
+	}
+	if containerPath, ok := containerPaths.Load(filepath.Clean(caddyfilePath)); ok {
+		dir = filepath.Dir(containerPath.(string))
+	}
+	return []byte(absoluteImports(string(content), dir, snippets)), nil
+}
+
+// absoluteImports rewrites the pattern of each import line naming files by
+// a relative path to an absolute one under dir. Snippet imports and patterns
+// with placeholders are left as written.
+func absoluteImports(content, dir string, snippets map[string]bool) string {
+	tokens, err := Tokenize(content)
+	if err != nil {
+		return content // Caddy reports the syntax error
+	}
+
+	var b strings.Builder
+	last := 0
+	lineStart := true // The next word starts a directive
+	for i, tok := range tokens {
+		switch tok.Kind {
+		case TokenNewline, TokenOpenBrace, TokenCloseBrace:
+			lineStart = true
+			continue
+		case TokenComment:
+			continue
+		}
+		first := lineStart
+		lineStart = false
+		if !first || tok.Kind != TokenWord || tok.Text != "import" || i+1 >= len(tokens) {
+			continue
+		}
+		arg := tokens[i+1]
+		if arg.Kind != TokenWord && arg.Kind != TokenQuoted {
+			continue
+		}
+		pattern := arg.Value()
+		if pattern == "" || filepath.IsAbs(pattern) || strings.Contains(pattern, "{") || snippets[pattern] {
+			continue
+		}
+		path := filepath.Join(dir, pattern)
+		if strings.ContainsAny(path, " \t\"#") {
+			path = `"` + strings.ReplaceAll(path, `"`, `\"`) + `"`
+		}
+		b.WriteString(content[last:arg.Offset])
+		b.WriteString(path)
+		last = arg.End()
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
@@ -0,0 +1,224 @@
+package caddy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAdmin is a minimal stand-in for Caddy's admin API
+type fakeAdmin struct {
+	mu     sync.Mutex
+	loaded string // Last Caddyfile POSTed to /load
+	hosts  []string
+}
+
+func (f *fakeAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.hosts = append(f.hosts, r.Host)
+	f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && (r.URL.Path == "/adapt" || r.URL.Path == "/load"):
+		if r.Header.Get("Content-Type") != "text/caddyfile" {
+			http.Error(w, `{"error":"unexpected content type"}`, http.StatusBadRequest)
+			return
+		}
+		// Treat unbalanced braces as an adapt error, like Caddy would
+		if strings.Count(string(body), "{") != strings.Count(string(body), "}") {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"adapting config using caddyfile: Caddyfile:2 - Error during parsing: Unexpected EOF"}`)
+			return
+		}
+		if r.URL.Path == "/load" {
+			f.mu.Lock()
+			f.loaded = string(body)
+			f.mu.Unlock()
+			return
+		}
+		io.WriteString(w, `{"result":{"apps":{"http":{}}},"warnings":[{"file":"Caddyfile","line":1,"message":"input is not formatted with 'caddy fmt'"}]}`)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/config/"):
+		io.WriteString(w, `{"apps":{"http":{}}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeTestCaddyfile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Caddyfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write Caddyfile: %v", err)
+	}
+	return path
+}
+
+func TestNewAdminClientAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		wantURL string
+		wantErr bool
+	}{
+		{"", "http://localhost:2019", false},
+		{"localhost:2019", "http://localhost:2019", false},
+		{"http://10.0.0.5:2019/", "http://10.0.0.5:2019", false},
+		{"unix//run/caddy/admin.sock", "http://127.0.0.1", false},
+		{"unix:///run/caddy/admin.sock", "http://127.0.0.1", false},
+		{"unix/", "", true},
+		{"localhost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			client, err := NewAdminClient(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAdminClient(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if err == nil && client.baseURL != tt.wantURL {
+				t.Errorf("baseURL = %q, want %q", client.baseURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestAdminClientValidateAndLoad(t *testing.T) {
+	fake := &fakeAdmin{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewAdminClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewAdminClient failed: %v", err)
+	}
+
+	valid := writeTestCaddyfile(t, "app.example.com {\n\treverse_proxy localhost:8080\n}\n")
+	if err := client.ValidateFile(valid); err != nil {
+		t.Errorf("ValidateFile(valid) = %v", err)
+	}
+	if err := client.LoadFile(valid); err != nil {
+		t.Errorf("LoadFile(valid) = %v", err)
+	}
+	if !strings.Contains(fake.loaded, "reverse_proxy localhost:8080") {
+		t.Errorf("Expected Caddyfile to be loaded, got %q", fake.loaded)
+	}
+
+	result, err := client.Adapt([]byte("app.example.com {\n}\n"))
+	if err != nil {
+		t.Fatalf("Adapt failed: %v", err)
+	}
+	if len(result.Config) == 0 || len(result.Warnings) != 1 || result.Warnings[0].Line != 1 {
+		t.Errorf("Unexpected adapt result: %+v", result)
+	}
+
+	config, err := client.Config("apps/http")
+	if err != nil || !strings.Contains(string(config), "apps") {
+		t.Errorf("Config() = %s, %v", config, err)
+	}
+}
+
+func TestAdminClientAbsoluteImports(t *testing.T) {
+	fake := &fakeAdmin{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewAdminClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTestCaddyfile(t, "(common) {\n\tencode gzip\n}\n"+
+		"# import notes.caddy\n"+
+		"import sites/*.caddy\n"+
+		"import /etc/caddy/global.caddy\n"+
+		"import {$CONF_DIR}/extra.caddy\n"+
+		"app.example.com {\n\timport common\n\timport \"headers dir/app.caddy\"\n\trespond import\n}\n")
+	dir := filepath.Dir(path)
+	want := "(common) {\n\tencode gzip\n}\n" +
+		"# import notes.caddy\n" +
+		"import " + filepath.Join(dir, "sites/*.caddy") + "\n" +
+		"import /etc/caddy/global.caddy\n" +
+		"import {$CONF_DIR}/extra.caddy\n" +
+		"app.example.com {\n\timport common\n\timport \"" + filepath.Join(dir, "headers dir/app.caddy") + "\"\n\trespond import\n}\n"
+	if err := client.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if fake.loaded != want {
+		t.Errorf("unexpected Caddyfile loaded:\n%s", fake.loaded)
+	}
+
+	// Caddy in a container resolves them in its own directory
+	SetContainerPath(path, "/etc/caddy/Caddyfile")
+	defer SetContainerPath(path, "")
+	if err := client.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(fake.loaded, "\nimport /etc/caddy/sites/*.caddy\n") {
+		t.Errorf("imports not resolved in the container directory:\n%s", fake.loaded)
+	}
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), dir) {
+		t.Error("the Caddyfile on disk was changed")
+	}
+}
+
+func TestAdminClientErrors(t *testing.T) {
+	fake := &fakeAdmin{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, _ := NewAdminClient(server.URL)
+	broken := writeTestCaddyfile(t, "app.example.com {\n\treverse_proxy localhost:8080\n")
+
+	err := client.ValidateFile(broken)
+	var adminErr *AdminError
+	if !errors.As(err, &adminErr) {
+		t.Fatalf("Expected AdminError, got %v", err)
+	}
+	if adminErr.StatusCode != http.StatusBadRequest || !strings.Contains(adminErr.Message, "Unexpected EOF") {
+		t.Errorf("Unexpected error: %+v", adminErr)
+	}
+
+	if err := client.LoadFile(broken); err == nil {
+		t.Error("Expected LoadFile to fail for invalid Caddyfile")
+	}
+	if fake.loaded != "" {
+		t.Error("Invalid Caddyfile should not be loaded")
+	}
+
+	if err := client.ValidateFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing Caddyfile")
+	}
+}
+
+func TestAdminClientUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "admin.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	fake := &fakeAdmin{}
+	server := httptest.NewUnstartedServer(fake)
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := NewAdminClient("unix/" + socketPath)
+	if err != nil {
+		t.Fatalf("NewAdminClient failed: %v", err)
+	}
+
+	valid := writeTestCaddyfile(t, "app.example.com {\n}\n")
+	if err := client.LoadFile(valid); err != nil {
+		t.Fatalf("LoadFile over unix socket failed: %v", err)
+	}
+	if len(fake.hosts) != 1 || fake.hosts[0] != "127.0.0.1" {
+		t.Errorf("Expected loopback Host header, got %v", fake.hosts)
+	}
+}
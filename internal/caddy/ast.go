@@ -0,0 +1,817 @@
+package caddy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind classifies a Caddyfile token
+type TokenKind int
+
+const (
+	TokenWord       TokenKind = iota // Bare word; may contain placeholders like {host}
+	TokenQuoted                      // "double quoted" string
+	TokenBacktick                    // `backtick` string
+	TokenHeredoc                     // <<MARKER ... MARKER
+	TokenOpenBrace                   // { opening a block
+	TokenCloseBrace                  // } closing a block
+	TokenComment                     // # to end of line
+	TokenNewline                     // Line break (significant: ends a directive)
+)
+
+// Token is a lexical token and its position in the source
+type Token struct {
+	Kind   TokenKind
+	Text   string // Exact source text, including quotes
+	Line   int    // Line of the first character (1-indexed)
+	Offset int    // Byte offset of the first character
+}
+
+// End returns the byte offset just past the token
+func (t Token) End() int {
+	return t.Offset + len(t.Text)
+}
+
+// EndLine returns the line of the token's last character
+func (t Token) EndLine() int {
+	return t.Line + strings.Count(t.Text, "\n")
+}
+
+// Value returns the token's value with quoting removed
+func (t Token) Value() string {
+	switch t.Kind {
+	case TokenQuoted:
+		inner := strings.TrimSuffix(strings.TrimPrefix(t.Text, `"`), `"`)
+		var b strings.Builder
+		for i := 0; i < len(inner); i++ {
+			// \" is the only escape; other backslashes are literal
+			if inner[i] == '\\' && i+1 < len(inner) && inner[i+1] == '"' {
+				i++
+			}
+			b.WriteByte(inner[i])
+		}
+		return b.String()
+	case TokenBacktick:
+		return strings.TrimSuffix(strings.TrimPrefix(t.Text, "`"), "`")
+	case TokenHeredoc:
+		// Body lines between the opening and closing marker lines, with the
+		// closing marker's indentation stripped from each line
+		first := strings.IndexByte(t.Text, '\n')
+		last := strings.LastIndexByte(t.Text, '\n')
+		if first < 0 || last <= first {
+			return ""
+		}
+		indent := t.Text[last+1:]
+		indent = indent[:len(indent)-len(strings.TrimLeft(indent, " \t"))]
+		lines := strings.Split(t.Text[first+1:last], "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, indent)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return t.Text
+}
+
+// Tokenize splits a Caddyfile into tokens. Whitespace other than newlines is
+// dropped; every other byte of the source belongs to exactly one token.
+// An unterminated quote or heredoc is returned as a token running to the end
+// of the input together with an error.
+func Tokenize(content string) ([]Token, error) {
+	var tokens []Token
+	var firstErr error
+	line := 1
+
+	emit := func(kind TokenKind, start, end int) {
+		tokens = append(tokens, Token{Kind: kind, Text: content[start:end], Line: line, Offset: start})
+		line += strings.Count(content[start:end], "\n")
+	}
+	fail := func(format string, args ...interface{}) {
+		if firstErr == nil {
+			firstErr = fmt.Errorf(format, args...)
+		}
+	}
+
+	for i := 0; i < len(content); {
+		ch := content[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+
+		case ch == '\n':
+			emit(TokenNewline, i, i+1)
+			i++
+
+		case ch == '#':
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			emit(TokenComment, i, i+end)
+			i += end
+
+		case ch == '"':
+			end := i + 1
+			for end < len(content) && content[end] != '"' {
+				if content[end] == '\\' && end+1 < len(content) {
+					end++
+				}
+				end++
+			}
+			if end >= len(content) {
+				fail("line %d: unterminated quoted string", line)
+				emit(TokenQuoted, i, len(content))
+				i = len(content)
+				continue
+			}
+			emit(TokenQuoted, i, end+1)
+			i = end + 1
+
+		case ch == '`':
+			end := strings.IndexByte(content[i+1:], '`')
+			if end < 0 {
+				fail("line %d: unterminated backtick string", line)
+				emit(TokenBacktick, i, len(content))
+				i = len(content)
+				continue
+			}
+			emit(TokenBacktick, i, i+end+2)
+			i += end + 2
+
+		default:
+			if end, ok, err := scanHeredoc(content, i); ok {
+				if err != nil {
+					fail("line %d: %v", line, err)
+				}
+				emit(TokenHeredoc, i, end)
+				i = end
+				continue
+			}
+
+			end := i
+			for end < len(content) && !strings.ContainsRune(" \t\r\n", rune(content[end])) {
+				end++
+			}
+			word := content[i:end]
+			switch {
+			case word == "{":
+				emit(TokenOpenBrace, i, end)
+			case word == "}":
+				emit(TokenCloseBrace, i, end)
+			case strings.HasSuffix(word, "{") && strings.Count(word, "{") > strings.Count(word, "}"):
+				// "example.com{" - a block opened without a space
+				emit(TokenWord, i, end-1)
+				emit(TokenOpenBrace, end-1, end)
+			default:
+				emit(TokenWord, i, end)
+			}
+			i = end
+		}
+	}
+
+	return tokens, firstErr
+}
+
+// scanHeredoc recognizes a heredoc starting at i ("<<MARKER" followed by a
+// newline). Returns the offset just past the closing marker and whether a
+// heredoc was found.
+func scanHeredoc(content string, i int) (int, bool, error) {
+	if !strings.HasPrefix(content[i:], "<<") {
+		return 0, false, nil
+	}
+	j := i + 2
+	for j < len(content) && isHeredocMarkerChar(content[j]) {
+		j++
+	}
+	marker := content[i+2 : j]
+	rest := strings.TrimLeft(content[j:], " \t\r")
+	if marker == "" || (rest != "" && rest[0] != '\n') {
+		return 0, false, nil
+	}
+
+	// The body ends at the first line starting with the marker
+	pos := j + strings.IndexByte(content[j:], '\n')
+	if pos < j {
+		return len(content), true, fmt.Errorf("unterminated heredoc <<%s", marker)
+	}
+	for pos < len(content) {
+		lineStart := pos + 1
+		lineEnd := strings.IndexByte(content[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += lineStart
+		}
+		// Arguments may follow the closing marker ("HTML 200")
+		text := strings.TrimLeft(content[lineStart:lineEnd], " \t")
+		if rest, ok := strings.CutPrefix(text, marker); ok && (rest == "" || strings.ContainsRune(" \t\r", rune(rest[0]))) {
+			return lineEnd - len(rest), true, nil
+		}
+		pos = lineEnd
+	}
+	return len(content), true, fmt.Errorf("unterminated heredoc <<%s", marker)
+}
+
+func isHeredocMarkerChar(ch byte) bool {
+	return ch == '_' || ch == '-' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// Directive is one directive inside a block: its name and arguments, plus a
+// nested block if it has one
+type Directive struct {
+	Tokens    []Token      // Name and argument tokens
+	Block     []*Directive // Nested directives; nil when the directive has no block
+	Comments  []string     // Comment lines directly above the directive
+	LineStart int
+	LineEnd   int
+}
+
+// Name returns the directive name (first token)
+func (d *Directive) Name() string {
+	if len(d.Tokens) == 0 {
+		return ""
+	}
+	return d.Tokens[0].Value()
+}
+
+// Args returns the directive's arguments with quoting removed
+func (d *Directive) Args() []string {
+	var args []string
+	for _, tok := range d.Tokens[min(1, len(d.Tokens)):] {
+		args = append(args, tok.Value())
+	}
+	return args
+}
+
+// HasBlock reports whether the directive opens a block
+func (d *Directive) HasBlock() bool {
+	return d.Block != nil
+}
+
+// WalkDirectives calls fn for every directive in the tree, parents before children
+func WalkDirectives(directives []*Directive, fn func(*Directive)) {
+	for _, d := range directives {
+		fn(d)
+		WalkDirectives(d.Block, fn)
+	}
+}
+
+// BlockKind identifies a top-level Caddyfile block
+type BlockKind int
+
+const (
+	BlockSite       BlockKind = iota // example.com { ... }
+	BlockGlobal                      // { ... } global options (first block only)
+	BlockSnippet                     // (name) { ... }
+	BlockNamedRoute                  // &(name) { ... }
+	BlockMatcher                     // @name { ... } at top level (kept verbatim)
+	BlockImport                      // import file-or-snippet
+	BlockOther                       // Anything else that isn't valid at top level
+)
+
+// Block is a top-level Caddyfile block. Leading + Comments + Raw is the exact
+// source text of the block and everything between it and the previous block.
+type Block struct {
+	Kind      BlockKind
+	Keys      []string     // Site addresses; snippet/route name without wrapper; matcher or import args
+	Body      []*Directive // Directives inside the braces
+	Leading   string       // Blank lines and detached comments before the block
+	Comments  string       // Comment lines directly above the block, plus the key line's indentation
+	Raw       string       // Source from the first key to the closing brace
+	LineStart int          // Line of the first key (1-indexed)
+	LineEnd   int          // Line of the closing brace
+
+	open, close int // Brace offsets within Raw; -1 for a block without braces
+}
+
+// HasMarker reports whether the block is preceded by a "# === domain ===" marker
+func (b *Block) HasMarker() bool {
+	lines := strings.Split(strings.TrimRight(b.Comments, " \t"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return isMarkerComment(lines[i])
+		}
+	}
+	return false
+}
+
+// Content returns the text between the block's braces, without the rest of
+// the opening line and the closing brace's line
+func (b *Block) Content() string {
+	if b.open < 0 || b.close <= b.open {
+		return ""
+	}
+	inner := b.Raw[b.open+1 : b.close]
+	first := strings.IndexByte(inner, '\n')
+	if first < 0 {
+		return strings.TrimSpace(inner)
+	}
+	inner = inner[first+1:]
+	last := strings.LastIndexByte(inner, '\n')
+	if last < 0 {
+		return ""
+	}
+	return inner[:last]
+}
+
+// isMarkerComment reports whether a line is a "# === name ===" marker
+func isMarkerComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "# ===") && strings.HasSuffix(line, "===")
+}
+
+// File is a parsed Caddyfile. String() reproduces the source byte for byte
+// until blocks are modified; edits only touch the text of the affected block.
+type File struct {
+	Blocks  []*Block
+	Trailer string // Text after the last block
+}
+
+// String serializes the file
+func (f *File) String() string {
+	var b strings.Builder
+	for _, block := range f.Blocks {
+		b.WriteString(block.Leading)
+		b.WriteString(block.Comments)
+		b.WriteString(block.Raw)
+	}
+	b.WriteString(f.Trailer)
+	return b.String()
+}
+
+// ParseAST parses a Caddyfile into blocks and directives.
+// The returned File is never nil: on a syntax error it still holds every byte
+// of the input (unparseable parts stay in the surrounding text) and the first
+// error is returned alongside it.
+func ParseAST(content string) (*File, error) {
+	tokens, err := Tokenize(content)
+	p := &parser{src: content, tokens: tokens, err: err}
+
+	f := &File{}
+	prevEnd := 0
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.tokens) {
+			break
+		}
+		if p.peek().Kind == TokenCloseBrace {
+			p.fail(p.peek().Line, "unexpected '}'")
+			p.pos++
+			continue
+		}
+
+		block, start, end := p.parseBlock(f)
+		block.Leading, block.Comments = splitLeading(content, prevEnd, start)
+		f.Blocks = append(f.Blocks, block)
+		prevEnd = end
+	}
+	f.Trailer = content[prevEnd:]
+
+	return f, p.err
+}
+
+// parser turns a token stream into blocks and directives
+type parser struct {
+	src    string
+	tokens []Token
+	pos    int
+	err    error
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) fail(line int, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+}
+
+// skipBlank skips newlines and comments between top-level blocks
+func (p *parser) skipBlank() {
+	for p.pos < len(p.tokens) {
+		kind := p.peek().Kind
+		if kind != TokenNewline && kind != TokenComment {
+			return
+		}
+		p.pos++
+	}
+}
+
+// parseBlock parses one top-level block. Returns the block and its source span.
+func (p *parser) parseBlock(f *File) (*Block, int, int) {
+	first := p.peek()
+	block := &Block{LineStart: first.Line, open: -1, close: -1}
+	start := first.Offset
+	end := first.End()
+
+	// Keys run to the opening brace or end of line; a trailing comma
+	// continues the address list on the next line
+	var keys []Token
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		if tok.Kind == TokenOpenBrace || tok.Kind == TokenCloseBrace || tok.Kind == TokenComment {
+			break
+		}
+		if tok.Kind == TokenNewline {
+			if len(keys) == 0 || !strings.HasSuffix(keys[len(keys)-1].Text, ",") {
+				break
+			}
+			p.pos++
+			continue
+		}
+		keys = append(keys, tok)
+		end = tok.End()
+		block.LineEnd = tok.EndLine()
+		p.pos++
+	}
+	block.Kind, block.Keys = classifyKeys(keys, len(f.Blocks) == 0)
+
+	if p.pos < len(p.tokens) && p.peek().Kind == TokenOpenBrace {
+		open := p.peek()
+		block.open = open.Offset - start
+		p.pos++
+		block.Body = p.parseDirectives(true)
+		if p.pos < len(p.tokens) && p.peek().Kind == TokenCloseBrace {
+			closeTok := p.peek()
+			block.close = closeTok.Offset - start
+			end = closeTok.End()
+			block.LineEnd = closeTok.Line
+			p.pos++
+		} else {
+			p.fail(open.Line, "unclosed block")
+			end, block.LineEnd = p.lastEnd(end, block.LineEnd)
+		}
+	} else if block.Kind == BlockSite && !hasSiteBlocks(f) {
+		// A single site may be written without braces; the rest of the file is its body
+		block.Body = p.parseDirectives(false)
+		end, block.LineEnd = p.lastEnd(end, block.LineEnd)
+	} else if block.Kind != BlockImport {
+		p.fail(first.Line, "expected '{' after %q", first.Text)
+		block.Kind = BlockOther
+	}
+
+	block.Raw = p.src[start:end]
+	return block, start, end
+}
+
+// lastEnd returns the end of the last consumed token that isn't a newline or comment
+func (p *parser) lastEnd(end, line int) (int, int) {
+	for i := p.pos - 1; i >= 0; i-- {
+		tok := p.tokens[i]
+		if tok.Kind != TokenNewline && tok.Kind != TokenComment {
+			if tok.End() > end {
+				return tok.End(), tok.EndLine()
+			}
+			break
+		}
+	}
+	return end, line
+}
+
+// hasSiteBlocks reports whether the file already has a block that isn't global options
+func hasSiteBlocks(f *File) bool {
+	for _, b := range f.Blocks {
+		if b.Kind != BlockGlobal {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyKeys determines the block kind from its key tokens
+func classifyKeys(keys []Token, first bool) (BlockKind, []string) {
+	if len(keys) == 0 {
+		if first {
+			return BlockGlobal, nil
+		}
+		return BlockOther, nil
+	}
+
+	name := keys[0].Value()
+	switch {
+	case name == "import":
+		var args []string
+		for _, tok := range keys[1:] {
+			args = append(args, tok.Value())
+		}
+		return BlockImport, args
+	case len(keys) == 1 && strings.HasPrefix(name, "(") && strings.HasSuffix(name, ")"):
+		return BlockSnippet, []string{strings.TrimSpace(name[1 : len(name)-1])}
+	case len(keys) == 1 && strings.HasPrefix(name, "&(") && strings.HasSuffix(name, ")"):
+		return BlockNamedRoute, []string{strings.TrimSpace(name[2 : len(name)-1])}
+	case strings.HasPrefix(name, "@"):
+		var args []string
+		for _, tok := range keys {
+			args = append(args, tok.Value())
+		}
+		return BlockMatcher, args
+	}
+
+	// Site addresses are separated by commas and/or whitespace
+	var addresses []string
+	for _, tok := range keys {
+		for _, addr := range strings.Split(tok.Value(), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return BlockSite, addresses
+}
+
+// parseDirectives parses directives until a closing brace (not consumed)
+// or, when inBlock is false, the end of input
+func (p *parser) parseDirectives(inBlock bool) []*Directive {
+	directives := []*Directive{}
+	var comments []string
+
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		switch tok.Kind {
+		case TokenNewline:
+			p.pos++
+		case TokenComment:
+			comments = append(comments, tok.Text)
+			p.pos++
+		case TokenCloseBrace:
+			if inBlock {
+				return directives
+			}
+			p.fail(tok.Line, "unexpected '}'")
+			p.pos++
+		default:
+			d := p.parseDirective()
+			d.Comments = comments
+			comments = nil
+			directives = append(directives, d)
+		}
+	}
+	return directives
+}
+
+// parseDirective parses tokens up to the end of the line, and the nested
+// block if the line opens one
+func (p *parser) parseDirective() *Directive {
+	d := &Directive{LineStart: p.peek().Line, LineEnd: p.peek().Line}
+
+	for p.pos < len(p.tokens) {
+		tok := p.peek()
+		switch tok.Kind {
+		case TokenNewline, TokenCloseBrace:
+			return d
+		case TokenComment:
+			p.pos++
+		case TokenOpenBrace:
+			p.pos++
+			d.Block = p.parseDirectives(true)
+			if p.pos < len(p.tokens) {
+				d.LineEnd = p.peek().Line
+				p.pos++ // Closing brace
+			} else {
+				p.fail(tok.Line, "unclosed block")
+			}
+			return d
+		default:
+			d.Tokens = append(d.Tokens, tok)
+			d.LineEnd = tok.EndLine()
+			p.pos++
+		}
+	}
+	return d
+}
+
+// splitLeading splits the text between two blocks into detached text and the
+// comment lines attached to the next block (no blank line in between, up to
+// and including a "# === domain ===" marker)
+func splitLeading(src string, prevEnd, start int) (string, string) {
+	between := src[prevEnd:start]
+
+	// Start of the key's line; text after the previous block's closing brace
+	// on the same line never belongs to the next block
+	lineStart := strings.LastIndexByte(between, '\n') + 1
+	if lineStart == 0 && prevEnd > 0 {
+		return between, ""
+	}
+
+	attached := lineStart
+	for attached > 0 {
+		// between[:attached] ends with '\n'; look at the line before it
+		prevLineStart := strings.LastIndexByte(between[:attached-1], '\n') + 1
+		if prevLineStart == 0 && prevEnd > 0 {
+			break // Rest of the previous block's closing line
+		}
+		line := between[prevLineStart : attached-1]
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		attached = prevLineStart
+		if isMarkerComment(line) {
+			break // A marker starts the entry; comments above it belong to the file
+		}
+	}
+	return between[:attached], between[attached:]
+}
+
+// parseSingleBlock parses text that must contain exactly one block
+func parseSingleBlock(text string) (*File, error) {
+	f, err := ParseAST(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Blocks) != 1 {
+		return nil, fmt.Errorf("expected exactly one block, found %d", len(f.Blocks))
+	}
+	return f, nil
+}
+
+// siteHost returns a site address without scheme and port, lowercased
+func siteHost(address string) string {
+	host := strings.ToLower(address)
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexByte(host, '/'); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return host
+}
+
+// FindSite returns the site block serving an address. An exact address match
+// wins; otherwise scheme, port and case are ignored.
+func (f *File) FindSite(address string) *Block {
+	for _, b := range f.Blocks {
+		if b.Kind != BlockSite {
+			continue
+		}
+		for _, key := range b.Keys {
+			if key == address {
+				return b
+			}
+		}
+	}
+	host := siteHost(address)
+	for _, b := range f.Blocks {
+		if b.Kind != BlockSite {
+			continue
+		}
+		for _, key := range b.Keys {
+			if siteHost(key) == host {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// FindSnippet returns the snippet block with the given name
+func (f *File) FindSnippet(name string) *Block {
+	for _, b := range f.Blocks {
+		if b.Kind == BlockSnippet && len(b.Keys) > 0 && b.Keys[0] == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Append adds the blocks in text to the end of the file, separated from the
+// existing content by a blank line
+func (f *File) Append(text string) error {
+	added, err := ParseAST(text)
+	if err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+
+	separator := "\n"
+	if current := f.String(); current != "" && !strings.HasSuffix(current, "\n") {
+		separator = "\n\n"
+	}
+	if len(added.Blocks) == 0 {
+		f.Trailer += separator + added.Trailer
+		return nil
+	}
+	added.Blocks[0].Leading = f.Trailer + separator + added.Blocks[0].Leading
+	f.Blocks = append(f.Blocks, added.Blocks...)
+	f.Trailer = added.Trailer
+	return nil
+}
+
+// Remove deletes a block together with its attached comments. Detached
+// comments before it are kept; surplus blank lines are dropped.
+func (f *File) Remove(block *Block) {
+	idx := f.index(block)
+	if idx < 0 {
+		return
+	}
+
+	// Keep detached comments (e.g. a section header) but not the whitespace
+	// that separated the block from its predecessor
+	kept := ""
+	if strings.TrimSpace(block.Leading) != "" {
+		kept = strings.TrimRight(block.Leading, " \t\r\n")
+	}
+
+	f.Blocks = append(f.Blocks[:idx], f.Blocks[idx+1:]...)
+	if idx < len(f.Blocks) {
+		next := f.Blocks[idx]
+		if idx == 0 && kept == "" {
+			next.Leading = strings.TrimLeft(next.Leading, "\r\n")
+		}
+		if kept != "" && !strings.HasPrefix(strings.TrimLeft(next.Leading, "\r"), "\n") {
+			kept += "\n" // Don't let a kept comment swallow the next block
+		}
+		next.Leading = kept + next.Leading
+	} else {
+		f.Trailer = kept + f.Trailer
+		if len(f.Blocks) == 0 && kept == "" {
+			f.Trailer = strings.TrimLeft(f.Trailer, "\r\n")
+		}
+	}
+}
+
+// Replace swaps a block for the single block in text, keeping its position
+// and attached comments. A "# === domain ===" marker in text replaces the
+// block's existing marker.
+func (f *File) Replace(block *Block, text string) error {
+	idx := f.index(block)
+	if idx < 0 {
+		return fmt.Errorf("block not found")
+	}
+	parsed, err := parseSingleBlock(text)
+	if err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+
+	// The new key line's indentation, and comments the new text brings along
+	replacement := parsed.Blocks[0]
+	added := strings.TrimLeft(replacement.Leading, "\r\n") + replacement.Comments
+	split := strings.LastIndexByte(added, '\n') + 1
+	addedComments, indent := added[:split], added[split:]
+
+	// Keep the old comments; a new marker takes the place of the old one
+	oldComments := block.Comments[:strings.LastIndexByte(block.Comments, '\n')+1]
+	var comments strings.Builder
+	placed := false
+	for _, line := range strings.SplitAfter(oldComments, "\n") {
+		if replacement.HasMarker() && isMarkerComment(line) {
+			if !placed {
+				comments.WriteString(addedComments)
+				placed = true
+			}
+			continue
+		}
+		comments.WriteString(line)
+	}
+	if !placed {
+		comments.WriteString(addedComments)
+	}
+
+	replacement.Leading = block.Leading
+	replacement.Comments = comments.String() + indent
+	f.Blocks[idx] = replacement
+	return nil
+}
+
+// SetContent replaces the text between a block's braces, keeping the opening
+// and closing lines. content is written as-is (callers supply indentation).
+func (f *File) SetContent(block *Block, content string) error {
+	idx := f.index(block)
+	if idx < 0 {
+		return fmt.Errorf("block not found")
+	}
+	if block.open < 0 || block.close < 0 {
+		return fmt.Errorf("block has no braces")
+	}
+
+	// Keep the rest of the opening line and the closing brace's line
+	raw := block.Raw
+	inner := raw[block.open+1 : block.close]
+	head, tail := raw[:block.open+1]+"\n", raw[block.close:]
+	if first := strings.IndexByte(inner, '\n'); first >= 0 {
+		head = raw[:block.open+1+first+1]
+		tail = raw[block.open+1+strings.LastIndexByte(inner, '\n')+1:]
+	}
+
+	parsed, err := parseSingleBlock(head + content + "\n" + tail)
+	if err != nil {
+		return fmt.Errorf("invalid content: %w", err)
+	}
+	updated := parsed.Blocks[0]
+	updated.Leading = block.Leading
+	updated.Comments = block.Comments
+	f.Blocks[idx] = updated
+	return nil
+}
+
+// index returns the position of block in the file, or -1
+func (f *File) index(block *Block) int {
+	for i, b := range f.Blocks {
+		if b == block {
+			return i
+		}
+	}
+	return -1
+}
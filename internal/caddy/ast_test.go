@@ -0,0 +1,491 @@
+package caddy
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata golden files")
+
+// corpusFiles returns the real-world Caddyfiles under testdata
+func corpusFiles(t testing.TB) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "caddyfiles", "*.Caddyfile"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no Caddyfiles in testdata (err: %v)", err)
+	}
+	return files
+}
+
+// describeFile renders the parse result in a stable text form for golden files
+func describeFile(file *File) string {
+	var b strings.Builder
+	kinds := map[BlockKind]string{
+		BlockSite: "site", BlockGlobal: "global", BlockSnippet: "snippet", BlockNamedRoute: "route",
+		BlockMatcher: "matcher", BlockImport: "import", BlockOther: "other",
+	}
+	for _, block := range file.Blocks {
+		fmt.Fprintf(&b, "%s %q lines %d-%d", kinds[block.Kind], block.Keys, block.LineStart, block.LineEnd)
+		if block.HasMarker() {
+			b.WriteString(" marker")
+		}
+		b.WriteString("\n")
+		describeDirectives(&b, block.Body, "\t")
+	}
+
+	parsed := parsedFromAST(file)
+	for _, e := range parsed.Entries {
+		fmt.Fprintf(&b, "entry %s domains=%q target=%s port=%d ssl=%t ip_restricted=%t oauth=%t websocket=%t imports=%q",
+			e.Domain, e.Domains, e.Target, e.Port, e.SSL, e.IPRestricted, e.OAuthHeaders, e.WebSocket, e.Imports)
+		if e.IsHostRoute() {
+			fmt.Fprintf(&b, " parent=%s matcher=%s lines %d-%d", e.ParentSite, e.Matcher, e.LineStart, e.LineEnd)
+			if e.HasMarker {
+				b.WriteString(" marker")
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, s := range parsed.Snippets {
+		fmt.Fprintf(&b, "snippet %s category=%q content=%q\n", s.Name, s.Category, s.Content)
+	}
+	return b.String()
+}
+
+func describeDirectives(b *strings.Builder, directives []*Directive, indent string) {
+	for _, d := range directives {
+		fmt.Fprintf(b, "%s%q lines %d-%d", indent, append([]string{d.Name()}, d.Args()...), d.LineStart, d.LineEnd)
+		if len(d.Comments) > 0 {
+			fmt.Fprintf(b, " comments=%q", d.Comments)
+		}
+		b.WriteString("\n")
+		describeDirectives(b, d.Block, indent+"\t")
+	}
+}
+
+func TestGoldenCorpus(t *testing.T) {
+	for _, path := range corpusFiles(t) {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := ParseAST(string(content))
+			if err != nil {
+				t.Fatalf("ParseAST failed: %v", err)
+			}
+			if got := file.String(); got != string(content) {
+				t.Fatalf("round trip mismatch:\n%s", got)
+			}
+
+			got := describeFile(file)
+			goldenPath := strings.TrimSuffix(path, ".Caddyfile") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("missing golden file (run with -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("parse result differs from %s:\n%s", goldenPath, got)
+			}
+		})
+	}
+}
+
+func TestParseASTErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"unclosed block", "a.example.com {\n\treverse_proxy x:1\n", "line 1: unclosed block"},
+		{"stray close", "a.example.com {\n}\n}\n", "line 3: unexpected '}'"},
+		{"unterminated quote", "a.example.com {\n\trespond \"oops\n}\n", "line 2: unterminated quoted string"},
+		{"unterminated heredoc", "a.example.com {\n\trespond <<EOF\n\thello\n}\n", "line 2: unterminated heredoc <<EOF"},
+		{"missing brace", "a.example.com {\n}\nb.example.com\n\treverse_proxy x:1\n", "line 3: expected '{'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseAST(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			// Nothing is lost even when the input doesn't parse
+			if file.String() != tt.input {
+				t.Errorf("round trip mismatch: %q", file.String())
+			}
+		})
+	}
+}
+
+func TestParseASTLineEndings(t *testing.T) {
+	input := "# === a.example.com ===\r\na.example.com {\r\n\treverse_proxy 10.0.0.1:80\r\n}\r\n"
+	file, err := ParseAST(input)
+	if err != nil {
+		t.Fatalf("ParseAST failed: %v", err)
+	}
+	if file.String() != input {
+		t.Errorf("CRLF round trip mismatch: %q", file.String())
+	}
+	if len(file.Blocks) != 1 || !file.Blocks[0].HasMarker() || file.Blocks[0].Body[0].Args()[0] != "10.0.0.1:80" {
+		t.Errorf("unexpected parse: %s", describeFile(file))
+	}
+}
+
+func TestTokenValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`plain`, "plain"},
+		{`"quoted \"value\""`, `quoted "value"`},
+		{`"C:\path"`, `C:\path`},
+		{"`raw \"json\"`", `raw "json"`},
+		{"<<EOF\n\t\tline one\n\t\t  line two\n\t\tEOF", "line one\n  line two"},
+	}
+
+	for _, tt := range tests {
+		tokens, err := Tokenize(tt.input)
+		if err != nil || len(tokens) != 1 {
+			t.Fatalf("Tokenize(%q) = %v, %v", tt.input, tokens, err)
+		}
+		if got := tokens[0].Value(); got != tt.want {
+			t.Errorf("Value(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+const editFixture = `{
+	email admin@example.com
+}
+
+# Media services
+# === plex.example.com ===
+plex.example.com {
+	reverse_proxy http://10.0.28.9:32400
+}
+
+# === app.example.com ===
+# Keep this comment
+app.example.com, www.example.com {
+	reverse_proxy http://10.0.28.5:8080 {
+		header_up Upgrade {http.request.header.Upgrade}
+	}
+}
+
+# === last.example.com ===
+last.example.com {
+	respond "bye"
+}
+`
+
+func mustParse(t *testing.T, content string) *File {
+	t.Helper()
+	file, err := ParseAST(content)
+	if err != nil {
+		t.Fatalf("ParseAST failed: %v", err)
+	}
+	return file
+}
+
+func TestFileRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		want   string
+	}{
+		{
+			// Detached section comment stays, attached marker goes
+			name:   "middle with section comment",
+			domain: "plex.example.com",
+			want: `{
+	email admin@example.com
+}
+
+# Media services
+
+# === app.example.com ===
+# Keep this comment
+app.example.com, www.example.com {
+	reverse_proxy http://10.0.28.5:8080 {
+		header_up Upgrade {http.request.header.Upgrade}
+	}
+}
+
+# === last.example.com ===
+last.example.com {
+	respond "bye"
+}
+`,
+		},
+		{
+			name:   "secondary address",
+			domain: "www.example.com",
+			want: `{
+	email admin@example.com
+}
+
+# Media services
+# === plex.example.com ===
+plex.example.com {
+	reverse_proxy http://10.0.28.9:32400
+}
+
+# === last.example.com ===
+last.example.com {
+	respond "bye"
+}
+`,
+		},
+		{
+			name:   "last block",
+			domain: "last.example.com",
+			want: `{
+	email admin@example.com
+}
+
+# Media services
+# === plex.example.com ===
+plex.example.com {
+	reverse_proxy http://10.0.28.9:32400
+}
+
+# === app.example.com ===
+# Keep this comment
+app.example.com, www.example.com {
+	reverse_proxy http://10.0.28.5:8080 {
+		header_up Upgrade {http.request.header.Upgrade}
+	}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := mustParse(t, editFixture)
+			site := file.FindSite(tt.domain)
+			if site == nil {
+				t.Fatalf("FindSite(%q) = nil", tt.domain)
+			}
+			file.Remove(site)
+			if got := file.String(); got != tt.want {
+				t.Errorf("Remove(%s) =\n%s\nwant:\n%s", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileRemoveFirstBlock(t *testing.T) {
+	file := mustParse(t, "# === a.example.com ===\na.example.com {\n}\n\nb.example.com {\n}\n")
+	file.Remove(file.Blocks[0])
+	if got := file.String(); got != "b.example.com {\n}\n" {
+		t.Errorf("got %q", got)
+	}
+	file.Remove(file.Blocks[0])
+	if got := file.String(); got != "" {
+		t.Errorf("got %q after removing every block", got)
+	}
+}
+
+func TestFileReplace(t *testing.T) {
+	file := mustParse(t, editFixture)
+	site := file.FindSite("app.example.com")
+	block := "# === app.example.com ===\napp.example.com {\n\treverse_proxy http://10.0.28.6:9090\n}\n"
+	if err := file.Replace(site, block); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+
+	want := strings.Replace(editFixture, `# === app.example.com ===
+# Keep this comment
+app.example.com, www.example.com {
+	reverse_proxy http://10.0.28.5:8080 {
+		header_up Upgrade {http.request.header.Upgrade}
+	}
+}`, `# === app.example.com ===
+# Keep this comment
+app.example.com {
+	reverse_proxy http://10.0.28.6:9090
+}`, 1)
+	if got := file.String(); got != want {
+		t.Errorf("Replace =\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := file.Replace(file.FindSite("plex.example.com"), "broken {\n"); err == nil {
+		t.Error("expected error for an unbalanced replacement")
+	}
+}
+
+func TestFileAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"trailing newline", "a.example.com {\n}\n", "a.example.com {\n}\n\n# === b.example.com ===\nb.example.com {\n}\n"},
+		{"no trailing newline", "a.example.com {\n}", "a.example.com {\n}\n\n# === b.example.com ===\nb.example.com {\n}\n"},
+		{"empty file", "", "\n# === b.example.com ===\nb.example.com {\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := mustParse(t, tt.content)
+			if err := file.Append("# === b.example.com ===\nb.example.com {\n}\n"); err != nil {
+				t.Fatalf("Append failed: %v", err)
+			}
+			if got := file.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if reparsed := mustParse(t, file.String()); reparsed.FindSite("b.example.com") == nil {
+				t.Error("appended site not found after reparse")
+			}
+		})
+	}
+
+	file := mustParse(t, "a.example.com {\n}\n")
+	if err := file.Append("b.example.com {\n"); err == nil {
+		t.Error("expected error appending an unbalanced block")
+	}
+	if file.String() != "a.example.com {\n}\n" {
+		t.Error("failed append should leave the file untouched")
+	}
+}
+
+func TestFileSetContent(t *testing.T) {
+	file := mustParse(t, "(headers) {\n\theader X-Old 1\n}\n\n(empty) { }\n")
+
+	if err := file.SetContent(file.FindSnippet("headers"), "\theader X-New 2\n\theader X-Other 3"); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	if err := file.SetContent(file.FindSnippet("empty"), "\trespond 204"); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+
+	want := "(headers) {\n\theader X-New 2\n\theader X-Other 3\n}\n\n(empty) {\n\trespond 204\n}\n"
+	if got := file.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := file.FindSnippet("headers").Content(); got != "\theader X-New 2\n\theader X-Other 3" {
+		t.Errorf("Content() = %q", got)
+	}
+
+	if err := file.SetContent(file.FindSnippet("headers"), "\theader {"); err == nil {
+		t.Error("expected error for unbalanced content")
+	}
+}
+
+func TestFindSite(t *testing.T) {
+	file := mustParse(t, "https://a.example.com:443 {\n}\n\nB.example.com:8080 {\n}\n\nb.example.com {\n}\n")
+
+	tests := []struct {
+		address  string
+		wantLine int
+	}{
+		{"https://a.example.com:443", 1},
+		{"a.example.com", 1},
+		{"b.example.com", 7}, // Exact match beats host match
+		{"b.example.com:8080", 4},
+		{"c.example.com", 0},
+	}
+	for _, tt := range tests {
+		site := file.FindSite(tt.address)
+		switch {
+		case tt.wantLine == 0 && site != nil:
+			t.Errorf("FindSite(%q) found line %d, want none", tt.address, site.LineStart)
+		case tt.wantLine != 0 && (site == nil || site.LineStart != tt.wantLine):
+			t.Errorf("FindSite(%q) = %v, want line %d", tt.address, site, tt.wantLine)
+		}
+	}
+}
+
+func TestRemoveEntryFile(t *testing.T) {
+	path := writeTestCaddyfile(t, editFixture)
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveEntry(path, "plex.example.com"); err != nil {
+		t.Fatalf("RemoveEntry failed: %v", err)
+	}
+	if err := RemoveEntry(path, "missing.example.com"); err == nil {
+		t.Error("expected error removing a missing site")
+	}
+	if err := ReplaceEntry(path, "last.example.com", "last.example.com {\n\trespond \"hi\"\n}\n"); err != nil {
+		t.Fatalf("ReplaceEntry failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	entries, err := ParseCaddyfile(string(content))
+	if err != nil {
+		t.Fatalf("ParseCaddyfile failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Domain != "app.example.com" || entries[1].Domain != "last.example.com" {
+		t.Errorf("unexpected entries after edits: %+v", entries)
+	}
+	if !strings.Contains(string(content), "# Media services\n") || !strings.Contains(string(content), `respond "hi"`) {
+		t.Errorf("unexpected content:\n%s", content)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("permissions changed to %v", info.Mode().Perm())
+	}
+}
+
+func FuzzParseAST(f *testing.F) {
+	for _, path := range corpusFiles(f) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(content))
+	}
+	f.Add(editFixture)
+	f.Add(wildcardFixture)
+	f.Add("a {\n} b {\n}")
+	f.Add("respond <<EOF\nx\nEOF")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		file, err := ParseAST(content)
+		if got := file.String(); got != content {
+			t.Fatalf("round trip mismatch:\ninput: %q\noutput: %q", content, got)
+		}
+		if err != nil {
+			return
+		}
+
+		// Removing any block leaves a file that still parses
+		for i := range file.Blocks {
+			edited, _ := ParseAST(content)
+			edited.Remove(edited.Blocks[i])
+			reparsed, err := ParseAST(edited.String())
+			if err != nil {
+				t.Fatalf("removing block %d broke the file: %v\ninput: %q\noutput: %q", i, err, content, edited.String())
+			}
+			if len(reparsed.Blocks) != len(file.Blocks)-1 {
+				t.Fatalf("removing block %d left %d blocks, want %d\ninput: %q", i, len(reparsed.Blocks), len(file.Blocks)-1, content)
+			}
+		}
+
+		// Removing any host route leaves a file that still parses
+		for _, entry := range parsedFromAST(file).Entries {
+			if !entry.IsHostRoute() {
+				continue
+			}
+			edited, _ := ParseAST(content)
+			if edited.RemoveHostRoute(entry.Domain) != nil {
+				continue
+			}
+			if _, err := ParseAST(edited.String()); err != nil {
+				t.Fatalf("removing route %s broke the file: %v\ninput: %q\noutput: %q", entry.Domain, err, content, edited.String())
+			}
+		}
+	})
+}
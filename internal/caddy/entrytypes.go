@@ -0,0 +1,84 @@
+package caddy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RedirectCodes are the status codes a redirect entry may use
+var RedirectCodes = []int{301, 302, 303, 307, 308}
+
+// redirectCodeArg returns the redir code argument: the permanent and
+// temporary keywords for 301 and 302, the number otherwise
+func redirectCodeArg(code int) string {
+	switch code {
+	case 0, 302:
+		return "temporary"
+	case 301:
+		return "permanent"
+	default:
+		return strconv.Itoa(code)
+	}
+}
+
+// writeRedirect writes the redir directive of a redirect entry. redir runs
+// before respond, so a LAN restriction wouldn't stop it and none is written.
+// {uri} starts with a slash, so the destination's trailing one is dropped.
+func writeRedirect(b *strings.Builder, input GenerateBlockInput) {
+	to := input.RedirectTo
+	if input.RedirectPreservePath {
+		to = strings.TrimSuffix(to, "/") + "{uri}"
+	}
+	b.WriteString(fmt.Sprintf("\tredir %s %s\n", to, redirectCodeArg(input.RedirectCode)))
+}
+
+// writeStatic writes the root and file_server directives of a static site
+func writeStatic(b *strings.Builder, input GenerateBlockInput) {
+	b.WriteString(fmt.Sprintf("\troot * %s\n", input.StaticRoot))
+	if input.StaticBrowse {
+		b.WriteString("\tfile_server browse\n")
+	} else {
+		b.WriteString("\tfile_server\n")
+	}
+}
+
+// parseRedirect fills the redirect fields from the arguments of a redir
+// directive; false if the redir has a matcher and so only covers some paths
+func parseRedirect(entry *CaddyEntry, args []string) bool {
+	if len(args) == 0 || len(args) > 2 || strings.HasPrefix(args[0], "@") || args[0] == "*" ||
+		(len(args) == 2 && strings.HasPrefix(args[0], "/")) {
+		return false
+	}
+	entry.RedirectTo, entry.RedirectPreservePath = strings.CutSuffix(args[0], "{uri}")
+	entry.RedirectCode = 302
+	if len(args) == 2 {
+		switch args[1] {
+		case "permanent":
+			entry.RedirectCode = 301
+		case "temporary":
+		default:
+			if code, err := strconv.Atoi(args[1]); err == nil {
+				entry.RedirectCode = code
+			}
+		}
+	}
+	return true
+}
+
+// parseStaticRoot returns the directory of a root directive, skipping a
+// leading matcher
+func parseStaticRoot(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[len(args)-1]
+}
+
+// fileServerBrowses reports whether a file_server directive lists
+// directories, as "file_server browse" or with a browse subdirective
+func fileServerBrowses(d *Directive) bool {
+	return slices.Contains(d.Args(), "browse") ||
+		slices.ContainsFunc(d.Block, func(sub *Directive) bool { return sub.Name() == "browse" })
+}
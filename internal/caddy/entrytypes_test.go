@@ -0,0 +1,83 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedirectRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		input GenerateBlockInput
+		want  string
+	}{
+		{GenerateBlockInput{RedirectTo: "https://example.com", RedirectCode: 301, RedirectPreservePath: true}, "\tredir https://example.com{uri} permanent\n"},
+		{GenerateBlockInput{RedirectTo: "https://new.example.com/landing", RedirectCode: 302}, "\tredir https://new.example.com/landing temporary\n"},
+		{GenerateBlockInput{RedirectTo: "https://example.com/", RedirectCode: 308, RedirectPreservePath: true}, "\tredir https://example.com{uri} 308\n"},
+	} {
+		input := tt.input
+		input.Type, input.FQDN = EntryRedirect, "www.example.com"
+		input.LANOnly, input.LANSubnet = true, "10.0.0.0/8"
+		block := GenerateCaddyBlock(input)
+		if !strings.HasSuffix(block, "www.example.com {\n"+tt.want+"}\n") {
+			t.Errorf("unexpected block:\n%s", block)
+			continue
+		}
+
+		entries, err := ParseCaddyfile(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := entries[0]
+		if entry.Type != EntryRedirect || entry.RedirectTo != strings.TrimSuffix(input.RedirectTo, "/") || entry.RedirectCode != input.RedirectCode ||
+			entry.RedirectPreservePath != input.RedirectPreservePath {
+			t.Errorf("redirect not parsed back from %q: %+v", tt.want, entry)
+		}
+	}
+
+	// A redir covering only some paths doesn't make a redirect entry
+	entries, _ := ParseCaddyfile("app.example.com {\n\tredir /old /new\n\tredir @legacy https://example.com\n\trespond ok\n}\n")
+	if entries[0].Type != EntryProxy || entries[0].RedirectTo != "" {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+	entries, _ = ParseCaddyfile("app.example.com {\n\thandle /old {\n\t\tredir /new\n\t}\n\thandle {\n\t\trespond ok\n\t}\n}\n")
+	if entries[0].Type == EntryRedirect || entries[0].RedirectTo != "" {
+		t.Errorf("a redir nested in a handle block made a redirect entry: %+v", entries[0])
+	}
+	entries, _ = ParseCaddyfile("old.example.com {\n\tredir https://new.example.com{uri}\n}\n")
+	if entry := entries[0]; entry.Type != EntryRedirect || entry.RedirectCode != 302 || entry.Destination() != "https://new.example.com{uri}" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestStaticSiteRoundTrip(t *testing.T) {
+	input := GenerateBlockInput{FQDN: "docs.example.com", Type: EntryStatic, StaticRoot: "/srv/docs", StaticBrowse: true,
+		SelectedSnippets: []string{"security_headers"}}
+	block := GenerateCaddyBlock(input)
+	if want := "\timport security_headers\n\n\troot * /srv/docs\n\tfile_server browse\n}\n"; !strings.HasSuffix(block, want) {
+		t.Errorf("unexpected block:\n%s", block)
+	}
+	entries, err := ParseCaddyfile(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := entries[0]; entry.Type != EntryStatic || entry.StaticRoot != "/srv/docs" || !entry.StaticBrowse || entry.Destination() != "/srv/docs" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	// The LAN restriction applies: file_server runs after respond
+	input.LANOnly, input.LANSubnet, input.StaticBrowse = true, "10.0.0.0/8", false
+	block = GenerateCaddyBlock(input)
+	if !strings.Contains(block, "\trespond @external 404\n\n\troot * /srv/docs\n\tfile_server\n") {
+		t.Errorf("unexpected restricted block:\n%s", block)
+	}
+
+	// A reverse proxy serving some files itself stays a reverse proxy
+	entries, _ = ParseCaddyfile("app.example.com {\n\thandle /assets/* {\n\t\troot * /srv\n\t\tfile_server {\n\t\t\tbrowse\n\t\t}\n\t}\n\treverse_proxy app:80\n}\n")
+	if entry := entries[0]; entry.Type != EntryProxy || entry.Target != "app" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	entries, _ = ParseCaddyfile("files.example.com {\n\troot /srv/files\n\tfile_server {\n\t\tbrowse\n\t}\n}\n")
+	if entry := entries[0]; entry.Type != EntryStatic || entry.StaticRoot != "/srv/files" || !entry.StaticBrowse {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
@@ -2,6 +2,7 @@ package caddy
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -13,6 +14,20 @@ var proxyLevelSnippetNames = map[string]bool{
 	// Add other known proxy-level snippets here as needed
 }
 
+// Map of known snippet names that respond to requests themselves (e.g. with
+// 404 for external clients). Handle blocks run before such directives, so in
+// a site with path routes they are imported inside each handle block.
+var handlerSnippetNames = map[string]bool{
+	"ip_restricted": true,
+	"cors_headers":  true,
+	"rate_limiting": true,
+}
+
+// isHandlerSnippet checks if a given snippet name should be imported inside each handle block of a site with path routes.
+func isHandlerSnippet(snippetName string) bool {
+	return handlerSnippetNames[snippetName]
+}
+
 // isProxyLevelSnippet checks if a given snippet name should be imported inside a reverse_proxy block.
 func isProxyLevelSnippet(snippetName string) bool {
 	return proxyLevelSnippetNames[snippetName]
@@ -20,19 +35,30 @@ func isProxyLevelSnippet(snippetName string) bool {
 
 // GenerateBlockInput contains all parameters needed to generate a Caddy block
 type GenerateBlockInput struct {
-	FQDN              string   // DEPRECATED: Use Domains instead. Kept for backwards compatibility
-	Domains           []string // Multiple FQDNs for this entry (e.g., ["app.example.com", "api.example.com"])
-	Target            string   // Reverse proxy target (IP or hostname)
-	Port              int      // Service port
-	SSL               bool     // Use https:// vs http://
-	LANOnly           bool     // Restrict to LAN subnet
-	OAuth             bool     // Include OAuth headers
-	WebSocket         bool     // Include WebSocket headers
-	LANSubnet         string   // LAN subnet for IP restriction (e.g., "10.0.28.0/24")
-	AllowedExtIP      string   // Allowed external IP (e.g., "166.1.123.74/32")
-	AvailableSnippets []string // List of available snippet names from Caddyfile
-	SelectedSnippets  []string // List of snippet names to import
-	CustomCaddyConfig string   // Custom Caddy directives for one-off features
+	FQDN              string        // DEPRECATED: Use Domains instead. Kept for backwards compatibility
+	Domains           []string      // Multiple FQDNs for this entry (e.g., ["app.example.com", "api.example.com"])
+	Target            string        // Reverse proxy target (IP or hostname)
+	Port              int           // Service port
+	SSL               bool          // Use https:// vs http://
+	LANOnly           bool          // Restrict to LAN subnet
+	OAuth             bool          // Include OAuth headers
+	WebSocket         bool          // Include WebSocket headers
+	LANSubnet         string        // LAN subnet for IP restriction (e.g., "10.0.28.0/24")
+	AllowedExtIP      string        // Allowed external IP (e.g., "166.1.123.74/32")
+	AvailableSnippets []string      // List of available snippet names from Caddyfile
+	SelectedSnippets  []string      // List of snippet names to import
+	CustomCaddyConfig string        // Custom Caddy directives for one-off features
+	Routes            []PathRoute   // Services under a path, served before Target
+	Upstreams         []string      // Further upstreams (host or host:port) load balanced with Target
+	LoadBalancing     LoadBalancing // Load balancing policy and health checks
+	ProxyOptions      []string      // Other reverse_proxy subdirectives, written as given
+
+	Type                 EntryType // Reverse proxy (default), redirect or static site
+	RedirectTo           string    // Redirect destination URL
+	RedirectCode         int       // Redirect status code (0 means 302)
+	RedirectPreservePath bool      // Append the request's path and query to RedirectTo
+	StaticRoot           string    // Directory served by a static site
+	StaticBrowse         bool      // List directories without an index file
 }
 
 // GenerateCaddyBlock generates a Caddy configuration block from input parameters
@@ -58,6 +84,41 @@ func GenerateCaddyBlock(input GenerateBlockInput) string {
 	// Generate comma-separated domain list for Caddy block
 	domainList := strings.Join(domains, ", ")
 
+	// Header marker comment (uses primary domain)
+	b.WriteString(fmt.Sprintf("# === %s ===\n", primaryDomain))
+
+	// Domain block (comma-separated if multiple domains)
+	b.WriteString(fmt.Sprintf("%s {\n", domainList))
+	b.WriteString(GenerateRouteBody(input))
+
+	// Close block
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// GenerateHostRoute generates a host route for a wildcard site block: a host
+// matcher for the domains and a handle block with the same body as a site
+// block. The matcher name shown here may get a suffix when the route is added
+// to a block that already uses it.
+func GenerateHostRoute(input GenerateBlockInput) string {
+	domains := input.Domains
+	if len(domains) == 0 && input.FQDN != "" {
+		domains = []string{input.FQDN}
+	}
+	if len(domains) == 0 {
+		domains = []string{"unknown.example.com"}
+	}
+	return hostRouteText("", "@"+matcherLabel(domains[0]), domains, GenerateRouteBody(input))
+}
+
+// GenerateRouteBody generates the directives inside a site block (indented one
+// level): snippet imports, custom config, LAN restriction and reverse_proxy,
+// or a handle block per path route followed by a catch-all one.
+// Host routes in wildcard site blocks wrap the same body in a handle block.
+func GenerateRouteBody(input GenerateBlockInput) string {
+	var b strings.Builder
+
 	// Helper function to check if a snippet is selected
 	hasSnippet := func(name string) bool {
 		for _, s := range input.SelectedSnippets {
@@ -68,12 +129,6 @@ func GenerateCaddyBlock(input GenerateBlockInput) string {
 		return false
 	}
 
-	// Header marker comment (uses primary domain)
-	b.WriteString(fmt.Sprintf("# === %s ===\n", primaryDomain))
-
-	// Domain block (comma-separated if multiple domains)
-	b.WriteString(fmt.Sprintf("%s {\n", domainList))
-
 	// Separate snippets into site-level and proxy-level
 	var siteLevelSnippets []string
 	var proxyLevelSnippets []string
@@ -85,12 +140,18 @@ func GenerateCaddyBlock(input GenerateBlockInput) string {
 		}
 	}
 
-	// Import site-level snippets
+	// Import site-level snippets. With path routes, the ones answering
+	// requests themselves go inside each handle block instead.
+	imported := 0
 	for _, snippetName := range siteLevelSnippets {
+		if len(input.Routes) > 0 && isHandlerSnippet(snippetName) {
+			continue
+		}
 		b.WriteString(fmt.Sprintf("\timport %s\n", snippetName))
+		imported++
 	}
 	// Add a newline if there were site-level snippets for better formatting
-	if len(siteLevelSnippets) > 0 {
+	if imported > 0 {
 		b.WriteString("\n")
 	}
 
@@ -106,71 +167,187 @@ func GenerateCaddyBlock(input GenerateBlockInput) string {
 		b.WriteString("\n")
 	}
 
-	// LAN-only restriction (only if snippet not used)
+	// Redirects and static sites have no upstream
+	switch input.Type {
+	case EntryRedirect:
+		writeRedirect(&b, input)
+		return b.String()
+	case EntryStatic:
+		if input.LANOnly && !hasSnippet("ip_restricted") {
+			writeLANRestriction(&b, "\t", input)
+			b.WriteString("\n")
+		}
+		writeStatic(&b, input)
+		return b.String()
+	}
+
+	// Path routes come before the site's own upstream, which then serves
+	// every other path
+	if len(input.Routes) > 0 {
+		writePathRoutes(&b, input, siteLevelSnippets)
+		return b.String()
+	}
+
+	// LAN-only restriction (only if snippet not used). client_ip is the
+	// visitor's address behind trusted proxies such as Cloudflare's edge and
+	// the connection's address otherwise.
 	if input.LANOnly && !hasSnippet("ip_restricted") {
-		b.WriteString("\t@external {\n")
-		b.WriteString(fmt.Sprintf("\t\tnot remote_ip %s %s\n", input.LANSubnet, input.AllowedExtIP))
-		b.WriteString("\t}\n")
-		b.WriteString("\trespond @external 404\n")
+		writeLANRestriction(&b, "\t", input)
 		b.WriteString("\n")
 	}
 
-	// Reverse proxy directive - infer protocol from port
-	// Port 443 is always HTTPS, port 80 is always HTTP
-	// For non-standard ports, use the SSL setting
-	var protocol string
-	if input.Port == 443 {
-		protocol = "https"
-	} else if input.Port == 80 {
-		protocol = "http"
-	} else {
-		// Non-standard port - use SSL checkbox
-		if input.SSL {
-			protocol = "https"
-		} else {
-			protocol = "http"
+	writeReverseProxy(&b, "\t", upstreamList(input.Target, input.Port, input.SSL, input.Upstreams), input.LoadBalancing, input.ProxyOptions, proxyLevelSnippets, input.OAuth, input.WebSocket)
+
+	return b.String()
+}
+
+// upstreamURL returns a reverse_proxy upstream, inferring the protocol from
+// the port: 443 is always HTTPS, 80 is always HTTP, and other ports use ssl
+func upstreamURL(target string, port int, ssl bool) string {
+	return fmt.Sprintf("%s://%s:%d", upstreamScheme(port, ssl), target, port)
+}
+
+// upstreamScheme returns the protocol upstreamURL infers
+func upstreamScheme(port int, ssl bool) string {
+	if port == 443 || (port != 80 && ssl) {
+		return "https"
+	}
+	return "http"
+}
+
+// writeLANRestriction writes the inline LAN-only restriction at indent
+func writeLANRestriction(b *strings.Builder, indent string, input GenerateBlockInput) {
+	b.WriteString(indent + "@external {\n")
+	b.WriteString(fmt.Sprintf("%s\tnot client_ip %s %s\n", indent, input.LANSubnet, input.AllowedExtIP))
+	b.WriteString(indent + "}\n")
+	b.WriteString(indent + "respond @external 404\n")
+}
+
+// oauthHeaderLines are the header_up lines written for the OAuth option
+var oauthHeaderLines = []string{
+	"header_up X-Forwarded-User {http.request.header.X-Forwarded-User}",
+	"header_up X-Forwarded-Groups {http.request.header.X-Forwarded-Groups}",
+	"header_up X-Forwarded-Email {http.request.header.X-Forwarded-Email}",
+	"header_up X-Forwarded-Preferred-Username {http.request.header.X-Forwarded-Preferred-Username}",
+}
+
+// webSocketHeaderLines are the header_up lines written for the WebSocket option
+var webSocketHeaderLines = []string{
+	"header_up Upgrade {http.request.header.Upgrade}",
+	"header_up Connection {http.request.header.Connection}",
+}
+
+// writeReverseProxy writes a reverse_proxy directive at indent, as a block when
+// it load balances, has further options, imports proxy-level snippets or sets headers
+func writeReverseProxy(b *strings.Builder, indent string, upstreams []string, lb LoadBalancing, options []string, proxyLevelSnippets []string, oauth, webSocket bool) {
+	upstream := strings.Join(upstreams, " ")
+
+	// Determine if a reverse_proxy block is needed (for load balancing, headers or proxy-level snippets)
+	if lb.IsZero() && len(options) == 0 && !oauth && !webSocket && len(proxyLevelSnippets) == 0 {
+		// Simple one-line reverse_proxy (no headers or proxy-level snippets)
+		b.WriteString(fmt.Sprintf("%sreverse_proxy %s\n", indent, upstream))
+		return
+	}
+
+	// Open reverse_proxy block
+	b.WriteString(fmt.Sprintf("%sreverse_proxy %s {\n", indent, upstream))
+
+	// Load balancing and health checks, then the options kept as written
+	for _, line := range lb.Directives() {
+		b.WriteString(indent + "\t" + line + "\n")
+	}
+	for _, option := range options {
+		for _, line := range strings.Split(option, "\n") {
+			b.WriteString(indent + "\t" + line + "\n")
 		}
 	}
+	if (!lb.IsZero() || len(options) > 0) && (len(proxyLevelSnippets) > 0 || oauth || webSocket) {
+		b.WriteString("\n")
+	}
 
-	// Determine if a reverse_proxy block is needed (for headers or proxy-level snippets)
-	needsProxyBlock := input.OAuth || input.WebSocket || len(proxyLevelSnippets) > 0
+	// Import proxy-level snippets
+	for _, snippetName := range proxyLevelSnippets {
+		b.WriteString(fmt.Sprintf("%s\timport %s\n", indent, snippetName))
+	}
+	// Add a newline if there were proxy-level snippets for better formatting
+	if len(proxyLevelSnippets) > 0 {
+		b.WriteString("\n")
+	}
 
-	if needsProxyBlock {
-		// Open reverse_proxy block
-		b.WriteString(fmt.Sprintf("\treverse_proxy %s://%s:%d {\n", protocol, input.Target, input.Port))
+	// OAuth headers (inside reverse_proxy block)
+	if oauth {
+		for _, line := range oauthHeaderLines {
+			b.WriteString(indent + "\t" + line + "\n")
+		}
+	}
 
-		// Import proxy-level snippets
-		for _, snippetName := range proxyLevelSnippets {
+	// WebSocket headers (inside reverse_proxy block)
+	if webSocket {
+		for _, line := range webSocketHeaderLines {
+			b.WriteString(indent + "\t" + line + "\n")
+		}
+	}
+
+	// Close reverse_proxy block
+	b.WriteString(indent + "}\n")
+}
+
+// writePathRoutes writes a handle (or handle_path) block per path route and a
+// catch-all handle block for the site's own upstream. Handle blocks run
+// before site-level respond directives, so the IP restriction and the
+// snippets that answer requests themselves go inside every block instead.
+func writePathRoutes(b *strings.Builder, input GenerateBlockInput, siteLevelSnippets []string) {
+	// Restrictions applying to every block
+	var guards []string
+	for _, snippetName := range siteLevelSnippets {
+		if isHandlerSnippet(snippetName) {
+			guards = append(guards, snippetName)
+		}
+	}
+	writeGuards := func() {
+		for _, snippetName := range guards {
 			b.WriteString(fmt.Sprintf("\t\timport %s\n", snippetName))
 		}
-		// Add a newline if there were proxy-level snippets for better formatting
-		if len(proxyLevelSnippets) > 0 {
-			b.WriteString("\n")
+		if input.LANOnly && !slices.Contains(guards, "ip_restricted") {
+			writeLANRestriction(b, "\t\t", input)
 		}
+	}
 
-		// OAuth headers (inside reverse_proxy block)
-		if input.OAuth {
-			b.WriteString("\t\theader_up X-Forwarded-User {http.request.header.X-Forwarded-User}\n")
-			b.WriteString("\t\theader_up X-Forwarded-Groups {http.request.header.X-Forwarded-Groups}\n")
-			b.WriteString("\t\theader_up X-Forwarded-Email {http.request.header.X-Forwarded-Email}\n")
-			b.WriteString("\t\theader_up X-Forwarded-Preferred-Username {http.request.header.X-Forwarded-Preferred-Username}\n")
+	for _, route := range input.Routes {
+		directive := "handle"
+		if route.StripPrefix {
+			directive = "handle_path"
 		}
+		b.WriteString(fmt.Sprintf("\t%s %s {\n", directive, route.Path))
+		writeGuards()
 
-		// WebSocket headers (inside reverse_proxy block)
-		if input.WebSocket {
-			b.WriteString("\t\theader_up Upgrade {http.request.header.Upgrade}\n")
-			b.WriteString("\t\theader_up Connection {http.request.header.Connection}\n")
+		var proxyLevel []string
+		for _, snippetName := range route.Snippets {
+			switch {
+			case slices.Contains(guards, snippetName):
+				// Already imported for the whole site
+			case isProxyLevelSnippet(snippetName):
+				proxyLevel = append(proxyLevel, snippetName)
+			default:
+				b.WriteString(fmt.Sprintf("\t\timport %s\n", snippetName))
+			}
 		}
-
-		// Close reverse_proxy block
-		b.WriteString("\t}\n")
-	} else {
-		// Simple one-line reverse_proxy (no headers or proxy-level snippets)
-		b.WriteString(fmt.Sprintf("\treverse_proxy %s://%s:%d\n", protocol, input.Target, input.Port))
+		writeReverseProxy(b, "\t\t", []string{route.Upstream()}, LoadBalancing{}, nil, proxyLevel, false, false)
+		b.WriteString("\t}\n\n")
 	}
 
-	// Close block
-	b.WriteString("}\n")
-
-	return b.String()
+	// Every other path goes to the site's own upstream
+	if input.Target == "" {
+		return
+	}
+	var proxyLevelSnippets []string
+	for _, snippetName := range input.SelectedSnippets {
+		if isProxyLevelSnippet(snippetName) {
+			proxyLevelSnippets = append(proxyLevelSnippets, snippetName)
+		}
+	}
+	b.WriteString("\thandle {\n")
+	writeGuards()
+	writeReverseProxy(b, "\t\t", upstreamList(input.Target, input.Port, input.SSL, input.Upstreams), input.LoadBalancing, input.ProxyOptions, proxyLevelSnippets, input.OAuth, input.WebSocket)
+	b.WriteString("\t}\n")
 }
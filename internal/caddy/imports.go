@@ -0,0 +1,398 @@
+package caddy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Source is one file of a Caddyfile tree: the root Caddyfile or a file it imports
+type Source struct {
+	Path string
+	File *File
+
+	content string      // Text as read, to detect edits
+	mode    os.FileMode // Permissions kept on write
+}
+
+// Tree is a Caddyfile together with the files pulled in by its top-level
+// import lines, root first and the rest in the order Caddy reads them
+type Tree struct {
+	Sources []*Source
+
+	// Warnings are the imports that name no readable file, e.g. a missing
+	// file or a path only the Caddy container sees. The tree is still
+	// complete without them and can be edited.
+	Warnings []error
+}
+
+// Has reports whether path is one of the tree's files
+func (t *Tree) Has(path string) bool {
+	return t.source(path) != nil
+}
+
+// source returns the file at path, or nil
+func (t *Tree) source(path string) *Source {
+	for _, src := range t.Sources {
+		if samePath(src.Path, path) {
+			return src
+		}
+	}
+	return nil
+}
+
+// find returns the first file for which has is true, or nil
+func (t *Tree) find(has func(*File) bool) *Source {
+	for _, src := range t.Sources {
+		if has(src.File) {
+			return src
+		}
+	}
+	return nil
+}
+
+// LoadTree reads a Caddyfile and the files its top-level imports name,
+// recursively. An import is a file import unless a snippet of that name was
+// defined before it; patterns are relative to the importing file, may be
+// globs (hidden files are skipped) or directories (every file in it).
+// Absolute patterns under the directory Caddy sees the Caddyfile in (see
+// SetContainerPath) are read from the Caddyfile's directory on the host.
+// Like ParseAST, the returned tree holds everything that could be read; the
+// first syntax error is returned alongside it, and imports that couldn't be
+// read are listed in its Warnings. Only an unreadable root Caddyfile returns
+// a nil tree.
+func LoadTree(caddyfilePath string) (*Tree, error) {
+	root, err := readSource(caddyfilePath)
+	if root == nil {
+		return nil, err
+	}
+	l := &treeLoader{tree: &Tree{}, snippets: map[string]bool{}, err: err, hostDir: filepath.Dir(caddyfilePath)}
+	if containerPath, ok := containerPaths.Load(filepath.Clean(caddyfilePath)); ok {
+		l.containerDir = filepath.Dir(containerPath.(string))
+	}
+	l.add(root)
+	return l.tree, l.err
+}
+
+// containerPaths maps a Caddyfile on the host to its path in the container
+var containerPaths sync.Map
+
+// SetContainerPath records the path Caddy reads the Caddyfile at
+// caddyfilePath from (e.g. inside its Docker container), so absolute imports
+// written for Caddy resolve on the host. An empty containerPath clears it.
+func SetContainerPath(caddyfilePath, containerPath string) {
+	if caddyfilePath == "" {
+		return
+	}
+	if containerPath == "" {
+		containerPaths.Delete(filepath.Clean(caddyfilePath))
+		return
+	}
+	containerPaths.Store(filepath.Clean(caddyfilePath), containerPath)
+}
+
+// treeLoader follows imports depth first, in the order Caddy does
+type treeLoader struct {
+	tree     *Tree
+	snippets map[string]bool // Snippets defined so far
+	err      error
+
+	hostDir      string // Directory of the root Caddyfile
+	containerDir string // Directory Caddy sees it in, if set
+}
+
+// hostPattern maps an absolute import pattern under the container directory
+// to the host
+func (l *treeLoader) hostPattern(pattern string) string {
+	if l.containerDir == "" || !filepath.IsAbs(pattern) {
+		return pattern
+	}
+	rel, err := filepath.Rel(l.containerDir, pattern)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return pattern
+	}
+	return filepath.Join(l.hostDir, rel)
+}
+
+// add appends a file to the tree, then the files it imports
+func (l *treeLoader) add(src *Source) {
+	l.tree.Sources = append(l.tree.Sources, src)
+	for _, block := range src.File.Blocks {
+		switch block.Kind {
+		case BlockSnippet:
+			l.snippets[block.Keys[0]] = true
+		case BlockImport:
+			if len(block.Keys) == 0 || l.snippets[block.Keys[0]] {
+				continue
+			}
+			paths, err := importPaths(src.Path, l.hostPattern(block.Keys[0]))
+			if err != nil {
+				l.warn(fmt.Errorf("%s:%d: %w", src.Path, block.LineStart, err))
+				continue
+			}
+			for _, path := range paths {
+				if l.tree.Has(path) {
+					continue // Already read (or an import cycle)
+				}
+				imported, err := readSource(path)
+				if imported == nil {
+					l.warn(err)
+					continue
+				}
+				if err != nil {
+					l.fail(err)
+				}
+				l.add(imported)
+			}
+		}
+	}
+}
+
+// fail keeps the first syntax error
+func (l *treeLoader) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// warn records an import that couldn't be read
+func (l *treeLoader) warn(err error) {
+	l.tree.Warnings = append(l.tree.Warnings, err)
+}
+
+// readSource reads and parses one file. On a syntax error the source is
+// returned along with the error; nil means the file couldn't be read.
+func readSource(path string) (*Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	file, err := ParseAST(string(content))
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return &Source{Path: path, File: file, content: string(content), mode: info.Mode().Perm()}, err
+}
+
+// importPaths resolves an import pattern to the files it names. Patterns with
+// placeholders ({$DIR}) can't be resolved and name no files.
+func importPaths(importer, pattern string) ([]string, error) {
+	if strings.Contains(pattern, "{") {
+		return nil, nil
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(importer), pattern)
+	}
+
+	var matches []string
+	if strings.ContainsAny(pattern, "*?[") {
+		globbed, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid import pattern %s: %w", pattern, err)
+		}
+		matches = globbed
+	} else {
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("imported file %s not found", pattern)
+		}
+		if !info.IsDir() {
+			return []string{pattern}, nil
+		}
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read imported directory: %w", err)
+		}
+		for _, entry := range entries {
+			matches = append(matches, filepath.Join(pattern, entry.Name()))
+		}
+	}
+
+	var paths []string
+	for _, match := range matches {
+		if strings.HasPrefix(filepath.Base(match), ".") {
+			continue
+		}
+		if info, err := os.Stat(match); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		paths = append(paths, match)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// editTree loads the Caddyfile tree, applies edit to it and writes back every
+// file the edit changed, with its original permissions. An imported file left
+// without blocks is deleted. Nothing is written if a file of the tree can't
+// be parsed or the edit fails; imports that can't be read (the tree's
+// Warnings) are left out of the edit.
+func editTree(caddyfilePath string, edit func(*Tree) error) error {
+	tree, err := LoadTree(caddyfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse Caddyfile: %w", err)
+	}
+	if err := edit(tree); err != nil {
+		return err
+	}
+
+	for i, src := range tree.Sources {
+		content := src.File.String()
+		if content == src.content {
+			continue
+		}
+		if i > 0 && len(src.File.Blocks) == 0 && strings.TrimSpace(content) == "" {
+			if err := os.Remove(src.Path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", src.Path, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(src.Path, []byte(content), src.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", src.Path, err)
+		}
+	}
+	return nil
+}
+
+// siteFileUnsafe matches characters left out of site file names
+var siteFileUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SiteFileName returns the name of the file holding a site of its own
+// (e.g. plex.example.com.caddy; *.example.com becomes _wildcard.example.com.caddy)
+func SiteFileName(domain string) string {
+	name := strings.ReplaceAll(strings.ToLower(siteHost(domain)), "*", "_wildcard")
+	return siteFileUnsafe.ReplaceAllString(name, "_") + ".caddy"
+}
+
+// AppendSiteFile writes block to a new file of its own in dir, named after
+// domain. A relative dir is relative to the Caddyfile. Unless an import of the
+// Caddyfile already covers the file, "import <dir>/*.caddy" is appended to
+// the Caddyfile. Returns the path of the new file.
+func AppendSiteFile(caddyfilePath, dir, domain, block string) (string, error) {
+	if _, err := ParseAST(block); err != nil {
+		return "", fmt.Errorf("invalid block: %w", err)
+	}
+	info, err := os.Stat(caddyfilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat Caddyfile: %w", err)
+	}
+
+	absDir := dir
+	if !filepath.IsAbs(dir) {
+		absDir = filepath.Join(filepath.Dir(caddyfilePath), dir)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create site directory: %w", err)
+	}
+	path := filepath.Join(absDir, SiteFileName(domain))
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("site file %s already exists", path)
+	}
+	if err := os.WriteFile(path, []byte(strings.TrimRight(block, "\n")+"\n"), info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write site file: %w", err)
+	}
+
+	tree, _ := LoadTree(caddyfilePath)
+	if tree != nil && tree.Has(path) {
+		return path, nil
+	}
+	line := "import " + filepath.ToSlash(filepath.Join(dir, "*.caddy"))
+	if err := editCaddyfile(caddyfilePath, func(file *File) error {
+		return file.Append(line + "\n")
+	}); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// ImportsSuffix is appended to a Caddyfile backup's path for the copy of the
+// files the Caddyfile imported when the backup was taken
+const ImportsSuffix = ".imports.json"
+
+// importedFile is a file saved with a backup
+type importedFile struct {
+	Path    string      `json:"path"`
+	Content string      `json:"content"`
+	Mode    os.FileMode `json:"mode"`
+}
+
+// backupImports saves the imported files of the tree next to a backup of its
+// root. The list is written even when empty: restoring it removes files that
+// were imported since.
+func backupImports(caddyfilePath, backupPath string) error {
+	files := []importedFile{}
+	if tree, _ := LoadTree(caddyfilePath); tree != nil {
+		for _, src := range tree.Sources[1:] {
+			files = append(files, importedFile{Path: src.Path, Content: src.content, Mode: src.mode})
+		}
+	}
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode imported files: %w", err)
+	}
+	if err := os.WriteFile(backupPath+ImportsSuffix, data, 0600); err != nil {
+		return fmt.Errorf("failed to back up imported files: %w", err)
+	}
+	return nil
+}
+
+// restoreImports puts back the imported files saved with a backup, and
+// removes the files in before (the tree as it was before the restore) that
+// weren't imported at the time. Backups taken before imports were followed
+// have no saved files; their imports are left alone.
+func restoreImports(before *Tree, backupPath string) error {
+	data, err := os.ReadFile(backupPath + ImportsSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read imported files of backup: %w", err)
+	}
+	var files []importedFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("failed to parse imported files of backup: %w", err)
+	}
+
+	saved := &Tree{}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(file.Path, []byte(file.Content), file.Mode); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		saved.Sources = append(saved.Sources, &Source{Path: file.Path})
+	}
+	if before == nil {
+		return nil
+	}
+	for _, src := range before.Sources[1:] {
+		if !saved.Has(src.Path) {
+			if err := os.Remove(src.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", src.Path, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package caddy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files (path relative to dir -> content)
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLoadCaddyfileFollowsImports(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Caddyfile": "(ip_restricted) {\n\t@external not remote_ip 10.0.0.0/8\n}\n\n" +
+			"import ip_restricted\nimport sites/*.caddy\nimport extra\n\n" +
+			"root.example.com {\n\treverse_proxy localhost:80\n}\n",
+		"sites/plex.caddy":     "plex.example.com {\n\timport ip_restricted\n\treverse_proxy 10.0.0.5:32400\n}\n",
+		"sites/b.caddy":        "(headers) {\n\theader X-Test 1\n}\n\nb.example.com {\n\treverse_proxy b:80\n}\n",
+		"sites/.hidden.caddy":  "hidden.example.com {\n}\n",
+		"sites/notes.txt":      "not imported\n",
+		"extra/one":            "one.example.com {\n\treverse_proxy one:80\n}\n\nimport ../Caddyfile\n",
+		"extra/nested/skipped": "nested.example.com {\n}\n",
+	})
+
+	parsed, err := LoadCaddyfile(filepath.Join(dir, "Caddyfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	var order []string
+	for _, entry := range parsed.Entries {
+		rel, _ := filepath.Rel(dir, entry.File)
+		files[entry.Domain] = rel
+		order = append(order, entry.Domain)
+	}
+	if got := strings.Join(order, " "); got != "root.example.com b.example.com plex.example.com one.example.com" {
+		t.Fatalf("unexpected entries: %s", got)
+	}
+	if files["plex.example.com"] != "sites/plex.caddy" || files["root.example.com"] != "Caddyfile" {
+		t.Errorf("unexpected entry files: %v", files)
+	}
+	if len(parsed.Snippets) != 2 || parsed.Snippets[1].Name != "headers" || !strings.HasSuffix(parsed.Snippets[1].File, "b.caddy") {
+		t.Errorf("unexpected snippets: %+v", parsed.Snippets)
+	}
+	if entry := parsed.Entries[2]; entry.LineStart != 1 || len(entry.Imports) != 1 {
+		t.Errorf("unexpected imported entry: %+v", entry)
+	}
+
+	// A missing import is logged, not fatal
+	writeFiles(t, dir, map[string]string{"Caddyfile": "import missing.caddy\n\nroot.example.com {\n}\n"})
+	if parsed, err := LoadCaddyfile(filepath.Join(dir, "Caddyfile")); err != nil || len(parsed.Entries) != 1 {
+		t.Errorf("expected the root's entry despite the missing import, got %v, %v", parsed.Entries, err)
+	}
+	if tree, err := LoadTree(filepath.Join(dir, "Caddyfile")); err != nil || len(tree.Warnings) != 1 || !strings.Contains(tree.Warnings[0].Error(), "missing.caddy") {
+		t.Errorf("expected the missing import as a warning, got %v", err)
+	}
+}
+
+// TestEditWithUnresolvedImports tests that imports which can't be read don't
+// block edits of the files that can, while syntax errors still do
+func TestEditWithUnresolvedImports(t *testing.T) {
+	dir := t.TempDir()
+	caddyfile := filepath.Join(dir, "Caddyfile")
+	writeFiles(t, dir, map[string]string{
+		"Caddyfile": "import missing.caddy\nimport /etc/caddy/sites/*\n\napp.example.com {\n\treverse_proxy app:80\n}\n",
+	})
+
+	if err := ReplaceEntry(caddyfile, "app.example.com", "app.example.com {\n\treverse_proxy app:8080\n}"); err != nil {
+		t.Fatalf("edit blocked by a dangling import: %v", err)
+	}
+	if got := readFile(t, caddyfile); !strings.Contains(got, "app:8080") || !strings.Contains(got, "import missing.caddy") {
+		t.Errorf("unexpected Caddyfile:\n%s", got)
+	}
+
+	// With the container path known, the absolute import is read from the host
+	writeFiles(t, dir, map[string]string{"sites/nas.caddy": "nas.example.com {\n\treverse_proxy nas:5000\n}\n"})
+	SetContainerPath(caddyfile, "/etc/caddy/Caddyfile")
+	defer SetContainerPath(caddyfile, "")
+	if err := RemoveEntry(caddyfile, "nas.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sites/nas.caddy")); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied container-path import removed, got %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{"sites/bad.caddy": "bad.example.com {\n"})
+	if err := ReplaceEntry(caddyfile, "app.example.com", "app.example.com {\n\treverse_proxy app:9090\n}"); err == nil {
+		t.Error("expected a syntax error in an import to block the edit")
+	}
+	if got := readFile(t, caddyfile); strings.Contains(got, "app:9090") {
+		t.Errorf("Caddyfile written despite the syntax error:\n%s", got)
+	}
+}
+
+func TestEditImportedFiles(t *testing.T) {
+	dir := t.TempDir()
+	caddyfile := filepath.Join(dir, "Caddyfile")
+	root := "(common) {\n\tencode gzip\n}\n\nimport sites/*.caddy\n"
+	plex := "# === plex.example.com ===\nplex.example.com {\n\treverse_proxy 10.0.0.5:32400\n}\n"
+	wildcard := "*.example.com {\n\t@app host app.example.com\n\thandle @app {\n\t\treverse_proxy app:80\n\t}\n}\n"
+	writeFiles(t, dir, map[string]string{
+		"Caddyfile":        root,
+		"sites/plex.caddy": plex,
+		"sites/all.caddy":  "# Wildcard\n" + wildcard + "\nnas.example.com {\n\treverse_proxy nas:5000\n}\n",
+	})
+	backupPath, err := BackupCaddyfile(caddyfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceEntry(caddyfile, "plex.example.com", "plex.example.com {\n\treverse_proxy 10.0.0.6:32400\n}"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(dir, "sites/plex.caddy")); !strings.Contains(got, "10.0.0.6") || !strings.HasPrefix(got, "# === plex") {
+		t.Errorf("replace didn't edit the site's file:\n%s", got)
+	}
+	if readFile(t, caddyfile) != root {
+		t.Error("replace changed the Caddyfile")
+	}
+
+	if err := AddHostRoute(caddyfile, []string{"git.example.com"}, "reverse_proxy git:3000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveEntry(caddyfile, "nas.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	all := readFile(t, filepath.Join(dir, "sites/all.caddy"))
+	if !strings.Contains(all, "reverse_proxy git:3000") || strings.Contains(all, "nas.example.com") {
+		t.Errorf("unexpected wildcard file:\n%s", all)
+	}
+	if err := UpdateSnippet(caddyfile, "common", "\tencode zstd gzip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the only site of a file removes the file
+	if err := RemoveEntry(caddyfile, "plex.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sites/plex.caddy")); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied site file removed, got %v", err)
+	}
+	if err := RemoveEntry(caddyfile, "missing.example.com"); err == nil {
+		t.Error("expected an error for an unknown site")
+	}
+
+	// New site files; the existing import covers them
+	path, err := AppendSiteFile(caddyfile, "sites", "*.lan.example.com", "*.lan.example.com {\n\trespond ok\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "_wildcard.lan.example.com.caddy" {
+		t.Errorf("unexpected site file %s", path)
+	}
+	if strings.Count(readFile(t, caddyfile), "import") != 1 {
+		t.Errorf("expected no import added:\n%s", readFile(t, caddyfile))
+	}
+	if _, err := AppendSiteFile(caddyfile, "sites", "*.lan.example.com", "*.lan.example.com {\n}"); err == nil {
+		t.Error("expected an error for an existing site file")
+	}
+
+	// Restoring the backup puts back every imported file and removes new ones
+	if err := RestoreFromBackup(caddyfile, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, caddyfile) != root || readFile(t, filepath.Join(dir, "sites/plex.caddy")) != plex ||
+		!strings.Contains(readFile(t, filepath.Join(dir, "sites/all.caddy")), "nas.example.com") {
+		t.Error("backup not fully restored")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the site file created after the backup removed, got %v", err)
+	}
+	if backups, _ := ListBackups(caddyfile); len(backups) != 1 {
+		t.Errorf("expected the imports file not listed as a backup, got %v", backups)
+	}
+}
+
+func TestAppendSiteFileAddsImport(t *testing.T) {
+	dir := t.TempDir()
+	caddyfile := filepath.Join(dir, "Caddyfile")
+	writeFiles(t, dir, map[string]string{"Caddyfile": "root.example.com {\n\trespond ok\n}\n"})
+	backupPath, err := BackupCaddyfile(caddyfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := AppendSiteFile(caddyfile, "sites", "app.example.com", GenerateCaddyBlock(GenerateBlockInput{FQDN: "app.example.com", Target: "app", Port: 80}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, caddyfile); !strings.HasSuffix(got, "}\n\nimport sites/*.caddy\n") {
+		t.Errorf("expected the import appended:\n%s", got)
+	}
+	parsed, _ := LoadCaddyfile(caddyfile)
+	if len(parsed.Entries) != 2 || parsed.Entries[1].File != path {
+		t.Errorf("expected the new site loaded from %s, got %+v", path, parsed.Entries)
+	}
+
+	// Rolling back removes the file, which is no longer imported after the restore
+	if err := RestoreFromBackup(caddyfile, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the site file removed, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package caddy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LoadBalancing holds the reverse_proxy subdirectives choosing between
+// upstreams and taking unhealthy ones out of rotation
+type LoadBalancing struct {
+	Policy           string   // lb_policy with its arguments (e.g. "least_conn", "cookie lb")
+	TryDuration      string   // lb_try_duration: how long to retry other upstreams
+	HealthURI        string   // health_uri: path of active health checks
+	HealthInterval   string   // health_interval between active health checks
+	HealthTimeout    string   // health_timeout of an active health check
+	FailDuration     string   // fail_duration: how long a failed request is remembered (passive checks)
+	MaxFails         int      // max_fails within fail_duration marking an upstream down
+	UnhealthyStatus  []string // unhealthy_status codes counting as failures (e.g. "5xx")
+	UnhealthyLatency string   // unhealthy_latency counting slow responses as failures
+}
+
+// lbPolicies are the lb_policy values Caddy knows
+var lbPolicies = []string{
+	"random", "random_choose", "least_conn", "round_robin", "weighted_round_robin", "first",
+	"ip_hash", "client_ip_hash", "uri_hash", "query", "header", "cookie",
+}
+
+// caddyDuration matches a Caddy duration (Go durations plus d for days)
+var caddyDuration = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h|d))+$`)
+
+// IsZero reports whether no setting is given
+func (lb LoadBalancing) IsZero() bool {
+	return len(lb.Directives()) == 0
+}
+
+// Directives returns the settings as reverse_proxy subdirectives, in a fixed order
+func (lb LoadBalancing) Directives() []string {
+	var lines []string
+	add := func(name, value string) {
+		if value != "" {
+			lines = append(lines, name+" "+value)
+		}
+	}
+	add("lb_policy", lb.Policy)
+	add("lb_try_duration", lb.TryDuration)
+	add("health_uri", lb.HealthURI)
+	add("health_interval", lb.HealthInterval)
+	add("health_timeout", lb.HealthTimeout)
+	add("fail_duration", lb.FailDuration)
+	if lb.MaxFails > 0 {
+		add("max_fails", strconv.Itoa(lb.MaxFails))
+	}
+	add("unhealthy_status", strings.Join(lb.UnhealthyStatus, " "))
+	add("unhealthy_latency", lb.UnhealthyLatency)
+	return lines
+}
+
+// String formats the settings as ParseLoadBalancing input, one per line
+func (lb LoadBalancing) String() string {
+	return strings.Join(lb.Directives(), "\n")
+}
+
+// set stores one subdirective; false if it isn't a load balancing setting.
+// Values are kept as written; validate checks them.
+func (lb *LoadBalancing) set(name string, args []string) bool {
+	value := strings.Join(args, " ")
+	switch name {
+	case "lb_policy":
+		lb.Policy = value
+	case "lb_try_duration":
+		lb.TryDuration = value
+	case "health_uri":
+		lb.HealthURI = value
+	case "health_interval":
+		lb.HealthInterval = value
+	case "health_timeout":
+		lb.HealthTimeout = value
+	case "fail_duration":
+		lb.FailDuration = value
+	case "max_fails":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			n = -1 // Rejected by validate
+		}
+		lb.MaxFails = n
+	case "unhealthy_status":
+		lb.UnhealthyStatus = append([]string{}, args...)
+	case "unhealthy_latency":
+		lb.UnhealthyLatency = value
+	default:
+		return false
+	}
+	return true
+}
+
+// validate checks the policy, durations and counts
+func (lb LoadBalancing) validate() error {
+	if lb.Policy != "" && !slices.Contains(lbPolicies, strings.Fields(lb.Policy)[0]) {
+		return fmt.Errorf("unknown lb_policy %q (one of %s)", lb.Policy, strings.Join(lbPolicies, ", "))
+	}
+	for name, value := range map[string]string{
+		"lb_try_duration":   lb.TryDuration,
+		"health_interval":   lb.HealthInterval,
+		"health_timeout":    lb.HealthTimeout,
+		"fail_duration":     lb.FailDuration,
+		"unhealthy_latency": lb.UnhealthyLatency,
+	} {
+		if value != "" && !caddyDuration.MatchString(value) {
+			return fmt.Errorf("%s %q is not a duration (e.g. 10s, 1m)", name, value)
+		}
+	}
+	if lb.HealthURI != "" && !strings.HasPrefix(lb.HealthURI, "/") {
+		return fmt.Errorf("health_uri %q must start with /", lb.HealthURI)
+	}
+	if lb.MaxFails < 0 {
+		return fmt.Errorf("max_fails must be a positive number")
+	}
+	for _, status := range lb.UnhealthyStatus {
+		if _, err := strconv.Atoi(strings.TrimSuffix(status, "xx")); err != nil {
+			return fmt.Errorf("unhealthy_status %q must be a status code or class (e.g. 503, 5xx)", status)
+		}
+	}
+	return nil
+}
+
+// ParseLoadBalancing parses load balancing settings written one per line as
+// reverse_proxy subdirectives, e.g.
+//
+//	lb_policy least_conn
+//	health_uri /healthz
+//	health_interval 10s
+//	fail_duration 30s
+//	max_fails 3
+//	unhealthy_status 5xx
+//
+// Blank lines are skipped.
+func ParseLoadBalancing(text string) (LoadBalancing, error) {
+	var lb LoadBalancing
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return LoadBalancing{}, fmt.Errorf("line %d: %s needs a value", i+1, fields[0])
+		}
+		if !lb.set(fields[0], fields[1:]) {
+			return LoadBalancing{}, fmt.Errorf("line %d: unknown setting %s", i+1, fields[0])
+		}
+	}
+	if err := lb.validate(); err != nil {
+		return LoadBalancing{}, err
+	}
+	return lb, nil
+}
+
+// upstreamSchemes are the protocols a reverse_proxy upstream can give
+var upstreamSchemes = []string{"http", "https", "h2c"}
+
+// ParseUpstreams parses further upstreams separated by spaces or commas, as
+// host or host:port with an optional scheme (https://); upstreams without
+// one use the protocol of the first.
+func ParseUpstreams(text string) ([]string, error) {
+	var upstreams []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		scheme, address, hasScheme := strings.Cut(field, "://")
+		if !hasScheme {
+			address = field
+		}
+		host, portText, hasPort := strings.Cut(address, ":")
+		port, _ := strconv.Atoi(portText)
+		if host == "" || strings.Contains(address, "/") || (hasPort && port <= 0) || (hasScheme && !slices.Contains(upstreamSchemes, scheme)) {
+			return nil, fmt.Errorf("invalid upstream %q (use host or host:port, optionally with http://, https:// or h2c://)", field)
+		}
+		if slices.Contains(upstreams, field) {
+			return nil, fmt.Errorf("upstream %s is listed more than once", field)
+		}
+		upstreams = append(upstreams, field)
+	}
+	return upstreams, nil
+}
+
+// upstreamList returns the upstreams of a reverse_proxy directive: Target
+// first, then the further upstreams with their own protocol or else its
+// protocol, and its port when they give none
+func upstreamList(target string, port int, ssl bool, more []string) []string {
+	list := []string{upstreamURL(target, port, ssl)}
+	for _, upstream := range more {
+		scheme, address, ok := strings.Cut(upstream, "://")
+		if !ok {
+			scheme, address = upstreamScheme(port, ssl), upstream
+		}
+		if _, p, _ := splitUpstream(address); p == 0 && !strings.HasPrefix(address, "{") {
+			address = fmt.Sprintf("%s:%d", address, port)
+		}
+		list = append(list, scheme+"://"+address)
+	}
+	return list
+}
@@ -0,0 +1,133 @@
+package caddy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadBalancingRoundTrip(t *testing.T) {
+	lb, err := ParseLoadBalancing("lb_policy cookie lb_session\nhealth_uri /healthz\nhealth_interval 10s\n\nfail_duration 30s\nmax_fails 3\nunhealthy_status 5xx 429\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstreams, err := ParseUpstreams("10.0.0.6, https://10.0.0.7:8443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := GenerateBlockInput{
+		FQDN:             "app.example.com",
+		Target:           "10.0.0.5",
+		Port:             8080,
+		WebSocket:        true,
+		SelectedSnippets: []string{"performance"},
+		Upstreams:        upstreams,
+		LoadBalancing:    lb,
+	}
+	block := GenerateCaddyBlock(input)
+	want := "\treverse_proxy http://10.0.0.5:8080 http://10.0.0.6:8080 https://10.0.0.7:8443 {\n" +
+		"\t\tlb_policy cookie lb_session\n\t\thealth_uri /healthz\n\t\thealth_interval 10s\n" +
+		"\t\tfail_duration 30s\n\t\tmax_fails 3\n\t\tunhealthy_status 5xx 429\n\n\t\timport performance\n"
+	if !strings.Contains(block, want) {
+		t.Errorf("unexpected block:\n%s", block)
+	}
+
+	entries, err := ParseCaddyfile(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := entries[0]
+	if entry.Target != "10.0.0.5" || entry.Port != 8080 || !entry.WebSocket {
+		t.Errorf("unexpected first upstream: %+v", entry)
+	}
+	if !reflect.DeepEqual(entry.Upstreams, []string{"10.0.0.6:8080", "https://10.0.0.7:8443"}) {
+		t.Errorf("unexpected upstreams: %v", entry.Upstreams)
+	}
+	if !reflect.DeepEqual(entry.LoadBalancing, lb) {
+		t.Errorf("load balancing not parsed back:\n got %+v\nwant %+v", entry.LoadBalancing, lb)
+	}
+
+	// Regenerating from the parsed entry gives the same block
+	input.Upstreams, input.LoadBalancing = entry.Upstreams, entry.LoadBalancing
+	if again := GenerateCaddyBlock(input); again != block {
+		t.Errorf("block changed on round trip:\n%s", again)
+	}
+
+	// Upstreams given with "to"
+	entries, _ = ParseCaddyfile("app.example.com {\n\treverse_proxy {\n\t\tto a:80 b:80\n\t\tlb_policy first\n\t}\n}\n")
+	if entry := entries[0]; entry.Target != "a" || !reflect.DeepEqual(entry.Upstreams, []string{"b:80"}) || entry.LoadBalancing.Policy != "first" {
+		t.Errorf("unexpected entry from to: %+v", entry)
+	}
+}
+
+// TestReverseProxyOptionsRoundTrip tests that reverse_proxy subdirectives
+// without a setting of their own survive an edit
+func TestReverseProxyOptionsRoundTrip(t *testing.T) {
+	options := "\t\tlb_retries 3\n\t\tlb_try_interval 250ms\n\t\tlb_retry_match {\n\t\t\tmethod POST\n\t\t}\n" +
+		"\t\thealth_port 8081\n\t\thealth_status 2xx\n\t\thealth_headers {\n\t\t\tHost example.internal\n\t\t}\n" +
+		"\t\thealth_body \"^ok$\"\n\t\thealth_passes 2\n\t\thealth_fails 3\n\t\tunhealthy_request_count 100\n" +
+		"\t\t# Keep the client's host\n\t\theader_up Host {upstream_hostport}\n" +
+		"\t\ttransport http {\n\t\t\ttls_insecure_skip_verify\n\t\t}\n"
+	content := "app.example.com {\n\treverse_proxy https://10.0.0.5:8443 10.0.0.6:8443 h2c://10.0.0.7:9000 {\n" +
+		"\t\tlb_policy first\n" + options +
+		"\t\theader_up Upgrade {http.request.header.Upgrade}\n\t}\n}\n"
+	entries, err := ParseCaddyfile(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := entries[0]
+	if len(entry.ProxyOptions) != 12 || !entry.WebSocket || entry.LoadBalancing.Policy != "first" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if !reflect.DeepEqual(entry.Upstreams, []string{"10.0.0.6:8443", "h2c://10.0.0.7:9000"}) {
+		t.Errorf("unexpected upstreams: %v", entry.Upstreams)
+	}
+
+	input := GenerateBlockInput{
+		FQDN:          "app.example.com",
+		Target:        entry.Target,
+		Port:          entry.Port,
+		SSL:           entry.SSL,
+		WebSocket:     entry.WebSocket,
+		Upstreams:     entry.Upstreams,
+		LoadBalancing: entry.LoadBalancing,
+		ProxyOptions:  entry.ProxyOptions,
+	}
+	block := GenerateCaddyBlock(input)
+	if want := "\treverse_proxy https://10.0.0.5:8443 https://10.0.0.6:8443 h2c://10.0.0.7:9000 {\n\t\tlb_policy first\n" + options; !strings.Contains(block, want) {
+		t.Errorf("options not written back:\n%s", block)
+	}
+	if strings.Count(block, "header_up Upgrade") != 1 {
+		t.Errorf("expected the WebSocket headers once:\n%s", block)
+	}
+
+	entries, err = ParseCaddyfile(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := entries[0]
+	if !reflect.DeepEqual(again.ProxyOptions, entry.ProxyOptions) || !reflect.DeepEqual(again.Upstreams, entry.Upstreams) || !reflect.DeepEqual(again.LoadBalancing, entry.LoadBalancing) {
+		t.Errorf("entry changed on round trip:\n got %+v\nwant %+v", again, entry)
+	}
+}
+
+func TestParseLoadBalancingErrors(t *testing.T) {
+	for _, text := range []string{
+		"lb_policy fastest",
+		"health_interval soon",
+		"health_uri healthz",
+		"max_fails many",
+		"unhealthy_status bad",
+		"health_port 8081",
+		"lb_policy",
+	} {
+		if _, err := ParseLoadBalancing(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+	for _, text := range []string{"a:b", "http://", "a/b", "a a", "ftp://a"} {
+		if _, err := ParseUpstreams(text); err == nil {
+			t.Errorf("expected an error for upstreams %q", text)
+		}
+	}
+}
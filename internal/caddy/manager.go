@@ -62,7 +62,8 @@ func ListDockerContainers(filterCaddy bool) ([]DockerContainer, error) {
 	return containers, nil
 }
 
-// BackupCaddyfile creates a timestamped backup of the Caddyfile
+// BackupCaddyfile creates a timestamped backup of the Caddyfile. The files it
+// imports are saved alongside (<backup>.imports.json).
 func BackupCaddyfile(caddyfilePath string) (string, error) {
 	// Get original file permissions
 	fileInfo, err := os.Stat(caddyfilePath)
@@ -85,12 +86,18 @@ func BackupCaddyfile(caddyfilePath string) (string, error) {
 	if err := os.WriteFile(backupPath, content, originalPerms); err != nil {
 		return "", fmt.Errorf("failed to write backup: %w", err)
 	}
+	if err := backupImports(caddyfilePath, backupPath); err != nil {
+		os.Remove(backupPath)
+		return "", err
+	}
 
 	return backupPath, nil
 }
 
-// AppendEntry adds a new Caddy block to the Caddyfile
-func AppendEntry(caddyfilePath string, block string) error {
+// editCaddyfile parses the Caddyfile, applies edit to it and writes the result
+// back with the original permissions. Nothing is written if the file can't be
+// parsed or the edit fails.
+func editCaddyfile(caddyfilePath string, edit func(*File) error) error {
 	// Get original file permissions
 	fileInfo, err := os.Stat(caddyfilePath)
 	if err != nil {
@@ -104,104 +111,127 @@ func AppendEntry(caddyfilePath string, block string) error {
 		return fmt.Errorf("failed to read Caddyfile: %w", err)
 	}
 
-	// Append new block with spacing
-	newContent := string(content)
-
-	// Ensure file ends with newline before adding new block
-	if len(newContent) > 0 && newContent[len(newContent)-1] != '\n' {
-		newContent += "\n"
+	file, err := ParseAST(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse Caddyfile: %w", err)
+	}
+	if err := edit(file); err != nil {
+		return err
 	}
-
-	// Add blank line before new block for spacing
-	newContent += "\n" + block
 
 	// Write updated content with original permissions
-	if err := os.WriteFile(caddyfilePath, []byte(newContent), originalPerms); err != nil {
+	if err := os.WriteFile(caddyfilePath, []byte(file.String()), originalPerms); err != nil {
 		return fmt.Errorf("failed to write Caddyfile: %w", err)
 	}
 
 	return nil
 }
 
-// RemoveEntry removes a Caddy block from the Caddyfile by domain marker or domain line
-// Uses brace counting to handle nested blocks correctly
-func RemoveEntry(caddyfilePath string, domain string) error {
-	// Get original file permissions
-	fileInfo, err := os.Stat(caddyfilePath)
-	if err != nil {
-		return fmt.Errorf("failed to stat Caddyfile: %w", err)
-	}
-	originalPerms := fileInfo.Mode().Perm()
-
-	// Read current content
-	content, err := os.ReadFile(caddyfilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read Caddyfile: %w", err)
-	}
+// AppendEntry appends a Caddy block to the end of the Caddyfile, separated by a blank line
+func AppendEntry(caddyfilePath string, block string) error {
+	return editCaddyfile(caddyfilePath, func(file *File) error {
+		return file.Append(block)
+	})
+}
 
-	lines := strings.Split(string(content), "\n")
-	newLines := []string{}
-	skipBlock := false
-	skipMarker := false
-	braceCount := 0
-	marker := fmt.Sprintf("# === %s ===", domain)
+// The functions below edit the file of the Caddyfile tree (the Caddyfile or a
+// file it imports) that holds the site, route or snippet.
 
-	for i, line := range lines {
-		// Check for marker comment (skip this line if found)
-		if strings.TrimSpace(line) == marker {
-			skipMarker = true
-			skipBlock = true
-			continue
+// RemoveEntry removes the site block serving domain, with its marker and
+// other attached comments. A domain served by a host route inside a wildcard
+// site block has just that route removed. Everything else in the file is left
+// untouched; an imported file left empty is deleted.
+func RemoveEntry(caddyfilePath string, domain string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		if src := tree.find(func(f *File) bool { return f.FindSite(domain) != nil }); src != nil {
+			src.File.Remove(src.File.FindSite(domain))
+			return nil
+		}
+		src := tree.find(func(f *File) bool {
+			_, route := f.FindHostRoute(domain)
+			return route != nil
+		})
+		if src == nil {
+			return fmt.Errorf("site %s not found in Caddyfile", domain)
 		}
+		return src.File.RemoveHostRoute(domain)
+	})
+}
 
-		// Check for domain line (with or without marker)
-		stripped := strings.TrimSpace(line)
-		domainPattern1 := domain + " {"
-		domainPattern2 := domain + "{"
-
-		if !skipBlock && (stripped == domainPattern1 || stripped == domainPattern2 || strings.HasPrefix(stripped, domain+",") || strings.HasPrefix(stripped, domain+" ")) {
-			// Check if this line ends with opening brace
-			if strings.HasSuffix(stripped, "{") {
-				skipBlock = true
-				braceCount = 1
-				// Skip marker line if it's on the previous line
-				if i > 0 && strings.TrimSpace(lines[i-1]) == marker && !skipMarker {
-					// Remove the marker that was already added to newLines
-					if len(newLines) > 0 {
-						newLines = newLines[:len(newLines)-1]
-					}
-				}
-				continue
+// AddHostRoute adds a route for hosts to the wildcard or catch-all site block
+// serving them. body holds the route's directives as GenerateRouteBody returns them.
+func AddHostRoute(caddyfilePath string, hosts []string, body string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		if len(hosts) == 0 {
+			return fmt.Errorf("no hosts for route")
+		}
+		src := tree.find(func(f *File) bool { return f.CoveringSite(hosts[0]) != nil })
+		if src == nil {
+			return fmt.Errorf("no wildcard site block serves %s", hosts[0])
+		}
+		file := src.File
+		site := file.CoveringSite(hosts[0])
+		for _, host := range hosts[1:] {
+			if file.CoveringSite(host) != site {
+				return fmt.Errorf("%s and %s are served by different site blocks", hosts[0], host)
 			}
 		}
+		return file.AddHostRoute(site, hosts, body)
+	})
+}
 
-		if skipBlock {
-			if strings.HasSuffix(stripped, "{") && stripped != "" {
-				braceCount++
-			}
-			if stripped == "}" {
-				braceCount--
-				if braceCount == 0 {
-					skipBlock = false
-					skipMarker = false
-				}
-			}
-			continue
+// ReplaceHostRoute rewrites the host route serving domain with new hosts and
+// body, keeping its matcher name and position in the wildcard site block
+func ReplaceHostRoute(caddyfilePath string, domain string, hosts []string, body string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		src := tree.find(func(f *File) bool {
+			_, route := f.FindHostRoute(domain)
+			return route != nil
+		})
+		if src == nil {
+			return fmt.Errorf("host route %s not found in Caddyfile", domain)
 		}
+		return src.File.ReplaceHostRoute(domain, hosts, body)
+	})
+}
 
-		newLines = append(newLines, line)
-	}
+// ReplaceEntry replaces the site block serving domain with block, keeping its
+// position in the file and any comments above it
+func ReplaceEntry(caddyfilePath string, domain string, block string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		src := tree.find(func(f *File) bool { return f.FindSite(domain) != nil })
+		if src == nil {
+			return fmt.Errorf("site %s not found in Caddyfile", domain)
+		}
+		return src.File.Replace(src.File.FindSite(domain), block)
+	})
+}
 
-	// Write updated content with original permissions
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(caddyfilePath, []byte(newContent), originalPerms); err != nil {
-		return fmt.Errorf("failed to write Caddyfile: %w", err)
-	}
+// UpdateSnippet replaces the content of a snippet, keeping its (name) { and } lines
+func UpdateSnippet(caddyfilePath string, name string, content string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		src := tree.find(func(f *File) bool { return f.FindSnippet(name) != nil })
+		if src == nil {
+			return fmt.Errorf("snippet %s not found in Caddyfile", name)
+		}
+		return src.File.SetContent(src.File.FindSnippet(name), content)
+	})
+}
 
-	return nil
+// RemoveSnippet removes a snippet definition from the Caddyfile
+func RemoveSnippet(caddyfilePath string, name string) error {
+	return editTree(caddyfilePath, func(tree *Tree) error {
+		src := tree.find(func(f *File) bool { return f.FindSnippet(name) != nil })
+		if src == nil {
+			return fmt.Errorf("snippet %s not found in Caddyfile", name)
+		}
+		src.File.Remove(src.File.FindSnippet(name))
+		return nil
+	})
 }
 
-// RestoreFromBackup restores a Caddyfile from a backup
+// RestoreFromBackup restores a Caddyfile from a backup, along with the files
+// it imported when the backup was taken. Files imported since are removed.
 func RestoreFromBackup(caddyfilePath, backupPath string) error {
 	// Get backup file permissions to preserve them
 	backupInfo, err := os.Stat(backupPath)
@@ -216,12 +246,17 @@ func RestoreFromBackup(caddyfilePath, backupPath string) error {
 		return fmt.Errorf("failed to read backup: %w", err)
 	}
 
+	var before *Tree
+	if _, err := os.Stat(backupPath + ImportsSuffix); err == nil {
+		before, _ = LoadTree(caddyfilePath)
+	}
+
 	// Restore to original location with backup's permissions
 	if err := os.WriteFile(caddyfilePath, content, backupPerms); err != nil {
 		return fmt.Errorf("failed to restore Caddyfile: %w", err)
 	}
 
-	return nil
+	return restoreImports(before, backupPath)
 }
 
 // FormatCaddyfile runs caddy fmt to format the Caddyfile
@@ -290,9 +325,7 @@ func ValidateCaddyfile(caddyfilePath, containerPath, containerName, composeFileP
 		if containerPath != "" {
 			pathForValidation = containerPath
 		}
-		cmdStr = strings.ReplaceAll(validationCommand, "{path}", pathForValidation)
-		cmdStr = strings.ReplaceAll(cmdStr, "{container}", containerName)
-		cmdStr = strings.ReplaceAll(cmdStr, "{compose_file}", composeFilePath)
+		cmdStr = ExpandCommand(validationCommand, pathForValidation, containerName, composeFilePath)
 
 		parts := strings.Fields(cmdStr)
 		if len(parts) == 0 {
@@ -328,6 +361,49 @@ func RestartCaddy(containerName string) error {
 	return nil
 }
 
+// ExpandCommand fills in the {path}, {container} and {compose_file} placeholders of a command template
+func ExpandCommand(template, path, containerName, composeFilePath string) string {
+	cmdStr := strings.ReplaceAll(template, "{path}", path)
+	cmdStr = strings.ReplaceAll(cmdStr, "{container}", containerName)
+	cmdStr = strings.ReplaceAll(cmdStr, "{compose_file}", composeFilePath)
+	return cmdStr
+}
+
+// ReloadCaddy applies the Caddyfile by running the configured reload command
+// and returns the command's combined output.
+// Parameters:
+//   - caddyfilePath: Host path (for reading/writing)
+//   - containerPath: Path inside Docker container. If empty, uses caddyfilePath
+//   - containerName: Docker container name
+//   - composeFilePath: Path to docker-compose.yml (for compose method)
+//   - reloadCommand: Reload command with {path}, {container}, {compose_file} placeholders.
+//     If empty, falls back to restarting the container with RestartCaddy
+func ReloadCaddy(caddyfilePath, containerPath, containerName, composeFilePath, reloadCommand string) (string, error) {
+	if reloadCommand == "" {
+		if containerName == "" {
+			return "", fmt.Errorf("no reload command configured")
+		}
+		return "", RestartCaddy(containerName)
+	}
+
+	pathForReload := caddyfilePath
+	if containerPath != "" {
+		pathForReload = containerPath
+	}
+	cmdStr := ExpandCommand(reloadCommand, pathForReload, containerName, composeFilePath)
+
+	parts := strings.Fields(cmdStr)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("invalid reload command: empty command")
+	}
+
+	output, err := exec.Command(parts[0], parts[1:]...).CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("reload command failed: %s\nCommand: %s\nOutput: %s", err, cmdStr, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // BackupInfo holds information about a backup file
 type BackupInfo struct {
 	Path      string
@@ -335,8 +411,9 @@ type BackupInfo struct {
 	Size      int64
 }
 
-// ListBackups returns a list of all Caddyfile backups, sorted by timestamp (newest first)
-func ListBackups(caddyfilePath string) ([]BackupInfo, error) {
+// findBackups returns the paths of all Caddyfile backups. Companion files
+// stored next to a backup (<backup>.*.json, e.g., its DNS snapshot) are skipped.
+func findBackups(caddyfilePath string) ([]string, error) {
 	dir := filepath.Dir(caddyfilePath)
 	baseName := filepath.Base(caddyfilePath)
 	pattern := baseName + ".backup.*"
@@ -346,6 +423,34 @@ func ListBackups(caddyfilePath string) ([]BackupInfo, error) {
 		return nil, fmt.Errorf("failed to find backups: %w", err)
 	}
 
+	backups := matches[:0]
+	for _, match := range matches {
+		if filepath.Ext(match) != ".json" {
+			backups = append(backups, match)
+		}
+	}
+	return backups, nil
+}
+
+// RemoveBackup deletes a backup along with its companion files
+func RemoveBackup(backupPath string) error {
+	if err := os.Remove(backupPath); err != nil {
+		return err
+	}
+	companions, _ := filepath.Glob(backupPath + ".*.json")
+	for _, companion := range companions {
+		os.Remove(companion)
+	}
+	return nil
+}
+
+// ListBackups returns a list of all Caddyfile backups, sorted by timestamp (newest first)
+func ListBackups(caddyfilePath string) ([]BackupInfo, error) {
+	matches, err := findBackups(caddyfilePath)
+	if err != nil {
+		return nil, err
+	}
+
 	var backups []BackupInfo
 	for _, match := range matches {
 		info, err := os.Stat(match)
@@ -374,13 +479,9 @@ func ListBackups(caddyfilePath string) ([]BackupInfo, error) {
 
 // GetOldBackups returns a list of backups older than maxAge without deleting them
 func GetOldBackups(caddyfilePath string, maxAge time.Duration) ([]BackupInfo, error) {
-	dir := filepath.Dir(caddyfilePath)
-	baseName := filepath.Base(caddyfilePath)
-	pattern := baseName + ".backup.*"
-
-	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	matches, err := findBackups(caddyfilePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find backups: %w", err)
+		return nil, err
 	}
 
 	var oldBackups []BackupInfo
@@ -414,13 +515,9 @@ func GetOldBackups(caddyfilePath string, maxAge time.Duration) ([]BackupInfo, er
 
 // CleanupOldBackups removes backup files older than a specified duration
 func CleanupOldBackups(caddyfilePath string, maxAge time.Duration) error {
-	dir := filepath.Dir(caddyfilePath)
-	baseName := filepath.Base(caddyfilePath)
-	pattern := baseName + ".backup.*"
-
-	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	matches, err := findBackups(caddyfilePath)
 	if err != nil {
-		return fmt.Errorf("failed to find backups: %w", err)
+		return err
 	}
 
 	now := time.Now()
@@ -431,7 +528,7 @@ func CleanupOldBackups(caddyfilePath string, maxAge time.Duration) error {
 		}
 
 		if now.Sub(info.ModTime()) > maxAge {
-			os.Remove(match)
+			RemoveBackup(match)
 		}
 	}
 
@@ -457,7 +554,7 @@ func CleanupByCount(caddyfilePath string, maxCount int) (int, error) {
 	deleted := 0
 	// backups are sorted newest first, so delete from maxCount onwards
 	for _, b := range backups[maxCount:] {
-		if err := os.Remove(b.Path); err == nil {
+		if err := RemoveBackup(b.Path); err == nil {
 			deleted++
 		}
 	}
@@ -490,7 +587,7 @@ func CleanupBySize(caddyfilePath string, maxMB int) (int, error) {
 	deleted := 0
 	// Delete oldest first (backups sorted newest first, so iterate from end)
 	for i := len(backups) - 1; i >= 0 && totalSize > maxBytes; i-- {
-		if err := os.Remove(backups[i].Path); err == nil {
+		if err := RemoveBackup(backups[i].Path); err == nil {
 			totalSize -= backups[i].Size
 			deleted++
 		}
@@ -152,6 +152,40 @@ func TestValidateCaddyfilePathReplacement(t *testing.T) {
 	}
 }
 
+// TestReloadCaddyCustomCommand tests that the reload template is expanded and its output returned
+func TestReloadCaddyCustomCommand(t *testing.T) {
+	output, err := ReloadCaddy("/srv/Caddyfile", "/etc/caddy/Caddyfile", "caddy-test", "/srv/compose.yml",
+		"echo reload {container} {path} {compose_file}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "reload caddy-test /etc/caddy/Caddyfile /srv/compose.yml" {
+		t.Errorf("unexpected output: %q", output)
+	}
+
+	// Without a container path, {path} is the host path
+	output, err = ReloadCaddy("/srv/Caddyfile", "", "", "", "echo {path}")
+	if err != nil || output != "/srv/Caddyfile" {
+		t.Errorf("expected host path, got %q (err: %v)", output, err)
+	}
+}
+
+// TestReloadCaddyFailure tests that a failing reload reports the command and its output
+func TestReloadCaddyFailure(t *testing.T) {
+	_, err := ReloadCaddy("/srv/Caddyfile", "", "", "", "ls {path}")
+	if err == nil {
+		t.Fatal("expected reload to fail for missing file")
+	}
+	if !contains(err.Error(), "Command: ls /srv/Caddyfile") || !contains(err.Error(), "Output:") {
+		t.Errorf("error should include command and output: %v", err)
+	}
+
+	// No template and no container means there is nothing to run
+	if _, err := ReloadCaddy("/srv/Caddyfile", "", "", "", ""); err == nil {
+		t.Error("expected error when no reload command is configured")
+	}
+}
+
 func TestCleanupByCount(t *testing.T) {
 	tmpDir := t.TempDir()
 	caddyfilePath := filepath.Join(tmpDir, "Caddyfile")
@@ -238,3 +272,27 @@ func TestCleanupByCountNoop(t *testing.T) {
 		t.Errorf("expected 0 deleted, got %d", deleted)
 	}
 }
+
+func TestBackupCompanionFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	caddyfilePath := filepath.Join(tmpDir, "Caddyfile")
+	os.WriteFile(caddyfilePath, []byte("test"), 0644)
+
+	backupPath := filepath.Join(tmpDir, "Caddyfile.backup.20240101_120000")
+	os.WriteFile(backupPath, []byte("backup content"), 0644)
+	os.WriteFile(backupPath+".dns.json", []byte("{}"), 0644)
+
+	// Companion files are not backups themselves
+	backups, _ := ListBackups(caddyfilePath)
+	if len(backups) != 1 || backups[0].Path != backupPath {
+		t.Fatalf("expected only the backup listed, got %+v", backups)
+	}
+
+	// ... and are removed with their backup
+	if err := RemoveBackup(backupPath); err != nil {
+		t.Fatalf("RemoveBackup: %v", err)
+	}
+	if _, err := os.Stat(backupPath + ".dns.json"); !os.IsNotExist(err) {
+		t.Errorf("expected DNS snapshot removed with its backup, got %v", err)
+	}
+}
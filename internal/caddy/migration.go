@@ -12,6 +12,10 @@ type MigrationOptions struct {
 	ImportSnippets bool // Import snippets
 	ArchiveOld     bool // Archive existing Caddyfile
 	FreshTemplate  bool // Start with fresh template (vs preserve global options)
+
+	// TrustCloudflare adds Cloudflare's IP ranges as trusted_proxies to the
+	// global options, so IP restrictions work for proxied entries
+	TrustCloudflare bool
 }
 
 // ArchiveCaddyfile creates a timestamped backup of the Caddyfile
@@ -93,6 +97,18 @@ func GenerateFreshCaddyfile(migrationContent *MigrationContent, options Migratio
 		result += "# Domain entries will be added here by the application\n"
 	}
 
+	// 6. Trust Cloudflare's proxies in the global options
+	if options.TrustCloudflare {
+		file, err := ParseAST(result)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse generated Caddyfile: %w", err)
+		}
+		if err := file.TrustCloudflare(); err != nil {
+			return "", fmt.Errorf("failed to add trusted proxies: %w", err)
+		}
+		result = file.String()
+	}
+
 	return result, nil
 }
 
@@ -159,6 +175,8 @@ func GetMigrationSummary(migrationContent *MigrationContent, options MigrationOp
 		formatImportAction(options.ImportSnippets))
 	summary += fmt.Sprintf("- Global Options: %s\n",
 		formatGlobalAction(migrationContent.HasGlobalOptions(), options.FreshTemplate))
+	summary += fmt.Sprintf("- Trust Cloudflare Proxies: %s\n",
+		formatBoolAction(options.TrustCloudflare))
 	summary += fmt.Sprintf("- Archive Original: %s\n",
 		formatBoolAction(options.ArchiveOld))
 
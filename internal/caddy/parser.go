@@ -2,49 +2,11 @@ package caddy
 
 import (
 	"log"
+	"slices"
 	"strconv"
 	"strings"
 )
 
-// countBracesOutsideQuotes counts { and } characters that are not inside quoted strings.
-// Returns (openCount, closeCount).
-func countBracesOutsideQuotes(line string) (int, int) {
-	openCount := 0
-	closeCount := 0
-	inQuote := false
-	quoteChar := rune(0)
-	escaped := false
-
-	for _, ch := range line {
-		if escaped {
-			escaped = false
-			continue
-		}
-		if ch == '\\' {
-			escaped = true
-			continue
-		}
-		if !inQuote && (ch == '"' || ch == '\'') {
-			inQuote = true
-			quoteChar = ch
-			continue
-		}
-		if inQuote && ch == quoteChar {
-			inQuote = false
-			quoteChar = 0
-			continue
-		}
-		if !inQuote {
-			if ch == '{' {
-				openCount++
-			} else if ch == '}' {
-				closeCount++
-			}
-		}
-	}
-	return openCount, closeCount
-}
-
 // ParserLogger is an optional logger for parser debugging
 var ParserLogger *log.Logger
 
@@ -54,81 +16,91 @@ type ParsedCaddyfile struct {
 	Snippets []Snippet // List of parsed snippets with full metadata
 }
 
-// ParseCaddyfile parses a Caddyfile and extracts domain entries
+// ParseCaddyfile parses a Caddyfile and extracts domain entries.
+// On a syntax error the entries that could be parsed are returned with the error.
 func ParseCaddyfile(content string) ([]CaddyEntry, error) {
-	parsed := ParseCaddyfileWithSnippets(content)
-	return parsed.Entries, nil
+	file, err := ParseAST(content)
+	return parsedFromAST(file).Entries, err
 }
 
-// ParseCaddyfileWithSnippets parses a Caddyfile and extracts both domain entries and snippets
+// ParseCaddyfileWithSnippets parses a Caddyfile and extracts both domain entries and snippets.
+// Syntax errors are logged to ParserLogger; everything that could be parsed is returned.
 func ParseCaddyfileWithSnippets(content string) ParsedCaddyfile {
-	var entries []CaddyEntry
-	var snippets []Snippet
-	lines := strings.Split(content, "\n")
-
-	// Skip global block and snippet definitions
-	inGlobalBlock := false
-
-	for i := 0; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
+	file, err := ParseAST(content)
+	if err != nil && ParserLogger != nil {
+		ParserLogger.Printf("Caddyfile syntax error: %v", err)
+	}
+	return parsedFromAST(file)
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// LoadCaddyfile reads a Caddyfile and the files it imports (see LoadTree) and
+// extracts their entries and snippets, each with the path of its file.
+// Syntax and import errors are logged to ParserLogger and everything that
+// could be parsed is returned; only an unreadable Caddyfile is an error.
+func LoadCaddyfile(caddyfilePath string) (ParsedCaddyfile, error) {
+	tree, err := LoadTree(caddyfilePath)
+	if tree == nil {
+		return ParsedCaddyfile{}, err
+	}
+	if ParserLogger != nil {
+		if err != nil {
+			ParserLogger.Printf("Caddyfile error: %v", err)
 		}
-
-		// Detect global block
-		if line == "{" && i == 0 {
-			inGlobalBlock = true
-			continue
+		for _, warning := range tree.Warnings {
+			ParserLogger.Printf("Caddyfile import skipped: %v", warning)
 		}
+	}
 
-		// Parse snippet definitions: (name) {
-		if strings.HasPrefix(line, "(") && strings.Contains(line, ")") {
-			snippet, endLine := parseSnippetBlock(lines, i)
-			if snippet != nil {
-				snippets = append(snippets, *snippet)
-				if ParserLogger != nil {
-					ParserLogger.Printf("✓ Parsed snippet: %s (category: %s, confidence: %.2f) at lines %d-%d",
-						snippet.Name, snippet.Category, snippet.Confidence, snippet.LineStart, snippet.LineEnd)
-				}
-			}
-			i = endLine
-			continue
+	var parsed ParsedCaddyfile
+	for _, src := range tree.Sources {
+		fileParsed := parsedFromAST(src.File)
+		for i := range fileParsed.Entries {
+			fileParsed.Entries[i].File = src.Path
 		}
+		for i := range fileParsed.Snippets {
+			fileParsed.Snippets[i].File = src.Path
+		}
+		parsed.Entries = append(parsed.Entries, fileParsed.Entries...)
+		parsed.Snippets = append(parsed.Snippets, fileParsed.Snippets...)
+	}
+	return parsed, nil
+}
 
-		// Look for domain blocks: domain.com { or domain.com, other.com {
-		if strings.HasSuffix(line, "{") && !inGlobalBlock {
-			// Check if this looks like a domain (has a dot or is a domain pattern)
-			domainPart := strings.TrimSuffix(line, "{")
-			domainPart = strings.TrimSpace(domainPart)
+// parsedFromAST builds entries and snippets from the top-level blocks
+func parsedFromAST(file *File) ParsedCaddyfile {
+	var entries []CaddyEntry
+	var snippets []Snippet
 
-			// Skip if it's a matcher block like @external {
-			if strings.HasPrefix(domainPart, "@") {
-				if ParserLogger != nil {
-					ParserLogger.Printf("Skipping matcher block: %s at line %d", domainPart, i+1)
-				}
-				i = skipBlock(lines, i)
-				continue
+	for _, block := range file.Blocks {
+		switch block.Kind {
+		case BlockSnippet:
+			snippet := snippetFromBlock(block)
+			snippets = append(snippets, snippet)
+			if ParserLogger != nil {
+				ParserLogger.Printf("✓ Parsed snippet: %s (category: %s, confidence: %.2f) at lines %d-%d",
+					snippet.Name, snippet.Category, snippet.Confidence, snippet.LineStart, snippet.LineEnd)
 			}
-
-			// Parse domain block
+		case BlockSite:
+			routes := HostRoutes(block)
+			entry := entryFromBlock(block, routes)
+			entries = append(entries, entry)
 			if ParserLogger != nil {
-				ParserLogger.Printf("Parsing domain block: %s at line %d", domainPart, i+1)
+				ParserLogger.Printf("✓ Parsed entry for %s (lines %d-%d)", entry.Domain, entry.LineStart, entry.LineEnd)
 			}
-			entry, endLine := parseDomainBlock(lines, i)
-			if entry != nil {
-				entries = append(entries, *entry)
+
+			// Host routes in a wildcard block are entries of their own
+			for _, route := range routes {
+				routeEntry := entryFromHostRoute(block, route)
+				entries = append(entries, routeEntry)
 				if ParserLogger != nil {
-					ParserLogger.Printf("✓ Parsed entry for %s (lines %d-%d)", entry.Domain, entry.LineStart, entry.LineEnd)
+					ParserLogger.Printf("✓ Parsed host route %s in %s for %s (lines %d-%d)",
+						route.Matcher, entry.Domain, routeEntry.Domain, routeEntry.LineStart, routeEntry.LineEnd)
 				}
 			}
-			i = endLine
-		}
-
-		// Check if we're exiting global block
-		if inGlobalBlock && line == "}" {
-			inGlobalBlock = false
+		case BlockMatcher:
+			if ParserLogger != nil {
+				ParserLogger.Printf("Skipping matcher block: %s at line %d", strings.Join(block.Keys, " "), block.LineStart)
+			}
 		}
 	}
 
@@ -138,65 +110,15 @@ func ParseCaddyfileWithSnippets(content string) ParsedCaddyfile {
 	}
 }
 
-// skipBlock skips an entire block by counting braces
-func skipBlock(lines []string, start int) int {
-	// Count braces on start line
-	open, close := countBracesOutsideQuotes(lines[start])
-	braceCount := open - close
-
-	for i := start + 1; i < len(lines); i++ {
-		line := lines[i]
-		open, close := countBracesOutsideQuotes(line)
-		braceCount += open
-		braceCount -= close
-
-		if braceCount == 0 {
-			return i
-		}
+// snippetFromBlock builds a Snippet from a (name) { ... } block
+func snippetFromBlock(block *Block) Snippet {
+	snippet := Snippet{
+		Name:      block.Keys[0],
+		Content:   block.Content(),
+		LineStart: block.LineStart,
+		LineEnd:   block.LineEnd,
 	}
 
-	return len(lines) - 1
-}
-
-// parseSnippetBlock extracts a snippet definition and its content
-func parseSnippetBlock(lines []string, start int) (*Snippet, int) {
-	snippet := &Snippet{
-		LineStart: start + 1, // 1-indexed
-	}
-
-	// Extract snippet name from first line: (snippet_name) {
-	firstLine := strings.TrimSpace(lines[start])
-	snippetName := strings.TrimPrefix(firstLine, "(")
-	snippetName = strings.TrimSuffix(snippetName, " {")
-	snippetName = strings.TrimSuffix(snippetName, "{")
-	snippetName = strings.TrimSpace(snippetName)
-	snippetName = strings.TrimSuffix(snippetName, ")")
-	snippet.Name = snippetName
-
-	// Extract complete block with brace counting
-	braceCount := 1 // Opening brace on first line
-	endLine := start
-	var contentLines []string
-
-	for i := start + 1; i < len(lines); i++ {
-		line := lines[i]
-
-		open, close := countBracesOutsideQuotes(line)
-		braceCount += open
-		braceCount -= close
-
-		// Don't include the closing brace in content
-		if braceCount == 0 {
-			endLine = i
-			break
-		}
-
-		contentLines = append(contentLines, line)
-	}
-
-	snippet.LineEnd = endLine + 1 // 1-indexed
-	snippet.Content = strings.Join(contentLines, "\n")
-
 	// Auto-categorize the snippet
 	category, confidence := CategorizeSnippet(snippet.Content)
 	snippet.Category = category
@@ -206,185 +128,299 @@ func parseSnippetBlock(lines []string, start int) (*Snippet, int) {
 	// Generate description
 	snippet.Description = GenerateDescription(category, snippet.Content)
 
-	return snippet, endLine
+	return snippet
 }
 
-// parseDomainBlock extracts a complete domain block and parses its contents
-func parseDomainBlock(lines []string, start int) (*CaddyEntry, int) {
-	entry := &CaddyEntry{
-		LineStart: start + 1, // 1-indexed
+// entryFromBlock builds a CaddyEntry from a site block. Directives belonging to
+// host routes describe those routes, not the site itself.
+func entryFromBlock(block *Block, routes []HostRoute) CaddyEntry {
+	entry := CaddyEntry{
+		Domains:   append([]string{}, block.Keys...),
 		Imports:   []string{},
-		Domains:   []string{},
+		RawBlock:  block.Raw,
+		LineStart: block.LineStart,
+		LineEnd:   block.LineEnd,
+		HasMarker: block.HasMarker(),
 	}
-
-	// Extract domain(s) from first line
-	firstLine := strings.TrimSpace(lines[start])
-
-	// More aggressive whitespace handling - handle domain.com{, domain.com  {, etc.
-	// Find the opening brace and extract everything before it
-	braceIndex := strings.Index(firstLine, "{")
-	var domainPart string
-	if braceIndex >= 0 {
-		domainPart = strings.TrimSpace(firstLine[:braceIndex])
-	} else {
-		// Shouldn't happen if we got here, but handle it gracefully
-		domainPart = strings.TrimSuffix(firstLine, "{")
-		domainPart = strings.TrimSpace(domainPart)
+	if len(entry.Domains) > 0 {
+		entry.Domain = entry.Domains[0] // Primary is first
 	}
 
-	// Check for marker comment on previous line
-	if start > 0 {
-		prevLine := strings.TrimSpace(lines[start-1])
-		if strings.HasPrefix(prevLine, "# ===") && strings.HasSuffix(prevLine, "===") {
-			entry.HasMarker = true
-		}
+	routed := make(map[*Directive]bool)
+	for _, route := range routes {
+		routed[route.Define] = true
+		routed[route.Handler] = true
 	}
-
-	// Handle multiple domains (comma-separated)
-	// More aggressive whitespace trimming for each domain
-	if strings.Contains(domainPart, ",") {
-		domains := strings.Split(domainPart, ",")
-		for _, d := range domains {
-			d = strings.TrimSpace(d)
-			if d != "" { // Skip empty strings from extra commas
-				entry.Domains = append(entry.Domains, d)
-			}
-		}
-		if len(entry.Domains) > 0 {
-			entry.Domain = entry.Domains[0] // Primary is first
+	var body []*Directive
+	for _, d := range block.Body {
+		if !routed[d] {
+			body = append(body, d)
 		}
-	} else {
-		entry.Domain = strings.TrimSpace(domainPart)
-		entry.Domains = []string{entry.Domain}
 	}
+	entry.Routes, body = splitPathRoutes(body)
+	parseBlockContents(&entry, body)
+	setDefaultPort(&entry)
+	dropSiteGuards(&entry)
 
-	// Extract complete block with brace counting
-	blockLines := []string{lines[start]}
-	braceCount := 1
-	endLine := start
-
-	for i := start + 1; i < len(lines); i++ {
-		line := lines[i]
-		blockLines = append(blockLines, line)
+	return entry
+}
 
-		open, close := countBracesOutsideQuotes(line)
-		braceCount += open
-		braceCount -= close
+// entryFromHostRoute builds a CaddyEntry for a host route in a wildcard site block
+func entryFromHostRoute(block *Block, route HostRoute) CaddyEntry {
+	entry := CaddyEntry{
+		Domain:     route.Hosts[0],
+		Domains:    append([]string{}, route.Hosts...),
+		Imports:    []string{},
+		LineStart:  min(route.Define.LineStart, route.Handler.LineStart),
+		LineEnd:    max(route.Define.LineEnd, route.Handler.LineEnd),
+		ParentSite: block.Keys[0],
+		Matcher:    route.Matcher,
+	}
+	if n := len(route.Define.Comments); n > 0 {
+		entry.HasMarker = isMarkerComment(route.Define.Comments[n-1])
+	}
 
-		if braceCount == 0 {
-			endLine = i
-			break
-		}
+	// Raw text of the matcher and its handler
+	lines := blockLines(block)
+	for _, d := range []*Directive{route.Define, route.Handler} {
+		entry.RawBlock += strings.Join(lines[d.LineStart-block.LineStart:d.LineEnd-block.LineStart+1], "")
 	}
+	entry.RawBlock = strings.TrimRight(entry.RawBlock, "\r\n")
 
-	entry.LineEnd = endLine + 1 // 1-indexed
-	entry.RawBlock = strings.Join(blockLines, "\n")
+	body := []*Directive{route.Handler}
+	if route.Handler.Name() == "handle" {
+		entry.Routes, body = splitPathRoutes(route.Handler.Block)
+	}
+	parseBlockContents(&entry, body)
+	setDefaultPort(&entry)
+	dropSiteGuards(&entry)
 
-	// Parse block contents
-	parseBlockContents(entry, blockLines)
+	return entry
+}
 
-	// Set default port if not specified
+// setDefaultPort fills in the port implied by the scheme when none was given
+func setDefaultPort(entry *CaddyEntry) {
 	if entry.Port == 0 {
-		if entry.SSL {
-			entry.Port = 443
-		} else {
-			entry.Port = 80
-		}
+		entry.Port = defaultPort(entry.SSL)
 	}
-
-	return entry, endLine
 }
 
-// parseBlockContents extracts configuration details from block lines
-func parseBlockContents(entry *CaddyEntry, lines []string) {
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+// defaultPort returns the port implied by the scheme
+func defaultPort(ssl bool) int {
+	if ssl {
+		return 443
+	}
+	return 80
+}
 
-		// Parse reverse_proxy directive
-		if strings.HasPrefix(trimmed, "reverse_proxy ") {
-			parseReverseProxy(entry, trimmed)
-		}
+// parseBlockContents extracts configuration details from a site's directives,
+// including those nested in handle, route and reverse_proxy blocks. A site
+// with a reverse_proxy is a reverse proxy; otherwise one with a file_server
+// is a static site and one whose own body redirects every path is a redirect
+// (a redir nested in a handle or route block only covers some requests).
+func parseBlockContents(entry *CaddyEntry, body []*Directive) {
+	proxied, served, redirected := false, false, false
+	WalkDirectives(body, func(d *Directive) {
+		name := d.Name()
+		args := d.Args()
+
+		switch {
+		case name == "reverse_proxy":
+			proxied = true
+			if upstreams := reverseProxyUpstreams(d); len(upstreams) > 0 {
+				parseUpstream(entry, upstreams[0])
+				entry.Upstreams = nil
+				for _, upstream := range upstreams[1:] {
+					entry.Upstreams = append(entry.Upstreams, furtherUpstream(entry, upstream))
+				}
+				entry.LoadBalancing = LoadBalancing{}
+				entry.ProxyOptions = nil
+				for _, sub := range d.Block {
+					if !entry.LoadBalancing.set(sub.Name(), sub.Args()) && isProxyOption(sub) {
+						entry.ProxyOptions = append(entry.ProxyOptions, directiveText(sub))
+					}
+				}
+			}
 
-		// Parse import statements
-		if strings.HasPrefix(trimmed, "import ") {
-			importName := strings.TrimPrefix(trimmed, "import ")
-			importName = strings.TrimSpace(importName)
-			entry.Imports = append(entry.Imports, importName)
+		case name == "import" && len(args) > 0:
+			entry.Imports = append(entry.Imports, args[0])
 
 			// Check for IP restriction import
-			if importName == "ip_restricted" {
+			if args[0] == "ip_restricted" {
 				entry.IPRestricted = true
 			}
-		}
 
 		// Detect inline IP restriction matcher
-		if strings.HasPrefix(trimmed, "@") && strings.Contains(trimmed, "not_allowed") {
+		case strings.HasPrefix(name, "@") && (strings.Contains(name, "not_allowed") || strings.Contains(name, "external")):
 			entry.IPRestricted = true
+
+		case name == "header_up" && len(args) > 0:
+			if slices.Contains(oauthHeaderLines, directiveLine(d)) {
+				entry.OAuthHeaders = true
+			}
+			switch args[0] {
+			// Detect OAuth headers
+			case "X-Real-IP", "X-Forwarded-For":
+				entry.OAuthHeaders = true
+			// Detect WebSocket support
+			case "Upgrade":
+				entry.WebSocket = true
+			case "Connection":
+				if strings.Contains(strings.Join(args[1:], " "), "Upgrade") {
+					entry.WebSocket = true
+				}
+			}
+
+		case name == "file_server":
+			served = true
+			if fileServerBrowses(d) {
+				entry.StaticBrowse = true
+			}
+
+		case name == "root" && entry.StaticRoot == "":
+			entry.StaticRoot = parseStaticRoot(args)
 		}
-		if strings.HasPrefix(trimmed, "@") && strings.Contains(trimmed, "external") {
-			entry.IPRestricted = true
+	})
+	for _, d := range body {
+		if d.Name() == "redir" && parseRedirect(entry, d.Args()) {
+			redirected = true
+			break
 		}
+	}
+
+	switch {
+	case proxied:
+		entry.Type = EntryProxy
+	case served:
+		entry.Type = EntryStatic
+	case redirected:
+		entry.Type = EntryRedirect
+	}
+}
 
-		// Detect OAuth headers
-		if strings.Contains(trimmed, "header_up X-Real-IP") {
-			entry.OAuthHeaders = true
+// isProxyOption reports whether a reverse_proxy subdirective is kept as
+// written: everything but the upstreams, snippet imports and the headers the
+// OAuth and WebSocket options write themselves
+func isProxyOption(sub *Directive) bool {
+	switch sub.Name() {
+	case "to", "import":
+		return false
+	case "header_up":
+		args := sub.Args()
+		line := directiveLine(sub)
+		if slices.Contains(oauthHeaderLines, line) || slices.Contains(webSocketHeaderLines, line) || (len(args) > 0 && args[0] == "Upgrade") {
+			return false
 		}
-		if strings.Contains(trimmed, "header_up X-Forwarded-For") {
-			entry.OAuthHeaders = true
+		return !(len(args) > 1 && args[0] == "Connection" && strings.Contains(strings.Join(args[1:], " "), "Upgrade"))
+	}
+	return true
+}
+
+// directiveLine returns a directive's name and arguments on one line
+func directiveLine(d *Directive) string {
+	return strings.Join(append([]string{d.Name()}, d.Args()...), " ")
+}
+
+// directiveText returns a directive as written, with the comments above it
+// and its block's lines indented by a tab
+func directiveText(d *Directive) string {
+	lines := append([]string{}, d.Comments...)
+	var words []string
+	for _, tok := range d.Tokens {
+		words = append(words, tok.Text)
+	}
+	line := strings.Join(words, " ")
+	if !d.HasBlock() {
+		return strings.Join(append(lines, line), "\n")
+	}
+	lines = append(lines, line+" {")
+	for _, sub := range d.Block {
+		for _, subLine := range strings.Split(directiveText(sub), "\n") {
+			lines = append(lines, "\t"+subLine)
 		}
+	}
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+// furtherUpstream returns an upstream after the first as kept in
+// CaddyEntry.Upstreams: without its scheme when it's the one upstreamList
+// gives it from the first upstream, as written otherwise
+func furtherUpstream(entry *CaddyEntry, upstream string) string {
+	scheme, host, ok := strings.Cut(upstream, "://")
+	if !ok {
+		return upstream
+	}
+	port := entry.Port
+	if port == 0 {
+		port = defaultPort(entry.SSL)
+	}
+	if scheme == upstreamScheme(port, entry.SSL) {
+		return host
+	}
+	return upstream
+}
+
+// reverseProxyUpstream returns the first upstream of a reverse_proxy directive
+// (see reverseProxyUpstreams)
+func reverseProxyUpstream(d *Directive) string {
+	if upstreams := reverseProxyUpstreams(d); len(upstreams) > 0 {
+		return upstreams[0]
+	}
+	return ""
+}
 
-		// Detect WebSocket support
-		if strings.Contains(trimmed, "header_up Upgrade") {
-			entry.WebSocket = true
+// reverseProxyUpstreams returns the upstreams of a reverse_proxy directive,
+// skipping a leading matcher, followed by those of "to" subdirectives
+func reverseProxyUpstreams(d *Directive) []string {
+	var upstreams []string
+	for i, arg := range d.Args() {
+		if i == 0 && (strings.HasPrefix(arg, "@") || strings.HasPrefix(arg, "/") || arg == "*") {
+			continue
 		}
-		if strings.Contains(trimmed, "header_up Connection") && strings.Contains(trimmed, "Upgrade") {
-			entry.WebSocket = true
+		upstreams = append(upstreams, arg)
+	}
+	for _, sub := range d.Block {
+		if sub.Name() == "to" {
+			upstreams = append(upstreams, sub.Args()...)
 		}
 	}
+	return upstreams
 }
 
-// parseReverseProxy extracts target, port, and SSL from reverse_proxy directive
-func parseReverseProxy(entry *CaddyEntry, line string) {
-	// Example: reverse_proxy https://10.0.28.9:32400
-	// Example: reverse_proxy http://localhost:80
-	// Example: reverse_proxy 10.0.28.3:4080
-
-	// Remove "reverse_proxy " prefix
-	line = strings.TrimPrefix(line, "reverse_proxy ")
-	line = strings.TrimSpace(line)
+// parseUpstream extracts target, port, and SSL from a reverse_proxy upstream
+func parseUpstream(entry *CaddyEntry, upstream string) {
+	entry.Target, entry.Port, entry.SSL = splitUpstream(upstream)
+}
 
-	// Remove any trailing {
-	line = strings.TrimSuffix(line, "{")
-	line = strings.TrimSpace(line)
+// splitUpstream splits a reverse_proxy upstream into target, port (0 if not
+// given) and SSL
+func splitUpstream(upstream string) (target string, port int, ssl bool) {
+	// Example: https://10.0.28.9:32400
+	// Example: http://localhost:80
+	// Example: 10.0.28.3:4080
 
 	// Check for SSL
-	if strings.HasPrefix(line, "https://") {
-		entry.SSL = true
-		line = strings.TrimPrefix(line, "https://")
-	} else if strings.HasPrefix(line, "http://") {
-		entry.SSL = false
-		line = strings.TrimPrefix(line, "http://")
+	if strings.HasPrefix(upstream, "https://") {
+		ssl = true
+		upstream = strings.TrimPrefix(upstream, "https://")
 	} else {
 		// No scheme specified, assume http
-		entry.SSL = false
+		upstream = strings.TrimPrefix(upstream, "http://")
+	}
+
+	// A placeholder ({$BACKEND}) is kept whole
+	if strings.HasPrefix(upstream, "{") && strings.HasSuffix(upstream, "}") {
+		return upstream, 0, ssl
 	}
 
 	// Extract host:port
 	// Could be: 10.0.28.9:32400 or localhost:80
-	parts := strings.Split(line, ":")
-	if len(parts) >= 1 {
-		entry.Target = parts[0]
-	}
+	parts := strings.Split(upstream, ":")
+	target = parts[0]
 	if len(parts) >= 2 {
-		// Extract port number (might have other params after)
-		portStr := parts[1]
-		// Port might be followed by space or other params
-		portFields := strings.Fields(portStr)
-		if len(portFields) > 0 {
-			if port, err := strconv.Atoi(portFields[0]); err == nil {
-				entry.Port = port
-			}
+		if p, err := strconv.Atoi(parts[1]); err == nil {
+			port = p
 		}
 	}
+	return target, port, ssl
 }
-
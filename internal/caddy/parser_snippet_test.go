@@ -13,12 +13,11 @@ func TestParseSnippetBlock(t *testing.T) {
 	respond @external 403
 }`
 
-	lines := strings.Split(caddyfile, "\n")
-	snippet, endLine := parseSnippetBlock(lines, 0)
-
-	if snippet == nil {
-		t.Fatal("Expected snippet to be parsed, got nil")
+	parsed := ParseCaddyfileWithSnippets(caddyfile)
+	if len(parsed.Snippets) != 1 {
+		t.Fatalf("Expected 1 snippet, got %d", len(parsed.Snippets))
 	}
+	snippet := parsed.Snippets[0]
 
 	// Verify snippet name
 	if snippet.Name != "ip_restricted" {
@@ -33,9 +32,6 @@ func TestParseSnippetBlock(t *testing.T) {
 	if snippet.LineEnd != 6 {
 		t.Errorf("Expected LineEnd=6, got %d", snippet.LineEnd)
 	}
-	if endLine != 5 {
-		t.Errorf("Expected endLine=5 (0-indexed), got %d", endLine)
-	}
 
 	// Verify content (should not include wrapper)
 	expectedContent := `	@external {
@@ -314,7 +310,7 @@ plex.angelsomething.com {
 	}
 }
 
-func TestCountBracesOutsideQuotes(t *testing.T) {
+func TestTokenizeBraces(t *testing.T) {
 	tests := []struct {
 		name      string
 		input     string
@@ -330,13 +326,28 @@ func TestCountBracesOutsideQuotes(t *testing.T) {
 		{"single quotes", `header 'value {with} braces'`, 0, 0},
 		{"no braces", "reverse_proxy localhost:8080", 0, 0},
 		{"nested real braces", "@matcher { not { remote_ip } }", 2, 2},
+		{"placeholders", "header_up Host {upstream_hostport} {$SUFFIX}", 0, 0},
+		{"attached brace", "example.com{", 1, 0},
+		{"backtick", "respond `{\"ok\": true}`", 0, 0},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotOpen, gotClose := countBracesOutsideQuotes(tt.input)
+			tokens, err := Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Tokenize(%q) error: %v", tt.input, err)
+			}
+			gotOpen, gotClose := 0, 0
+			for _, tok := range tokens {
+				switch tok.Kind {
+				case TokenOpenBrace:
+					gotOpen++
+				case TokenCloseBrace:
+					gotClose++
+				}
+			}
 			if gotOpen != tt.wantOpen || gotClose != tt.wantClose {
-				t.Errorf("countBracesOutsideQuotes(%q) = (%d, %d), want (%d, %d)",
+				t.Errorf("Tokenize(%q) braces = (%d, %d), want (%d, %d)",
 					tt.input, gotOpen, gotClose, tt.wantOpen, tt.wantClose)
 			}
 		})
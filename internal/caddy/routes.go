@@ -0,0 +1,136 @@
+package caddy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Upstream returns the route's reverse_proxy upstream
+func (r PathRoute) Upstream() string {
+	return upstreamURL(r.Target, r.Port, r.SSL)
+}
+
+// String formats a route as one line of ParsePathRoutes input
+func (r PathRoute) String() string {
+	parts := []string{r.Path, r.Upstream()}
+	if r.StripPrefix {
+		parts = append(parts, "strip")
+	}
+	for _, snippetName := range r.Snippets {
+		parts = append(parts, "+"+snippetName)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParsePathRoutes parses path routes written one per line as
+//
+//	<path> <upstream> [strip] [+snippet...]
+//
+// e.g. "/grafana/* grafana:3000 strip +ip_restricted". "strip" serves the
+// route with handle_path, so the upstream sees paths without the prefix.
+// The upstream is host[:port] with an optional http:// or https:// scheme;
+// the port defaults to 80 (443 for https). Blank lines are skipped.
+func ParsePathRoutes(text string) ([]PathRoute, error) {
+	var routes []PathRoute
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("route %d: expected \"<path> <upstream>\"", i+1)
+		}
+		if !strings.HasPrefix(fields[0], "/") {
+			return nil, fmt.Errorf("route %d: path %q must start with /", i+1, fields[0])
+		}
+		if slices.ContainsFunc(routes, func(r PathRoute) bool { return r.Path == fields[0] }) {
+			return nil, fmt.Errorf("route %d: path %s is routed more than once", i+1, fields[0])
+		}
+
+		route := PathRoute{Path: fields[0]}
+		route.Target, route.Port, route.SSL = splitUpstream(fields[1])
+		if route.Target == "" {
+			return nil, fmt.Errorf("route %d: invalid upstream %q", i+1, fields[1])
+		}
+		if route.Port == 0 {
+			route.Port = defaultPort(route.SSL)
+		}
+		for _, option := range fields[2:] {
+			switch {
+			case option == "strip":
+				route.StripPrefix = true
+			case strings.HasPrefix(option, "+") && len(option) > 1:
+				route.Snippets = append(route.Snippets, option[1:])
+			default:
+				return nil, fmt.Errorf("route %d: unknown option %q (use strip or +snippet)", i+1, option)
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// FormatPathRoutes formats routes as ParsePathRoutes input, one per line
+func FormatPathRoutes(routes []PathRoute) string {
+	lines := make([]string, len(routes))
+	for i, route := range routes {
+		lines[i] = route.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isPathRoute reports whether a directive of a site's body is a path route:
+// a handle or handle_path block whose matcher is a path
+func isPathRoute(d *Directive) bool {
+	name, args := d.Name(), d.Args()
+	return (name == "handle" || name == "handle_path") && len(args) == 1 &&
+		strings.HasPrefix(args[0], "/") && len(d.Block) > 0
+}
+
+// splitPathRoutes separates the path routes of a site's body from its other
+// directives
+func splitPathRoutes(body []*Directive) (routes []PathRoute, rest []*Directive) {
+	for _, d := range body {
+		if isPathRoute(d) {
+			routes = append(routes, pathRouteFromDirective(d))
+		} else {
+			rest = append(rest, d)
+		}
+	}
+	return routes, rest
+}
+
+// pathRouteFromDirective builds a PathRoute from a handle or handle_path block:
+// the first reverse_proxy upstream and the imported snippets
+func pathRouteFromDirective(d *Directive) PathRoute {
+	route := PathRoute{Path: d.Args()[0], StripPrefix: d.Name() == "handle_path"}
+	found := false
+	WalkDirectives(d.Block, func(sub *Directive) {
+		switch sub.Name() {
+		case "reverse_proxy":
+			if upstream := reverseProxyUpstream(sub); upstream != "" && !found {
+				route.Target, route.Port, route.SSL = splitUpstream(upstream)
+				found = true
+			}
+		case "import":
+			if args := sub.Args(); len(args) > 0 && !slices.Contains(route.Snippets, args[0]) {
+				route.Snippets = append(route.Snippets, args[0])
+			}
+		}
+	})
+	if route.Port == 0 {
+		route.Port = defaultPort(route.SSL)
+	}
+	return route
+}
+
+// dropSiteGuards removes from the routes' snippets the ones the generator
+// imports in every handle block because the site selected them
+func dropSiteGuards(entry *CaddyEntry) {
+	for i, route := range entry.Routes {
+		entry.Routes[i].Snippets = slices.DeleteFunc(route.Snippets, func(name string) bool {
+			return isHandlerSnippet(name) && slices.Contains(entry.Imports, name)
+		})
+	}
+}
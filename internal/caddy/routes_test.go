@@ -0,0 +1,93 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathRoutesRoundTrip(t *testing.T) {
+	routes, err := ParsePathRoutes("/grafana/* grafana:3000 strip +security_headers +performance\n\n/prometheus* https://10.0.0.9:9090 +ip_restricted\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := GenerateBlockInput{
+		FQDN:             "example.com",
+		Target:           "10.0.0.5",
+		Port:             8080,
+		SelectedSnippets: []string{"ip_restricted", "security_headers"},
+		Routes:           routes,
+	}
+	block := GenerateCaddyBlock(input)
+	for _, want := range []string{
+		"\timport security_headers\n\n\thandle_path /grafana/* {\n\t\timport ip_restricted\n\t\timport security_headers\n\t\treverse_proxy http://grafana:3000 {\n\t\t\timport performance\n",
+		"\thandle /prometheus* {\n\t\timport ip_restricted\n\t\treverse_proxy https://10.0.0.9:9090\n\t}\n",
+		"\thandle {\n\t\timport ip_restricted\n\t\treverse_proxy http://10.0.0.5:8080\n\t}\n}\n",
+	} {
+		if !strings.Contains(block, want) {
+			t.Errorf("expected %q in:\n%s", want, block)
+		}
+	}
+	if strings.Count(block, "import ip_restricted") != 3 {
+		t.Errorf("expected ip_restricted only inside the handle blocks:\n%s", block)
+	}
+
+	entries, err := ParseCaddyfile(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := entries[0]
+	if entry.Target != "10.0.0.5" || entry.Port != 8080 || !entry.IPRestricted {
+		t.Errorf("unexpected site upstream: %+v", entry)
+	}
+	// ip_restricted is imported for the whole site, not by the route
+	if got := FormatPathRoutes(entry.Routes); got != "/grafana/* http://grafana:3000 strip +security_headers +performance\n/prometheus* https://10.0.0.9:9090" {
+		t.Errorf("unexpected formatted routes:\n%s", got)
+	}
+
+	// The inline LAN restriction also goes inside each block
+	block = GenerateCaddyBlock(GenerateBlockInput{FQDN: "example.com", Target: "app", Port: 80, LANOnly: true,
+		LANSubnet: "10.0.0.0/8", Routes: routes[:1]})
+	if strings.Count(block, "\t\trespond @external 404\n") != 2 {
+		t.Errorf("expected the restriction in both handle blocks:\n%s", block)
+	}
+}
+
+func TestParsePathRoutesInHostRoute(t *testing.T) {
+	content := "*.example.com {\n\t@tools host tools.example.com\n\thandle @tools {\n" +
+		"\t\thandle_path /grafana/* {\n\t\t\treverse_proxy grafana:3000\n\t\t}\n" +
+		"\t\thandle {\n\t\t\treverse_proxy tools:80\n\t\t}\n\t}\n\n" +
+		"\thandle /status {\n\t\trespond ok\n\t}\n}\n"
+	entries, err := ParseCaddyfile(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the site and its host route, got %+v", entries)
+	}
+	// A path handle without an upstream is still a route of the site
+	if site := entries[0]; len(site.Routes) != 1 || site.Routes[0].Path != "/status" || site.Routes[0].Target != "" {
+		t.Errorf("unexpected site routes: %+v", site.Routes)
+	}
+	route := entries[1]
+	if route.Target != "tools" || len(route.Routes) != 1 || route.Routes[0].Target != "grafana" || !route.Routes[0].StripPrefix {
+		t.Errorf("unexpected host route: %+v", route)
+	}
+}
+
+func TestParsePathRoutesErrors(t *testing.T) {
+	for _, text := range []string{
+		"grafana grafana:3000",
+		"/grafana",
+		"/grafana grafana:3000 stripped",
+		"/grafana http://",
+		"/a a:1\n/a b:2",
+	} {
+		if _, err := ParsePathRoutes(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+	routes, err := ParsePathRoutes("/app https://app")
+	if err != nil || routes[0].Port != 443 || !routes[0].SSL {
+		t.Errorf("expected the https port default, got %+v, %v", routes, err)
+	}
+}
@@ -20,6 +20,7 @@ const (
 	SnippetCORS
 	SnippetCompression
 	SnippetRateLimit
+	SnippetAuthentication
 	SnippetCustom
 )
 
@@ -46,6 +47,8 @@ func (c SnippetCategory) String() string {
 		return "Compression"
 	case SnippetRateLimit:
 		return "Rate Limiting"
+	case SnippetAuthentication:
+		return "Authentication"
 	case SnippetCustom:
 		return "Custom"
 	default:
@@ -76,6 +79,8 @@ func (c SnippetCategory) ColorCode() string {
 		return "#00B894" // Green - optimization
 	case SnippetRateLimit:
 		return "#FDCB6E" // Gold - rate limit
+	case SnippetAuthentication:
+		return "#E17055" // Terracotta - login
 	case SnippetCustom:
 		return "#B2BEC3" // Gray - custom
 	default:
@@ -91,6 +96,7 @@ type Snippet struct {
 	Description  string          // User-friendly description
 	LineStart    int             // Location in Caddyfile (1-indexed)
 	LineEnd      int             // End location in Caddyfile (1-indexed)
+	File         string          // File defining the snippet (the Caddyfile or an imported file)
 	AutoDetected bool            // Was category auto-detected?
 	Confidence   float64         // Confidence score for auto-detection (0.0-1.0)
 }
@@ -121,6 +127,12 @@ var categorizationHints = []CategorizationHint{
 		MinMatches: 2,
 		Confidence: 0.95,
 	},
+	{
+		Category:   SnippetIPRestriction,
+		Keywords:   []string{"client_ip", "not client_ip", "@external", "respond 403"},
+		MinMatches: 2,
+		Confidence: 0.95,
+	},
 	{
 		Category:   SnippetSecurityHeaders,
 		Keywords:   []string{"header", "X-Frame-Options", "X-Content-Type-Options", "Strict-Transport-Security"},
@@ -175,6 +187,24 @@ var categorizationHints = []CategorizationHint{
 		MinMatches: 1,
 		Confidence: 0.9,
 	},
+	{
+		Category:   SnippetAuthentication,
+		Keywords:   []string{"forward_auth", "copy_headers"},
+		MinMatches: 1,
+		Confidence: 0.95,
+	},
+	{
+		Category:   SnippetAuthentication,
+		Keywords:   []string{"basic_auth"},
+		MinMatches: 1,
+		Confidence: 0.95,
+	},
+	{
+		Category:   SnippetAuthentication,
+		Keywords:   []string{"basicauth"},
+		MinMatches: 1,
+		Confidence: 0.95,
+	},
 }
 
 // CategorizeSnippet auto-detects the category of a snippet based on its content
@@ -212,7 +242,7 @@ func CategorizeSnippet(content string) (SnippetCategory, float64) {
 func GenerateDescription(category SnippetCategory, content string) string {
 	switch category {
 	case SnippetIPRestriction:
-		if strings.Contains(content, "remote_ip") {
+		if strings.Contains(content, "remote_ip") || strings.Contains(content, "client_ip") {
 			return "Restricts access based on IP address ranges"
 		}
 		return "IP-based access control"
@@ -263,6 +293,12 @@ func GenerateDescription(category SnippetCategory, content string) string {
 	case SnippetRateLimit:
 		return "Rate limiting to prevent abuse"
 
+	case SnippetAuthentication:
+		if strings.Contains(content, "forward_auth") {
+			return "Login through an authentication server (forward_auth)"
+		}
+		return "HTTP basic authentication"
+
 	case SnippetCustom:
 		return "Custom configuration snippet"
 
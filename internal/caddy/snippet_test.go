@@ -75,6 +75,18 @@ func TestCategorizeSnippet_OAuthHeaders(t *testing.T) {
 	}
 }
 
+func TestCategorizeSnippet_Authentication(t *testing.T) {
+	for _, content := range []string{
+		"forward_auth authelia:9091 {\n\turi /api/authz/forward-auth\n\tcopy_headers Remote-User Remote-Groups\n}",
+		"basic_auth {\n\talice $2a$14$Zkx19XLiW6VYouLHR5NmfOFU0z2GTNmpkT/5qqR7hx4IjWJPDhjvG\n}",
+	} {
+		category, confidence := CategorizeSnippet(content)
+		if category != SnippetAuthentication || confidence < 0.9 {
+			t.Errorf("Expected category %v with high confidence, got %v (%.2f)", SnippetAuthentication, category, confidence)
+		}
+	}
+}
+
 func TestCategorizeSnippet_Unknown(t *testing.T) {
 	content := `some_directive {
 		custom_option value
@@ -173,6 +185,7 @@ func TestSnippetCategory_ColorCode(t *testing.T) {
 		SnippetCORS,
 		SnippetCompression,
 		SnippetRateLimit,
+		SnippetAuthentication,
 		SnippetCustom,
 		SnippetUnknown,
 	}
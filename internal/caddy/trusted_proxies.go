@@ -0,0 +1,161 @@
+package caddy
+
+import (
+	"strings"
+)
+
+// CloudflareIPRanges are the address ranges Cloudflare proxies requests from
+// (https://www.cloudflare.com/ips/)
+var CloudflareIPRanges = []string{
+	"173.245.48.0/20",
+	"103.21.244.0/22",
+	"103.22.200.0/22",
+	"103.31.4.0/22",
+	"141.101.64.0/18",
+	"108.162.192.0/18",
+	"190.93.240.0/20",
+	"188.114.96.0/20",
+	"197.234.240.0/22",
+	"198.41.128.0/17",
+	"162.158.0.0/15",
+	"104.16.0.0/13",
+	"104.24.0.0/14",
+	"172.64.0.0/13",
+	"131.0.72.0/22",
+	"2400:cb00::/32",
+	"2606:4700::/32",
+	"2803:f800::/32",
+	"2405:b500::/32",
+	"2405:8100::/32",
+	"2a06:98c0::/29",
+	"2c0f:f248::/32",
+}
+
+// cloudflareServerOptions are the lines of the servers global option that
+// make client_ip the visitor's address for requests proxied by Cloudflare
+func cloudflareServerOptions() []string {
+	return []string{
+		"trusted_proxies static " + strings.Join(CloudflareIPRanges, " "),
+		"client_ip_headers CF-Connecting-IP X-Forwarded-For",
+	}
+}
+
+// Global returns the global options block, or nil if the file has none
+func (f *File) Global() *Block {
+	for _, b := range f.Blocks {
+		if b.Kind == BlockGlobal {
+			return b
+		}
+	}
+	return nil
+}
+
+// serversOption returns the servers option of the global block that applies
+// to every server (no listener address), or nil
+func serversOption(global *Block) *Directive {
+	for _, d := range global.Body {
+		if d.Name() == "servers" && len(d.Args()) == 0 && d.HasBlock() {
+			return d
+		}
+	}
+	return nil
+}
+
+// TrustsProxies reports whether the global options set trusted_proxies, so
+// client_ip matchers see the address of the visitor behind a proxy
+func (f *File) TrustsProxies() bool {
+	global := f.Global()
+	if global == nil {
+		return false
+	}
+	trusted := false
+	WalkDirectives(global.Body, func(d *Directive) {
+		if d.Name() == "trusted_proxies" {
+			trusted = true
+		}
+	})
+	return trusted
+}
+
+// TrustCloudflare sets trusted_proxies to Cloudflare's IP ranges (and the
+// header carrying the visitor's address) in the servers global option,
+// replacing earlier values. A global options block is added if missing.
+func (f *File) TrustCloudflare() error {
+	settings := cloudflareServerOptions()
+
+	global := f.Global()
+	if global == nil {
+		var b strings.Builder
+		b.WriteString("{\n\tservers {\n")
+		for _, line := range settings {
+			b.WriteString("\t\t" + line + "\n")
+		}
+		b.WriteString("\t}\n}\n\n")
+
+		parsed, err := ParseAST(b.String() + f.String())
+		if err != nil {
+			return err
+		}
+		*f = *parsed
+		return nil
+	}
+
+	// Edit the block line by line; directive lines are absolute, content
+	// starts on the line after the opening brace
+	var lines []string
+	if content := global.Content(); content != "" {
+		lines = strings.Split(content, "\n")
+	}
+	base := global.LineStart + 1
+	if global.LineEnd == global.LineStart {
+		base = global.LineStart // { admin off }
+	}
+
+	servers := serversOption(global)
+	if servers == nil {
+		lines = append(lines, "\tservers {")
+		for _, line := range settings {
+			lines = append(lines, "\t\t"+line)
+		}
+		lines = append(lines, "\t}")
+		return f.SetContent(global, strings.Join(lines, "\n"))
+	}
+
+	// Drop the old settings and add ours before the closing brace
+	drop := map[int]bool{}
+	for _, d := range servers.Block {
+		if d.Name() == "trusted_proxies" || d.Name() == "client_ip_headers" {
+			for line := d.LineStart; line <= d.LineEnd; line++ {
+				drop[line-base] = true
+			}
+		}
+	}
+	opening := lines[servers.LineStart-base]
+	indent := opening[:len(opening)-len(strings.TrimLeft(opening, " \t"))] + "\t"
+
+	var out []string
+	for i, line := range lines {
+		if drop[i] {
+			continue
+		}
+		if i == servers.LineEnd-base {
+			for _, setting := range settings {
+				out = append(out, indent+setting)
+			}
+		}
+		out = append(out, line)
+	}
+	return f.SetContent(global, strings.Join(out, "\n"))
+}
+
+// TrustCloudflareProxies updates the global options of the Caddyfile with
+// TrustCloudflare, unless they already set trusted_proxies: a list the user
+// configured (e.g. the cloudflare module, or their own proxy as well) is kept.
+func TrustCloudflareProxies(caddyfilePath string) error {
+	return editCaddyfile(caddyfilePath, func(file *File) error {
+		if file.TrustsProxies() {
+			return nil
+		}
+		return file.TrustCloudflare()
+	})
+}
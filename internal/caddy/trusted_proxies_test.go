@@ -0,0 +1,85 @@
+package caddy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrustCloudflare(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keep    []string // Lines that must survive
+	}{
+		{"no global block", "app.example.com {\n\treverse_proxy localhost:8080\n}\n", []string{"reverse_proxy localhost:8080"}},
+		{"global without servers", "{\n\tadmin off\n}\n\napp.example.com {\n\trespond ok\n}\n", []string{"admin off"}},
+		{
+			"servers with old settings",
+			"{\n\t# Managed\n\tservers {\n\t\ttimeouts {\n\t\t\tread_body 10s\n\t\t}\n\t\ttrusted_proxies static 10.0.0.1/32\n\t}\n\tauto_https off\n}\n",
+			[]string{"read_body 10s", "auto_https off", "# Managed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseAST(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.TrustsProxies() != strings.Contains(tt.content, "trusted_proxies") {
+				t.Errorf("TrustsProxies = %v before editing", file.TrustsProxies())
+			}
+			if err := file.TrustCloudflare(); err != nil {
+				t.Fatal(err)
+			}
+			// Applying it twice changes nothing
+			once := file.String()
+			if err := file.TrustCloudflare(); err != nil {
+				t.Fatal(err)
+			}
+			if file.String() != once {
+				t.Errorf("second edit changed the file:\n%s\n---\n%s", once, file.String())
+			}
+
+			reparsed, err := ParseAST(once)
+			if err != nil {
+				t.Fatalf("edited Caddyfile doesn't parse: %v\n%s", err, once)
+			}
+			if !reparsed.TrustsProxies() {
+				t.Errorf("expected trusted_proxies:\n%s", once)
+			}
+			if strings.Contains(once, "10.0.0.1/32") || strings.Count(once, "trusted_proxies") != 1 {
+				t.Errorf("old trusted_proxies not replaced:\n%s", once)
+			}
+			if !strings.Contains(once, "client_ip_headers CF-Connecting-IP") {
+				t.Errorf("expected client_ip_headers:\n%s", once)
+			}
+			for _, line := range tt.keep {
+				if !strings.Contains(once, line) {
+					t.Errorf("lost %q:\n%s", line, once)
+				}
+			}
+		})
+	}
+}
+
+// TestTrustCloudflareProxiesKeepsExisting tests that trusted_proxies the user
+// configured are left alone
+func TestTrustCloudflareProxiesKeepsExisting(t *testing.T) {
+	content := "{\n\tservers {\n\t\ttrusted_proxies cloudflare {\n\t\t\tinterval 12h\n\t\t}\n\t}\n}\n\napp.example.com {\n\trespond ok\n}\n"
+	path := filepath.Join(t.TempDir(), "Caddyfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrustCloudflareProxies(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("expected the Caddyfile unchanged, got:\n%s", got)
+	}
+}
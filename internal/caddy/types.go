@@ -1,18 +1,106 @@
 package caddy
 
+import "fmt"
+
+// EntryType is what a site does with requests
+type EntryType int
+
+const (
+	EntryProxy    EntryType = iota // reverse_proxy to an upstream (the default)
+	EntryRedirect                  // redir to another URL
+	EntryStatic                    // file_server from a directory
+)
+
+// String returns the human-readable entry type
+func (t EntryType) String() string {
+	switch t {
+	case EntryRedirect:
+		return "Redirect"
+	case EntryStatic:
+		return "Static Site"
+	default:
+		return "Reverse Proxy"
+	}
+}
+
+// Icon returns the symbol shown next to entries of this type in the list
+func (t EntryType) Icon() string {
+	switch t {
+	case EntryRedirect:
+		return "↪"
+	case EntryStatic:
+		return "▤"
+	default:
+		return "⇄"
+	}
+}
+
 // CaddyEntry represents a parsed Caddy configuration entry
 type CaddyEntry struct {
-	Domain       string   // Primary domain (e.g., "plex.angelsomething.com")
-	Domains      []string // All domains if multi-domain block
-	Target       string   // Target IP or hostname from reverse_proxy
-	Port         int      // Port number from reverse_proxy
-	SSL          bool     // true if https://, false if http://
-	IPRestricted bool     // true if has IP restriction (import or inline)
-	OAuthHeaders bool     // true if has OAuth/OIDC headers
-	WebSocket    bool     // true if has WebSocket headers
-	Imports      []string // List of imported snippets
-	RawBlock     string   // Original block text
-	LineStart    int      // Line number where block starts (1-indexed)
-	LineEnd      int      // Line number where block ends
-	HasMarker    bool     // true if has # === domain === marker
+	Domain        string        // Primary domain (e.g., "plex.angelsomething.com")
+	Domains       []string      // All domains if multi-domain block
+	Type          EntryType     // Reverse proxy, redirect or static site
+	Target        string        // Target IP or hostname from reverse_proxy
+	Port          int           // Port number from reverse_proxy
+	SSL           bool          // true if https://, false if http://
+	IPRestricted  bool          // true if has IP restriction (import or inline)
+	OAuthHeaders  bool          // true if has OAuth/OIDC headers
+	WebSocket     bool          // true if has WebSocket headers
+	Imports       []string      // List of imported snippets
+	RawBlock      string        // Original block text
+	LineStart     int           // Line number where block starts (1-indexed)
+	LineEnd       int           // Line number where block ends
+	File          string        // File holding the block: the Caddyfile or a file it imports ("" if parsed from text)
+	HasMarker     bool          // true if has # === domain === marker
+	ParentSite    string        // Wildcard or catch-all site serving this host route ("" for a site block)
+	Matcher       string        // Host matcher of a host route (e.g. "@plex")
+	Routes        []PathRoute   // Services under a path of the site, in block order
+	Upstreams     []string      // Further upstreams of the reverse_proxy after Target, as host:port (with a scheme if not Target's)
+	LoadBalancing LoadBalancing // Load balancing policy and health checks of the reverse_proxy
+	ProxyOptions  []string      // Other reverse_proxy subdirectives (transport, header_up, ...), as written
+
+	RedirectTo           string // Redirect: destination URL, without the preserved path
+	RedirectCode         int    // Redirect: status code (301 permanent, 302 temporary, 307, 308)
+	RedirectPreservePath bool   // Redirect: the request's path and query are appended ({uri})
+	StaticRoot           string // Static site: directory served by file_server
+	StaticBrowse         bool   // Static site: directories without an index are listed
+}
+
+// Destination describes where the entry sends requests: the upstream of a
+// reverse proxy, the URL of a redirect or the directory of a static site
+func (e CaddyEntry) Destination() string {
+	switch e.Type {
+	case EntryRedirect:
+		if e.RedirectPreservePath {
+			return e.RedirectTo + "{uri}"
+		}
+		return e.RedirectTo
+	case EntryStatic:
+		return e.StaticRoot
+	default:
+		return fmt.Sprintf("%s:%d", e.Target, e.Port)
+	}
+}
+
+// UpstreamURLs returns the upstreams of a reverse proxy as written in its
+// reverse_proxy directive: Target first, then the further upstreams
+func (e CaddyEntry) UpstreamURLs() []string {
+	return upstreamList(e.Target, e.Port, e.SSL, e.Upstreams)
+}
+
+// PathRoute is a service served under a path of a site by a handle block, or
+// a handle_path block that strips the path before proxying
+type PathRoute struct {
+	Path        string   // Path matcher (e.g. "/grafana/*")
+	StripPrefix bool     // handle_path: the upstream sees the path without the prefix
+	Target      string   // Upstream IP or hostname
+	Port        int      // Upstream port
+	SSL         bool     // true if https://
+	Snippets    []string // Snippets imported in the route
+}
+
+// IsHostRoute reports whether the entry is a host route inside a wildcard or
+// catch-all site block rather than a site block of its own
+func (e CaddyEntry) IsHostRoute() bool {
+	return e.ParentSite != ""
 }
@@ -0,0 +1,396 @@
+package caddy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HostRoute is a route for specific hosts inside a wildcard or catch-all site
+// block: a named host matcher plus the directive that handles it.
+//
+//	*.example.com {
+//		@plex host plex.example.com
+//		handle @plex {
+//			reverse_proxy 10.0.0.5:32400
+//		}
+//	}
+type HostRoute struct {
+	Matcher string     // Matcher name including the @ (e.g. "@plex")
+	Hosts   []string   // Hosts the matcher selects
+	Define  *Directive // Matcher definition
+	Handler *Directive // handle, route or reverse_proxy directive using the matcher
+}
+
+// hostRouteHandlers are the directives that can serve a host route
+var hostRouteHandlers = map[string]bool{
+	"handle":        true,
+	"route":         true,
+	"reverse_proxy": true,
+}
+
+// IsWildcardSite reports whether a site address serves more than one host:
+// a wildcard (*.example.com) or a catch-all without a host (:443, https://)
+func IsWildcardSite(address string) bool {
+	host := siteHost(address)
+	return host == "" || host == "*" || strings.HasPrefix(host, "*.")
+}
+
+// SiteCovers reports whether a wildcard or catch-all site address serves host.
+// A wildcard covers names exactly one label below it, like a TLS certificate.
+func SiteCovers(address, host string) bool {
+	site := siteHost(address)
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	switch {
+	case site == "" || site == "*":
+		return true
+	case strings.HasPrefix(site, "*."):
+		dot := strings.IndexByte(host, '.')
+		return dot > 0 && host[dot:] == site[1:]
+	}
+	return false
+}
+
+// isWildcardBlock reports whether any address of a site block is a wildcard or catch-all
+func isWildcardBlock(block *Block) bool {
+	if block.Kind != BlockSite || block.open < 0 {
+		return false
+	}
+	for _, key := range block.Keys {
+		if IsWildcardSite(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// HostRoutes returns the host routes defined at the top level of a wildcard or
+// catch-all site block. A matcher counts only when host is its sole condition;
+// the first directive using it is its handler.
+func HostRoutes(block *Block) []HostRoute {
+	if !isWildcardBlock(block) {
+		return nil
+	}
+
+	var routes []HostRoute
+	for _, d := range block.Body {
+		if hosts := hostMatcherHosts(d); len(hosts) > 0 {
+			routes = append(routes, HostRoute{Matcher: d.Name(), Hosts: hosts, Define: d})
+		}
+	}
+
+	var matched []HostRoute
+	for _, route := range routes {
+		for _, d := range block.Body {
+			args := d.Args()
+			if hostRouteHandlers[d.Name()] && len(args) > 0 && args[0] == route.Matcher {
+				route.Handler = d
+				matched = append(matched, route)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// hostMatcherHosts returns the hosts of a named matcher whose only condition is
+// host, in either the "@name host a b" or the "@name { host a b }" form
+func hostMatcherHosts(d *Directive) []string {
+	name := d.Name()
+	if !strings.HasPrefix(name, "@") || len(name) < 2 {
+		return nil
+	}
+
+	args := d.Args()
+	if !d.HasBlock() {
+		if len(args) > 1 && args[0] == "host" {
+			return args[1:]
+		}
+		return nil
+	}
+	if len(args) > 0 || len(d.Block) == 0 {
+		return nil
+	}
+	var hosts []string
+	for _, sub := range d.Block {
+		if sub.Name() != "host" || sub.HasBlock() {
+			return nil
+		}
+		hosts = append(hosts, sub.Args()...)
+	}
+	return hosts
+}
+
+// FindHostRoute returns the host route serving host and the site block that contains it
+func (f *File) FindHostRoute(host string) (*Block, *HostRoute) {
+	for _, b := range f.Blocks {
+		routes := HostRoutes(b)
+		for i := range routes {
+			for _, h := range routes[i].Hosts {
+				if strings.EqualFold(h, host) {
+					return b, &routes[i]
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CoveringSite returns the site block a host route for host belongs in: a
+// wildcard block covering it, else a catch-all block, else nil
+func (f *File) CoveringSite(host string) *Block {
+	var catchAll *Block
+	for _, b := range f.Blocks {
+		if !isWildcardBlock(b) {
+			continue
+		}
+		for _, key := range b.Keys {
+			if !SiteCovers(key, host) {
+				continue
+			}
+			if strings.HasPrefix(siteHost(key), "*.") {
+				return b
+			}
+			if catchAll == nil {
+				catchAll = b
+			}
+		}
+	}
+	return catchAll
+}
+
+// matcherNameChars matches characters that can't appear in a generated matcher name
+var matcherNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// matcherLabel derives a matcher name (without the @) from the first label of host
+func matcherLabel(host string) string {
+	label := strings.ToLower(host)
+	if dot := strings.IndexByte(label, '.'); dot > 0 {
+		label = label[:dot]
+	}
+	label = strings.Trim(matcherNameChars.ReplaceAllString(label, "_"), "_")
+	if label == "" {
+		label = "host"
+	}
+	return label
+}
+
+// hostMatcherName derives a matcher name from the first label of host that
+// isn't already defined in the block ("@plex", then "@plex_2", ...)
+func hostMatcherName(block *Block, host string) string {
+	label := matcherLabel(host)
+	taken := make(map[string]bool)
+	for _, d := range block.Body {
+		if strings.HasPrefix(d.Name(), "@") {
+			taken[d.Name()] = true
+		}
+	}
+	name := "@" + label
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("@%s_%d", label, i)
+	}
+	return name
+}
+
+// hostRouteText renders a marker comment, matcher definition and handle block.
+// body holds the route's directives indented one level, as inside a site block.
+func hostRouteText(indent, matcher string, hosts []string, body string) string {
+	return fmt.Sprintf("%s# === %s ===\n", indent, hosts[0]) +
+		matcherText(indent, matcher, hosts) +
+		handlerText(indent, "handle", matcher, body)
+}
+
+// matcherText renders a one-line host matcher definition
+func matcherText(indent, matcher string, hosts []string) string {
+	return fmt.Sprintf("%s%s host %s\n", indent, matcher, strings.Join(hosts, " "))
+}
+
+// handlerText renders a handler block for a matcher, indenting body to match
+func handlerText(indent, handler, matcher, body string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s%s %s {\n", indent, handler, matcher))
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + "}\n")
+	return b.String()
+}
+
+// AddHostRoute adds a host route for hosts to a wildcard or catch-all site
+// block, after its existing host routes or else at the end of the block.
+// body holds the route's directives indented one level, as GenerateRouteBody
+// returns them.
+func (f *File) AddHostRoute(block *Block, hosts []string, body string) error {
+	if !isWildcardBlock(block) {
+		return fmt.Errorf("%s is not a wildcard site block", strings.Join(block.Keys, ", "))
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts for route")
+	}
+	lines := blockLines(block)
+	closing := len(lines) - 1
+	if closing < 1 || strings.TrimSpace(lines[closing]) != "}" {
+		return fmt.Errorf("closing brace of %s is not on its own line", block.Keys[0])
+	}
+
+	// Insert after the last host route, or before the closing brace
+	at := closing
+	last := 0
+	for _, route := range HostRoutes(block) {
+		for _, d := range []*Directive{route.Define, route.Handler} {
+			last = max(last, d.LineEnd-block.LineStart+1)
+		}
+	}
+	if last > 0 && last < closing {
+		at = last
+	}
+
+	text := hostRouteText("\t", hostMatcherName(block, hosts[0]), hosts, body)
+	if at > 1 && strings.TrimSpace(lines[at-1]) != "" {
+		text = "\n" + text
+	}
+	if at < closing && strings.TrimSpace(lines[at]) != "" {
+		text += "\n"
+	}
+
+	updated := append(append(append([]string{}, lines[:at]...), text), lines[at:]...)
+	return f.setRaw(block, strings.Join(updated, ""))
+}
+
+// ReplaceHostRoute rewrites the host route serving host with new hosts and
+// body, keeping its matcher name, handler directive and position
+func (f *File) ReplaceHostRoute(host string, hosts []string, body string) error {
+	block, route := f.FindHostRoute(host)
+	if route == nil {
+		return fmt.Errorf("host route %s not found in Caddyfile", host)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts for route")
+	}
+	lines := blockLines(block)
+	for _, d := range []*Directive{route.Define, route.Handler} {
+		if d.LineStart-block.LineStart < 1 || d.LineEnd-block.LineStart >= len(lines)-1 {
+			return fmt.Errorf("route %s shares a line with the site's braces", route.Matcher)
+		}
+	}
+	indent := lineIndent(lines[route.Define.LineStart-block.LineStart])
+
+	// Keep the marker above the matcher in step with the primary host
+	if marker := route.Define.LineStart - block.LineStart - 1; marker > 0 && isMarkerComment(lines[marker]) {
+		lines[marker] = fmt.Sprintf("%s# === %s ===\n", lineIndent(lines[marker]), hosts[0])
+	}
+
+	// A one-line "reverse_proxy @name upstream" handler becomes a handle block
+	handler := route.Handler.Name()
+	if handler == "reverse_proxy" {
+		handler = "handle"
+	}
+	edits := []struct {
+		d    *Directive
+		text string
+	}{
+		{route.Define, matcherText(indent, route.Matcher, hosts)},
+		{route.Handler, handlerText(indent, handler, route.Matcher, body)},
+	}
+
+	// Rewrite the later directive first so earlier line numbers stay valid
+	if edits[0].d.LineStart > edits[1].d.LineStart {
+		edits[0], edits[1] = edits[1], edits[0]
+	}
+	for i := len(edits) - 1; i >= 0; i-- {
+		start, end := edits[i].d.LineStart-block.LineStart, edits[i].d.LineEnd-block.LineStart
+		lines = append(append(append([]string{}, lines[:start]...), edits[i].text), lines[end+1:]...)
+	}
+	return f.setRaw(block, strings.Join(lines, ""))
+}
+
+// RemoveHostRoute deletes the host route serving host: its matcher definition,
+// handler and the comments attached to them
+func (f *File) RemoveHostRoute(host string) error {
+	block, route := f.FindHostRoute(host)
+	if route == nil {
+		return fmt.Errorf("host route %s not found in Caddyfile", host)
+	}
+	lines := blockLines(block)
+
+	remove := make(map[int]bool)
+	for _, d := range []*Directive{route.Define, route.Handler} {
+		start, end := d.LineStart-block.LineStart, d.LineEnd-block.LineStart
+		if start < 1 || end >= len(lines)-1 {
+			return fmt.Errorf("route %s shares a line with the site's braces", route.Matcher)
+		}
+		for i := attachedCommentStart(lines, start); i <= end; i++ {
+			remove[i] = true
+		}
+	}
+
+	// Drop the blank lines the route leaves behind: doubled blank lines, and
+	// blank lines next to the site's braces
+	var kept []string
+	gap := false
+	for i, line := range lines {
+		if remove[i] {
+			gap = true
+			continue
+		}
+		if gap && strings.TrimSpace(line) == "" {
+			prevBlank := strings.TrimSpace(kept[len(kept)-1]) == ""
+			if prevBlank || len(kept) == 1 || i == len(lines)-2 {
+				continue
+			}
+		}
+		if gap && i == len(lines)-1 && len(kept) > 1 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			kept = kept[:len(kept)-1]
+		}
+		gap = false
+		kept = append(kept, line)
+	}
+	return f.setRaw(block, strings.Join(kept, ""))
+}
+
+// blockLines splits a block's source into lines; line i is block.LineStart+i
+func blockLines(block *Block) []string {
+	return strings.SplitAfter(block.Raw, "\n")
+}
+
+// attachedCommentStart returns the first line of the comments directly above
+// line idx, stopping at a "# === name ===" marker
+func attachedCommentStart(lines []string, idx int) int {
+	for idx > 1 {
+		line := lines[idx-1]
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		idx--
+		if isMarkerComment(line) {
+			break
+		}
+	}
+	return idx
+}
+
+// lineIndent returns the leading whitespace of a line
+func lineIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// setRaw replaces a block's source, keeping its position and surrounding comments
+func (f *File) setRaw(block *Block, raw string) error {
+	idx := f.index(block)
+	if idx < 0 {
+		return fmt.Errorf("block not found")
+	}
+	parsed, err := parseSingleBlock(raw)
+	if err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+	updated := parsed.Blocks[0]
+	updated.Leading = block.Leading
+	updated.Comments = block.Comments
+	f.Blocks[idx] = updated
+	return nil
+}
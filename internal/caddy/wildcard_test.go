@@ -0,0 +1,265 @@
+package caddy
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const wildcardFixture = `# === *.example.com ===
+*.example.com {
+	tls {
+		dns cloudflare {env.CF_API_TOKEN}
+	}
+
+	# === plex.example.com ===
+	@plex host plex.example.com
+	handle @plex {
+		reverse_proxy https://10.0.0.5:32400
+	}
+
+	@grafana host grafana.example.com
+	reverse_proxy @grafana 10.0.0.7:3000
+
+	handle {
+		respond 404
+	}
+}
+
+app.example.com {
+	reverse_proxy 10.0.0.10:3000
+}
+`
+
+func TestSiteCovers(t *testing.T) {
+	tests := []struct {
+		address string
+		host    string
+		want    bool
+	}{
+		{"*.example.com", "plex.example.com", true},
+		{"*.example.com", "PLEX.Example.com.", true},
+		{"https://*.example.com:443", "plex.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "a.b.example.com", false},
+		{"*.example.com", "plex.example.org", false},
+		{":443", "anything.example.org", true},
+		{"https://", "anything.example.org", true},
+		{"app.example.com", "app.example.com", false}, // Not a wildcard
+	}
+	for _, tt := range tests {
+		if got := SiteCovers(tt.address, tt.host); got != tt.want {
+			t.Errorf("SiteCovers(%q, %q) = %t, want %t", tt.address, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHostRoutes(t *testing.T) {
+	file := mustParse(t, wildcardFixture)
+	routes := HostRoutes(file.Blocks[0])
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 host routes, got %d", len(routes))
+	}
+	if routes[0].Matcher != "@plex" || routes[0].Handler.Name() != "handle" {
+		t.Errorf("unexpected first route: %+v", routes[0])
+	}
+	if routes[1].Matcher != "@grafana" || routes[1].Handler.Name() != "reverse_proxy" {
+		t.Errorf("unexpected second route: %+v", routes[1])
+	}
+	if HostRoutes(file.FindSite("app.example.com")) != nil {
+		t.Error("a regular site block has no host routes")
+	}
+}
+
+func TestParseHostRouteEntries(t *testing.T) {
+	entries, err := ParseCaddyfile(wildcardFixture)
+	if err != nil {
+		t.Fatalf("ParseCaddyfile failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected wildcard, 2 host routes and app, got %d entries", len(entries))
+	}
+
+	plex := entries[1]
+	if plex.Domain != "plex.example.com" || plex.ParentSite != "*.example.com" || plex.Matcher != "@plex" {
+		t.Errorf("unexpected host route entry: %+v", plex)
+	}
+	if plex.Target != "10.0.0.5" || plex.Port != 32400 || !plex.SSL || !plex.HasMarker {
+		t.Errorf("host route settings not parsed: %+v", plex)
+	}
+	if plex.LineStart != 8 || plex.LineEnd != 11 {
+		t.Errorf("host route lines = %d-%d, want 8-11", plex.LineStart, plex.LineEnd)
+	}
+	if entries[2].Target != "10.0.0.7" || entries[2].Port != 3000 {
+		t.Errorf("one-line reverse_proxy route not parsed: %+v", entries[2])
+	}
+	if entries[0].IsHostRoute() || entries[0].Target != "" {
+		t.Errorf("wildcard entry should not take its routes' upstreams: %+v", entries[0])
+	}
+}
+
+func TestFileAddHostRoute(t *testing.T) {
+	file := mustParse(t, wildcardFixture)
+	body := "\treverse_proxy 10.0.0.8:8096\n"
+	if err := file.AddHostRoute(file.Blocks[0], []string{"jellyfin.example.com"}, body); err != nil {
+		t.Fatalf("AddHostRoute failed: %v", err)
+	}
+
+	// Added after the last route, before the catch-all handle
+	want := strings.Replace(wildcardFixture, `	reverse_proxy @grafana 10.0.0.7:3000
+`, `	reverse_proxy @grafana 10.0.0.7:3000
+
+	# === jellyfin.example.com ===
+	@jellyfin host jellyfin.example.com
+	handle @jellyfin {
+		reverse_proxy 10.0.0.8:8096
+	}
+`, 1)
+	if got := file.String(); got != want {
+		t.Errorf("AddHostRoute =\n%s\nwant:\n%s", got, want)
+	}
+
+	// A taken matcher name gets a suffix
+	if err := file.AddHostRoute(file.Blocks[0], []string{"plex.example.com"}, body); err != nil {
+		t.Fatalf("AddHostRoute failed: %v", err)
+	}
+	if !strings.Contains(file.String(), "@plex_2 host plex.example.com") {
+		t.Errorf("expected a unique matcher name:\n%s", file.String())
+	}
+
+	if err := file.AddHostRoute(file.FindSite("app.example.com"), []string{"x.example.com"}, body); err == nil {
+		t.Error("expected error adding a route to a regular site block")
+	}
+}
+
+func TestFileAddHostRouteEmptyBlock(t *testing.T) {
+	file := mustParse(t, "*.example.com {\n\ttls internal\n}\n")
+	if err := file.AddHostRoute(file.Blocks[0], []string{"a.example.com", "b.example.com"}, "\trespond \"a\"\n"); err != nil {
+		t.Fatalf("AddHostRoute failed: %v", err)
+	}
+	want := `*.example.com {
+	tls internal
+
+	# === a.example.com ===
+	@a host a.example.com b.example.com
+	handle @a {
+		respond "a"
+	}
+}
+`
+	if got := file.String(); got != want {
+		t.Errorf("AddHostRoute =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileReplaceHostRoute(t *testing.T) {
+	file := mustParse(t, wildcardFixture)
+	if err := file.ReplaceHostRoute("grafana.example.com", []string{"metrics.example.com"}, "\treverse_proxy 10.0.0.7:3001\n"); err != nil {
+		t.Fatalf("ReplaceHostRoute failed: %v", err)
+	}
+	want := strings.Replace(wildcardFixture, `	@grafana host grafana.example.com
+	reverse_proxy @grafana 10.0.0.7:3000
+`, `	@grafana host metrics.example.com
+	handle @grafana {
+		reverse_proxy 10.0.0.7:3001
+	}
+`, 1)
+	if got := file.String(); got != want {
+		t.Errorf("ReplaceHostRoute =\n%s\nwant:\n%s", got, want)
+	}
+
+	// The marker follows the primary host
+	if err := file.ReplaceHostRoute("plex.example.com", []string{"media.example.com"}, "\treverse_proxy 10.0.0.5:8096\n"); err != nil {
+		t.Fatalf("ReplaceHostRoute failed: %v", err)
+	}
+	if !strings.Contains(file.String(), "\t# === media.example.com ===\n\t@plex host media.example.com\n") {
+		t.Errorf("marker not updated:\n%s", file.String())
+	}
+
+	if err := file.ReplaceHostRoute("missing.example.com", []string{"x"}, ""); err == nil {
+		t.Error("expected error replacing a missing route")
+	}
+}
+
+func TestFileRemoveHostRoute(t *testing.T) {
+	file := mustParse(t, wildcardFixture)
+	if err := file.RemoveHostRoute("plex.example.com"); err != nil {
+		t.Fatalf("RemoveHostRoute failed: %v", err)
+	}
+	want := strings.Replace(wildcardFixture, `	# === plex.example.com ===
+	@plex host plex.example.com
+	handle @plex {
+		reverse_proxy https://10.0.0.5:32400
+	}
+
+`, "", 1)
+	if got := file.String(); got != want {
+		t.Errorf("RemoveHostRoute =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Removing the last route leaves no blank line before the closing brace
+	file = mustParse(t, "*.example.com {\n\ttls internal\n\n\t@a host a.example.com\n\thandle @a {\n\t\trespond 200\n\t}\n}\n")
+	if err := file.RemoveHostRoute("a.example.com"); err != nil {
+		t.Fatalf("RemoveHostRoute failed: %v", err)
+	}
+	if got, want := file.String(), "*.example.com {\n\ttls internal\n}\n"; got != want {
+		t.Errorf("RemoveHostRoute = %q, want %q", got, want)
+	}
+}
+
+func TestHostRouteFileEdits(t *testing.T) {
+	path := writeTestCaddyfile(t, wildcardFixture)
+
+	if err := AddHostRoute(path, []string{"jellyfin.example.com"}, "\treverse_proxy 10.0.0.8:8096\n"); err != nil {
+		t.Fatalf("AddHostRoute failed: %v", err)
+	}
+	if err := AddHostRoute(path, []string{"jellyfin.example.org"}, "\treverse_proxy 10.0.0.8:8096\n"); err == nil {
+		t.Error("expected error adding a route no wildcard block serves")
+	}
+	if err := ReplaceHostRoute(path, "jellyfin.example.com", []string{"jellyfin.example.com"}, "\treverse_proxy 10.0.0.9:8096\n"); err != nil {
+		t.Fatalf("ReplaceHostRoute failed: %v", err)
+	}
+	// RemoveEntry finds host routes as well as site blocks
+	if err := RemoveEntry(path, "plex.example.com"); err != nil {
+		t.Fatalf("RemoveEntry failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	entries, err := ParseCaddyfile(string(content))
+	if err != nil {
+		t.Fatalf("ParseCaddyfile failed: %v\n%s", err, content)
+	}
+	var routes []string
+	for _, e := range entries {
+		if e.IsHostRoute() {
+			routes = append(routes, e.Domain+" "+e.Target)
+		}
+	}
+	if got := strings.Join(routes, ", "); got != "grafana.example.com 10.0.0.7, jellyfin.example.com 10.0.0.9" {
+		t.Errorf("host routes after edits: %s\n%s", got, content)
+	}
+}
+
+func TestGenerateHostRoute(t *testing.T) {
+	got := GenerateHostRoute(GenerateBlockInput{
+		Domains: []string{"plex.example.com"},
+		Target:  "10.0.0.5",
+		Port:    32400,
+		SSL:     true,
+	})
+	want := `# === plex.example.com ===
+@plex host plex.example.com
+handle @plex {
+	reverse_proxy https://10.0.0.5:32400
+}
+`
+	if got != want {
+		t.Errorf("GenerateHostRoute =\n%s\nwant:\n%s", got, want)
+	}
+
+	// The route body is the same as a site block's
+	block := GenerateCaddyBlock(GenerateBlockInput{FQDN: "plex.example.com", Target: "10.0.0.5", Port: 32400, SSL: true})
+	if !strings.Contains(block, GenerateRouteBody(GenerateBlockInput{Target: "10.0.0.5", Port: 32400, SSL: true})) {
+		t.Errorf("site block does not contain the route body:\n%s", block)
+	}
+}
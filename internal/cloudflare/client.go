@@ -2,19 +2,32 @@ package cloudflare
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 const apiBaseURL = "https://api.cloudflare.com/client/v4"
 
+// Retry defaults: up to 4 attempts, waiting 1s, 2s, 4s between them unless
+// Cloudflare asks for a specific wait with Retry-After
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 1 * time.Second
+	maxRetryDelay     = 60 * time.Second
+)
+
 // Client handles Cloudflare API communication
 type Client struct {
 	apiToken   string
 	httpClient *http.Client
+	baseURL    string        // API root (overridden in tests)
+	maxRetries int           // Retries after the first attempt
+	retryDelay time.Duration // Backoff before the first retry; doubles each retry
 }
 
 // NewClient creates a new Cloudflare API client
@@ -22,14 +35,52 @@ func NewClient(apiToken string) *Client {
 	return &Client{
 		apiToken: apiToken,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: 30 * time.Second, // Per attempt
 		},
+		baseURL:    apiBaseURL,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
 	}
 }
 
 // doRequest executes an authenticated API request and returns the response body.
-func (c *Client) doRequest(method, url string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, url, body)
+// Rate-limited (429) requests are retried with exponential backoff, honoring
+// Retry-After. Server errors and network failures are retried too, except for
+// POST where the request may already have been applied.
+// Non-200 responses are returned as *APIError.
+func (c *Client) doRequest(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		data, err := c.attempt(ctx, method, url, body)
+		if err == nil {
+			return data, nil
+		}
+		if attempt >= c.maxRetries || !c.retryable(ctx, method, err) {
+			return nil, err
+		}
+
+		delay := c.retryDelay << attempt
+		if apiErr, ok := asAPIError(err); ok && apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		delay = min(delay, maxRetryDelay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt makes a single API request
+func (c *Client) attempt(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -39,6 +90,9 @@ func (c *Client) doRequest(method, url string, body io.Reader) ([]byte, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
@@ -49,19 +103,56 @@ func (c *Client) doRequest(method, url string, body io.Reader) ([]byte, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+		var errResp apiErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil {
+			apiErr.Errors = errResp.Errors
+		}
+		return nil, apiErr
 	}
 
 	return respBody, nil
 }
 
+// retryable reports whether a failed attempt is worth repeating
+func (c *Client) retryable(ctx context.Context, method string, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	apiErr, ok := asAPIError(err)
+	if ok && apiErr.StatusCode == http.StatusTooManyRequests {
+		return true // Rejected before it was processed
+	}
+	if method == http.MethodPost {
+		return false
+	}
+	return !ok || apiErr.Temporary()
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
 // apiErrorResponse is the common error structure in Cloudflare API responses.
 type apiErrorResponse struct {
-	Success bool `json:"success"`
-	Errors  []struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	} `json:"errors"`
+	Success bool          `json:"success"`
+	Errors  []ErrorDetail `json:"errors"`
 }
 
 // checkAPIError unmarshals the common success/error fields and returns an error if the request failed.
@@ -71,29 +162,26 @@ func checkAPIError(data []byte) error {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 	if !resp.Success {
-		if len(resp.Errors) > 0 {
-			return fmt.Errorf("API error: %s", resp.Errors[0].Message)
-		}
-		return fmt.Errorf("API request failed")
+		return &APIError{StatusCode: http.StatusOK, Errors: resp.Errors}
 	}
 	return nil
 }
 
 // ListDNSRecords fetches all DNS records of a specific type from a zone.
 // Handles pagination automatically to retrieve all records.
-func (c *Client) ListDNSRecords(zoneID string, recordType string) ([]DNSRecord, error) {
+func (c *Client) ListDNSRecords(ctx context.Context, zoneID string, recordType string) ([]DNSRecord, error) {
 	var allRecords []DNSRecord
 	page := 1
 	perPage := 100  // Maximum allowed by Cloudflare API
 	maxPages := 100 // Safety limit: 10,000 records max
 
 	for {
-		url := fmt.Sprintf("%s/zones/%s/dns_records?page=%d&per_page=%d", apiBaseURL, zoneID, page, perPage)
+		url := fmt.Sprintf("%s/zones/%s/dns_records?page=%d&per_page=%d", c.baseURL, zoneID, page, perPage)
 		if recordType != "" {
 			url += fmt.Sprintf("&type=%s", recordType)
 		}
 
-		data, err := c.doRequest("GET", url, nil)
+		data, err := c.doRequest(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -104,12 +192,16 @@ func (c *Client) ListDNSRecords(zoneID string, recordType string) ([]DNSRecord,
 		}
 
 		if !apiResp.Success {
-			if len(apiResp.Errors) > 0 {
-				return nil, fmt.Errorf("API error: %s", apiResp.Errors[0].Message)
-			}
-			return nil, fmt.Errorf("API request failed")
+			return nil, &APIError{StatusCode: http.StatusOK, Errors: apiResp.Errors}
 		}
 
+		// zone_id is deprecated in record responses; fill it in so callers
+		// managing several zones know which zone each record came from
+		for i := range apiResp.Result {
+			if apiResp.Result[i].ZoneID == "" {
+				apiResp.Result[i].ZoneID = zoneID
+			}
+		}
 		allRecords = append(allRecords, apiResp.Result...)
 
 		if apiResp.ResultInfo == nil || page >= apiResp.ResultInfo.TotalPages {
@@ -126,15 +218,15 @@ func (c *Client) ListDNSRecords(zoneID string, recordType string) ([]DNSRecord,
 }
 
 // CreateDNSRecord creates a new DNS record in Cloudflare
-func (c *Client) CreateDNSRecord(zoneID string, record DNSRecord) (*DNSRecord, error) {
-	url := fmt.Sprintf("%s/zones/%s/dns_records", apiBaseURL, zoneID)
+func (c *Client) CreateDNSRecord(ctx context.Context, zoneID string, record DNSRecord) (*DNSRecord, error) {
+	url := fmt.Sprintf("%s/zones/%s/dns_records", c.baseURL, zoneID)
 
 	body, err := json.Marshal(record)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	data, err := c.doRequest("POST", url, bytes.NewBuffer(body))
+	data, err := c.doRequest(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -154,10 +246,10 @@ func (c *Client) CreateDNSRecord(zoneID string, record DNSRecord) (*DNSRecord, e
 }
 
 // DeleteDNSRecord deletes a DNS record from Cloudflare
-func (c *Client) DeleteDNSRecord(zoneID, recordID string) error {
-	url := fmt.Sprintf("%s/zones/%s/dns_records/%s", apiBaseURL, zoneID, recordID)
+func (c *Client) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
+	url := fmt.Sprintf("%s/zones/%s/dns_records/%s", c.baseURL, zoneID, recordID)
 
-	data, err := c.doRequest("DELETE", url, nil)
+	data, err := c.doRequest(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
@@ -166,15 +258,15 @@ func (c *Client) DeleteDNSRecord(zoneID, recordID string) error {
 }
 
 // UpdateDNSRecord updates an existing DNS record in Cloudflare
-func (c *Client) UpdateDNSRecord(zoneID, recordID string, record DNSRecord) (*DNSRecord, error) {
-	url := fmt.Sprintf("%s/zones/%s/dns_records/%s", apiBaseURL, zoneID, recordID)
+func (c *Client) UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record DNSRecord) (*DNSRecord, error) {
+	url := fmt.Sprintf("%s/zones/%s/dns_records/%s", c.baseURL, zoneID, recordID)
 
 	body, err := json.Marshal(record)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	data, err := c.doRequest("PATCH", url, bytes.NewBuffer(body))
+	data, err := c.doRequest(ctx, http.MethodPatch, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -192,3 +284,156 @@ func (c *Client) UpdateDNSRecord(zoneID, recordID string, record DNSRecord) (*DN
 
 	return &result.Result, nil
 }
+
+// maxBatchSize is the most changes sent in one batch request (the limit on
+// Cloudflare's free plan)
+const maxBatchSize = 200
+
+// batchDelete identifies a record to delete in a batch request
+type batchDelete struct {
+	ID string `json:"id"`
+}
+
+// batchBody is the request body of the batch endpoint
+type batchBody struct {
+	Deletes []batchDelete `json:"deletes,omitempty"`
+	Patches []DNSRecord   `json:"patches,omitempty"`
+	Posts   []DNSRecord   `json:"posts,omitempty"`
+}
+
+// BatchDNSRecords applies deletes, patches and posts in a zone through the
+// batch endpoint. Each request is atomic; batches larger than maxBatchSize
+// are split into several requests, sent in order. If a later request fails,
+// the returned result holds the changes applied by the earlier ones.
+func (c *Client) BatchDNSRecords(ctx context.Context, zoneID string, batch BatchRequest) (*BatchResult, error) {
+	url := fmt.Sprintf("%s/zones/%s/dns_records/batch", c.baseURL, zoneID)
+	chunks := splitBatch(batch, maxBatchSize)
+
+	applied := &BatchResult{}
+	for i, chunk := range chunks {
+		var body batchBody
+		for _, record := range chunk.Deletes {
+			body.Deletes = append(body.Deletes, batchDelete{ID: record.ID})
+		}
+		body.Patches = chunk.Patches
+		body.Posts = chunk.Posts
+
+		data, err := json.Marshal(body)
+		if err != nil {
+			return applied, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		resp, err := c.doRequest(ctx, http.MethodPost, url, data)
+		if err == nil {
+			err = checkAPIError(resp)
+		}
+		if err != nil {
+			if len(chunks) > 1 {
+				err = fmt.Errorf("batch %d of %d: %w", i+1, len(chunks), err)
+			}
+			return applied, err
+		}
+
+		var result struct {
+			Result BatchResult `json:"result"`
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return applied, fmt.Errorf("failed to decode result: %w", err)
+		}
+		applied.Deletes = append(applied.Deletes, result.Result.Deletes...)
+		applied.Patches = append(applied.Patches, result.Result.Patches...)
+		applied.Posts = append(applied.Posts, result.Result.Posts...)
+	}
+
+	return applied, nil
+}
+
+// splitBatch splits a batch into requests of at most size changes, keeping
+// deletes ahead of patches ahead of posts
+func splitBatch(batch BatchRequest, size int) []BatchRequest {
+	var chunks []BatchRequest
+	var current BatchRequest
+	add := func(list *[]DNSRecord, record DNSRecord) {
+		*list = append(*list, record)
+		if current.Len() == size {
+			chunks = append(chunks, current)
+			current = BatchRequest{}
+		}
+	}
+	for _, record := range batch.Deletes {
+		add(&current.Deletes, record)
+	}
+	for _, record := range batch.Patches {
+		add(&current.Patches, record)
+	}
+	for _, record := range batch.Posts {
+		add(&current.Posts, record)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// VerifyToken checks that the API token is valid and returns its status
+func (c *Client) VerifyToken(ctx context.Context) (*TokenStatus, error) {
+	data, err := c.doRequest(ctx, http.MethodGet, c.baseURL+"/user/tokens/verify", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAPIError(data); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result TokenStatus `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	return &result.Result, nil
+}
+
+// ListZones fetches every zone the API token can access.
+// Handles pagination automatically.
+func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
+	var allZones []Zone
+	page := 1
+	perPage := 50 // Maximum allowed by Cloudflare API
+	maxPages := 20
+
+	for {
+		url := fmt.Sprintf("%s/zones?page=%d&per_page=%d", c.baseURL, page, perPage)
+		data, err := c.doRequest(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Success    bool          `json:"success"`
+			Errors     []ErrorDetail `json:"errors"`
+			Result     []Zone        `json:"result"`
+			ResultInfo *ResultInfo   `json:"result_info,omitempty"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if !resp.Success {
+			return nil, &APIError{StatusCode: http.StatusOK, Errors: resp.Errors}
+		}
+		allZones = append(allZones, resp.Result...)
+
+		if resp.ResultInfo == nil || page >= resp.ResultInfo.TotalPages {
+			break
+		}
+
+		page++
+		if page > maxPages {
+			break
+		}
+	}
+
+	return allZones, nil
+}
@@ -0,0 +1,258 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client talking to handler with short retry delays
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := NewClient("test-token")
+	c.baseURL = server.URL
+	c.retryDelay = time.Millisecond
+	return c
+}
+
+func TestDoRequestRetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"success":false,"errors":[{"code":971,"message":"Please wait and consider throttling your request speed"}]}`)
+			return
+		}
+		io.WriteString(w, `{"success":true,"result":{"id":"rec-1","type":"CNAME","name":"plex.example.com"}}`)
+	})
+
+	// POST is retried on 429: the request was rejected before it was applied
+	record, err := c.CreateDNSRecord(context.Background(), "zone", DNSRecord{Type: "CNAME", Name: "plex.example.com"})
+	if err != nil {
+		t.Fatalf("CreateDNSRecord: %v", err)
+	}
+	if record.ID != "rec-1" || calls.Load() != 3 {
+		t.Errorf("got record %q after %d calls, want rec-1 after 3", record.ID, calls.Load())
+	}
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := c.ListDNSRecords(context.Background(), "zone", "CNAME")
+	if !IsRateLimited(err) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if want := int32(defaultMaxRetries + 1); calls.Load() != want {
+		t.Errorf("got %d attempts, want %d", calls.Load(), want)
+	}
+}
+
+func TestDoRequestDoesNotRetryPostOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if _, err := c.CreateDNSRecord(context.Background(), "zone", DNSRecord{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("POST was attempted %d times, want 1", calls.Load())
+	}
+
+	calls.Store(0)
+	if err := c.DeleteDNSRecord(context.Background(), "zone", "rec-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if want := int32(defaultMaxRetries + 1); calls.Load() != want {
+		t.Errorf("DELETE was attempted %d times, want %d", calls.Load(), want)
+	}
+}
+
+func TestDoRequestHonorsCancellation(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.ListDNSRecords(ctx, "zone", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled request waited %s for Retry-After", elapsed)
+	}
+}
+
+func TestAPIErrorCodes(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"success":false,"errors":[{"code":81053,"message":"An A, AAAA, or CNAME record with that host already exists."}]}`)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"success":false,"errors":[{"code":81044,"message":"Record does not exist."}]}`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"success":false,"errors":[{"code":9109,"message":"Invalid access token"}]}`)
+		}
+	})
+	ctx := context.Background()
+
+	_, err := c.CreateDNSRecord(ctx, "zone", DNSRecord{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || !apiErr.HasCode(CodeHostnameConflict) {
+		t.Fatalf("expected APIError with code %d, got %#v", CodeHostnameConflict, err)
+	}
+	if !IsRecordExists(err) || IsNotFound(err) {
+		t.Errorf("IsRecordExists/IsNotFound wrong for %v", err)
+	}
+	if want := "API error: An A, AAAA, or CNAME record with that host already exists. (code 81053)"; err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+
+	if err := c.DeleteDNSRecord(ctx, "zone", "gone"); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := c.ListDNSRecords(ctx, "zone", ""); !IsAuthError(err) {
+		t.Errorf("expected auth error, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{"Fri, 02 Jan 2026 15:04:15 GMT", 10 * time.Second},
+		{"Fri, 02 Jan 2026 15:00:00 GMT", 0}, // Already passed
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestBatchDNSRecordsChunks(t *testing.T) {
+	var bodies []batchBody
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/zones/zone/dns_records/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		var body batchBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		bodies = append(bodies, body)
+
+		// The third request fails; the first two stay applied
+		if len(bodies) == 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"success":false,"errors":[{"code":81053,"message":"An A, AAAA, or CNAME record with that host already exists."}]}`)
+			return
+		}
+		result := BatchResult{}
+		for _, d := range body.Deletes {
+			result.Deletes = append(result.Deletes, DNSRecord{ID: d.ID})
+		}
+		for _, p := range body.Posts {
+			p.ID = "new-" + p.Name
+			result.Posts = append(result.Posts, p)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "result": result})
+	})
+
+	var batch BatchRequest
+	for i := 0; i < maxBatchSize+50; i++ {
+		batch.Deletes = append(batch.Deletes, DNSRecord{ID: fmt.Sprintf("old-%d", i)})
+	}
+	for i := 0; i < maxBatchSize; i++ {
+		batch.Posts = append(batch.Posts, DNSRecord{Type: "CNAME", Name: fmt.Sprintf("host%d.example.com", i)})
+	}
+
+	applied, err := c.BatchDNSRecords(context.Background(), "zone", batch)
+	if !IsRecordExists(err) {
+		t.Fatalf("expected record exists error from the third request, got %v", err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("got %d requests, want 3", len(bodies))
+	}
+	if len(bodies[0].Deletes) != maxBatchSize || len(bodies[1].Deletes) != 50 || len(bodies[1].Posts) != maxBatchSize-50 {
+		t.Errorf("unexpected chunking: %d deletes, then %d deletes + %d posts",
+			len(bodies[0].Deletes), len(bodies[1].Deletes), len(bodies[1].Posts))
+	}
+	if len(applied.Deletes) != maxBatchSize+50 || len(applied.Posts) != maxBatchSize-50 {
+		t.Errorf("applied %d deletes and %d posts before the failure", len(applied.Deletes), len(applied.Posts))
+	}
+	if applied.Posts[0].ID != "new-host0.example.com" {
+		t.Errorf("got created record %+v", applied.Posts[0])
+	}
+}
+
+func TestVerifyTokenAndListZones(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/user/tokens/verify":
+			io.WriteString(w, `{"success":true,"result":{"id":"tok","status":"active"}}`)
+		case r.URL.Path == "/zones" && r.URL.Query().Get("page") == "1":
+			io.WriteString(w, `{"success":true,"result_info":{"page":1,"total_pages":2},"result":[
+				{"id":"z1","name":"example.com","status":"active","permissions":["#zone:read","#dns_records:read","#dns_records:edit"]}]}`)
+		case r.URL.Path == "/zones" && r.URL.Query().Get("page") == "2":
+			io.WriteString(w, `{"success":true,"result_info":{"page":2,"total_pages":2},"result":[
+				{"id":"z2","name":"example.net","status":"active","permissions":["#zone:read","#dns_records:read"]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	status, err := c.VerifyToken(ctx)
+	if err != nil || status.Status != "active" {
+		t.Fatalf("VerifyToken = %+v, %v", status, err)
+	}
+
+	zones, err := c.ListZones(ctx)
+	if err != nil {
+		t.Fatalf("ListZones: %v", err)
+	}
+	if len(zones) != 2 || zones[0].Name != "example.com" || zones[1].ID != "z2" {
+		t.Fatalf("got zones %+v", zones)
+	}
+	if !zones[0].CanEditDNS() || zones[1].CanEditDNS() {
+		t.Errorf("CanEditDNS: %t, %t", zones[0].CanEditDNS(), zones[1].CanEditDNS())
+	}
+	if !(Zone{Name: "unknown.example"}).CanEditDNS() {
+		t.Error("a zone without permissions should be assumed editable")
+	}
+}
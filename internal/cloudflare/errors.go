@@ -0,0 +1,92 @@
+package cloudflare
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cloudflare error codes callers commonly react to
+const (
+	CodeAuthentication        = 10000 // Authentication error
+	CodeInvalidToken          = 9109  // Invalid access token
+	CodeRecordNotFound        = 81044 // Record does not exist
+	CodeHostnameConflict      = 81053 // An A, AAAA or CNAME record with that host already exists
+	CodeRecordAlreadyExists   = 81057 // Record already exists
+	CodeIdenticalRecordExists = 81058 // An identical record already exists
+)
+
+// APIError is returned when Cloudflare rejects a request. It carries the HTTP
+// status and the error codes from the response body.
+type APIError struct {
+	StatusCode int           // HTTP status (200 when the body reported success=false)
+	Errors     []ErrorDetail // Errors from the response body, if any
+	RetryAfter time.Duration // Wait requested by the Retry-After header (0 if none)
+}
+
+// Error formats the first Cloudflare error, or the HTTP status when there is none
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		if e.StatusCode == http.StatusOK {
+			return "API request failed"
+		}
+		return fmt.Sprintf("API returned status %d", e.StatusCode)
+	}
+	messages := make([]string, len(e.Errors))
+	for i, detail := range e.Errors {
+		messages[i] = fmt.Sprintf("%s (code %d)", detail.Message, detail.Code)
+	}
+	return "API error: " + strings.Join(messages, "; ")
+}
+
+// HasCode reports whether the response carried any of the given error codes
+func (e *APIError) HasCode(codes ...int) bool {
+	for _, detail := range e.Errors {
+		for _, code := range codes {
+			if detail.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Temporary reports whether retrying the request later may succeed
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// asAPIError unwraps err to an *APIError
+func asAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// IsRecordExists reports whether err means the record (or one with the same
+// host that can't coexist with it) already exists
+func IsRecordExists(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.HasCode(CodeHostnameConflict, CodeRecordAlreadyExists, CodeIdenticalRecordExists)
+}
+
+// IsNotFound reports whether err means the record or zone doesn't exist
+func IsNotFound(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.HasCode(CodeRecordNotFound))
+}
+
+// IsRateLimited reports whether err is a 429 that outlasted the client's retries
+func IsRateLimited(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsAuthError reports whether err means the API token was rejected
+func IsAuthError(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden ||
+		apiErr.HasCode(CodeAuthentication, CodeInvalidToken))
+}
@@ -1,5 +1,10 @@
 package cloudflare
 
+import (
+	"context"
+	"strings"
+)
+
 // DNSRecord represents a Cloudflare DNS record
 type DNSRecord struct {
 	ID       string `json:"id"`
@@ -8,23 +13,47 @@ type DNSRecord struct {
 	Content  string `json:"content"`  // Target (IP for A record, domain for CNAME)
 	Proxied  bool   `json:"proxied"`  // Cloudflare proxy (orange cloud)
 	TTL      int    `json:"ttl"`      // Time to live in seconds (1 = auto)
+	Comment  string `json:"comment"`  // Free-form note; carries the ownership marker
 	ZoneID   string `json:"zone_id"`
 	ZoneName string `json:"zone_name"`
 }
 
+// OwnerMarker tags the comment of DNS records managed by LazyProxyFlare.
+// Comments are available on every plan, unlike record tags.
+const OwnerMarker = "managed-by:lazyproxyflare"
+
+// IsOwned reports whether the record carries the ownership marker
+func (r DNSRecord) IsOwned() bool {
+	return strings.Contains(r.Comment, OwnerMarker)
+}
+
+// Owned returns a copy of the record with the ownership marker added to its
+// comment, keeping any existing comment text
+func (r DNSRecord) Owned() DNSRecord {
+	switch comment := strings.TrimSpace(r.Comment); {
+	case r.IsOwned():
+	case comment == "":
+		r.Comment = OwnerMarker
+	default:
+		r.Comment = comment + " " + OwnerMarker
+	}
+	return r
+}
+
 // DNSClient defines the interface for DNS record operations.
 // Implemented by Client; useful for mocking in tests.
 type DNSClient interface {
-	ListDNSRecords(zoneID string, recordType string) ([]DNSRecord, error)
-	CreateDNSRecord(zoneID string, record DNSRecord) (*DNSRecord, error)
-	UpdateDNSRecord(zoneID, recordID string, record DNSRecord) (*DNSRecord, error)
-	DeleteDNSRecord(zoneID, recordID string) error
+	ListDNSRecords(ctx context.Context, zoneID string, recordType string) ([]DNSRecord, error)
+	CreateDNSRecord(ctx context.Context, zoneID string, record DNSRecord) (*DNSRecord, error)
+	UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record DNSRecord) (*DNSRecord, error)
+	DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error
+	BatchDNSRecords(ctx context.Context, zoneID string, batch BatchRequest) (*BatchResult, error)
 }
 
 // APIResponse is the standard Cloudflare API response wrapper
 type APIResponse struct {
 	Success    bool          `json:"success"`
-	Errors     []APIError    `json:"errors"`
+	Errors     []ErrorDetail `json:"errors"`
 	Result     []DNSRecord   `json:"result"`
 	ResultInfo *ResultInfo   `json:"result_info,omitempty"`
 }
@@ -38,8 +67,60 @@ type ResultInfo struct {
 	TotalPages int `json:"total_pages"`
 }
 
-// APIError represents a Cloudflare API error
-type APIError struct {
+// ErrorDetail is a single error in a Cloudflare API response
+type ErrorDetail struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
+
+// BatchRequest is a set of DNS changes for one zone, applied atomically by the
+// batch endpoint. Cloudflare applies deletes first, then patches, then posts.
+type BatchRequest struct {
+	Deletes []DNSRecord // Records to delete (only ID is sent)
+	Patches []DNSRecord // Records to update, identified by ID
+	Posts   []DNSRecord // Records to create
+}
+
+// Len returns the number of changes in the batch
+func (b BatchRequest) Len() int {
+	return len(b.Deletes) + len(b.Patches) + len(b.Posts)
+}
+
+// BatchResult holds the records returned for each applied change, in the
+// same order as the request
+type BatchResult struct {
+	Deletes []DNSRecord `json:"deletes"`
+	Patches []DNSRecord `json:"patches"`
+	Posts   []DNSRecord `json:"posts"`
+}
+
+// PermissionDNSEdit is the zone permission needed to manage DNS records
+const PermissionDNSEdit = "#dns_records:edit"
+
+// Zone is a Cloudflare zone the API token can access
+type Zone struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`        // Zone domain (e.g., "example.com")
+	Status      string   `json:"status"`      // active, pending, ...
+	Permissions []string `json:"permissions"` // The token's permissions on the zone
+}
+
+// CanEditDNS reports whether the token may edit the zone's DNS records.
+// A zone listed without permissions is assumed editable.
+func (z Zone) CanEditDNS() bool {
+	if len(z.Permissions) == 0 {
+		return true
+	}
+	for _, p := range z.Permissions {
+		if p == PermissionDNSEdit {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenStatus is the result of verifying an API token
+type TokenStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // active, disabled or expired
+}
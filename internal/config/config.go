@@ -12,15 +12,24 @@ var (
 	domainRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
 )
 
-// GetAPIToken retrieves the API token, expanding environment variables if necessary
+// GetAPIToken retrieves the DNS provider's credential (the PowerDNS API key or
+// the RFC2136 TSIG secret for those providers), expanding environment
+// variables if necessary
 func (c *Config) GetAPIToken() (string, error) {
-	if c.Cloudflare.APIToken == "" {
-		return "", fmt.Errorf("api_token is not set in the profile")
+	field, value := "api_token", c.Cloudflare.APIToken
+	switch c.DNS.ProviderName() {
+	case DNSProviderPowerDNS:
+		field, value = "api_key", c.DNS.PowerDNS.APIKey
+	case DNSProviderRFC2136:
+		field, value = "tsig_secret", c.DNS.RFC2136.TSIGSecret
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is not set in the profile", field)
 	}
 	// expandEnvVars handles both plaintext tokens and ${VAR_NAME} style variables
-	token := expandEnvVars(c.Cloudflare.APIToken)
+	token := expandEnvVars(value)
 	if token == "" {
-		return "", fmt.Errorf("api_token is empty or the referenced environment variable is not set")
+		return "", fmt.Errorf("%s is empty or the referenced environment variable is not set", field)
 	}
 	return token, nil
 }
@@ -28,11 +37,8 @@ func (c *Config) GetAPIToken() (string, error) {
 // ValidateStructure checks that required structural fields are present
 func (c *Config) ValidateStructure() error {
 	// Check required fields
-	if c.Cloudflare.APIToken == "" {
-		return fmt.Errorf("cloudflare.api_token is required")
-	}
-	if c.Cloudflare.ZoneID == "" {
-		return fmt.Errorf("cloudflare.zone_id is required")
+	if err := validateDNS(c.DNS, c.Cloudflare, c.Domain); err != nil {
+		return err
 	}
 	if c.Domain == "" {
 		return fmt.Errorf("domain is required")
@@ -40,18 +46,24 @@ func (c *Config) ValidateStructure() error {
 	if c.Caddy.CaddyfilePath == "" {
 		return fmt.Errorf("caddy.caddyfile_path is required")
 	}
-	// Either ContainerName (Docker) or CaddyBinaryPath (System) must be set
-	if c.Caddy.ContainerName == "" && c.Caddy.CaddyBinaryPath == "" {
-		return fmt.Errorf("either caddy.container_name (for Docker) or caddy.caddy_binary_path (for system) is required")
+	// One of ContainerName (Docker), CaddyBinaryPath (System) or AdminAddress (admin API) must be set
+	if c.Caddy.ContainerName == "" && c.Caddy.CaddyBinaryPath == "" && c.Caddy.AdminAddress == "" {
+		return fmt.Errorf("either caddy.container_name (for Docker), caddy.caddy_binary_path (for system) or caddy.admin_address is required")
 	}
 
 	// Validate formats
-	if !isValidZoneID(c.Cloudflare.ZoneID) {
-		return fmt.Errorf("cloudflare.zone_id has invalid format (should be 32 hex characters)")
-	}
 	if !isValidDomain(c.Domain) {
 		return fmt.Errorf("domain has invalid format (should be a valid FQDN)")
 	}
+	if err := validateIgnoreRules(c.Ignore); err != nil {
+		return err
+	}
+	if err := validateTunnel(c.Tunnel); err != nil {
+		return err
+	}
+	if c.Tunnel.Enabled() && !c.DNS.IsCloudflare() {
+		return fmt.Errorf("tunnel requires the cloudflare DNS provider")
+	}
 	if c.Defaults.LANSubnet != "" && !isValidCIDR(c.Defaults.LANSubnet) {
 		return fmt.Errorf("defaults.lan_subnet has invalid CIDR format")
 	}
@@ -233,6 +233,15 @@ func TestValidateStructure(t *testing.T) {
 		}
 	})
 
+	t.Run("admin address instead of container", func(t *testing.T) {
+		cfg := validConfig
+		cfg.Caddy.ContainerName = ""
+		cfg.Caddy.AdminAddress = "localhost:2019"
+		if err := cfg.ValidateStructure(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
 	t.Run("invalid lan_subnet CIDR", func(t *testing.T) {
 		cfg := validConfig
 		cfg.Defaults.LANSubnet = "not-cidr"
@@ -241,3 +250,31 @@ func TestValidateStructure(t *testing.T) {
 		}
 	})
 }
+
+func TestProfileToLegacyConfigReloadCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment DeploymentMethod
+		caddy      CaddyProxyConfig
+		want       string
+	}{
+		{"system binary", DeploymentSystem, CaddyProxyConfig{CaddyBinaryPath: "/usr/bin/caddy"}, "/usr/bin/caddy reload --config {path}"},
+		{"system default", DeploymentSystem, CaddyProxyConfig{}, "caddy reload --config {path}"},
+		{"plain docker", DeploymentDocker, CaddyProxyConfig{DockerMethod: "plain"}, "docker exec {container} caddy reload --config {path}"},
+		{"compose file", DeploymentDocker, CaddyProxyConfig{DockerMethod: "compose", ComposeFilePath: "/srv/compose.yml"}, "docker compose -f {compose_file} exec -T {container} caddy reload --config {path}"},
+		{"custom command", DeploymentSystem, CaddyProxyConfig{RestartCommand: "sudo systemctl reload caddy"}, "sudo systemctl reload caddy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &ProfileConfig{}
+			profile.Proxy.Deployment = tt.deployment
+			profile.Proxy.Caddy = tt.caddy
+
+			cfg := ProfileToLegacyConfig(profile)
+			if cfg.Caddy.ReloadCommand != tt.want {
+				t.Errorf("ReloadCommand = %q, want %q", cfg.Caddy.ReloadCommand, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DNS providers a profile can select
+const (
+	DNSProviderCloudflare = "cloudflare" // The default
+	DNSProviderPowerDNS   = "powerdns"   // PowerDNS Authoritative HTTP API
+	DNSProviderRFC2136    = "rfc2136"    // RFC 2136 dynamic updates (BIND, Knot, ...)
+)
+
+// TSIG algorithms an RFC2136 profile can sign updates with
+var tsigAlgorithms = []string{"hmac-sha1", "hmac-sha224", "hmac-sha256", "hmac-sha384", "hmac-sha512"}
+
+// DNSConfig selects the DNS host holding the profile's records. Cloudflare
+// profiles keep their credentials and zones in the cloudflare section.
+type DNSConfig struct {
+	// Provider is "cloudflare" (the default), "powerdns" or "rfc2136"
+	Provider string `yaml:"provider,omitempty"`
+
+	PowerDNS PowerDNSConfig `yaml:"powerdns,omitempty"`
+	RFC2136  RFC2136Config  `yaml:"rfc2136,omitempty"`
+}
+
+// PowerDNSConfig points at the HTTP API of a PowerDNS Authoritative server
+type PowerDNSConfig struct {
+	APIURL   string `yaml:"api_url"`             // e.g. http://ns1.lan:8081
+	APIKey   string `yaml:"api_key"`             // Plaintext or ${VAR_NAME}
+	ServerID string `yaml:"server_id,omitempty"` // Default: localhost
+
+	// Zones lists additional zones by name. The primary zone is the profile domain.
+	Zones []string `yaml:"zones,omitempty"`
+}
+
+// RFC2136Config sends dynamic updates (RFC 2136) signed with a TSIG key to an
+// authoritative server such as BIND or Knot. Zones are listed with AXFR, which
+// the key must be allowed to do.
+type RFC2136Config struct {
+	Server        string `yaml:"server"`                   // host or host:port (port 53 if omitted)
+	TSIGKeyName   string `yaml:"tsig_key_name"`            // Key name as configured on the server
+	TSIGSecret    string `yaml:"tsig_secret"`              // Base64 secret; plaintext or ${VAR_NAME}
+	TSIGAlgorithm string `yaml:"tsig_algorithm,omitempty"` // Default: hmac-sha256
+
+	// Zones lists additional zones by name. The primary zone is the profile domain.
+	Zones []string `yaml:"zones,omitempty"`
+}
+
+// Algorithm returns the TSIG algorithm, defaulting to hmac-sha256
+func (r RFC2136Config) Algorithm() string {
+	if r.TSIGAlgorithm == "" {
+		return "hmac-sha256"
+	}
+	return strings.ToLower(strings.TrimSuffix(r.TSIGAlgorithm, "."))
+}
+
+// Address returns the server as host:port
+func (r RFC2136Config) Address() string {
+	if _, _, err := net.SplitHostPort(r.Server); err == nil {
+		return r.Server
+	}
+	return net.JoinHostPort(strings.Trim(r.Server, "[]"), "53")
+}
+
+// ProviderName returns the selected provider, defaulting to Cloudflare
+func (d DNSConfig) ProviderName() string {
+	if d.Provider == "" {
+		return DNSProviderCloudflare
+	}
+	return d.Provider
+}
+
+// IsCloudflare reports whether the profile's records are hosted by Cloudflare
+func (d DNSConfig) IsCloudflare() bool {
+	return d.ProviderName() == DNSProviderCloudflare
+}
+
+// namedZones returns the additional zones of providers that identify zones by
+// name rather than by ID; ok is false for Cloudflare
+func (d DNSConfig) namedZones() (zones []string, ok bool) {
+	switch d.ProviderName() {
+	case DNSProviderPowerDNS:
+		return d.PowerDNS.Zones, true
+	case DNSProviderRFC2136:
+		return d.RFC2136.Zones, true
+	}
+	return nil, false
+}
+
+// validateDNS checks the provider selection and its credentials and zones
+func validateDNS(d DNSConfig, cf CloudflareConfig, domain string) error {
+	switch d.ProviderName() {
+	case DNSProviderCloudflare:
+		if cf.APIToken == "" {
+			return fmt.Errorf("cloudflare.api_token is required")
+		}
+		if cf.ZoneID == "" {
+			return fmt.Errorf("cloudflare.zone_id is required")
+		}
+		if !isValidZoneID(cf.ZoneID) {
+			return fmt.Errorf("cloudflare.zone_id has invalid format (should be 32 hex characters)")
+		}
+		return validateZones(cf.Zones, domain)
+	case DNSProviderPowerDNS:
+		return validatePowerDNS(d.PowerDNS, domain)
+	case DNSProviderRFC2136:
+		if cf.OwnedOnly {
+			return fmt.Errorf("cloudflare.owned_only needs record comments, which RFC 2136 servers don't keep")
+		}
+		return validateRFC2136(d.RFC2136, domain)
+	default:
+		return fmt.Errorf("dns.provider must be %q, %q or %q", DNSProviderCloudflare, DNSProviderPowerDNS, DNSProviderRFC2136)
+	}
+}
+
+// validatePowerDNS checks the PowerDNS API settings and zone names
+func validatePowerDNS(p PowerDNSConfig, domain string) error {
+	if p.APIURL == "" {
+		return fmt.Errorf("dns.powerdns.api_url is required")
+	}
+	if u, err := url.Parse(p.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("dns.powerdns.api_url must be an http(s) URL")
+	}
+	if p.APIKey == "" {
+		return fmt.Errorf("dns.powerdns.api_key is required")
+	}
+	return validateZoneNames("dns.powerdns.zones", p.Zones, domain)
+}
+
+// validateRFC2136 checks the server, TSIG key and zone names
+func validateRFC2136(r RFC2136Config, domain string) error {
+	if r.Server == "" {
+		return fmt.Errorf("dns.rfc2136.server is required")
+	}
+	if r.TSIGKeyName == "" || r.TSIGSecret == "" {
+		return fmt.Errorf("dns.rfc2136.tsig_key_name and tsig_secret are required")
+	}
+	if !slices.Contains(tsigAlgorithms, r.Algorithm()) {
+		return fmt.Errorf("dns.rfc2136.tsig_algorithm must be one of %s", strings.Join(tsigAlgorithms, ", "))
+	}
+	return validateZoneNames("dns.rfc2136.zones", r.Zones, domain)
+}
+
+// validateZoneNames checks additional zones given by name
+func validateZoneNames(field string, zones []string, domain string) error {
+	seen := map[string]bool{strings.ToLower(domain): true}
+	for i, zone := range zones {
+		zone = strings.TrimSuffix(zone, ".")
+		if !isValidDomain(zone) {
+			return fmt.Errorf("%s[%d] has invalid format (should be a valid FQDN)", field, i)
+		}
+		if seen[strings.ToLower(zone)] {
+			return fmt.Errorf("%s[%d] %s is listed more than once", field, i, zone)
+		}
+		seen[strings.ToLower(zone)] = true
+	}
+	return nil
+}
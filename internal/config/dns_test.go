@@ -0,0 +1,81 @@
+package config
+
+import "testing"
+
+func TestValidateDNS(t *testing.T) {
+	cf := CloudflareConfig{APIToken: "token", ZoneID: "abcdef0123456789abcdef0123456789"}
+	pdns := PowerDNSConfig{APIURL: "http://ns1.lan:8081", APIKey: "${PDNS_API_KEY}"}
+	withZones := func(zones ...string) PowerDNSConfig {
+		p := pdns
+		p.Zones = zones
+		return p
+	}
+	tsig := RFC2136Config{Server: "ns1.home.arpa", TSIGKeyName: "lazyproxyflare", TSIGSecret: "c2VjcmV0"}
+	withAlgorithm := func(algorithm string) RFC2136Config {
+		r := tsig
+		r.TSIGAlgorithm = algorithm
+		return r
+	}
+	tests := []struct {
+		name    string
+		dns     DNSConfig
+		cf      CloudflareConfig
+		wantErr bool
+	}{
+		{"cloudflare default", DNSConfig{}, cf, false},
+		{"cloudflare without token", DNSConfig{Provider: DNSProviderCloudflare}, CloudflareConfig{ZoneID: cf.ZoneID}, true},
+		{"powerdns", DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: pdns}, CloudflareConfig{}, false},
+		{"powerdns zones", DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: withZones("example.net.")}, CloudflareConfig{}, false},
+		{"powerdns duplicate zone", DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: withZones("Example.com")}, CloudflareConfig{}, true},
+		{"powerdns without key", DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: PowerDNSConfig{APIURL: pdns.APIURL}}, CloudflareConfig{}, true},
+		{"powerdns bad url", DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: PowerDNSConfig{APIURL: "ns1.lan:8081", APIKey: "key"}}, CloudflareConfig{}, true},
+		{"rfc2136", DNSConfig{Provider: DNSProviderRFC2136, RFC2136: tsig}, CloudflareConfig{}, false},
+		{"rfc2136 algorithm", DNSConfig{Provider: DNSProviderRFC2136, RFC2136: withAlgorithm("HMAC-SHA512.")}, CloudflareConfig{}, false},
+		{"rfc2136 unknown algorithm", DNSConfig{Provider: DNSProviderRFC2136, RFC2136: withAlgorithm("hmac-md5")}, CloudflareConfig{}, true},
+		{"rfc2136 without secret", DNSConfig{Provider: DNSProviderRFC2136, RFC2136: RFC2136Config{Server: tsig.Server, TSIGKeyName: "key"}}, CloudflareConfig{}, true},
+		{"rfc2136 owned only", DNSConfig{Provider: DNSProviderRFC2136, RFC2136: tsig}, CloudflareConfig{OwnedOnly: true}, true},
+		{"unknown provider", DNSConfig{Provider: "route53"}, cf, true},
+	}
+	for _, tt := range tests {
+		if err := validateDNS(tt.dns, tt.cf, "example.com"); (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPowerDNSZones(t *testing.T) {
+	t.Setenv("PDNS_API_KEY", "secret")
+	cfg := &Config{
+		Domain: "example.com",
+		DNS: DNSConfig{Provider: DNSProviderPowerDNS, PowerDNS: PowerDNSConfig{
+			APIURL: "http://ns1.lan:8081",
+			APIKey: "${PDNS_API_KEY}",
+			Zones:  []string{"example.net."},
+		}},
+	}
+	if !cfg.IsMultiZone() {
+		t.Error("expected a multi-zone profile")
+	}
+	if got := cfg.ZoneIDFor("app.example.net"); got != "example.net" {
+		t.Errorf("ZoneIDFor = %q, want example.net", got)
+	}
+	if got := cfg.ZoneIDFor("app.example.org"); got != "example.com" {
+		t.Errorf("ZoneIDFor fallback = %q, want example.com", got)
+	}
+	if token, err := cfg.GetAPIToken(); err != nil || token != "secret" {
+		t.Errorf("GetAPIToken = %q, %v; want the PowerDNS API key", token, err)
+	}
+}
+
+func TestRFC2136Address(t *testing.T) {
+	for server, want := range map[string]string{
+		"ns1.home.arpa":      "ns1.home.arpa:53",
+		"ns1.home.arpa:5353": "ns1.home.arpa:5353",
+		"fd00::53":           "[fd00::53]:53",
+		"[fd00::53]:5353":    "[fd00::53]:5353",
+	} {
+		if got := (RFC2136Config{Server: server}).Address(); got != want {
+			t.Errorf("Address(%q) = %q, want %q", server, got, want)
+		}
+	}
+}
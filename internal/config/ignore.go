@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IgnoreRule hides DNS records and Caddy sites that are never meant to be
+// synced, such as mail records or a Caddy admin site on :2019
+type IgnoreRule struct {
+	// Name is a glob (*, ?) or a /regex/ matched against the full,
+	// lowercase name. "@" matches the apex of any zone. Empty matches any name.
+	Name string `yaml:"name,omitempty"`
+
+	// Types limits the rule to DNS records of these types. A rule without
+	// types also matches Caddy sites.
+	Types []string `yaml:"types,omitempty"`
+}
+
+// isRegex reports whether the rule name is a /regex/
+func (r IgnoreRule) isRegex() bool {
+	return len(r.Name) > 1 && strings.HasPrefix(r.Name, "/") && strings.HasSuffix(r.Name, "/")
+}
+
+// validateIgnoreRules checks that every rule matches something and compiles
+func validateIgnoreRules(rules []IgnoreRule) error {
+	for i, rule := range rules {
+		if rule.Name == "" && len(rule.Types) == 0 {
+			return fmt.Errorf("ignore[%d] needs a name or types", i)
+		}
+		if rule.isRegex() {
+			if _, err := regexp.Compile(rule.Name[1 : len(rule.Name)-1]); err != nil {
+				return fmt.Errorf("ignore[%d].name is not a valid regex: %w", i, err)
+			}
+		} else if _, err := path.Match(strings.ToLower(rule.Name), ""); err != nil {
+			return fmt.Errorf("ignore[%d].name is not a valid glob: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// IgnoreMatcher returns a function reporting whether a name matches one of
+// the profile's ignore rules. recordType is the DNS record type, or "" for a
+// Caddy site. Returns nil when the profile has no rules; invalid rules are
+// skipped (they are rejected when the profile is validated).
+func (c *Config) IgnoreMatcher() func(name, recordType string) bool {
+	if len(c.Ignore) == 0 {
+		return nil
+	}
+
+	type compiled struct {
+		rule  IgnoreRule
+		regex *regexp.Regexp
+	}
+	var rules []compiled
+	for _, rule := range c.Ignore {
+		entry := compiled{rule: rule}
+		if rule.isRegex() {
+			re, err := regexp.Compile(rule.Name[1 : len(rule.Name)-1])
+			if err != nil {
+				continue
+			}
+			entry.regex = re
+		}
+		rules = append(rules, entry)
+	}
+	apexes := c.ZoneDomains()
+
+	return func(name, recordType string) bool {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		for _, r := range rules {
+			if len(r.rule.Types) > 0 && !slices.ContainsFunc(r.rule.Types, func(t string) bool {
+				return strings.EqualFold(t, recordType)
+			}) {
+				continue
+			}
+
+			var matched bool
+			switch {
+			case r.rule.Name == "":
+				matched = true
+			case r.rule.Name == "@":
+				matched = slices.ContainsFunc(apexes, func(apex string) bool {
+					return strings.EqualFold(apex, name)
+				})
+			case r.regex != nil:
+				matched = r.regex.MatchString(name)
+			default:
+				matched, _ = path.Match(strings.ToLower(r.rule.Name), name)
+			}
+			if matched {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import "testing"
+
+func TestIgnoreMatcher(t *testing.T) {
+	cfg := multiZoneConfig()
+	cfg.Ignore = []IgnoreRule{
+		{Name: "mail.*"},
+		{Name: `/^(autodiscover|autoconfig)\./`},
+		{Name: "@", Types: []string{"A", "AAAA"}},
+		{Name: ":*"},
+		{Name: "localhost"},
+		{Types: []string{"mx"}},
+	}
+	ignored := cfg.IgnoreMatcher()
+
+	tests := []struct {
+		name       string
+		recordType string
+		want       bool
+	}{
+		{"mail.example.com", "A", true},
+		{"Mail.Example.NET.", "CNAME", true},
+		{"autodiscover.example.com", "CNAME", true},
+		{"myautodiscover.example.com", "CNAME", false},
+		{"example.com", "A", true},
+		{"example.net", "AAAA", true},
+		{"example.com", "CNAME", false}, // Apex rule is limited to A/AAAA
+		{"example.com", "", false},      // ... so it leaves Caddy sites alone
+		{":2019", "", true},
+		{"localhost", "", true},
+		{"app.example.com", "MX", true},
+		{"app.example.com", "A", false},
+	}
+	for _, tt := range tests {
+		if got := ignored(tt.name, tt.recordType); got != tt.want {
+			t.Errorf("ignored(%q, %q) = %v, want %v", tt.name, tt.recordType, got, tt.want)
+		}
+	}
+
+	if (&Config{}).IgnoreMatcher() != nil {
+		t.Error("expected nil matcher without rules")
+	}
+}
+
+func TestValidateIgnoreRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []IgnoreRule
+		wantErr bool
+	}{
+		{"glob and types", []IgnoreRule{{Name: "_dmarc.*"}, {Types: []string{"MX"}}}, false},
+		{"empty rule", []IgnoreRule{{}}, true},
+		{"bad regex", []IgnoreRule{{Name: "/(unclosed/"}}, true},
+		{"bad glob", []IgnoreRule{{Name: "[a-"}}, true},
+	}
+	for _, tt := range tests {
+		if err := validateIgnoreRules(tt.rules); (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
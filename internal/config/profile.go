@@ -190,11 +190,8 @@ func SetLastUsedProfile(name string) error {
 // validateProfile validates a profile configuration
 func validateProfile(p *ProfileConfig) error {
 	// Check required fields
-	if p.Cloudflare.APIToken == "" {
-		return fmt.Errorf("cloudflare.api_token is required")
-	}
-	if p.Cloudflare.ZoneID == "" {
-		return fmt.Errorf("cloudflare.zone_id is required")
+	if err := validateDNS(p.DNS, p.Cloudflare, p.Domain); err != nil {
+		return err
 	}
 	if p.Domain == "" {
 		return fmt.Errorf("domain is required")
@@ -217,12 +214,18 @@ func validateProfile(p *ProfileConfig) error {
 	}
 
 	// Validate formats (reuse existing validators)
-	if !isValidZoneID(p.Cloudflare.ZoneID) {
-		return fmt.Errorf("cloudflare.zone_id has invalid format (should be 32 hex characters)")
-	}
 	if !isValidDomain(p.Domain) {
 		return fmt.Errorf("domain has invalid format (should be a valid FQDN)")
 	}
+	if err := validateIgnoreRules(p.Ignore); err != nil {
+		return err
+	}
+	if err := validateTunnel(p.Tunnel); err != nil {
+		return err
+	}
+	if p.Tunnel.Enabled() && !p.DNS.IsCloudflare() {
+		return fmt.Errorf("tunnel requires the cloudflare DNS provider")
+	}
 	if p.Defaults.LANSubnet != "" && !isValidCIDR(p.Defaults.LANSubnet) {
 		return fmt.Errorf("defaults.lan_subnet has invalid CIDR format")
 	}
@@ -238,6 +241,7 @@ func validateProfile(p *ProfileConfig) error {
 func ProfileToLegacyConfig(profile *ProfileConfig) *Config {
 	return &Config{
 		Cloudflare: profile.Cloudflare,
+		DNS:        profile.DNS,
 		Domain:     profile.Domain,
 		Caddy: CaddyConfig{
 			CaddyfilePath:          profile.Proxy.Caddy.CaddyfilePath,
@@ -247,9 +251,14 @@ func ProfileToLegacyConfig(profile *ProfileConfig) *Config {
 			ComposeFilePath:        profile.Proxy.Caddy.ComposeFilePath,
 			CaddyBinaryPath:        profile.Proxy.Caddy.CaddyBinaryPath,
 			ValidationCommand:      profile.Proxy.Caddy.GetValidationCommand(profile.Proxy.Deployment),
+			ReloadCommand:          profile.Proxy.Caddy.GetReloadCommand(profile.Proxy.Deployment),
+			AdminAddress:           profile.Proxy.Caddy.AdminAddress,
+			SiteFilesDir:           profile.Proxy.Caddy.SiteFilesDir,
 		},
 		Defaults: profile.Defaults,
 		UI:       profile.UI,
 		Backup:   profile.Backup,
+		Ignore:   profile.Ignore,
+		Tunnel:   profile.Tunnel,
 	}
 }
@@ -6,11 +6,14 @@ import "time"
 type ProfileConfig struct {
 	Profile    ProfileMetadata  `yaml:"profile"`
 	Cloudflare CloudflareConfig `yaml:"cloudflare"`
+	DNS        DNSConfig        `yaml:"dns,omitempty"`
 	Domain     string           `yaml:"domain"`
 	Proxy      ProxyConfig      `yaml:"proxy"`
 	Defaults   DefaultsConfig   `yaml:"defaults"`
 	UI         UIConfig         `yaml:"ui"`
 	Backup     BackupConfig     `yaml:"backup,omitempty"`
+	Ignore     []IgnoreRule     `yaml:"ignore,omitempty"`
+	Tunnel     TunnelConfig     `yaml:"tunnel,omitempty"`
 }
 
 // BackupConfig holds backup rotation settings
@@ -58,6 +61,8 @@ type CaddyProxyConfig struct {
 	CaddyBinaryPath        string `yaml:"caddy_binary_path,omitempty"`        // Path to caddy binary (for system deployment)
 	ValidationCommand      string `yaml:"validation_command,omitempty"`       // Optional custom command
 	RestartCommand         string `yaml:"restart_command,omitempty"`          // Optional custom command
+	AdminAddress           string `yaml:"admin_address,omitempty"`            // Caddy admin API; validate/reload via /adapt and /load
+	SiteFilesDir           string `yaml:"site_files_dir,omitempty"`           // Write new sites to files of their own in this directory
 }
 
 // GetValidationCommand returns the validation command with placeholders
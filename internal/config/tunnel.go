@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TunnelDomain is the domain Cloudflare routes tunnel traffic through
+const TunnelDomain = "cfargotunnel.com"
+
+// Tunnel modes: where ingress rules send traffic
+const (
+	TunnelModeCaddy  = "caddy"  // Through Caddy (the default)
+	TunnelModeDirect = "direct" // Straight to the entry's upstream
+)
+
+var tunnelIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// TunnelConfig exposes entries through a Cloudflare Tunnel run by a local
+// cloudflared. Entries whose DNS record is a CNAME to the tunnel get an
+// ingress rule in the cloudflared config.
+type TunnelConfig struct {
+	ID         string `yaml:"id"`          // Tunnel UUID
+	ConfigPath string `yaml:"config_path"` // cloudflared config.yml holding the ingress rules
+
+	// Mode is "caddy" (ingress points at Caddy, which proxies as usual) or
+	// "direct" (ingress points at the entry's upstream; no Caddy block)
+	Mode string `yaml:"mode,omitempty"`
+
+	// CaddyService is Caddy's address for ingress rules in caddy mode
+	// (default: https://localhost:443, with the hostname sent as SNI)
+	CaddyService string `yaml:"caddy_service,omitempty"`
+
+	// RestartCommand applies ingress changes (e.g., "systemctl restart
+	// cloudflared"); cloudflared doesn't reload its config on its own
+	RestartCommand string `yaml:"restart_command,omitempty"`
+}
+
+// Enabled reports whether the profile uses a tunnel
+func (t TunnelConfig) Enabled() bool {
+	return t.ID != ""
+}
+
+// CNAMETarget returns the record content that routes a hostname through the tunnel
+func (t TunnelConfig) CNAMETarget() string {
+	return strings.ToLower(t.ID) + "." + TunnelDomain
+}
+
+// Direct reports whether ingress rules point straight at upstreams
+func (t TunnelConfig) Direct() bool {
+	return t.Mode == TunnelModeDirect
+}
+
+// CaddyURL returns the service ingress rules use in caddy mode
+func (t TunnelConfig) CaddyURL() string {
+	if t.CaddyService != "" {
+		return t.CaddyService
+	}
+	return "https://localhost:443"
+}
+
+// validateTunnel checks the tunnel settings of a profile that uses one
+func validateTunnel(t TunnelConfig) error {
+	if !t.Enabled() {
+		return nil
+	}
+	if !tunnelIDRegex.MatchString(t.ID) {
+		return fmt.Errorf("tunnel.id has invalid format (should be the tunnel UUID)")
+	}
+	if t.ConfigPath == "" {
+		return fmt.Errorf("tunnel.config_path is required")
+	}
+	if t.Mode != "" && t.Mode != TunnelModeCaddy && t.Mode != TunnelModeDirect {
+		return fmt.Errorf("tunnel.mode must be %q or %q", TunnelModeCaddy, TunnelModeDirect)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestValidateTunnel(t *testing.T) {
+	const id = "6ff42ae2-765d-4adf-8112-31c55c1551ef"
+	tests := []struct {
+		name    string
+		tunnel  TunnelConfig
+		wantErr bool
+	}{
+		{"disabled", TunnelConfig{}, false},
+		{"caddy mode", TunnelConfig{ID: id, ConfigPath: "/etc/cloudflared/config.yml"}, false},
+		{"direct mode", TunnelConfig{ID: id, ConfigPath: "/etc/cloudflared/config.yml", Mode: TunnelModeDirect}, false},
+		{"bad id", TunnelConfig{ID: "my-tunnel", ConfigPath: "/etc/cloudflared/config.yml"}, true},
+		{"missing config path", TunnelConfig{ID: id}, true},
+		{"bad mode", TunnelConfig{ID: id, ConfigPath: "/etc/cloudflared/config.yml", Mode: "proxy"}, true},
+	}
+	for _, tt := range tests {
+		if err := validateTunnel(tt.tunnel); (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
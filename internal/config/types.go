@@ -3,11 +3,14 @@ package config
 // Config represents the application configuration
 type Config struct {
 	Cloudflare CloudflareConfig `yaml:"cloudflare"`
+	DNS        DNSConfig        `yaml:"dns,omitempty"`
 	Domain     string           `yaml:"domain"`
 	Caddy      CaddyConfig      `yaml:"caddy"`
 	Defaults   DefaultsConfig   `yaml:"defaults"`
 	UI         UIConfig         `yaml:"ui"`
 	Backup     BackupConfig     `yaml:"backup,omitempty"`
+	Ignore     []IgnoreRule     `yaml:"ignore,omitempty"`
+	Tunnel     TunnelConfig     `yaml:"tunnel,omitempty"`
 }
 
 // CloudflareConfig holds Cloudflare API credentials
@@ -17,6 +20,20 @@ type CloudflareConfig struct {
 	APIToken string `yaml:"api_token"`
 
 	ZoneID string `yaml:"zone_id"`
+
+	// Zones lists additional zones managed by the same profile. The primary
+	// zone is ZoneID together with the profile domain.
+	Zones []ZoneConfig `yaml:"zones,omitempty"`
+
+	// OwnedOnly limits orphan cleanup to DNS records tagged as managed by
+	// LazyProxyFlare. Other DNS-only records are listed as unmanaged.
+	OwnedOnly bool `yaml:"owned_only,omitempty"`
+}
+
+// ZoneConfig pairs an additional Cloudflare zone ID with the domain it serves
+type ZoneConfig struct {
+	ZoneID string `yaml:"zone_id"`
+	Domain string `yaml:"domain"`
 }
 
 // CaddyConfig holds Caddy-related configuration
@@ -28,6 +45,9 @@ type CaddyConfig struct {
 	ComposeFilePath        string `yaml:"compose_file_path,omitempty"`        // Path to docker-compose.yml
 	CaddyBinaryPath        string `yaml:"caddy_binary_path,omitempty"`        // Path to caddy binary (system deployment only)
 	ValidationCommand      string `yaml:"validation_command,omitempty"`       // Command with placeholders
+	ReloadCommand          string `yaml:"reload_command,omitempty"`           // Command with placeholders (empty: docker restart)
+	AdminAddress           string `yaml:"admin_address,omitempty"`            // Caddy admin API (host:port, URL or unix//path)
+	SiteFilesDir           string `yaml:"site_files_dir,omitempty"`           // New sites go to <dir>/<domain>.caddy (relative to the Caddyfile)
 }
 
 // DefaultsConfig holds default values for new entries
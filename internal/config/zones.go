@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllZones returns the primary zone followed by any additional zones.
+// Providers other than Cloudflare identify zones by name, so their zone ID
+// is the domain.
+func (c *Config) AllZones() []ZoneConfig {
+	if names, ok := c.DNS.namedZones(); ok {
+		zones := []ZoneConfig{{ZoneID: c.Domain, Domain: c.Domain}}
+		for _, name := range names {
+			name = strings.TrimSuffix(name, ".")
+			zones = append(zones, ZoneConfig{ZoneID: name, Domain: name})
+		}
+		return zones
+	}
+	zones := []ZoneConfig{{ZoneID: c.Cloudflare.ZoneID, Domain: c.Domain}}
+	return append(zones, c.Cloudflare.Zones...)
+}
+
+// IsMultiZone reports whether the profile manages more than one zone
+func (c *Config) IsMultiZone() bool {
+	return c != nil && len(c.AllZones()) > 1
+}
+
+// ZoneFor returns the zone whose domain is the longest suffix of fqdn
+func (c *Config) ZoneFor(fqdn string) (ZoneConfig, bool) {
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+
+	var best ZoneConfig
+	found := false
+	for _, zone := range c.AllZones() {
+		domain := strings.ToLower(zone.Domain)
+		if fqdn != domain && !strings.HasSuffix(fqdn, "."+domain) {
+			continue
+		}
+		if !found || len(domain) > len(best.Domain) {
+			best = zone
+			found = true
+		}
+	}
+	return best, found
+}
+
+// ZoneIDFor returns the zone ID that should hold the record for fqdn,
+// falling back to the primary zone when no zone domain matches
+func (c *Config) ZoneIDFor(fqdn string) string {
+	if zone, ok := c.ZoneFor(fqdn); ok {
+		return zone.ZoneID
+	}
+	return c.AllZones()[0].ZoneID
+}
+
+// ZoneDomains returns the domain of every zone, primary first
+func (c *Config) ZoneDomains() []string {
+	zones := c.AllZones()
+	domains := make([]string, len(zones))
+	for i, zone := range zones {
+		domains[i] = zone.Domain
+	}
+	return domains
+}
+
+// validateZones validates the additional zones of a profile
+func validateZones(zones []ZoneConfig, primaryDomain string) error {
+	seen := map[string]bool{strings.ToLower(primaryDomain): true}
+	for i, zone := range zones {
+		if !isValidZoneID(zone.ZoneID) {
+			return fmt.Errorf("cloudflare.zones[%d].zone_id has invalid format (should be 32 hex characters)", i)
+		}
+		if !isValidDomain(zone.Domain) {
+			return fmt.Errorf("cloudflare.zones[%d].domain has invalid format (should be a valid FQDN)", i)
+		}
+		domain := strings.ToLower(zone.Domain)
+		if seen[domain] {
+			return fmt.Errorf("cloudflare.zones[%d].domain %s is listed more than once", i, zone.Domain)
+		}
+		seen[domain] = true
+	}
+	return nil
+}
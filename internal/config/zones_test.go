@@ -0,0 +1,83 @@
+package config
+
+import "testing"
+
+func multiZoneConfig() *Config {
+	return &Config{
+		Cloudflare: CloudflareConfig{
+			ZoneID: "11111111111111111111111111111111",
+			Zones: []ZoneConfig{
+				{ZoneID: "22222222222222222222222222222222", Domain: "example.net"},
+				{ZoneID: "33333333333333333333333333333333", Domain: "lab.example.com"},
+			},
+		},
+		Domain: "example.com",
+	}
+}
+
+func TestZoneFor(t *testing.T) {
+	cfg := multiZoneConfig()
+
+	tests := []struct {
+		name     string
+		fqdn     string
+		wantZone string
+		found    bool
+	}{
+		{"primary zone", "app.example.com", "11111111111111111111111111111111", true},
+		{"additional zone", "bar.example.net", "22222222222222222222222222222222", true},
+		{"longest suffix wins", "nas.lab.example.com", "33333333333333333333333333333333", true},
+		{"zone apex", "example.net", "22222222222222222222222222222222", true},
+		{"case and trailing dot", "Bar.Example.NET.", "22222222222222222222222222222222", true},
+		{"suffix without dot boundary", "app.notexample.com", "", false},
+		{"unknown zone", "app.example.org", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, found := cfg.ZoneFor(tt.fqdn)
+			if found != tt.found {
+				t.Fatalf("ZoneFor(%q) found = %v, want %v", tt.fqdn, found, tt.found)
+			}
+			if zone.ZoneID != tt.wantZone {
+				t.Errorf("ZoneFor(%q) = %q, want %q", tt.fqdn, zone.ZoneID, tt.wantZone)
+			}
+		})
+	}
+}
+
+func TestZoneIDForFallsBackToPrimary(t *testing.T) {
+	cfg := multiZoneConfig()
+	if got := cfg.ZoneIDFor("app.example.org"); got != cfg.Cloudflare.ZoneID {
+		t.Errorf("ZoneIDFor unknown domain = %q, want primary %q", got, cfg.Cloudflare.ZoneID)
+	}
+	if !cfg.IsMultiZone() {
+		t.Error("expected multi-zone config")
+	}
+	if domains := cfg.ZoneDomains(); len(domains) != 3 || domains[0] != "example.com" {
+		t.Errorf("unexpected zone domains: %v", domains)
+	}
+}
+
+func TestValidateZones(t *testing.T) {
+	tests := []struct {
+		name    string
+		zones   []ZoneConfig
+		wantErr bool
+	}{
+		{"no extra zones", nil, false},
+		{"valid zone", []ZoneConfig{{ZoneID: "22222222222222222222222222222222", Domain: "example.net"}}, false},
+		{"invalid zone id", []ZoneConfig{{ZoneID: "nope", Domain: "example.net"}}, true},
+		{"invalid domain", []ZoneConfig{{ZoneID: "22222222222222222222222222222222", Domain: "not valid"}}, true},
+		{"duplicates primary", []ZoneConfig{{ZoneID: "22222222222222222222222222222222", Domain: "Example.com"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateZones(tt.zones, "example.com")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateZones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
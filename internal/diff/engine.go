@@ -1,22 +1,43 @@
 package diff
 
 import (
+	"fmt"
+	"net"
 	"strings"
 
 	"lazyproxyflare/internal/caddy"
 	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/tunnel"
 )
 
 // Compare compares DNS records with Caddy entries and returns sync status for each domain
 func Compare(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry) []SyncedEntry {
+	return CompareWithOptions(dnsRecords, caddyEntries, CompareOptions{})
+}
+
+// CompareWithOptions is Compare plus field-level drift detection for entries that exist on both sides
+func CompareWithOptions(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry, opts CompareOptions) []SyncedEntry {
 	var results []SyncedEntry
 
 	// Build map of DNS records by domain name (lowercase for case-insensitive matching)
+	// An A and AAAA record with the same name are paired as one dual-stack entry
 	dnsMap := make(map[string]*cloudflare.DNSRecord)
+	aaaaMap := make(map[string]*cloudflare.DNSRecord)
 	for i := range dnsRecords {
 		record := &dnsRecords[i]
 		domain := strings.ToLower(record.Name)
-		dnsMap[domain] = record
+		existing := dnsMap[domain]
+		switch {
+		case existing == nil:
+			dnsMap[domain] = record
+		case existing.Type == "A" && record.Type == "AAAA":
+			aaaaMap[domain] = record
+		case existing.Type == "AAAA" && record.Type == "A":
+			dnsMap[domain] = record
+			aaaaMap[domain] = existing
+		default:
+			dnsMap[domain] = record
+		}
 	}
 
 	// Build map of Caddy entries by domain name
@@ -31,7 +52,26 @@ func Compare(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry)
 		}
 	}
 
-	// Build set of all unique domains from both sources
+	// Wildcard records (*.example.com) serve Caddy domains that have no record of their own
+	coveredWildcards := make(map[string]bool)
+	for domain := range caddyMap {
+		if dnsMap[domain] == nil {
+			if wildcard := WildcardName(domain); dnsMap[wildcard] != nil {
+				coveredWildcards[wildcard] = true
+			}
+		}
+	}
+
+	// Build map of tunnel ingress rules by hostname (the first rule wins, as in cloudflared)
+	ingressMap := make(map[string]*tunnel.IngressRule)
+	for i := range opts.Ingress {
+		domain := normalizeHost(opts.Ingress[i].Hostname)
+		if ingressMap[domain] == nil {
+			ingressMap[domain] = &opts.Ingress[i]
+		}
+	}
+
+	// Build set of all unique domains from all sources
 	allDomains := make(map[string]bool)
 	for domain := range dnsMap {
 		allDomains[domain] = true
@@ -39,6 +79,9 @@ func Compare(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry)
 	for domain := range caddyMap {
 		allDomains[domain] = true
 	}
+	for domain := range ingressMap {
+		allDomains[domain] = true
+	}
 
 	// Compare each domain and determine status
 	for domain := range allDomains {
@@ -46,21 +89,50 @@ func Compare(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry)
 		caddyEntry := caddyMap[domain]
 
 		synced := SyncedEntry{
-			Domain: domain,
-			DNS:    dnsRecord,
-			Caddy:  caddyEntry,
+			Domain:  domain,
+			DNS:     dnsRecord,
+			DNSAAAA: aaaaMap[domain],
+			Caddy:   caddyEntry,
+			Ingress: ingressMap[domain],
+			Zone:    zoneFor(domain, opts.Zones),
 		}
 
 		// Determine sync status
 		if dnsRecord != nil && caddyEntry != nil {
-			// Both exist - synced
-			synced.Status = StatusSynced
+			// Both exist - synced unless fields have drifted
+			synced.Mismatches = findMismatches(dnsRecord, aaaaMap[domain], caddyEntry, synced.Ingress, opts)
+			synced.Status = mismatchStatus(synced.Mismatches)
+		} else if dnsRecord != nil && synced.Ingress != nil {
+			// Served by the tunnel straight from the upstream, without Caddy
+			synced.Mismatches = findMismatches(dnsRecord, aaaaMap[domain], nil, synced.Ingress, opts)
+			synced.Status = mismatchStatus(synced.Mismatches)
 		} else if dnsRecord != nil && caddyEntry == nil {
-			// Only in DNS
+			// Only in DNS, unless it is a wildcard record serving Caddy domains.
+			// Records LazyProxyFlare does not own are left to their owner.
 			synced.Status = StatusOrphanedDNS
+			if coveredWildcards[domain] {
+				synced.Status = StatusSynced
+			} else if opts.OwnedOnly && !ownsEntry(dnsRecord, aaaaMap[domain]) {
+				synced.Status = StatusUnmanagedDNS
+			}
 		} else if dnsRecord == nil && caddyEntry != nil {
-			// Only in Caddy
+			// Only in Caddy, unless a wildcard record serves the domain. The
+			// wildcard record is not the entry's own, so it is never edited or
+			// deleted through it and drift is reported on the wildcard's row.
 			synced.Status = StatusOrphanedCaddy
+			if wildcard := dnsMap[WildcardName(domain)]; wildcard != nil {
+				synced.WildcardDNS = wildcard
+				synced.Status = StatusSynced
+			}
+		} else {
+			// Only a tunnel ingress rule is left
+			synced.Status = StatusOrphanedIngress
+		}
+
+		if synced.Status.IsOrphaned() || synced.Status == StatusUnmanagedDNS {
+			if isIgnored(synced, opts.Ignore) {
+				synced.Status = StatusIgnored
+			}
 		}
 
 		results = append(results, synced)
@@ -68,3 +140,169 @@ func Compare(dnsRecords []cloudflare.DNSRecord, caddyEntries []caddy.CaddyEntry)
 
 	return results
 }
+
+// isIgnored reports whether a one-sided entry matches the ignore rules
+func isIgnored(entry SyncedEntry, ignore func(name, recordType string) bool) bool {
+	switch {
+	case ignore == nil:
+		return false
+	case entry.DNS == nil:
+		return ignore(entry.Domain, "")
+	case entry.DNSAAAA != nil && ignore(entry.Domain, entry.DNSAAAA.Type):
+		return true
+	default:
+		return ignore(entry.Domain, entry.DNS.Type)
+	}
+}
+
+// ownsEntry reports whether any of an entry's records carries the ownership marker
+func ownsEntry(record, aaaa *cloudflare.DNSRecord) bool {
+	return record.IsOwned() || (aaaa != nil && aaaa.IsOwned())
+}
+
+// findMismatches compares the fields of a DNS record, Caddy entry (nil for
+// tunnel entries without one) and tunnel ingress rule against each other and
+// the expected defaults
+func findMismatches(record, aaaa *cloudflare.DNSRecord, entry *caddy.CaddyEntry, ingress *tunnel.IngressRule, opts CompareOptions) []FieldMismatch {
+	var mismatches []FieldMismatch
+
+	viaTunnel := opts.TunnelTarget != "" && strings.EqualFold(record.Type, "CNAME") &&
+		normalizeHost(record.Content) == normalizeHost(opts.TunnelTarget)
+
+	// Tunnel records need an ingress rule, and ingress rules need a tunnel record
+	if viaTunnel && ingress == nil {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "ingress",
+			Expected: "ingress rule",
+			Actual:   "none",
+			Reason:   "DNS points at the tunnel but it has no ingress rule for this hostname",
+		})
+	} else if !viaTunnel && ingress != nil && opts.TunnelTarget != "" {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "dns_content",
+			Expected: opts.TunnelTarget,
+			Actual:   record.Content,
+			Reason:   "The tunnel has an ingress rule but DNS does not point at it",
+		})
+	}
+
+	// CNAME should point at the Caddy host
+	if !viaTunnel && ingress == nil && strings.EqualFold(record.Type, "CNAME") && opts.CNAMETarget != "" &&
+		normalizeHost(record.Content) != normalizeHost(opts.CNAMETarget) {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "dns_content",
+			Expected: opts.CNAMETarget,
+			Actual:   record.Content,
+			Reason:   "CNAME does not point at the profile's CNAME target",
+		})
+	}
+
+	// Caddy upstream given as an IP should match the A/AAAA record of the same family
+	if ip := caddyUpstreamIP(entry); ip != nil && !ip.IsLoopback() {
+		var addr *cloudflare.DNSRecord
+		if ip.To4() != nil && strings.EqualFold(record.Type, "A") {
+			addr = record
+		} else if ip.To4() == nil && strings.EqualFold(record.Type, "AAAA") {
+			addr = record
+		} else if ip.To4() == nil && aaaa != nil {
+			addr = aaaa
+		}
+		if addr != nil && !ip.Equal(net.ParseIP(addr.Content)) {
+			mismatches = append(mismatches, FieldMismatch{
+				Field:    "caddy_target",
+				Expected: addr.Content,
+				Actual:   entry.Target,
+				Reason:   fmt.Sprintf("Caddy proxies to a different host than the %s record", addr.Type),
+			})
+		}
+	}
+
+	if viaTunnel && !record.Proxied {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "proxied",
+			Expected: "true",
+			Actual:   "false",
+			Reason:   "Tunnel records only work when proxied",
+		})
+	} else if !viaTunnel && opts.CheckProxied && record.Proxied != opts.Proxied {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "proxied",
+			Expected: fmt.Sprint(opts.Proxied),
+			Actual:   fmt.Sprint(record.Proxied),
+			Reason:   "Proxied flag differs from profile default",
+		})
+	}
+
+	if opts.TTL > 0 && record.TTL != opts.TTL {
+		mismatches = append(mismatches, FieldMismatch{
+			Field:    "ttl",
+			Expected: FormatTTL(opts.TTL),
+			Actual:   FormatTTL(record.TTL),
+			Reason:   "TTL differs from profile default",
+		})
+	}
+
+	return mismatches
+}
+
+// caddyUpstreamIP returns the upstream of a Caddy entry when it is an IP address, else nil
+func caddyUpstreamIP(entry *caddy.CaddyEntry) net.IP {
+	if entry == nil {
+		return nil
+	}
+	return net.ParseIP(strings.Trim(entry.Target, "[]"))
+}
+
+// mismatchStatus picks the status for an entry that exists on both sides
+// DNS content drift takes precedence over target drift, which takes precedence over settings
+func mismatchStatus(mismatches []FieldMismatch) SyncStatus {
+	status := StatusSynced
+	for _, m := range mismatches {
+		switch m.Field {
+		case "dns_content":
+			return StatusDNSMismatch
+		case "caddy_target", "ingress":
+			status = StatusTargetMismatch
+		default:
+			if status == StatusSynced {
+				status = StatusSettingsMismatch
+			}
+		}
+	}
+	return status
+}
+
+// FormatTTL formats a Cloudflare TTL for display (1 means Auto)
+func FormatTTL(ttl int) string {
+	if ttl == 1 {
+		return "Auto"
+	}
+	return fmt.Sprintf("%ds", ttl)
+}
+
+// zoneFor returns the longest zone domain that domain belongs to, or "" if none match
+func zoneFor(domain string, zones []string) string {
+	best := ""
+	for _, zone := range zones {
+		z := normalizeHost(zone)
+		if (domain == z || strings.HasSuffix(domain, "."+z)) && len(z) > len(best) {
+			best = z
+		}
+	}
+	return best
+}
+
+// WildcardName returns the wildcard record name that would serve domain
+// (plex.example.com -> *.example.com), or "" for a wildcard or single-label name
+func WildcardName(domain string) string {
+	dot := strings.IndexByte(domain, '.')
+	if dot <= 0 || strings.HasPrefix(domain, "*.") || !strings.Contains(domain[dot+1:], ".") {
+		return ""
+	}
+	return "*" + domain[dot:]
+}
+
+// normalizeHost lowercases a hostname and strips any trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
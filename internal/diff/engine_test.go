@@ -5,6 +5,7 @@ import (
 
 	"lazyproxyflare/internal/caddy"
 	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/tunnel"
 )
 
 func TestCompare(t *testing.T) {
@@ -152,6 +153,11 @@ func TestSyncStatusString(t *testing.T) {
 		{StatusSynced, "Synced"},
 		{StatusOrphanedDNS, "Orphaned (DNS)"},
 		{StatusOrphanedCaddy, "Orphaned (Caddy)"},
+		{StatusDNSMismatch, "Mismatch (DNS)"},
+		{StatusTargetMismatch, "Mismatch (Target)"},
+		{StatusSettingsMismatch, "Mismatch (Settings)"},
+		{StatusUnmanagedDNS, "Unmanaged (DNS)"},
+		{StatusIgnored, "Ignored"},
 		{SyncStatus(99), "Unknown"},
 	}
 
@@ -170,3 +176,316 @@ func TestSyncStatusIcon(t *testing.T) {
 		t.Errorf("StatusOrphanedDNS.Icon() = %q, want ⚠", got)
 	}
 }
+
+func TestCompareWithOptions(t *testing.T) {
+	opts := CompareOptions{
+		CNAMETarget:  "home.example.com",
+		CheckProxied: true,
+		Proxied:      true,
+		TTL:          1,
+	}
+
+	tests := []struct {
+		name       string
+		dns        cloudflare.DNSRecord
+		caddy      caddy.CaddyEntry
+		wantStatus SyncStatus
+		wantFields []string
+	}{
+		{
+			name:       "matching CNAME",
+			dns:        cloudflare.DNSRecord{Name: "app.example.com", Type: "CNAME", Content: "Home.Example.com.", Proxied: true, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"app.example.com"}, Target: "localhost", Port: 8080},
+			wantStatus: StatusSynced,
+		},
+		{
+			name:       "CNAME points elsewhere",
+			dns:        cloudflare.DNSRecord{Name: "app.example.com", Type: "CNAME", Content: "other.example.net", Proxied: true, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"app.example.com"}, Target: "localhost", Port: 8080},
+			wantStatus: StatusDNSMismatch,
+			wantFields: []string{"dns_content"},
+		},
+		{
+			name:       "proxied and TTL differ from defaults",
+			dns:        cloudflare.DNSRecord{Name: "app.example.com", Type: "CNAME", Content: "home.example.com", Proxied: false, TTL: 300},
+			caddy:      caddy.CaddyEntry{Domains: []string{"app.example.com"}, Target: "localhost", Port: 8080},
+			wantStatus: StatusSettingsMismatch,
+			wantFields: []string{"proxied", "ttl"},
+		},
+		{
+			name:       "Caddy upstream disagrees with A record",
+			dns:        cloudflare.DNSRecord{Name: "nas.example.com", Type: "A", Content: "10.0.0.5", Proxied: false, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"nas.example.com"}, Target: "10.0.0.6", Port: 5000},
+			wantStatus: StatusTargetMismatch,
+			wantFields: []string{"caddy_target", "proxied"},
+		},
+		{
+			name:       "Caddy upstream disagrees with AAAA record",
+			dns:        cloudflare.DNSRecord{Name: "v6.example.com", Type: "AAAA", Content: "2001:db8::5", Proxied: true, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"v6.example.com"}, Target: "[2001:db8::6]", Port: 5000},
+			wantStatus: StatusTargetMismatch,
+			wantFields: []string{"caddy_target"},
+		},
+		{
+			name:       "AAAA record matches upstream written differently",
+			dns:        cloudflare.DNSRecord{Name: "v6.example.com", Type: "AAAA", Content: "2001:db8:0::5", Proxied: true, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"v6.example.com"}, Target: "2001:DB8::5", Port: 5000},
+			wantStatus: StatusSynced,
+		},
+		{
+			name:       "A record with loopback upstream is fine",
+			dns:        cloudflare.DNSRecord{Name: "nas.example.com", Type: "A", Content: "10.0.0.5", Proxied: true, TTL: 1},
+			caddy:      caddy.CaddyEntry{Domains: []string{"nas.example.com"}, Target: "127.0.0.1", Port: 5000},
+			wantStatus: StatusSynced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := CompareWithOptions([]cloudflare.DNSRecord{tt.dns}, []caddy.CaddyEntry{tt.caddy}, opts)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			entry := results[0]
+			if entry.Status != tt.wantStatus {
+				t.Errorf("got status %v, want %v", entry.Status, tt.wantStatus)
+			}
+			if len(entry.Mismatches) != len(tt.wantFields) {
+				t.Fatalf("got mismatches %+v, want fields %v", entry.Mismatches, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if entry.Mismatches[i].Field != field {
+					t.Errorf("mismatch %d: got field %q, want %q", i, entry.Mismatches[i].Field, field)
+				}
+				if entry.Mismatches[i].Reason == "" {
+					t.Errorf("mismatch %d: missing reason", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareWithoutOptionsSkipsDrift(t *testing.T) {
+	results := Compare(
+		[]cloudflare.DNSRecord{{Name: "app.example.com", Type: "CNAME", Content: "anything.example.net", TTL: 300}},
+		[]caddy.CaddyEntry{{Domains: []string{"app.example.com"}, Target: "localhost"}},
+	)
+	if len(results) != 1 || results[0].Status != StatusSynced || len(results[0].Mismatches) != 0 {
+		t.Errorf("Compare without options should not report drift, got %+v", results)
+	}
+}
+
+func TestCompareDualStack(t *testing.T) {
+	results := Compare(
+		[]cloudflare.DNSRecord{
+			{Name: "app.example.com", Type: "AAAA", Content: "2001:db8::5"},
+			{Name: "app.example.com", Type: "A", Content: "10.0.0.5"},
+			{Name: "v6.example.com", Type: "AAAA", Content: "2001:db8::6"},
+		},
+		[]caddy.CaddyEntry{{Domains: []string{"app.example.com"}, Target: "localhost"}},
+	)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+
+	byDomain := make(map[string]SyncedEntry)
+	for _, r := range results {
+		byDomain[r.Domain] = r
+	}
+
+	app := byDomain["app.example.com"]
+	if app.Status != StatusSynced || app.DNS == nil || app.DNS.Type != "A" || app.DNSAAAA == nil {
+		t.Fatalf("expected synced dual-stack entry, got %+v", app)
+	}
+	if app.DNSType() != DualStackType || app.DNSContent() != "10.0.0.5, 2001:db8::5" {
+		t.Errorf("got type %q content %q", app.DNSType(), app.DNSContent())
+	}
+	if !app.HasRecordType("A") || !app.HasRecordType("AAAA") || app.HasRecordType("CNAME") {
+		t.Error("HasRecordType should report both A and AAAA for a dual-stack entry")
+	}
+
+	v6 := byDomain["v6.example.com"]
+	if v6.Status != StatusOrphanedDNS || v6.DNSType() != "AAAA" || v6.DNSAAAA != nil {
+		t.Errorf("expected orphaned AAAA-only entry, got %+v", v6)
+	}
+}
+
+func TestCompareTagsZones(t *testing.T) {
+	opts := CompareOptions{Zones: []string{"example.com", "example.net", "lab.example.com"}}
+	results := CompareWithOptions(
+		[]cloudflare.DNSRecord{
+			{Name: "app.example.com", Type: "CNAME", Content: "home.example.com"},
+			{Name: "bar.example.net", Type: "CNAME", Content: "home.example.com"},
+		},
+		[]caddy.CaddyEntry{
+			{Domains: []string{"bar.example.net"}, Target: "localhost"},
+			{Domains: []string{"nas.lab.example.com"}, Target: "localhost"},
+			{Domains: []string{"other.example.org"}, Target: "localhost"},
+		},
+		opts,
+	)
+
+	want := map[string]string{
+		"app.example.com":     "example.com",
+		"bar.example.net":     "example.net",
+		"nas.lab.example.com": "lab.example.com",
+		"other.example.org":   "",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for _, r := range results {
+		if r.Zone != want[r.Domain] {
+			t.Errorf("%s: got zone %q, want %q", r.Domain, r.Zone, want[r.Domain])
+		}
+	}
+}
+
+func TestCompareWildcardDNS(t *testing.T) {
+	wildcard := cloudflare.DNSRecord{Name: "*.example.com", Type: "CNAME", Content: "home.example.com"}
+	results := Compare(
+		[]cloudflare.DNSRecord{
+			wildcard,
+			{Name: "own.example.com", Type: "A", Content: "10.0.0.1"},
+			{Name: "*.example.net", Type: "CNAME", Content: "home.example.com"},
+		},
+		[]caddy.CaddyEntry{
+			{Domains: []string{"plex.example.com"}, ParentSite: "*.example.com", Matcher: "@plex"},
+			{Domains: []string{"own.example.com"}},
+			{Domains: []string{"deep.lab.example.com"}},
+			{Domains: []string{"other.example.org"}},
+		},
+	)
+
+	byDomain := make(map[string]SyncedEntry)
+	for _, r := range results {
+		byDomain[r.Domain] = r
+	}
+
+	plex := byDomain["plex.example.com"]
+	if plex.Status != StatusSynced || plex.DNS != nil || plex.WildcardDNS == nil || plex.WildcardDNS.Name != "*.example.com" {
+		t.Errorf("expected host route served by the wildcard record, got %+v", plex)
+	}
+	if own := byDomain["own.example.com"]; own.WildcardDNS != nil || own.DNS == nil {
+		t.Errorf("a domain's own record wins over the wildcard, got %+v", own)
+	}
+	// A wildcard covers one label only
+	if deep := byDomain["deep.lab.example.com"]; deep.Status != StatusOrphanedCaddy || deep.WildcardDNS != nil {
+		t.Errorf("expected orphaned Caddy entry two labels below the wildcard, got %+v", deep)
+	}
+	if other := byDomain["other.example.org"]; other.Status != StatusOrphanedCaddy {
+		t.Errorf("expected orphaned Caddy entry, got %+v", other)
+	}
+
+	// A wildcard record serving Caddy domains is in use, one serving none is orphaned
+	if w := byDomain["*.example.com"]; w.Status != StatusSynced {
+		t.Errorf("wildcard record in use: got %s", w.Status)
+	}
+	if w := byDomain["*.example.net"]; w.Status != StatusOrphanedDNS {
+		t.Errorf("unused wildcard record: got %s", w.Status)
+	}
+}
+
+func TestCompareOwnedOnly(t *testing.T) {
+	owned := cloudflare.OwnerMarker
+	dns := []cloudflare.DNSRecord{
+		{Name: "old.example.com", Type: "CNAME", Comment: owned},
+		{Name: "mail.example.com", Type: "MX"},
+		{Name: "dual.example.com", Type: "A", Content: "10.0.0.1"},
+		{Name: "dual.example.com", Type: "AAAA", Content: "fd00::1", Comment: "ipv6 " + owned},
+		{Name: "app.example.com", Type: "CNAME"},
+	}
+	caddyEntries := []caddy.CaddyEntry{{Domains: []string{"app.example.com"}}}
+
+	want := map[string]SyncStatus{
+		"old.example.com":  StatusOrphanedDNS,
+		"mail.example.com": StatusUnmanagedDNS,
+		"dual.example.com": StatusOrphanedDNS, // One owned record claims the pair
+		"app.example.com":  StatusSynced,      // Ownership only matters for orphans
+	}
+	for _, r := range CompareWithOptions(dns, caddyEntries, CompareOptions{OwnedOnly: true}) {
+		if r.Status != want[r.Domain] {
+			t.Errorf("%s: got %s, want %s", r.Domain, r.Status, want[r.Domain])
+		}
+	}
+
+	// Without the option every DNS-only record is an orphan
+	for _, r := range Compare(dns, caddyEntries) {
+		if r.Status == StatusUnmanagedDNS {
+			t.Errorf("%s: unmanaged without OwnedOnly", r.Domain)
+		}
+	}
+}
+
+func TestCompareIgnore(t *testing.T) {
+	dns := []cloudflare.DNSRecord{
+		{Name: "mail.example.com", Type: "A"},
+		{Name: "app.example.com", Type: "CNAME"},
+		{Name: "old.example.com", Type: "CNAME"},
+	}
+	caddyEntries := []caddy.CaddyEntry{
+		{Domains: []string{":2019"}},
+		{Domains: []string{"app.example.com"}},
+	}
+	ignore := func(name, recordType string) bool {
+		return name == "mail.example.com" || name == ":2019" || name == "app.example.com"
+	}
+
+	want := map[string]SyncStatus{
+		"mail.example.com": StatusIgnored,
+		":2019":            StatusIgnored,
+		"app.example.com":  StatusSynced, // Entries on both sides are never ignored
+		"old.example.com":  StatusOrphanedDNS,
+	}
+	for _, r := range CompareWithOptions(dns, caddyEntries, CompareOptions{Ignore: ignore}) {
+		if r.Status != want[r.Domain] {
+			t.Errorf("%s: got %s, want %s", r.Domain, r.Status, want[r.Domain])
+		}
+	}
+}
+
+func TestCompareTunnelIngress(t *testing.T) {
+	const tunnelTarget = "6ff42ae2-765d-4adf-8112-31c55c1551ef.cfargotunnel.com"
+	dns := []cloudflare.DNSRecord{
+		{Name: "app.example.com", Type: "CNAME", Content: tunnelTarget, Proxied: true},    // Tunnel via Caddy
+		{Name: "direct.example.com", Type: "CNAME", Content: tunnelTarget, Proxied: true}, // Tunnel straight to the upstream
+		{Name: "norule.example.com", Type: "CNAME", Content: tunnelTarget, Proxied: true},
+		{Name: "moved.example.com", Type: "CNAME", Content: "example.com", Proxied: true},
+		{Name: "open.example.com", Type: "CNAME", Content: "example.com", Proxied: true},
+	}
+	caddyEntries := []caddy.CaddyEntry{
+		{Domains: []string{"app.example.com"}},
+		{Domains: []string{"norule.example.com"}},
+		{Domains: []string{"moved.example.com"}},
+		{Domains: []string{"open.example.com"}},
+	}
+	opts := CompareOptions{
+		CNAMETarget:  "example.com",
+		CheckProxied: true,
+		Proxied:      false, // Tunnel records are expected proxied regardless
+		TunnelTarget: tunnelTarget,
+		Ingress: []tunnel.IngressRule{
+			{Hostname: "app.example.com", Service: "https://localhost:443"},
+			{Hostname: "direct.example.com", Service: "http://192.168.1.10:8080"},
+			{Hostname: "moved.example.com", Service: "https://localhost:443"},
+			{Hostname: "stale.example.com", Service: "http://192.168.1.11:80"},
+		},
+	}
+
+	want := map[string]SyncStatus{
+		"app.example.com":    StatusSynced,
+		"direct.example.com": StatusSynced,
+		"norule.example.com": StatusTargetMismatch,
+		"moved.example.com":  StatusDNSMismatch,
+		"open.example.com":   StatusSettingsMismatch, // Proxied differs from the profile default
+		"stale.example.com":  StatusOrphanedIngress,
+	}
+	for _, r := range CompareWithOptions(dns, caddyEntries, opts) {
+		if r.Status != want[r.Domain] {
+			t.Errorf("%s: got %s, want %s (%+v)", r.Domain, r.Status, want[r.Domain], r.Mismatches)
+		}
+		if r.Domain == "direct.example.com" && r.Ingress == nil {
+			t.Error("direct.example.com: expected its ingress rule attached")
+		}
+	}
+}
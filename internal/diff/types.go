@@ -3,15 +3,22 @@ package diff
 import (
 	"lazyproxyflare/internal/caddy"
 	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/tunnel"
 )
 
 // SyncStatus represents the sync state of an entry
 type SyncStatus int
 
 const (
-	StatusSynced        SyncStatus = iota // Both exist
-	StatusOrphanedDNS                     // Exists in DNS only
-	StatusOrphanedCaddy                   // Exists in Caddy only
+	StatusSynced           SyncStatus = iota // Both exist
+	StatusOrphanedDNS                        // Exists in DNS only
+	StatusOrphanedCaddy                      // Exists in Caddy only
+	StatusDNSMismatch                        // Both exist, DNS content differs from expected target
+	StatusTargetMismatch                     // Both exist, Caddy upstream disagrees with the A/AAAA record
+	StatusSettingsMismatch                   // Both exist, proxied/TTL differ from profile defaults
+	StatusUnmanagedDNS                       // Exists in DNS only, not owned by LazyProxyFlare
+	StatusIgnored                            // Exists on one side only and matches an ignore rule
+	StatusOrphanedIngress                    // Tunnel ingress rule with no DNS record or Caddy config
 )
 
 // String returns human-readable status
@@ -23,6 +30,18 @@ func (s SyncStatus) String() string {
 		return "Orphaned (DNS)"
 	case StatusOrphanedCaddy:
 		return "Orphaned (Caddy)"
+	case StatusDNSMismatch:
+		return "Mismatch (DNS)"
+	case StatusTargetMismatch:
+		return "Mismatch (Target)"
+	case StatusSettingsMismatch:
+		return "Mismatch (Settings)"
+	case StatusUnmanagedDNS:
+		return "Unmanaged (DNS)"
+	case StatusIgnored:
+		return "Ignored"
+	case StatusOrphanedIngress:
+		return "Orphaned (Ingress)"
 	default:
 		return "Unknown"
 	}
@@ -35,17 +54,105 @@ func (s SyncStatus) Icon() string {
 		return "✓"
 	case StatusOrphanedDNS:
 		return "⚠"
-	case StatusOrphanedCaddy:
+	case StatusOrphanedCaddy, StatusOrphanedIngress:
 		return "⚠"
+	case StatusDNSMismatch, StatusTargetMismatch, StatusSettingsMismatch:
+		return "≠"
+	case StatusUnmanagedDNS, StatusIgnored:
+		return "·"
 	default:
 		return "?"
 	}
 }
 
+// IsMismatch reports whether both sides exist but some fields have drifted
+func (s SyncStatus) IsMismatch() bool {
+	return s == StatusDNSMismatch || s == StatusTargetMismatch || s == StatusSettingsMismatch
+}
+
+// IsOrphaned reports whether the entry is missing from DNS or Caddy
+func (s SyncStatus) IsOrphaned() bool {
+	return s == StatusOrphanedDNS || s == StatusOrphanedCaddy || s == StatusOrphanedIngress
+}
+
+// FieldMismatch explains a single drifted field on an entry
+type FieldMismatch struct {
+	Field    string // "dns_content", "proxied", "ttl", "caddy_target", "ingress"
+	Expected string
+	Actual   string
+	Reason   string // Human-readable explanation
+}
+
+// DualStackType is the pseudo record type for an entry managing both an A and an AAAA record
+const DualStackType = "A+AAAA"
+
 // SyncedEntry represents the result of comparing DNS and Caddy
 type SyncedEntry struct {
-	Domain string                   // Primary domain name
-	DNS    *cloudflare.DNSRecord    // nil if not in DNS
-	Caddy  *caddy.CaddyEntry        // nil if not in Caddy
-	Status SyncStatus               // Sync status
+	Domain      string                // Primary domain name
+	DNS         *cloudflare.DNSRecord // nil if not in DNS
+	DNSAAAA     *cloudflare.DNSRecord // AAAA record paired with an A record in DNS (dual-stack), else nil
+	WildcardDNS *cloudflare.DNSRecord // Wildcard record serving the domain when it has no record of its own
+	Caddy       *caddy.CaddyEntry     // nil if not in Caddy
+	Ingress     *tunnel.IngressRule   // Tunnel ingress rule for the domain, nil if none
+	Zone        string                // Zone domain the entry belongs to (empty if no zone matches)
+	Status      SyncStatus            // Sync status
+	Mismatches  []FieldMismatch       // Field-level drift (set when Status is a mismatch)
+}
+
+// DNSType returns the record type, or DualStackType when an A and AAAA record are paired
+func (e SyncedEntry) DNSType() string {
+	if e.DNS == nil {
+		return ""
+	}
+	if e.DNSAAAA != nil {
+		return DualStackType
+	}
+	return e.DNS.Type
+}
+
+// DNSContent returns the record content, joining both addresses for dual-stack entries
+func (e SyncedEntry) DNSContent() string {
+	if e.DNS == nil {
+		return ""
+	}
+	if e.DNSAAAA != nil {
+		return e.DNS.Content + ", " + e.DNSAAAA.Content
+	}
+	return e.DNS.Content
+}
+
+// HasRecordType reports whether the entry has a DNS record of the given type
+func (e SyncedEntry) HasRecordType(recordType string) bool {
+	if e.DNS != nil && e.DNS.Type == recordType {
+		return true
+	}
+	return e.DNSAAAA != nil && e.DNSAAAA.Type == recordType
+}
+
+// CompareOptions holds the expected values used for field-level drift detection
+// Zero values disable the corresponding check
+type CompareOptions struct {
+	CNAMETarget  string // Expected CNAME content (profile defaults.cname_target)
+	CheckProxied bool   // Compare proxied flag against Proxied
+	Proxied      bool   // Expected proxied flag (profile defaults.proxied)
+	TTL          int    // Expected TTL (1 = Auto)
+
+	// OwnedOnly reports DNS-only records without the ownership marker as
+	// unmanaged rather than orphaned, keeping them out of orphan cleanup
+	OwnedOnly bool
+
+	// Ignore reports whether a name matches the profile's ignore rules.
+	// recordType is the DNS record type, or "" for a Caddy site. Entries on
+	// one side only that match are marked ignored; nil ignores nothing.
+	Ignore func(name, recordType string) bool
+
+	// TunnelTarget is the CNAME content of records routed through the
+	// profile's Cloudflare Tunnel; Ingress are the tunnel's ingress rules.
+	// Tunnel records need a rule and are always proxied; rules need a record.
+	TunnelTarget string
+	Ingress      []tunnel.IngressRule
+
+	// Zones lists the zone domains managed by the profile. Each entry is
+	// tagged with the longest zone domain that is a suffix of its name.
+	Zones []string
 }
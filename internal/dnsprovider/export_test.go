@@ -0,0 +1,39 @@
+package dnsprovider
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// NewFakePowerDNS returns a PowerDNS provider for the zone example.com.
+// served by fakePowerDNS, for tests outside the package
+func NewFakePowerDNS(t *testing.T, rrsets ...pdnsRRset) *PowerDNS {
+	server := httptest.NewServer(&fakePowerDNS{rrsets: rrsets})
+	t.Cleanup(server.Close)
+	return NewPowerDNS(server.URL+"/", "", "secret")
+}
+
+// FakePowerDNSRRset is one RRset of the fake PowerDNS zone
+func FakePowerDNSRRset(name, recordType string, contents ...string) pdnsRRset {
+	set := pdnsRRset{Name: name, Type: recordType, TTL: 300}
+	for _, content := range contents {
+		set.Records = append(set.Records, pdnsRecord{Content: content})
+	}
+	return set
+}
+
+// NewFakeRFC2136 returns an RFC 2136 provider for the zone home.arpa.
+// served by fakeRFC2136, holding the records in zone file syntax
+func NewFakeRFC2136(t *testing.T, records ...string) *RFC2136 {
+	fake := &fakeRFC2136{}
+	for _, line := range records {
+		rr, err := dns.NewRR(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fake.zone = append(fake.zone, rr)
+	}
+	return NewRFC2136(startRFC2136(t, fake), "LPF", "hmac-sha256", testSecret)
+}
@@ -0,0 +1,417 @@
+package dnsprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"lazyproxyflare/internal/config"
+)
+
+// powerDNSDefaultTTL replaces Cloudflare's automatic TTL (1), which PowerDNS doesn't have
+const powerDNSDefaultTTL = 300
+
+// powerDNSCommentAccount is the account set on the RRset comments LazyProxyFlare writes
+const powerDNSCommentAccount = "lazyproxyflare"
+
+// PowerDNS manages records through the HTTP API of a PowerDNS Authoritative
+// server. PowerDNS groups records into RRsets (one per name and type) with
+// no record IDs, so a record's ID is derived from its name, type and content,
+// and the RRset's comments stand in for the record comment. Changes to a zone
+// are sent as one PATCH, which PowerDNS applies atomically.
+type PowerDNS struct {
+	apiKey     string
+	baseURL    string // <api_url>/api/v1/servers/<server_id>
+	httpClient *http.Client
+}
+
+// NewPowerDNS creates a client for the API at apiURL (e.g. http://ns1:8081).
+// serverID defaults to "localhost".
+func NewPowerDNS(apiURL, serverID, apiKey string) *PowerDNS {
+	if serverID == "" {
+		serverID = "localhost"
+	}
+	return &PowerDNS{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimSuffix(apiURL, "/") + "/api/v1/servers/" + url.PathEscape(serverID),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Info describes PowerDNS
+func (p *PowerDNS) Info() Info {
+	return infos[config.DNSProviderPowerDNS]
+}
+
+// PowerDNSError is returned when the PowerDNS API rejects a request
+type PowerDNSError struct {
+	StatusCode int
+	Message    string // The "error" field of the response, if any
+}
+
+// Error formats the API message, or the HTTP status when there is none
+func (e *PowerDNSError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("PowerDNS API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("PowerDNS API error: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Is makes a 404 match ErrNotFound
+func (e *PowerDNSError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// pdnsRRset is an RRset as the API reads and writes it
+type pdnsRRset struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	TTL        int           `json:"ttl,omitempty"`
+	ChangeType string        `json:"changetype,omitempty"` // REPLACE or DELETE (writes only)
+	Records    []pdnsRecord  `json:"records"`
+	Comments   []pdnsComment `json:"comments"`
+}
+
+type pdnsRecord struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+type pdnsComment struct {
+	Content string `json:"content"`
+	Account string `json:"account"`
+}
+
+// zoneURL returns the API URL of a zone. Zone IDs are canonical zone names.
+func (p *PowerDNS) zoneURL(zoneID string) string {
+	return p.baseURL + "/zones/" + url.PathEscape(canonical(zoneID))
+}
+
+// do sends a request and returns the response body. Non-2xx responses are
+// returned as *PowerDNSError.
+func (p *PowerDNS) do(ctx context.Context, method, url string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-API-Key", p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &PowerDNSError{StatusCode: resp.StatusCode}
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil {
+			apiErr.Message = errResp.Error
+		}
+		return nil, apiErr
+	}
+	return respBody, nil
+}
+
+// rrsetKey identifies an RRset within a zone
+type rrsetKey struct {
+	name string // Without the trailing dot, lowercase
+	typ  string
+}
+
+// rrset is an RRset being edited
+type rrset struct {
+	name     string // As PowerDNS returned it, without the trailing dot
+	ttl      int
+	records  []pdnsRecord // Contents are stored without the trailing dot of names
+	comment  string
+	comments []pdnsComment // The comments as loaded, kept unless comment changes
+}
+
+// zoneState is a zone's RRsets, changed in memory before a PATCH
+type zoneState struct {
+	zoneID  string
+	rrsets  map[rrsetKey]*rrset
+	changed map[rrsetKey]bool
+}
+
+// loadZone fetches every RRset of a zone
+func (p *PowerDNS) loadZone(ctx context.Context, zoneID string) (*zoneState, error) {
+	data, err := p.do(ctx, http.MethodGet, p.zoneURL(zoneID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var zone struct {
+		RRsets []pdnsRRset `json:"rrsets"`
+	}
+	if err := json.Unmarshal(data, &zone); err != nil {
+		return nil, fmt.Errorf("failed to decode zone: %w", err)
+	}
+
+	state := &zoneState{zoneID: zoneID, rrsets: map[rrsetKey]*rrset{}, changed: map[rrsetKey]bool{}}
+	for _, raw := range zone.RRsets {
+		set := &rrset{name: strings.TrimSuffix(raw.Name, "."), ttl: raw.TTL, comments: raw.Comments}
+		for _, record := range raw.Records {
+			record.Content = fromAPIContent(raw.Type, record.Content)
+			set.records = append(set.records, record)
+		}
+		var texts []string
+		for _, comment := range raw.Comments {
+			texts = append(texts, comment.Content)
+		}
+		set.comment = strings.Join(texts, " ")
+		state.rrsets[keyOf(raw.Name, raw.Type)] = set
+	}
+	return state, nil
+}
+
+// keyOf returns the key of the RRset holding records of name and type
+func keyOf(name, recordType string) rrsetKey {
+	return rrsetKey{name: strings.ToLower(strings.TrimSuffix(name, ".")), typ: strings.ToUpper(recordType)}
+}
+
+// records lists the enabled records of the zone, optionally of one type
+func (z *zoneState) records(recordType string) []Record {
+	var out []Record
+	for key, set := range z.rrsets {
+		if recordType != "" && key.typ != recordType {
+			continue
+		}
+		for _, record := range set.records {
+			if !record.Disabled {
+				out = append(out, z.record(key, set, record.Content))
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b Record) int {
+		return strings.Compare(a.Name+"|"+a.Type+"|"+a.Content, b.Name+"|"+b.Type+"|"+b.Content)
+	})
+	return out
+}
+
+// record returns one record of an RRset
+func (z *zoneState) record(key rrsetKey, set *rrset, content string) Record {
+	zoneName := strings.TrimSuffix(z.zoneID, ".")
+	return Record{
+		ID:       recordID(set.name, key.typ, content),
+		Type:     key.typ,
+		Name:     set.name,
+		Content:  content,
+		TTL:      set.ttl,
+		Comment:  set.comment,
+		ZoneID:   zoneName,
+		ZoneName: zoneName,
+	}
+}
+
+// find returns the RRset and index of the record with id
+func (z *zoneState) find(id string) (rrsetKey, int, error) {
+	name, recordType, content, ok := parseRecordID(id)
+	if !ok {
+		return rrsetKey{}, 0, fmt.Errorf("%w: invalid PowerDNS record ID %q", ErrNotFound, id)
+	}
+	key := keyOf(name, recordType)
+	if set, ok := z.rrsets[key]; ok {
+		for i, record := range set.records {
+			if record.Content == content {
+				return key, i, nil
+			}
+		}
+	}
+	return rrsetKey{}, 0, fmt.Errorf("%w: %s %s %s", ErrNotFound, recordType, name, content)
+}
+
+// remove deletes the record with id and returns it
+func (z *zoneState) remove(id string) (Record, error) {
+	key, i, err := z.find(id)
+	if err != nil {
+		return Record{}, err
+	}
+	set := z.rrsets[key]
+	removed := z.record(key, set, set.records[i].Content)
+	set.records = slices.Delete(set.records, i, i+1)
+	z.changed[key] = true
+	return removed, nil
+}
+
+// add adds a record, refusing duplicates and CNAMEs next to other data at
+// the same name
+func (z *zoneState) add(record Record) (Record, error) {
+	key := keyOf(record.Name, record.Type)
+	for other, set := range z.rrsets {
+		if other.name != key.name || len(set.records) == 0 {
+			continue
+		}
+		if other.typ == "CNAME" || key.typ == "CNAME" {
+			return Record{}, fmt.Errorf("%w: %s %s", ErrRecordExists, other.typ, record.Name)
+		}
+	}
+
+	set, ok := z.rrsets[key]
+	if !ok {
+		set = &rrset{name: strings.TrimSuffix(record.Name, ".")}
+		z.rrsets[key] = set
+	}
+	if slices.ContainsFunc(set.records, func(r pdnsRecord) bool { return r.Content == record.Content }) {
+		return Record{}, fmt.Errorf("%w: %s %s %s", ErrRecordExists, key.typ, record.Name, record.Content)
+	}
+	set.records = append(set.records, pdnsRecord{Content: record.Content})
+	set.ttl = record.TTL
+	if set.ttl <= 1 {
+		set.ttl = powerDNSDefaultTTL
+	}
+	if record.Comment != set.comment {
+		set.comment = record.Comment
+		set.comments = nil
+	}
+	z.changed[key] = true
+	return z.record(key, set, record.Content), nil
+}
+
+// patch returns the RRset changes for everything edited since loading
+func (z *zoneState) patch() []pdnsRRset {
+	var out []pdnsRRset
+	for key := range z.changed {
+		set := z.rrsets[key]
+		name := canonical(set.name)
+		if len(set.records) == 0 {
+			out = append(out, pdnsRRset{Name: name, Type: key.typ, ChangeType: "DELETE", Records: []pdnsRecord{}, Comments: []pdnsComment{}})
+			continue
+		}
+		change := pdnsRRset{Name: name, Type: key.typ, TTL: set.ttl, ChangeType: "REPLACE", Comments: set.comments}
+		for _, record := range set.records {
+			record.Content = toAPIContent(key.typ, record.Content)
+			change.Records = append(change.Records, record)
+		}
+		if change.Comments == nil {
+			change.Comments = []pdnsComment{}
+			if set.comment != "" {
+				change.Comments = append(change.Comments, pdnsComment{Content: set.comment, Account: powerDNSCommentAccount})
+			}
+		}
+		out = append(out, change)
+	}
+	slices.SortFunc(out, func(a, b pdnsRRset) int { return strings.Compare(a.Name+a.Type, b.Name+b.Type) })
+	return out
+}
+
+// ListDNSRecords lists the enabled records of a zone, optionally of one type
+func (p *PowerDNS) ListDNSRecords(ctx context.Context, zoneID string, recordType string) ([]Record, error) {
+	state, err := p.loadZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	return state.records(recordType), nil
+}
+
+// CreateDNSRecord adds a record to its RRset
+func (p *PowerDNS) CreateDNSRecord(ctx context.Context, zoneID string, record Record) (*Record, error) {
+	result, err := p.BatchDNSRecords(ctx, zoneID, BatchRequest{Posts: []Record{record}})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Posts[0], nil
+}
+
+// UpdateDNSRecord replaces a record. The returned record has a new ID when
+// the name, type or content changed.
+func (p *PowerDNS) UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record Record) (*Record, error) {
+	record.ID = recordID
+	result, err := p.BatchDNSRecords(ctx, zoneID, BatchRequest{Patches: []Record{record}})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Patches[0], nil
+}
+
+// DeleteDNSRecord removes a record from its RRset
+func (p *PowerDNS) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
+	_, err := p.BatchDNSRecords(ctx, zoneID, BatchRequest{Deletes: []Record{{ID: recordID}}})
+	return err
+}
+
+// BatchDNSRecords applies deletes, then patches, then posts to the zone and
+// sends them as a single PATCH. Nothing is applied if any change fails.
+func (p *PowerDNS) BatchDNSRecords(ctx context.Context, zoneID string, batch BatchRequest) (*BatchResult, error) {
+	state, err := p.loadZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{}
+	for _, record := range batch.Deletes {
+		removed, err := state.remove(record.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Deletes = append(result.Deletes, removed)
+	}
+	for _, record := range batch.Patches {
+		if _, err := state.remove(record.ID); err != nil {
+			return nil, err
+		}
+		updated, err := state.add(record)
+		if err != nil {
+			return nil, err
+		}
+		result.Patches = append(result.Patches, updated)
+	}
+	for _, record := range batch.Posts {
+		created, err := state.add(record)
+		if err != nil {
+			return nil, err
+		}
+		result.Posts = append(result.Posts, created)
+	}
+
+	if len(state.changed) == 0 {
+		return result, nil
+	}
+	body := map[string][]pdnsRRset{"rrsets": state.patch()}
+	if _, err := p.do(ctx, http.MethodPatch, p.zoneURL(zoneID), body); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fromAPIContent drops the trailing dot of a CNAME target, matching how
+// Cloudflare returns it
+func fromAPIContent(recordType, content string) string {
+	if strings.EqualFold(recordType, "CNAME") {
+		return strings.TrimSuffix(content, ".")
+	}
+	return content
+}
+
+// toAPIContent adds the trailing dot PowerDNS requires on CNAME targets
+func toAPIContent(recordType, content string) string {
+	if strings.EqualFold(recordType, "CNAME") {
+		return canonical(content)
+	}
+	return content
+}
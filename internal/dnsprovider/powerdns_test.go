@@ -0,0 +1,135 @@
+package dnsprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"lazyproxyflare/internal/cloudflare"
+)
+
+// fakePowerDNS serves one zone's RRsets and applies PATCHes to them
+type fakePowerDNS struct {
+	mu      sync.Mutex
+	rrsets  []pdnsRRset
+	patches [][]pdnsRRset
+}
+
+func (f *fakePowerDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("X-API-Key") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path != "/api/v1/servers/localhost/zones/example.com." {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Could not find domain"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]any{"name": "example.com.", "rrsets": f.rrsets})
+	case http.MethodPatch:
+		var body struct {
+			RRsets []pdnsRRset `json:"rrsets"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.patches = append(f.patches, body.RRsets)
+		for _, change := range body.RRsets {
+			kept := f.rrsets[:0]
+			for _, set := range f.rrsets {
+				if set.Name != change.Name || set.Type != change.Type {
+					kept = append(kept, set)
+				}
+			}
+			f.rrsets = kept
+			if change.ChangeType == "REPLACE" {
+				change.ChangeType = ""
+				f.rrsets = append(f.rrsets, change)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestPowerDNSRecords(t *testing.T) {
+	fake := &fakePowerDNS{rrsets: []pdnsRRset{
+		{Name: "example.com.", Type: "A", TTL: 3600, Records: []pdnsRecord{{Content: "203.0.113.1"}}},
+		{Name: "app.example.com.", Type: "CNAME", TTL: 300, Records: []pdnsRecord{{Content: "example.com."}},
+			Comments: []pdnsComment{{Content: cloudflare.OwnerMarker, Account: "lazyproxyflare"}}},
+		{Name: "nas.example.com.", Type: "A", TTL: 300, Records: []pdnsRecord{{Content: "192.168.1.10"}, {Content: "192.168.1.11", Disabled: true}}},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	p := NewPowerDNS(server.URL+"/", "", "secret")
+	ctx := context.Background()
+
+	records, err := p.ListDNSRecords(ctx, "example.com", "CNAME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Content != "example.com" || !records[0].IsOwned() || records[0].ZoneID != "example.com" {
+		t.Fatalf("unexpected CNAME records: %+v", records)
+	}
+	if all, _ := p.ListDNSRecords(ctx, "example.com", ""); len(all) != 3 {
+		t.Errorf("expected the disabled record left out, got %d records", len(all))
+	}
+
+	// Create: the CNAME target gets its trailing dot, the TTL a real value
+	created, err := p.CreateDNSRecord(ctx, "example.com", Record{Type: "CNAME", Name: "plex.example.com", Content: "example.com", TTL: 1, Comment: cloudflare.OwnerMarker})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "plex.example.com|CNAME|example.com" {
+		t.Errorf("unexpected ID %q", created.ID)
+	}
+	patch := fake.patches[0][0]
+	if patch.ChangeType != "REPLACE" || patch.Records[0].Content != "example.com." || patch.TTL != powerDNSDefaultTTL || patch.Comments[0].Content != cloudflare.OwnerMarker {
+		t.Errorf("unexpected patch: %+v", patch)
+	}
+
+	if _, err := p.CreateDNSRecord(ctx, "example.com", Record{Type: "A", Name: "plex.example.com", Content: "10.0.0.1"}); !IsRecordExists(err) {
+		t.Errorf("expected a conflict with the CNAME, got %v", err)
+	}
+
+	// Update keeps the disabled record of the RRset
+	updated, err := p.UpdateDNSRecord(ctx, "example.com", "nas.example.com|A|192.168.1.10", Record{Type: "A", Name: "nas.example.com", Content: "192.168.1.20", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != "nas.example.com|A|192.168.1.20" {
+		t.Errorf("unexpected ID after update %q", updated.ID)
+	}
+	if records := fake.patches[1][0].Records; len(records) != 2 || !records[0].Disabled || records[1].Content != "192.168.1.20" {
+		t.Errorf("unexpected records after update: %+v", records)
+	}
+
+	// Deleting the last record deletes the RRset
+	if err := p.DeleteDNSRecord(ctx, "example.com", created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if patch := fake.patches[2][0]; patch.ChangeType != "DELETE" || patch.Name != "plex.example.com." {
+		t.Errorf("unexpected delete patch: %+v", patch)
+	}
+	if err := p.DeleteDNSRecord(ctx, "example.com", created.ID); !IsNotFound(err) {
+		t.Errorf("expected not found for a deleted record, got %v", err)
+	}
+
+	// A failed batch sends nothing
+	_, err = p.BatchDNSRecords(ctx, "example.com", BatchRequest{
+		Deletes: []Record{{ID: "app.example.com|CNAME|example.com"}},
+		Posts:   []Record{{Type: "A", Name: "example.com", Content: "203.0.113.1"}},
+	})
+	if !IsRecordExists(err) || len(fake.patches) != 3 {
+		t.Errorf("expected the batch rejected before sending, got %v after %d patches", err, len(fake.patches))
+	}
+
+	if _, err := p.ListDNSRecords(ctx, "example.net", ""); !IsNotFound(err) || !strings.Contains(err.Error(), "Could not find domain") {
+		t.Errorf("expected not found for an unknown zone, got %v", err)
+	}
+}
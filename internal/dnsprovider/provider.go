@@ -0,0 +1,120 @@
+// Package dnsprovider abstracts the DNS host holding a profile's records.
+// Cloudflare is the default provider; PowerDNS is reached through its HTTP API
+// and other authoritative servers (BIND, Knot) through RFC 2136 updates.
+package dnsprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+)
+
+// Record is a DNS record. It shares the Cloudflare client's type so records
+// pass unchanged through the diff engine, journals and snapshots; providers
+// leave the fields they have no concept of (Proxied) zero.
+type Record = cloudflare.DNSRecord
+
+// BatchRequest is a set of changes for one zone: deletes, then patches, then posts
+type BatchRequest = cloudflare.BatchRequest
+
+// BatchResult holds the records returned for each applied change, in request order
+type BatchResult = cloudflare.BatchResult
+
+// Records manages the DNS records of a provider's zones. Zone IDs are the
+// ones AllZones returns for the profile.
+type Records interface {
+	ListDNSRecords(ctx context.Context, zoneID string, recordType string) ([]Record, error)
+	CreateDNSRecord(ctx context.Context, zoneID string, record Record) (*Record, error)
+	UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record Record) (*Record, error)
+	DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error
+	BatchDNSRecords(ctx context.Context, zoneID string, batch BatchRequest) (*BatchResult, error)
+}
+
+// Provider is a DNS host's record API along with what the host supports
+type Provider interface {
+	Records
+	Info() Info
+}
+
+// Info describes a provider
+type Info struct {
+	Name       string // Display name
+	Proxy      bool   // Records can be proxied by the provider (Cloudflare's orange cloud)
+	Comments   bool   // Records keep comments, which carry the ownership marker
+	DefaultTTL int    // TTL of the records LazyProxyFlare writes (1 = automatic)
+}
+
+var infos = map[string]Info{
+	config.DNSProviderCloudflare: {Name: "Cloudflare", Proxy: true, Comments: true, DefaultTTL: 1},
+	config.DNSProviderPowerDNS:   {Name: "PowerDNS", Comments: true, DefaultTTL: powerDNSDefaultTTL},
+	config.DNSProviderRFC2136:    {Name: "RFC2136", DefaultTTL: rfc2136DefaultTTL},
+}
+
+// InfoFor returns the info of the profile's provider
+func InfoFor(cfg *config.Config) Info {
+	return infos[cfg.DNS.ProviderName()]
+}
+
+// New returns the profile's provider, authenticated with the token from
+// cfg.GetAPIToken
+func New(cfg *config.Config, token string) Provider {
+	switch cfg.DNS.ProviderName() {
+	case config.DNSProviderPowerDNS:
+		return NewPowerDNS(cfg.DNS.PowerDNS.APIURL, cfg.DNS.PowerDNS.ServerID, token)
+	case config.DNSProviderRFC2136:
+		r := cfg.DNS.RFC2136
+		return NewRFC2136(r.Address(), r.TSIGKeyName, r.Algorithm(), token)
+	}
+	return cloudflareProvider{cloudflare.NewClient(token)}
+}
+
+// cloudflareProvider is the Cloudflare API client as a Provider
+type cloudflareProvider struct {
+	*cloudflare.Client
+}
+
+// Info describes Cloudflare
+func (cloudflareProvider) Info() Info {
+	return infos[config.DNSProviderCloudflare]
+}
+
+// Errors returned by providers other than Cloudflare, whose client has its own
+// error codes. Use IsRecordExists and IsNotFound to check for either.
+var (
+	ErrRecordExists = errors.New("record already exists")
+	ErrNotFound     = errors.New("record not found")
+)
+
+// IsRecordExists reports whether err means the record (or one with the same
+// host that can't coexist with it) already exists
+func IsRecordExists(err error) bool {
+	return errors.Is(err, ErrRecordExists) || cloudflare.IsRecordExists(err)
+}
+
+// IsNotFound reports whether err means the record or zone doesn't exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || cloudflare.IsNotFound(err)
+}
+
+// recordID derives the ID of a record from its name, type and content, for
+// providers whose records have no IDs of their own
+func recordID(name, recordType, content string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "|" + strings.ToUpper(recordType) + "|" + content
+}
+
+// parseRecordID splits an ID made by recordID
+func parseRecordID(id string) (name, recordType, content string, ok bool) {
+	parts := strings.SplitN(id, "|", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// canonical returns a name as a fully qualified domain name (trailing dot)
+func canonical(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
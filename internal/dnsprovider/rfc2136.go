@@ -0,0 +1,263 @@
+package dnsprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"lazyproxyflare/internal/config"
+)
+
+// rfc2136DefaultTTL replaces Cloudflare's automatic TTL (1), which DNS doesn't have
+const rfc2136DefaultTTL = 300
+
+// RFC2136 manages records on an authoritative server (BIND, Knot, ...)
+// through dynamic updates signed with a TSIG key, and lists zones with AXFR.
+// DNS has no record IDs or comments: a record's ID is derived from its name,
+// type and content, and comments are dropped. Each call is sent as a single
+// update message, which the server applies atomically.
+type RFC2136 struct {
+	server    string // host:port
+	keyName   string // TSIG key name, fully qualified
+	algorithm string // TSIG algorithm, fully qualified (e.g. hmac-sha256.)
+	secret    string // Base64 TSIG secret
+	timeout   time.Duration
+}
+
+// NewRFC2136 creates a client for the server at host:port, signing with the
+// named TSIG key
+func NewRFC2136(server, keyName, algorithm, secret string) *RFC2136 {
+	return &RFC2136{
+		server:    server,
+		keyName:   dns.Fqdn(strings.ToLower(keyName)),
+		algorithm: dns.Fqdn(strings.ToLower(algorithm)),
+		secret:    secret,
+		timeout:   10 * time.Second,
+	}
+}
+
+// Info describes RFC 2136 servers
+func (r *RFC2136) Info() Info {
+	return infos[config.DNSProviderRFC2136]
+}
+
+// RFC2136Error is returned when the server refuses an update
+type RFC2136Error struct {
+	Rcode int
+}
+
+// Error names the response code
+func (e *RFC2136Error) Error() string {
+	return fmt.Sprintf("dynamic update refused: %s", dns.RcodeToString[e.Rcode])
+}
+
+// Is maps failed prerequisites to ErrRecordExists and ErrNotFound
+func (e *RFC2136Error) Is(target error) bool {
+	switch target {
+	case ErrRecordExists:
+		return e.Rcode == dns.RcodeYXDomain || e.Rcode == dns.RcodeYXRrset
+	case ErrNotFound:
+		return e.Rcode == dns.RcodeNameError || e.Rcode == dns.RcodeNXRrset || e.Rcode == dns.RcodeNotZone
+	}
+	return false
+}
+
+// tsigSecret returns the secrets map the dns package signs with
+func (r *RFC2136) tsigSecret() map[string]string {
+	return map[string]string{r.keyName: r.secret}
+}
+
+// ListDNSRecords transfers the zone with AXFR and returns its records,
+// optionally of one type. The SOA record is left out.
+func (r *RFC2136) ListDNSRecords(ctx context.Context, zoneID string, recordType string) ([]Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m := new(dns.Msg)
+	m.SetAxfr(canonical(zoneID))
+	m.SetTsig(r.keyName, r.algorithm, 300, time.Now().Unix())
+
+	transfer := &dns.Transfer{TsigSecret: r.tsigSecret(), DialTimeout: r.timeout, ReadTimeout: r.timeout}
+	envelopes, err := transfer.In(m, r.server)
+	if err != nil {
+		return nil, fmt.Errorf("zone transfer of %s failed: %w", zoneID, err)
+	}
+
+	var records []Record
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("zone transfer of %s failed: %w", zoneID, envelope.Error)
+		}
+		for _, rr := range envelope.RR {
+			record := fromRR(zoneID, rr)
+			if record.Type == "SOA" || (recordType != "" && record.Type != recordType) {
+				continue
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// CreateDNSRecord adds a record
+func (r *RFC2136) CreateDNSRecord(ctx context.Context, zoneID string, record Record) (*Record, error) {
+	result, err := r.BatchDNSRecords(ctx, zoneID, BatchRequest{Posts: []Record{record}})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Posts[0], nil
+}
+
+// UpdateDNSRecord replaces a record. The returned record has a new ID when
+// the name, type or content changed.
+func (r *RFC2136) UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record Record) (*Record, error) {
+	record.ID = recordID
+	result, err := r.BatchDNSRecords(ctx, zoneID, BatchRequest{Patches: []Record{record}})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Patches[0], nil
+}
+
+// DeleteDNSRecord removes a record. Removing a record that doesn't exist
+// succeeds, as RFC 2136 specifies.
+func (r *RFC2136) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
+	_, err := r.BatchDNSRecords(ctx, zoneID, BatchRequest{Deletes: []Record{{ID: recordID}}})
+	return err
+}
+
+// BatchDNSRecords sends deletes, patches and posts as one update message.
+// New records must not collide with a CNAME (or, for a CNAME, with anything)
+// at their name unless the batch removes records there; the server checks
+// this as a prerequisite, so nothing is applied on a conflict.
+func (r *RFC2136) BatchDNSRecords(ctx context.Context, zoneID string, batch BatchRequest) (*BatchResult, error) {
+	result := &BatchResult{}
+	var removes, inserts []dns.RR
+	freed := map[string]bool{} // Names that lose records in this batch
+
+	remove := func(id string) (Record, error) {
+		name, recordType, content, ok := parseRecordID(id)
+		if !ok {
+			return Record{}, fmt.Errorf("%w: invalid record ID %q", ErrNotFound, id)
+		}
+		record := Record{Type: recordType, Name: name, Content: content}
+		rr, err := toRR(record)
+		if err != nil {
+			return Record{}, err
+		}
+		removes = append(removes, rr)
+		freed[strings.ToLower(name)] = true
+		return withZone(zoneID, record), nil
+	}
+	for _, record := range batch.Deletes {
+		removed, err := remove(record.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Deletes = append(result.Deletes, removed)
+	}
+	var added []Record
+	for _, record := range batch.Patches {
+		if _, err := remove(record.ID); err != nil {
+			return nil, err
+		}
+		added = append(added, record)
+	}
+	added = append(added, batch.Posts...)
+
+	m := new(dns.Msg)
+	m.SetUpdate(canonical(zoneID))
+	for i, record := range added {
+		if record.TTL <= 1 {
+			record.TTL = rfc2136DefaultTTL
+		}
+		rr, err := toRR(record)
+		if err != nil {
+			return nil, err
+		}
+		inserts = append(inserts, rr)
+
+		if !freed[strings.ToLower(strings.TrimSuffix(record.Name, "."))] {
+			if rr.Header().Rrtype == dns.TypeCNAME {
+				m.NameNotUsed([]dns.RR{rr})
+			} else {
+				m.RRsetNotUsed([]dns.RR{&dns.CNAME{Hdr: dns.RR_Header{Name: rr.Header().Name, Rrtype: dns.TypeCNAME}}})
+			}
+		}
+
+		record.Comment = ""
+		record.ID = recordID(record.Name, record.Type, record.Content)
+		if i < len(batch.Patches) {
+			result.Patches = append(result.Patches, withZone(zoneID, record))
+		} else {
+			result.Posts = append(result.Posts, withZone(zoneID, record))
+		}
+	}
+	if len(removes) > 0 {
+		m.Remove(removes)
+	}
+	if len(inserts) > 0 {
+		m.Insert(inserts)
+	}
+	if len(m.Ns) == 0 {
+		return result, nil
+	}
+
+	if err := r.send(ctx, m); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// send signs and sends an update message over TCP
+func (r *RFC2136) send(ctx context.Context, m *dns.Msg) error {
+	m.SetTsig(r.keyName, r.algorithm, 300, time.Now().Unix())
+	client := &dns.Client{Net: "tcp", TsigSecret: r.tsigSecret(), Timeout: r.timeout}
+	in, _, err := client.ExchangeContext(ctx, m, r.server)
+	if err != nil {
+		return fmt.Errorf("dynamic update failed: %w", err)
+	}
+	if in.Rcode != dns.RcodeSuccess {
+		return &RFC2136Error{Rcode: in.Rcode}
+	}
+	return nil
+}
+
+// fromRR converts a resource record from a zone transfer
+func fromRR(zoneID string, rr dns.RR) Record {
+	hdr := rr.Header()
+	record := Record{
+		Type:    dns.TypeToString[hdr.Rrtype],
+		Name:    strings.TrimSuffix(hdr.Name, "."),
+		Content: strings.TrimPrefix(rr.String(), hdr.String()),
+		TTL:     int(hdr.Ttl),
+	}
+	if cname, ok := rr.(*dns.CNAME); ok {
+		record.Content = strings.TrimSuffix(cname.Target, ".")
+	}
+	record.ID = recordID(record.Name, record.Type, record.Content)
+	return withZone(zoneID, record)
+}
+
+// toRR parses a record into a resource record
+func toRR(record Record) (dns.RR, error) {
+	content := record.Content
+	if strings.EqualFold(record.Type, "CNAME") {
+		content = canonical(content)
+	}
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", canonical(record.Name), record.TTL, strings.ToUpper(record.Type), content))
+	if err != nil || rr == nil {
+		return nil, fmt.Errorf("invalid %s record %s: %v", record.Type, record.Name, err)
+	}
+	return rr, nil
+}
+
+// withZone sets the zone fields of a record
+func withZone(zoneID string, record Record) Record {
+	record.ZoneID = strings.TrimSuffix(zoneID, ".")
+	record.ZoneName = record.ZoneID
+	return record
+}
@@ -0,0 +1,194 @@
+package dnsprovider
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	testKeyName = "lpf."
+	testSecret  = "c2VjcmV0LWtleS1mb3ItdGVzdGluZw=="
+)
+
+// fakeRFC2136 serves one zone over TCP, answering AXFR and applying the
+// prerequisites and updates LazyProxyFlare sends
+type fakeRFC2136 struct {
+	mu      sync.Mutex
+	zone    []dns.RR
+	updates int
+}
+
+func (f *fakeRFC2136) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	if req.IsTsig() == nil || w.TsigStatus() != nil {
+		resp.Rcode = dns.RcodeNotAuth
+		w.WriteMsg(resp)
+		return
+	}
+	resp.SetTsig(testKeyName, dns.HmacSHA256, 300, int64(req.IsTsig().TimeSigned))
+	if req.Question[0].Name != "home.arpa." {
+		resp.Rcode = dns.RcodeNotAuth
+		w.WriteMsg(resp)
+		return
+	}
+
+	if req.Opcode == dns.OpcodeQuery && req.Question[0].Qtype == dns.TypeAXFR {
+		soa, _ := dns.NewRR("home.arpa. 3600 IN SOA ns.home.arpa. admin.home.arpa. 1 3600 600 86400 300")
+		ch := make(chan *dns.Envelope, 1)
+		ch <- &dns.Envelope{RR: append(append([]dns.RR{soa}, f.zone...), soa)}
+		close(ch)
+		(&dns.Transfer{TsigSecret: map[string]string{testKeyName: testSecret}}).Out(w, req, ch)
+		return
+	}
+
+	// Prerequisites: only the "not in use" forms are sent
+	for _, pre := range req.Answer {
+		for _, rr := range f.zone {
+			if !strings.EqualFold(rr.Header().Name, pre.Header().Name) {
+				continue
+			}
+			if pre.Header().Rrtype == dns.TypeANY {
+				resp.Rcode = dns.RcodeYXDomain
+			} else if rr.Header().Rrtype == pre.Header().Rrtype {
+				resp.Rcode = dns.RcodeYXRrset
+			}
+		}
+		if resp.Rcode != dns.RcodeSuccess {
+			w.WriteMsg(resp)
+			return
+		}
+	}
+	f.updates++
+	for _, rr := range req.Ns {
+		switch rr.Header().Class {
+		case dns.ClassNONE:
+			kept := f.zone[:0]
+			for _, existing := range f.zone {
+				rr.Header().Class, rr.Header().Ttl = dns.ClassINET, existing.Header().Ttl
+				if !dns.IsDuplicate(existing, rr) {
+					kept = append(kept, existing)
+				}
+			}
+			f.zone = kept
+		case dns.ClassINET:
+			f.zone = append(f.zone, rr)
+		}
+	}
+	w.WriteMsg(resp)
+}
+
+// startRFC2136 starts a fake server and returns its address
+func startRFC2136(t *testing.T, fake *fakeRFC2136) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           fake,
+		TsigSecret:        map[string]string{testKeyName: testSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// The default rejects updates
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return listener.Addr().String()
+}
+
+func TestRFC2136Records(t *testing.T) {
+	fake := &fakeRFC2136{}
+	for _, line := range []string{
+		"home.arpa. 3600 IN A 192.168.1.1",
+		"app.home.arpa. 300 IN CNAME home.arpa.",
+		"nas.home.arpa. 300 IN A 192.168.1.10",
+	} {
+		rr, _ := dns.NewRR(line)
+		fake.zone = append(fake.zone, rr)
+	}
+	p := NewRFC2136(startRFC2136(t, fake), "LPF", "hmac-sha256", testSecret)
+	ctx := context.Background()
+
+	records, err := p.ListDNSRecords(ctx, "home.arpa", "CNAME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Name != "app.home.arpa" || records[0].Content != "home.arpa" || records[0].ID != "app.home.arpa|CNAME|home.arpa" || records[0].ZoneID != "home.arpa" {
+		t.Fatalf("unexpected CNAME records: %+v", records)
+	}
+	if all, _ := p.ListDNSRecords(ctx, "home.arpa", ""); len(all) != 3 {
+		t.Errorf("expected the SOA left out, got %d records", len(all))
+	}
+
+	// Create: the CNAME target gets its trailing dot, the TTL a real value
+	created, err := p.CreateDNSRecord(ctx, "home.arpa", Record{Type: "CNAME", Name: "plex.home.arpa", Content: "home.arpa", TTL: 1, Comment: "dropped"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "plex.home.arpa|CNAME|home.arpa" || created.TTL != rfc2136DefaultTTL || created.Comment != "" {
+		t.Errorf("unexpected created record: %+v", created)
+	}
+	if cname, ok := fake.zone[3].(*dns.CNAME); !ok || cname.Target != "home.arpa." || cname.Hdr.Ttl != rfc2136DefaultTTL {
+		t.Errorf("unexpected record in zone: %v", fake.zone[3])
+	}
+
+	// Prerequisites reject records that can't coexist with the CNAME
+	if _, err := p.CreateDNSRecord(ctx, "home.arpa", Record{Type: "A", Name: "plex.home.arpa", Content: "10.0.0.1"}); !IsRecordExists(err) {
+		t.Errorf("expected a conflict with the CNAME, got %v", err)
+	}
+	if _, err := p.CreateDNSRecord(ctx, "home.arpa", Record{Type: "CNAME", Name: "nas.home.arpa", Content: "home.arpa"}); !IsRecordExists(err) {
+		t.Errorf("expected a conflict with the A record, got %v", err)
+	}
+
+	// Update replaces the record in one message
+	updated, err := p.UpdateDNSRecord(ctx, "home.arpa", "nas.home.arpa|A|192.168.1.10", Record{Type: "A", Name: "nas.home.arpa", Content: "192.168.1.20", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != "nas.home.arpa|A|192.168.1.20" {
+		t.Errorf("unexpected ID after update %q", updated.ID)
+	}
+
+	// Replacing a CNAME with an A record in one batch passes the prerequisites
+	_, err = p.BatchDNSRecords(ctx, "home.arpa", BatchRequest{
+		Deletes: []Record{{ID: created.ID}},
+		Posts:   []Record{{Type: "A", Name: "plex.home.arpa", Content: "192.168.1.30"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := p.ListDNSRecords(ctx, "home.arpa", "")
+	var ids []string
+	for _, record := range all {
+		ids = append(ids, record.ID)
+	}
+	if got := strings.Join(ids, " "); got != "home.arpa|A|192.168.1.1 app.home.arpa|CNAME|home.arpa nas.home.arpa|A|192.168.1.20 plex.home.arpa|A|192.168.1.30" {
+		t.Errorf("unexpected zone after updates: %s", got)
+	}
+
+	if err := p.DeleteDNSRecord(ctx, "home.arpa", "not-an-id"); !IsNotFound(err) {
+		t.Errorf("expected not found for an invalid ID, got %v", err)
+	}
+	if _, err := p.ListDNSRecords(ctx, "example.net", ""); err == nil {
+		t.Error("expected the transfer of an unknown zone to fail")
+	}
+
+	// Updates signed with the wrong key are refused
+	wrong := NewRFC2136(p.server, "lpf", "hmac-sha256", "d3Jvbmc=")
+	if _, err := wrong.CreateDNSRecord(ctx, "home.arpa", Record{Type: "A", Name: "x.home.arpa", Content: "10.0.0.1"}); err == nil {
+		t.Error("expected an update with the wrong secret to fail")
+	}
+	if fake.updates != 3 {
+		t.Errorf("expected 3 applied updates, got %d", fake.updates)
+	}
+}
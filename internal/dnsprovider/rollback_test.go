@@ -0,0 +1,60 @@
+package dnsprovider_test
+
+import (
+	"context"
+	"testing"
+
+	"lazyproxyflare/internal/dnsprovider"
+	"lazyproxyflare/internal/journal"
+)
+
+// rollbackUpdate updates app's A record from 10.0.0.1 to 10.0.0.2 the way an
+// entry edit does, rolls the journal back and returns the A records left
+func rollbackUpdate(t *testing.T, p dnsprovider.Provider, zoneID, name string) []dnsprovider.Record {
+	t.Helper()
+	ctx := context.Background()
+	before := dnsprovider.Record{ID: name + "|A|10.0.0.1", Type: "A", Name: name, Content: "10.0.0.1", TTL: 300}
+
+	j, err := journal.Begin(t.TempDir(), "update", name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := before
+	after.Content = "10.0.0.2"
+	updated, err := p.UpdateDNSRecord(ctx, zoneID, before.ID, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.RecordDNSUpdate(zoneID, before, *updated)
+
+	if err := j.Rollback(ctx, p, func() error { return nil }); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	records, err := p.ListDNSRecords(ctx, zoneID, "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []dnsprovider.Record
+	for _, record := range records {
+		if record.Name == name {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+func TestPowerDNSRollbackUpdate(t *testing.T) {
+	p := dnsprovider.NewFakePowerDNS(t, dnsprovider.FakePowerDNSRRset("app.example.com.", "A", "10.0.0.1"))
+	records := rollbackUpdate(t, p, "example.com", "app.example.com")
+	if len(records) != 1 || records[0].Content != "10.0.0.1" {
+		t.Errorf("expected the record reverted to 10.0.0.1, got %+v", records)
+	}
+}
+
+func TestRFC2136RollbackUpdate(t *testing.T) {
+	p := dnsprovider.NewFakeRFC2136(t, "app.home.arpa. 300 IN A 10.0.0.1")
+	records := rollbackUpdate(t, p, "home.arpa", "app.home.arpa")
+	if len(records) != 1 || records[0].Content != "10.0.0.1" {
+		t.Errorf("expected exactly one record, reverted to 10.0.0.1, got %+v", records)
+	}
+}
@@ -0,0 +1,394 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/dnsprovider"
+)
+
+// StepKind identifies a change recorded in a journal
+type StepKind string
+
+const (
+	StepDNSCreate StepKind = "dns_create" // Inverse: delete the created record
+	StepDNSUpdate StepKind = "dns_update" // Inverse: put the snapshot back
+	StepDNSDelete StepKind = "dns_delete" // Inverse: recreate the record from its snapshot
+	StepCaddyfile StepKind = "caddyfile"  // Inverse: restore the backup (and reload if it was applied)
+	StepFile      StepKind = "file"       // Inverse: restore the backup of another config file
+)
+
+// Status is the state of a journal on disk
+type Status string
+
+const (
+	StatusPending     Status = "pending"      // Operation in progress
+	StatusRollingBack Status = "rolling_back" // Inverse operations being replayed
+	StatusCommitted   Status = "committed"    // Operation finished; file about to be removed
+)
+
+// Step is one applied change together with what is needed to undo it
+type Step struct {
+	Kind          StepKind              `json:"kind"`
+	ZoneID        string                `json:"zone_id,omitempty"`
+	Record        *cloudflare.DNSRecord `json:"record,omitempty"`  // Created record, or snapshot before update/delete
+	Updated       *cloudflare.DNSRecord `json:"updated,omitempty"` // Record after an update, with its current ID
+	CaddyfilePath string                `json:"caddyfile_path,omitempty"`
+	FilePath      string                `json:"file_path,omitempty"`
+	BackupPath    string                `json:"backup_path,omitempty"`
+	Reloaded      bool                  `json:"reloaded,omitempty"` // Caddy is running the modified Caddyfile
+	Undone        bool                  `json:"undone,omitempty"`
+}
+
+// Journal records the steps of a multi-system operation (DNS + Caddy) so a
+// failure part way through can be undone, even after a crash.
+// Every change is written to disk as it is recorded; the file is removed on
+// Commit or after a clean Rollback. Persistence is best-effort: if the file
+// can't be written the in-memory journal still supports Rollback.
+type Journal struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Domain    string    `json:"domain"`
+	Caddyfile string    `json:"caddyfile"`            // Caddyfile of the profile that started the operation
+	PID       int       `json:"pid"`                  // Process running the operation
+	ProcStart string    `json:"proc_start,omitempty"` // Start time of that process, to tell a reused PID apart
+	BootID    string    `json:"boot_id,omitempty"`    // Boot the process ran in
+	Started   time.Time `json:"started"`
+	Status    Status    `json:"status"`
+	Steps     []Step    `json:"steps"`
+
+	path string
+}
+
+// Begin starts a journal for an operation and writes it to dir
+func Begin(dir, operation, domain, caddyfilePath string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	now := time.Now()
+	pid := os.Getpid()
+	id := fmt.Sprintf("%s-%d", now.UTC().Format("20060102T150405.000000000"), pid)
+	j := &Journal{
+		ID:        id,
+		Operation: operation,
+		Domain:    domain,
+		Caddyfile: caddyfilePath,
+		PID:       pid,
+		ProcStart: processStart(pid),
+		BootID:    bootID(),
+		Started:   now,
+		Status:    StatusPending,
+		Steps:     []Step{},
+		path:      filepath.Join(dir, id+".json"),
+	}
+	if err := j.save(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Load reads a journal file
+func Load(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", filepath.Base(path), err)
+	}
+	j.path = path
+	return &j, nil
+}
+
+// Pending returns the journals left in dir by interrupted operations, oldest first
+func Pending(dir string) ([]*Journal, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journals: %w", err)
+	}
+	sort.Strings(matches)
+
+	var journals []*Journal
+	for _, match := range matches {
+		j, err := Load(match)
+		if err != nil {
+			return journals, err
+		}
+		journals = append(journals, j)
+	}
+	return journals, nil
+}
+
+// save writes the journal atomically (temp file + rename)
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// record appends a step and persists the journal. Safe on a nil journal.
+func (j *Journal) record(step Step) {
+	if j == nil {
+		return
+	}
+	j.Steps = append(j.Steps, step)
+	_ = j.save()
+}
+
+// RecordDNSCreate records a created DNS record (undo deletes it)
+func (j *Journal) RecordDNSCreate(zoneID string, created cloudflare.DNSRecord) {
+	j.record(Step{Kind: StepDNSCreate, ZoneID: zoneID, Record: &created})
+}
+
+// RecordDNSUpdate records the state of a DNS record before and after it was
+// updated. Providers that derive IDs from the content (PowerDNS, RFC 2136)
+// give the updated record a new ID, so the undo must use after's.
+func (j *Journal) RecordDNSUpdate(zoneID string, before, after cloudflare.DNSRecord) {
+	j.record(Step{Kind: StepDNSUpdate, ZoneID: zoneID, Record: &before, Updated: &after})
+}
+
+// RecordDNSDelete records a DNS record that was deleted (undo recreates it)
+func (j *Journal) RecordDNSDelete(zoneID string, before cloudflare.DNSRecord) {
+	j.record(Step{Kind: StepDNSDelete, ZoneID: zoneID, Record: &before})
+}
+
+// RecordCaddyfile records a Caddyfile backup taken before the file is modified
+func (j *Journal) RecordCaddyfile(caddyfilePath, backupPath string) {
+	j.record(Step{Kind: StepCaddyfile, CaddyfilePath: caddyfilePath, BackupPath: backupPath})
+}
+
+// RecordFile records a backup of a config file other than the Caddyfile
+// (e.g., the cloudflared config) taken before the file is modified. Undoing
+// it only restores the file: the service using it is restarted as the last
+// step of an operation, so it never runs a file that is rolled back.
+func (j *Journal) RecordFile(path, backupPath string) {
+	j.record(Step{Kind: StepFile, FilePath: path, BackupPath: backupPath})
+}
+
+// MarkReloaded notes that Caddy is now running the modified Caddyfile,
+// so undoing the Caddyfile step also needs a reload
+func (j *Journal) MarkReloaded() {
+	if j == nil {
+		return
+	}
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		if j.Steps[i].Kind == StepCaddyfile {
+			j.Steps[i].Reloaded = true
+			_ = j.save()
+			return
+		}
+	}
+}
+
+// Commit marks the operation as finished and removes the journal file.
+// The committed status is written first so that a file which can't be
+// removed is only cleaned up by recovery, never rolled back.
+func (j *Journal) Commit() error {
+	if j == nil {
+		return nil
+	}
+	j.Status = StatusCommitted
+	saveErr := j.save()
+	if err := j.remove(); err != nil {
+		if saveErr != nil {
+			return fmt.Errorf("%w (and %v)", err, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove deletes the journal file
+func (j *Journal) remove() error {
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
+
+// Rollback replays the inverse of every step that hasn't been undone, newest
+// first. Failed inverses don't stop the replay; their errors are joined and
+// the journal is kept on disk so recovery can retry them.
+// reload is called once at the end if a Caddyfile that Caddy had already
+// loaded was restored.
+func (j *Journal) Rollback(ctx context.Context, dns dnsprovider.Records, reload func() error) error {
+	if j == nil {
+		return nil
+	}
+	j.Status = StatusRollingBack
+	_ = j.save()
+
+	var errs []error
+	var restored []int // Caddyfile steps that Caddy had loaded
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		step := &j.Steps[i]
+		if step.Undone {
+			continue
+		}
+		if err := undo(ctx, *step, dns); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		step.Undone = true
+		if step.Kind == StepCaddyfile && step.Reloaded {
+			restored = append(restored, i)
+		}
+		_ = j.save()
+	}
+
+	if len(restored) > 0 && reload != nil {
+		if err := reload(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload restored Caddyfile: %w", err))
+			// Keep the restore pending so recovery retries the reload
+			for _, i := range restored {
+				j.Steps[i].Undone = false
+			}
+			_ = j.save()
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return j.remove()
+}
+
+// Interrupted reports whether the process that started the journal is gone,
+// i.e. the operation will never finish on its own.
+// A live process only counts as the owner if it is from the same boot and
+// started at the same time; otherwise its PID was reused.
+func (j *Journal) Interrupted() bool {
+	if j.PID <= 0 {
+		return true
+	}
+	if j.BootID != "" {
+		if current := bootID(); current != "" && current != j.BootID {
+			return true
+		}
+	}
+	process, err := os.FindProcess(j.PID)
+	if err != nil {
+		return true
+	}
+	if process.Signal(syscall.Signal(0)) != nil {
+		return true
+	}
+	if j.ProcStart != "" {
+		if current := processStart(j.PID); current != "" && current != j.ProcStart {
+			return true
+		}
+	}
+	return false
+}
+
+// bootID identifies the current boot, or is empty where /proc doesn't provide it
+func bootID() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// processStart returns the start time of a process in clock ticks since boot
+// (field 22 of /proc/<pid>/stat), or is empty where /proc isn't available
+func processStart(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ""
+	}
+	// The command name (field 2) is in parentheses and may contain spaces
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return ""
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
+
+// Recover finishes or undoes a journal left by an interrupted operation.
+// A committed journal only needs its file removed; anything else is rolled back.
+func (j *Journal) Recover(ctx context.Context, dns dnsprovider.Records, reload func() error) error {
+	if j.Status == StatusCommitted {
+		return j.remove()
+	}
+	// A Caddyfile step that was restored but not yet reloaded still needs the
+	// reload; treat any applied Caddyfile step as possibly loaded by Caddy
+	for i := range j.Steps {
+		if j.Steps[i].Kind == StepCaddyfile && !j.Steps[i].Undone {
+			j.Steps[i].Reloaded = true
+		}
+	}
+	return j.Rollback(ctx, dns, reload)
+}
+
+// Describe returns a one-line summary of the journal for logs and messages
+func (j *Journal) Describe() string {
+	kinds := make([]string, 0, len(j.Steps))
+	for _, step := range j.Steps {
+		kinds = append(kinds, string(step.Kind))
+	}
+	return fmt.Sprintf("%s %s (started %s, steps: %s)",
+		j.Operation, j.Domain, j.Started.Format("2006-01-02 15:04:05"), strings.Join(kinds, ", "))
+}
+
+// undo applies the inverse of a single step
+func undo(ctx context.Context, step Step, dns dnsprovider.Records) error {
+	switch step.Kind {
+	case StepDNSCreate:
+		// A created record that is already gone needs no undoing
+		if err := dns.DeleteDNSRecord(ctx, step.ZoneID, step.Record.ID); err != nil && !dnsprovider.IsNotFound(err) {
+			return fmt.Errorf("failed to delete created %s record %s: %w", step.Record.Type, step.Record.Name, err)
+		}
+	case StepDNSUpdate:
+		// Journals written before the updated record was kept only have the old ID
+		id := step.Record.ID
+		if step.Updated != nil && step.Updated.ID != "" {
+			id = step.Updated.ID
+		}
+		if _, err := dns.UpdateDNSRecord(ctx, step.ZoneID, id, *step.Record); err != nil {
+			return fmt.Errorf("failed to revert %s record %s: %w", step.Record.Type, step.Record.Name, err)
+		}
+	case StepDNSDelete:
+		record := *step.Record
+		record.ID = ""
+		if _, err := dns.CreateDNSRecord(ctx, step.ZoneID, record); err != nil {
+			return fmt.Errorf("failed to recreate %s record %s: %w", record.Type, record.Name, err)
+		}
+	case StepCaddyfile:
+		if err := caddy.RestoreFromBackup(step.CaddyfilePath, step.BackupPath); err != nil {
+			return err
+		}
+	case StepFile:
+		if err := caddy.RestoreFromBackup(step.FilePath, step.BackupPath); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", step.FilePath, err)
+		}
+	default:
+		return fmt.Errorf("unknown journal step %q", step.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lazyproxyflare/internal/cloudflare"
+)
+
+// fakeDNS is an in-memory cloudflare.DNSClient
+type fakeDNS struct {
+	records map[string]cloudflare.DNSRecord
+	nextID  int
+	failOn  string // Method name that should fail
+}
+
+func newFakeDNS(records ...cloudflare.DNSRecord) *fakeDNS {
+	f := &fakeDNS{records: map[string]cloudflare.DNSRecord{}}
+	for _, r := range records {
+		f.records[r.ID] = r
+	}
+	return f
+}
+
+func (f *fakeDNS) ListDNSRecords(ctx context.Context, zoneID, recordType string) ([]cloudflare.DNSRecord, error) {
+	var out []cloudflare.DNSRecord
+	for _, r := range f.records {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (f *fakeDNS) CreateDNSRecord(ctx context.Context, zoneID string, record cloudflare.DNSRecord) (*cloudflare.DNSRecord, error) {
+	if f.failOn == "create" {
+		return nil, errors.New("create failed")
+	}
+	f.nextID++
+	record.ID = fmt.Sprintf("new-%d", f.nextID)
+	f.records[record.ID] = record
+	return &record, nil
+}
+
+func (f *fakeDNS) UpdateDNSRecord(ctx context.Context, zoneID, recordID string, record cloudflare.DNSRecord) (*cloudflare.DNSRecord, error) {
+	if f.failOn == "update" {
+		return nil, errors.New("update failed")
+	}
+	record.ID = recordID
+	f.records[recordID] = record
+	return &record, nil
+}
+
+func (f *fakeDNS) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
+	if f.failOn == "delete" {
+		return errors.New("delete failed")
+	}
+	delete(f.records, recordID)
+	return nil
+}
+
+func (f *fakeDNS) BatchDNSRecords(ctx context.Context, zoneID string, batch cloudflare.BatchRequest) (*cloudflare.BatchResult, error) {
+	if f.failOn == "batch" {
+		return &cloudflare.BatchResult{}, errors.New("batch failed")
+	}
+	result := &cloudflare.BatchResult{}
+	for _, r := range batch.Deletes {
+		delete(f.records, r.ID)
+		result.Deletes = append(result.Deletes, r)
+	}
+	for _, r := range batch.Patches {
+		f.records[r.ID] = r
+		result.Patches = append(result.Patches, r)
+	}
+	for _, r := range batch.Posts {
+		created, _ := f.CreateDNSRecord(ctx, zoneID, r)
+		result.Posts = append(result.Posts, *created)
+	}
+	return result, nil
+}
+
+func (f *fakeDNS) findByName(name string) (cloudflare.DNSRecord, bool) {
+	for _, r := range f.records {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return cloudflare.DNSRecord{}, false
+}
+
+// setupCaddyfile writes a Caddyfile and a backup of its original content
+func setupCaddyfile(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	caddyfilePath := filepath.Join(dir, "Caddyfile")
+	backupPath := caddyfilePath + ".backup.1"
+	if err := os.WriteFile(backupPath, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(caddyfilePath, []byte("modified\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return caddyfilePath, backupPath
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRollbackReplaysInverses(t *testing.T) {
+	dir := t.TempDir()
+	caddyfilePath, backupPath := setupCaddyfile(t)
+
+	updated := cloudflare.DNSRecord{ID: "upd", Type: "A", Name: "upd.example.com", Content: "10.0.0.2"}
+	created := cloudflare.DNSRecord{ID: "new", Type: "CNAME", Name: "new.example.com", Content: "example.com"}
+	dns := newFakeDNS(updated, created)
+
+	j, err := Begin(dir, "update", "app.example.com", caddyfilePath)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	j.RecordCaddyfile(caddyfilePath, backupPath)
+	j.MarkReloaded()
+	j.RecordDNSUpdate("zone", cloudflare.DNSRecord{ID: "upd", Type: "A", Name: "upd.example.com", Content: "10.0.0.1"}, updated)
+	j.RecordDNSDelete("zone", cloudflare.DNSRecord{ID: "gone", Type: "AAAA", Name: "gone.example.com", Content: "2001:db8::1"})
+	j.RecordDNSCreate("zone", created)
+
+	reloads := 0
+	if err := j.Rollback(context.Background(), dns, func() error { reloads++; return nil }); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	if _, ok := dns.records["new"]; ok {
+		t.Error("created record should have been deleted")
+	}
+	if dns.records["upd"].Content != "10.0.0.1" {
+		t.Errorf("updated record should be reverted, got %q", dns.records["upd"].Content)
+	}
+	if r, ok := dns.findByName("gone.example.com"); !ok || r.Content != "2001:db8::1" {
+		t.Error("deleted record should have been recreated")
+	}
+	if got := readFile(t, caddyfilePath); got != "original\n" {
+		t.Errorf("Caddyfile should be restored, got %q", got)
+	}
+	if reloads != 1 {
+		t.Errorf("expected one reload, got %d", reloads)
+	}
+	if pending, _ := Pending(dir); len(pending) != 0 {
+		t.Errorf("journal should be removed after clean rollback, found %d", len(pending))
+	}
+}
+
+func TestRollbackWithoutReload(t *testing.T) {
+	dir := t.TempDir()
+	caddyfilePath, backupPath := setupCaddyfile(t)
+
+	// Validation failed before reload: restore only
+	j, _ := Begin(dir, "create", "app.example.com", caddyfilePath)
+	j.RecordCaddyfile(caddyfilePath, backupPath)
+
+	reloads := 0
+	if err := j.Rollback(context.Background(), newFakeDNS(), func() error { reloads++; return nil }); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if reloads != 0 {
+		t.Errorf("Caddy never loaded the change, expected no reload, got %d", reloads)
+	}
+}
+
+func TestCommitRemovesJournal(t *testing.T) {
+	dir := t.TempDir()
+	j, _ := Begin(dir, "create", "app.example.com", "/etc/caddy/Caddyfile")
+	j.RecordDNSCreate("zone", cloudflare.DNSRecord{ID: "1", Type: "A", Name: "app.example.com"})
+
+	if pending, _ := Pending(dir); len(pending) != 1 {
+		t.Fatalf("expected journal on disk before commit, found %d", len(pending))
+	}
+	if err := j.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if pending, _ := Pending(dir); len(pending) != 0 {
+		t.Errorf("expected no journals after commit, found %d", len(pending))
+	}
+}
+
+func TestRecoverInterruptedJournal(t *testing.T) {
+	dir := t.TempDir()
+	caddyfilePath, backupPath := setupCaddyfile(t)
+	dns := newFakeDNS(cloudflare.DNSRecord{ID: "new", Type: "CNAME", Name: "app.example.com"})
+
+	// Simulate a crash after the Caddyfile edit and DNS create
+	j, _ := Begin(dir, "create", "app.example.com", caddyfilePath)
+	j.RecordCaddyfile(caddyfilePath, backupPath)
+	j.RecordDNSCreate("zone", dns.records["new"])
+
+	pending, err := Pending(dir)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected 1 pending journal, got %d (err: %v)", len(pending), err)
+	}
+	recovered := pending[0]
+	if recovered.Domain != "app.example.com" || len(recovered.Steps) != 2 {
+		t.Fatalf("unexpected journal: %+v", recovered)
+	}
+
+	reloads := 0
+	if err := recovered.Recover(context.Background(), dns, func() error { reloads++; return nil }); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(dns.records) != 0 {
+		t.Error("created record should have been deleted")
+	}
+	if got := readFile(t, caddyfilePath); got != "original\n" {
+		t.Errorf("Caddyfile should be restored, got %q", got)
+	}
+	// Caddy may have loaded the edit before the crash, so recovery reloads
+	if reloads != 1 {
+		t.Errorf("expected recovery to reload, got %d", reloads)
+	}
+	if pending, _ := Pending(dir); len(pending) != 0 {
+		t.Errorf("expected no journals after recovery, found %d", len(pending))
+	}
+}
+
+func TestRecoverCommittedJournal(t *testing.T) {
+	dir := t.TempDir()
+	dns := newFakeDNS(cloudflare.DNSRecord{ID: "1", Type: "A", Name: "app.example.com"})
+
+	// Crash between marking committed and removing the file
+	j, _ := Begin(dir, "create", "app.example.com", "/etc/caddy/Caddyfile")
+	j.RecordDNSCreate("zone", dns.records["1"])
+	j.Status = StatusCommitted
+	j.save()
+
+	pending, _ := Pending(dir)
+	if err := pending[0].Recover(context.Background(), dns, nil); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(dns.records) != 1 {
+		t.Error("committed operation should not be undone")
+	}
+	if pending, _ := Pending(dir); len(pending) != 0 {
+		t.Errorf("expected committed journal to be removed, found %d", len(pending))
+	}
+}
+
+func TestRollbackFailureKeepsJournal(t *testing.T) {
+	dir := t.TempDir()
+	dns := newFakeDNS(
+		cloudflare.DNSRecord{ID: "a", Type: "A", Name: "app.example.com"},
+		cloudflare.DNSRecord{ID: "b", Type: "AAAA", Name: "app.example.com"},
+	)
+
+	j, _ := Begin(dir, "create", "app.example.com", "/etc/caddy/Caddyfile")
+	j.RecordDNSCreate("zone", dns.records["a"])
+	j.RecordDNSCreate("zone", dns.records["b"])
+
+	dns.failOn = "delete"
+	if err := j.Rollback(context.Background(), dns, nil); err == nil {
+		t.Fatal("expected rollback error")
+	}
+
+	pending, _ := Pending(dir)
+	if len(pending) != 1 || pending[0].Status != StatusRollingBack {
+		t.Fatalf("expected journal kept in rolling_back state, got %+v", pending)
+	}
+
+	// Recovery retries the remaining inverses
+	dns.failOn = ""
+	if err := pending[0].Recover(context.Background(), dns, nil); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(dns.records) != 0 {
+		t.Errorf("expected both records deleted, %d left", len(dns.records))
+	}
+}
+
+func TestRollbackReloadFailureRetried(t *testing.T) {
+	dir := t.TempDir()
+	caddyfilePath, backupPath := setupCaddyfile(t)
+
+	j, _ := Begin(dir, "delete", "app.example.com", caddyfilePath)
+	j.RecordCaddyfile(caddyfilePath, backupPath)
+	j.MarkReloaded()
+
+	if err := j.Rollback(context.Background(), newFakeDNS(), func() error { return errors.New("caddy down") }); err == nil {
+		t.Fatal("expected reload error")
+	}
+
+	pending, _ := Pending(dir)
+	if len(pending) != 1 || pending[0].Steps[0].Undone {
+		t.Fatalf("Caddyfile step should stay pending until reload succeeds: %+v", pending)
+	}
+
+	reloads := 0
+	if err := pending[0].Recover(context.Background(), newFakeDNS(), func() error { reloads++; return nil }); err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if reloads != 1 {
+		t.Errorf("expected reload on retry, got %d", reloads)
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *Journal
+	j.RecordDNSCreate("zone", cloudflare.DNSRecord{})
+	j.MarkReloaded()
+	if err := j.Commit(); err != nil {
+		t.Errorf("Commit on nil journal: %v", err)
+	}
+	if err := j.Rollback(context.Background(), nil, nil); err != nil {
+		t.Errorf("Rollback on nil journal: %v", err)
+	}
+}
+
+func TestInterrupted(t *testing.T) {
+	j, _ := Begin(t.TempDir(), "create", "app.example.com", "/etc/caddy/Caddyfile")
+	if j.Interrupted() {
+		t.Error("journal owned by this process should not be interrupted")
+	}
+
+	j.PID = 0
+	if !j.Interrupted() {
+		t.Error("journal without an owner should be interrupted")
+	}
+}
+
+func TestInterruptedReusedPID(t *testing.T) {
+	j, _ := Begin(t.TempDir(), "create", "app.example.com", "/etc/caddy/Caddyfile")
+	if j.BootID == "" || j.ProcStart == "" {
+		t.Skip("/proc not available")
+	}
+
+	// Same PID, but a process started later
+	j.ProcStart = "0"
+	if !j.Interrupted() {
+		t.Error("journal of a process whose PID was reused should be interrupted")
+	}
+
+	// Same PID after a reboot
+	j.ProcStart = processStart(j.PID)
+	j.BootID = "00000000-0000-0000-0000-000000000000"
+	if !j.Interrupted() {
+		t.Error("journal from a previous boot should be interrupted")
+	}
+
+	// Journals written before the check existed fall back to the PID
+	j.ProcStart, j.BootID = "", ""
+	if j.Interrupted() {
+		t.Error("journal owned by this process should not be interrupted")
+	}
+}
+
+func TestRollbackRestoresFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	os.WriteFile(configPath, []byte("ingress: []\n"), 0644)
+	backupPath := configPath + ".bak"
+	os.WriteFile(backupPath, []byte("ingress: [old]\n"), 0644)
+
+	j, _ := Begin(dir, "create", "app.example.com", "Caddyfile")
+	j.RecordFile(configPath, backupPath)
+	j.MarkReloaded() // Only applies to Caddyfile steps
+
+	reloads := 0
+	if err := j.Rollback(context.Background(), newFakeDNS(), func() error { reloads++; return nil }); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "ingress: [old]\n" {
+		t.Errorf("file not restored: %q", data)
+	}
+	if reloads != 0 {
+		t.Errorf("restoring a file should not reload Caddy, got %d reloads", reloads)
+	}
+}
@@ -0,0 +1,227 @@
+package manifest
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+)
+
+// Load reads and parses a manifest file
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses manifest YAML
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Resolve fills in profile defaults and validates every service
+func (m *Manifest) Resolve(cfg *config.Config) ([]Desired, error) {
+	domain := strings.ToLower(cfg.Domain)
+	seen := make(map[string]bool)
+	desired := make([]Desired, 0, len(m.Services))
+
+	for i, svc := range m.Services {
+		name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(svc.Subdomain), "."))
+		if name == "" {
+			return nil, fmt.Errorf("services[%d]: subdomain is required", i)
+		}
+
+		// Accept either "app" or "app.example.com". In a multi-zone profile,
+		// names in the additional zones are given as FQDNs and kept as-is.
+		subdomain := strings.TrimSuffix(name, "."+domain)
+		fqdn := subdomain + "." + domain
+		if zone, ok := cfg.ZoneFor(name); ok && !strings.EqualFold(zone.Domain, domain) {
+			subdomain, fqdn = name, name
+			if name == strings.ToLower(zone.Domain) {
+				return nil, fmt.Errorf("services[%d]: apex domain %s is not supported", i, name)
+			}
+		} else if subdomain == domain || subdomain == "" {
+			return nil, fmt.Errorf("services[%d]: apex domain %s is not supported", i, domain)
+		}
+		if seen[fqdn] {
+			return nil, fmt.Errorf("services[%d]: duplicate service %s", i, fqdn)
+		}
+		seen[fqdn] = true
+
+		d := Desired{
+			Subdomain: subdomain,
+			FQDN:      fqdn,
+			DNSType:   strings.ToUpper(svc.DNSType),
+			DNSTarget: svc.DNSTarget,
+			Proxied:   cfg.Defaults.Proxied,
+			DNSOnly:   svc.DNSOnly,
+			Target:    svc.Target,
+			Port:      svc.Port,
+			SSL:       cfg.Defaults.SSL,
+			Snippets:  svc.Snippets,
+		}
+		if d.DNSType == "" {
+			d.DNSType = "CNAME"
+		}
+		if d.DNSTarget == "" && d.DNSType == "CNAME" {
+			d.DNSTarget = cfg.Defaults.CNAMETarget
+		}
+		if svc.Proxied != nil {
+			d.Proxied = *svc.Proxied
+		}
+		if d.Target == "" {
+			d.Target = "localhost"
+		}
+		if d.Port == 0 {
+			d.Port = cfg.Defaults.Port
+		}
+		if svc.SSL != nil {
+			d.SSL = *svc.SSL
+		}
+
+		// Validate resolved values
+		switch d.DNSType {
+		case "CNAME":
+			if d.DNSTarget == "" {
+				return nil, fmt.Errorf("services[%d] (%s): dns_target is required (no defaults.cname_target set)", i, fqdn)
+			}
+		case "A":
+			if !isIPv4(d.DNSTarget) {
+				return nil, fmt.Errorf("services[%d] (%s): dns_target must be an IPv4 address for A records", i, fqdn)
+			}
+		case "AAAA":
+			if !isIPv6(d.DNSTarget) {
+				return nil, fmt.Errorf("services[%d] (%s): dns_target must be an IPv6 address for AAAA records", i, fqdn)
+			}
+		case diff.DualStackType:
+			ipv4, ipv6, ok := splitDualStack(d.DNSTarget)
+			if !ok || !isIPv4(ipv4) || !isIPv6(ipv6) {
+				return nil, fmt.Errorf("services[%d] (%s): dns_target must be \"IPv4, IPv6\" for A+AAAA records", i, fqdn)
+			}
+			d.DNSTarget = ipv4 + ", " + ipv6
+		default:
+			return nil, fmt.Errorf("services[%d] (%s): unsupported dns_type %q (expected CNAME, A, AAAA or A+AAAA)", i, fqdn, svc.DNSType)
+		}
+		if err := resolveEntryType(&d, svc); err != nil {
+			return nil, fmt.Errorf("services[%d] (%s): %w", i, fqdn, err)
+		}
+		if d.Type == caddy.EntryProxy && (d.Port < 1 || d.Port > 65535) {
+			return nil, fmt.Errorf("services[%d] (%s): port %d out of range", i, fqdn, d.Port)
+		}
+
+		desired = append(desired, d)
+	}
+
+	return desired, nil
+}
+
+// resolveEntryType sets what the service's Caddy block does and checks the
+// settings of that type. Redirects and static sites have no upstream.
+func resolveEntryType(d *Desired, svc Service) error {
+	kind := strings.ToLower(svc.Type)
+	if kind == "" {
+		switch {
+		case svc.RedirectTo != "":
+			kind = "redirect"
+		case svc.StaticRoot != "":
+			kind = "static"
+		default:
+			kind = "reverse_proxy"
+		}
+	}
+	hasProxy := svc.Target != "" || svc.Port != 0 || svc.SSL != nil ||
+		len(svc.Routes) > 0 || len(svc.Upstreams) > 0 || len(svc.LoadBalancing) > 0
+
+	switch kind {
+	case "reverse_proxy":
+		if svc.RedirectTo != "" || svc.StaticRoot != "" {
+			return fmt.Errorf("redirect_to and static_root need type redirect or static")
+		}
+		d.Type = caddy.EntryProxy
+		var err error
+		if d.Routes, err = caddy.ParsePathRoutes(strings.Join(svc.Routes, "\n")); err != nil {
+			return fmt.Errorf("invalid routes: %w", err)
+		}
+		if d.Upstreams, err = caddy.ParseUpstreams(strings.Join(svc.Upstreams, " ")); err != nil {
+			return fmt.Errorf("invalid upstreams: %w", err)
+		}
+		if d.LoadBalancing, err = caddy.ParseLoadBalancing(strings.Join(svc.LoadBalancing, "\n")); err != nil {
+			return fmt.Errorf("invalid load_balancing: %w", err)
+		}
+
+	case "redirect":
+		if hasProxy || svc.StaticRoot != "" {
+			return fmt.Errorf("a redirect has no upstream or static_root")
+		}
+		u, err := url.Parse(svc.RedirectTo)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("redirect_to must be an absolute http(s) URL")
+		}
+		d.Type, d.Target, d.Port, d.SSL = caddy.EntryRedirect, "", 0, false
+		d.RedirectTo, d.RedirectCode, d.PreservePath = svc.RedirectTo, 301, true
+		if svc.RedirectCode != 0 {
+			d.RedirectCode = svc.RedirectCode
+		}
+		if !slices.Contains(caddy.RedirectCodes, d.RedirectCode) {
+			return fmt.Errorf("redirect_code must be 301, 302, 303, 307 or 308")
+		}
+		if svc.PreservePath != nil {
+			d.PreservePath = *svc.PreservePath
+		}
+		if d.PreservePath {
+			// The request path is appended to the URL without its trailing slash
+			d.RedirectTo = strings.TrimSuffix(d.RedirectTo, "/")
+		}
+
+	case "static":
+		if hasProxy || svc.RedirectTo != "" {
+			return fmt.Errorf("a static site has no upstream or redirect_to")
+		}
+		if !strings.HasPrefix(svc.StaticRoot, "/") || strings.ContainsAny(svc.StaticRoot, " \t") {
+			return fmt.Errorf("static_root must be an absolute directory without spaces")
+		}
+		d.Type, d.Target, d.Port, d.SSL = caddy.EntryStatic, "", 0, false
+		d.StaticRoot, d.Browse = svc.StaticRoot, svc.Browse
+
+	default:
+		return fmt.Errorf("unsupported type %q (expected reverse_proxy, redirect or static)", svc.Type)
+	}
+	return nil
+}
+
+// isIPv4 reports whether s is a plain IPv4 address
+func isIPv4(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is4()
+}
+
+// isIPv6 reports whether s is an IPv6 address without a zone
+func isIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is6() && addr.Zone() == ""
+}
+
+// splitDualStack splits an "IPv4, IPv6" dual-stack target
+func splitDualStack(target string) (ipv4, ipv6 string, ok bool) {
+	parts := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
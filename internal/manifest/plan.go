@@ -0,0 +1,241 @@
+package manifest
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+)
+
+// Plan compares the manifest against the current entries (from diff.Compare)
+// and returns the creates, updates and deletes needed to reach the desired state
+func Plan(m *Manifest, cfg *config.Config, entries []diff.SyncedEntry) ([]Change, error) {
+	desired, err := m.Resolve(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index current entries by lowercase domain
+	entryMap := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		entryMap[strings.ToLower(entry.Domain)] = i
+	}
+
+	var changes []Change
+	matched := make(map[int]bool)
+
+	for i := range desired {
+		d := desired[i]
+		idx, ok := entryMap[d.FQDN]
+		if !ok {
+			changes = append(changes, Change{
+				Action:  ActionCreate,
+				Domain:  d.FQDN,
+				Desired: &d,
+				Fields:  createFields(d),
+			})
+			continue
+		}
+
+		matched[idx] = true
+		entry := entries[idx]
+		if fields := CompareFields(entry, d); len(fields) > 0 {
+			changes = append(changes, Change{
+				Action:  ActionUpdate,
+				Domain:  d.FQDN,
+				Desired: &d,
+				Entry:   &entry,
+				Fields:  fields,
+			})
+		}
+	}
+
+	// Prune: delete anything the manifest doesn't mention
+	if m.Prune {
+		var deletes []Change
+		for i := range entries {
+			// Unmanaged records belong to someone else; ignored entries are never touched
+			if matched[i] || entries[i].Status == diff.StatusUnmanagedDNS || entries[i].Status == diff.StatusIgnored {
+				continue
+			}
+			entry := entries[i]
+			deletes = append(deletes, Change{
+				Action: ActionDelete,
+				Domain: entry.Domain,
+				Entry:  &entry,
+			})
+		}
+		sort.Slice(deletes, func(i, j int) bool {
+			return deletes[i].Domain < deletes[j].Domain
+		})
+		changes = append(changes, deletes...)
+	}
+
+	return changes, nil
+}
+
+// CompareFields returns the field-level differences between an entry and its desired state
+func CompareFields(entry diff.SyncedEntry, d Desired) []FieldChange {
+	var fields []FieldChange
+
+	// DNS side
+	if entry.DNS == nil {
+		fields = append(fields, FieldChange{Field: "dns", Current: "missing", Desired: dnsSummary(d)})
+	} else {
+		if !strings.EqualFold(entry.DNSType(), d.DNSType) {
+			fields = append(fields, FieldChange{Field: "dns_type", Current: entry.DNSType(), Desired: d.DNSType})
+		}
+		if normalizeTarget(entry.DNSContent()) != normalizeTarget(d.DNSTarget) {
+			fields = append(fields, FieldChange{Field: "dns_target", Current: entry.DNSContent(), Desired: d.DNSTarget})
+		}
+		if entry.DNS.Proxied != d.Proxied {
+			fields = append(fields, FieldChange{Field: "proxied", Current: fmt.Sprint(entry.DNS.Proxied), Desired: fmt.Sprint(d.Proxied)})
+		}
+	}
+
+	// Caddy side
+	switch {
+	case d.DNSOnly && entry.Caddy != nil:
+		fields = append(fields, FieldChange{Field: "caddy", Current: entry.Caddy.Destination(), Desired: "none (dns_only)"})
+	case !d.DNSOnly && entry.Caddy == nil:
+		fields = append(fields, FieldChange{Field: "caddy", Current: "missing", Desired: caddySummary(d)})
+	case !d.DNSOnly:
+		if entry.Caddy.Type != d.Type {
+			fields = append(fields, FieldChange{Field: "type", Current: entry.Caddy.Type.String(), Desired: d.Type.String()})
+		}
+		fields = append(fields, compareEntryType(*entry.Caddy, d)...)
+		current, want := sortedCopy(entry.Caddy.Imports), sortedCopy(d.Snippets)
+		if strings.Join(current, ",") != strings.Join(want, ",") {
+			fields = append(fields, FieldChange{Field: "snippets", Current: strings.Join(current, ", "), Desired: strings.Join(want, ", ")})
+		}
+	}
+
+	return fields
+}
+
+// compareEntryType returns the differences in the settings of the desired
+// entry type: upstreams of a reverse proxy, the URL of a redirect or the
+// directory of a static site
+func compareEntryType(current caddy.CaddyEntry, d Desired) []FieldChange {
+	var fields []FieldChange
+	add := func(field, current, desired string) {
+		if current != desired {
+			fields = append(fields, FieldChange{Field: field, Current: current, Desired: desired})
+		}
+	}
+	want := desiredEntry(d)
+
+	switch d.Type {
+	case caddy.EntryRedirect:
+		add("redirect_to", current.Destination(), want.Destination())
+		add("redirect_code", fmt.Sprint(current.RedirectCode), fmt.Sprint(d.RedirectCode))
+	case caddy.EntryStatic:
+		add("static_root", current.StaticRoot, d.StaticRoot)
+		add("browse", fmt.Sprint(current.StaticBrowse), fmt.Sprint(d.Browse))
+	default:
+		if normalizeHost(current.Target) != normalizeHost(d.Target) {
+			fields = append(fields, FieldChange{Field: "target", Current: current.Target, Desired: d.Target})
+		}
+		add("port", fmt.Sprint(current.Port), fmt.Sprint(d.Port))
+		add("ssl", fmt.Sprint(current.SSL), fmt.Sprint(d.SSL))
+		add("upstreams", strings.Join(current.UpstreamURLs()[1:], " "), strings.Join(want.UpstreamURLs()[1:], " "))
+		add("load_balancing", strings.Join(current.LoadBalancing.Directives(), "; "), strings.Join(d.LoadBalancing.Directives(), "; "))
+		add("routes", strings.ReplaceAll(caddy.FormatPathRoutes(current.Routes), "\n", "; "), strings.ReplaceAll(caddy.FormatPathRoutes(d.Routes), "\n", "; "))
+	}
+	return fields
+}
+
+// desiredEntry returns the Caddy settings of a desired entry as a CaddyEntry
+func desiredEntry(d Desired) caddy.CaddyEntry {
+	return caddy.CaddyEntry{
+		Type:                 d.Type,
+		Target:               d.Target,
+		Port:                 d.Port,
+		SSL:                  d.SSL,
+		Routes:               d.Routes,
+		Upstreams:            d.Upstreams,
+		LoadBalancing:        d.LoadBalancing,
+		RedirectTo:           d.RedirectTo,
+		RedirectCode:         d.RedirectCode,
+		RedirectPreservePath: d.PreservePath,
+		StaticRoot:           d.StaticRoot,
+		StaticBrowse:         d.Browse,
+	}
+}
+
+// createFields lists the values a create will set
+func createFields(d Desired) []FieldChange {
+	fields := []FieldChange{{Field: "dns", Current: "", Desired: dnsSummary(d)}}
+	if !d.DNSOnly {
+		fields = append(fields, FieldChange{Field: "caddy", Current: "", Desired: caddySummary(d)})
+		if len(d.Snippets) > 0 {
+			fields = append(fields, FieldChange{Field: "snippets", Current: "", Desired: strings.Join(sortedCopy(d.Snippets), ", ")})
+		}
+	}
+	return fields
+}
+
+// dnsSummary formats a desired DNS record for display
+func dnsSummary(d Desired) string {
+	s := d.DNSType + " " + d.DNSTarget
+	if d.Proxied {
+		s += " (proxied)"
+	}
+	return s
+}
+
+// caddySummary formats where a desired entry sends requests, for display
+func caddySummary(d Desired) string {
+	summary := desiredEntry(d).Destination()
+	if d.Type != caddy.EntryProxy {
+		summary = strings.ToLower(d.Type.String()) + " " + summary
+	}
+	return summary
+}
+
+// normalizeHost lowercases a hostname and strips any trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// normalizeTarget normalizes a DNS target for comparison. IP addresses are
+// canonicalized so "2001:DB8:0::5" matches "2001:db8::5".
+func normalizeTarget(target string) string {
+	parts := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for i, part := range parts {
+		if addr, err := netip.ParseAddr(part); err == nil {
+			parts[i] = addr.String()
+		} else {
+			parts[i] = normalizeHost(part)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortedCopy returns a sorted copy of a string slice
+func sortedCopy(in []string) []string {
+	out := append([]string{}, in...)
+	sort.Strings(out)
+	return out
+}
+
+// Summary counts the changes by action
+func Summary(changes []Change) (create, update, del int) {
+	for _, c := range changes {
+		switch c.Action {
+		case ActionCreate:
+			create++
+		case ActionUpdate:
+			update++
+		case ActionDelete:
+			del++
+		}
+	}
+	return create, update, del
+}
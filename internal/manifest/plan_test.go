@@ -0,0 +1,350 @@
+package manifest
+
+import (
+	"testing"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Domain: "example.com",
+		Defaults: config.DefaultsConfig{
+			CNAMETarget: "home.example.com",
+			Proxied:     true,
+			Port:        80,
+		},
+	}
+}
+
+func TestParseAndResolveDefaults(t *testing.T) {
+	m, err := Parse([]byte(`
+services:
+  - subdomain: app
+    port: 8080
+  - subdomain: nas.example.com
+    dns_type: a
+    dns_target: 10.0.0.5
+    proxied: false
+    dns_only: true
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	desired, err := m.Resolve(testConfig())
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(desired) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(desired))
+	}
+
+	app := desired[0]
+	if app.FQDN != "app.example.com" || app.DNSType != "CNAME" || app.DNSTarget != "home.example.com" ||
+		!app.Proxied || app.Target != "localhost" || app.Port != 8080 {
+		t.Errorf("Unexpected defaults for app: %+v", app)
+	}
+
+	nas := desired[1]
+	if nas.Subdomain != "nas" || nas.DNSType != "A" || nas.Proxied || !nas.DNSOnly {
+		t.Errorf("Unexpected values for nas: %+v", nas)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"missing subdomain", "services:\n  - port: 80\n"},
+		{"duplicate", "services:\n  - subdomain: app\n  - subdomain: app.example.com\n"},
+		{"bad A record", "services:\n  - subdomain: app\n    dns_type: A\n    dns_target: nope\n"},
+		{"unsupported type", "services:\n  - subdomain: app\n    dns_type: MX\n"},
+		{"apex", "services:\n  - subdomain: example.com\n"},
+		{"IPv4 in AAAA record", "services:\n  - subdomain: app\n    dns_type: AAAA\n    dns_target: 10.0.0.5\n"},
+		{"dual-stack missing IPv6", "services:\n  - subdomain: app\n    dns_type: A+AAAA\n    dns_target: 10.0.0.5\n"},
+		{"unknown entry type", "services:\n  - subdomain: app\n    type: fastcgi\n"},
+		{"redirect with port", "services:\n  - subdomain: app\n    redirect_to: https://example.org\n    port: 80\n"},
+		{"relative redirect", "services:\n  - subdomain: app\n    redirect_to: /elsewhere\n"},
+		{"bad redirect code", "services:\n  - subdomain: app\n    redirect_to: https://example.org\n    redirect_code: 200\n"},
+		{"relative static root", "services:\n  - subdomain: app\n    static_root: www\n"},
+		{"static root on proxy", "services:\n  - subdomain: app\n    type: reverse_proxy\n    static_root: /srv/www\n"},
+		{"bad route", "services:\n  - subdomain: app\n    routes: [\"api 10.0.0.5:80\"]\n"},
+		{"bad upstream", "services:\n  - subdomain: app\n    upstreams: [\"ftp://10.0.0.5\"]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if _, err := m.Resolve(testConfig()); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	entries := []diff.SyncedEntry{
+		{
+			Domain: "app.example.com",
+			DNS:    &cloudflare.DNSRecord{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "home.example.com", Proxied: true},
+			Caddy:  &caddy.CaddyEntry{Domain: "app.example.com", Target: "localhost", Port: 8080},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "api.example.com",
+			DNS:    &cloudflare.DNSRecord{ID: "2", Type: "CNAME", Name: "api.example.com", Content: "home.example.com", Proxied: false},
+			Caddy:  &caddy.CaddyEntry{Domain: "api.example.com", Target: "localhost", Port: 3000},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "old.example.com",
+			DNS:    &cloudflare.DNSRecord{ID: "3", Type: "CNAME", Name: "old.example.com", Content: "home.example.com"},
+			Status: diff.StatusOrphanedDNS,
+		},
+	}
+
+	m, err := Parse([]byte(`
+prune: true
+services:
+  - subdomain: app
+    port: 8080
+  - subdomain: api
+    port: 3001
+  - subdomain: new
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	changes, err := Plan(m, testConfig(), entries)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	create, update, del := Summary(changes)
+	if create != 1 || update != 1 || del != 1 {
+		t.Fatalf("Expected 1/1/1 changes, got %d/%d/%d: %+v", create, update, del, changes)
+	}
+
+	for _, c := range changes {
+		switch c.Action {
+		case ActionCreate:
+			if c.Domain != "new.example.com" {
+				t.Errorf("Unexpected create for %s", c.Domain)
+			}
+		case ActionUpdate:
+			if c.Domain != "api.example.com" {
+				t.Errorf("Unexpected update for %s", c.Domain)
+			}
+			fields := map[string]FieldChange{}
+			for _, f := range c.Fields {
+				fields[f.Field] = f
+			}
+			if len(fields) != 2 || fields["proxied"].Desired != "true" || fields["port"].Desired != "3001" {
+				t.Errorf("Unexpected update fields: %+v", c.Fields)
+			}
+		case ActionDelete:
+			if c.Domain != "old.example.com" {
+				t.Errorf("Unexpected delete for %s", c.Domain)
+			}
+		}
+	}
+}
+
+func TestPlanEntryTypes(t *testing.T) {
+	dns := func(name string) *cloudflare.DNSRecord {
+		return &cloudflare.DNSRecord{Type: "CNAME", Name: name, Content: "home.example.com", Proxied: true}
+	}
+	entries := []diff.SyncedEntry{
+		{
+			Domain: "go.example.com",
+			DNS:    dns("go.example.com"),
+			Caddy: &caddy.CaddyEntry{Type: caddy.EntryRedirect, RedirectTo: "https://example.org",
+				RedirectCode: 301, RedirectPreservePath: true},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "www.example.com",
+			DNS:    dns("www.example.com"),
+			Caddy:  &caddy.CaddyEntry{Type: caddy.EntryStatic, StaticRoot: "/srv/www"},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "app.example.com",
+			DNS:    dns("app.example.com"),
+			Caddy: &caddy.CaddyEntry{Target: "10.0.0.5", Port: 8080, Upstreams: []string{"10.0.0.6:8080"},
+				Routes: []caddy.PathRoute{{Path: "/api/*", Target: "10.0.0.7", Port: 3000, StripPrefix: true}}},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "old.example.com",
+			DNS:    dns("old.example.com"),
+			Caddy:  &caddy.CaddyEntry{Target: "localhost", Port: 80},
+			Status: diff.StatusSynced,
+		},
+		{
+			Domain: "lb.example.com",
+			DNS:    dns("lb.example.com"),
+			Caddy:  &caddy.CaddyEntry{Target: "10.0.0.5", Port: 8080, Upstreams: []string{"10.0.0.6:8080"}},
+			Status: diff.StatusSynced,
+		},
+	}
+
+	m, err := Parse([]byte(`
+services:
+  - subdomain: go
+    redirect_to: https://example.org/
+  - subdomain: www
+    type: static
+    static_root: /srv/www
+  - subdomain: app
+    target: 10.0.0.5
+    port: 8080
+    upstreams: ["10.0.0.6:8080"]
+    routes: ["/api/* 10.0.0.7:3000 strip"]
+  - subdomain: old
+    redirect_to: https://example.org
+    redirect_code: 302
+  - subdomain: lb
+    target: 10.0.0.5
+    port: 8080
+    upstreams: ["10.0.0.6:8080", "https://10.0.0.7:8443"]
+    load_balancing: ["lb_policy least_conn"]
+    routes: ["/admin/* 10.0.0.8:9000"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	changes, err := Plan(m, testConfig(), entries)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	// Matching redirects, static sites and routed proxies converge
+	updates := map[string]map[string]FieldChange{}
+	for _, c := range changes {
+		if c.Action != ActionUpdate {
+			t.Fatalf("Unexpected %s for %s", c.Action, c.Domain)
+		}
+		fields := map[string]FieldChange{}
+		for _, f := range c.Fields {
+			fields[f.Field] = f
+		}
+		updates[c.Domain] = fields
+	}
+	if len(updates) != 2 {
+		t.Fatalf("Expected updates for old and lb only, got %+v", changes)
+	}
+
+	old := updates["old.example.com"]
+	if old["type"].Desired != "Redirect" || old["redirect_to"].Desired != "https://example.org{uri}" ||
+		old["redirect_code"].Desired != "302" || len(old) != 3 {
+		t.Errorf("Unexpected type change fields: %+v", old)
+	}
+
+	lb := updates["lb.example.com"]
+	if lb["upstreams"].Desired != "http://10.0.0.6:8080 https://10.0.0.7:8443" ||
+		lb["load_balancing"].Desired != "lb_policy least_conn" ||
+		lb["routes"].Desired != "/admin/* http://10.0.0.8:9000" || len(lb) != 3 {
+		t.Errorf("Unexpected upstream fields: %+v", lb)
+	}
+}
+
+func TestPlanWithoutPruneKeepsUnlisted(t *testing.T) {
+	entries := []diff.SyncedEntry{
+		{Domain: "old.example.com", DNS: &cloudflare.DNSRecord{Type: "CNAME"}, Status: diff.StatusOrphanedDNS},
+	}
+	changes, err := Plan(&Manifest{}, testConfig(), entries)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes without prune, got %+v", changes)
+	}
+}
+
+func TestCompareFieldsMissingSides(t *testing.T) {
+	d := Desired{FQDN: "app.example.com", DNSType: "CNAME", DNSTarget: "home.example.com", Target: "localhost", Port: 80}
+
+	orphanCaddy := diff.SyncedEntry{
+		Domain: "app.example.com",
+		Caddy:  &caddy.CaddyEntry{Target: "localhost", Port: 80},
+		Status: diff.StatusOrphanedCaddy,
+	}
+	fields := CompareFields(orphanCaddy, d)
+	if len(fields) != 1 || fields[0].Field != "dns" {
+		t.Errorf("Expected only missing dns, got %+v", fields)
+	}
+
+	orphanDNS := diff.SyncedEntry{
+		Domain: "app.example.com",
+		DNS:    &cloudflare.DNSRecord{Type: "CNAME", Content: "HOME.example.com."},
+		Status: diff.StatusOrphanedDNS,
+	}
+	fields = CompareFields(orphanDNS, d)
+	if len(fields) != 1 || fields[0].Field != "caddy" {
+		t.Errorf("Expected only missing caddy, got %+v", fields)
+	}
+}
+
+func TestPlanDualStack(t *testing.T) {
+	entries := []diff.SyncedEntry{
+		{
+			Domain:  "app.example.com",
+			DNS:     &cloudflare.DNSRecord{Type: "A", Content: "10.0.0.5"},
+			DNSAAAA: &cloudflare.DNSRecord{Type: "AAAA", Content: "2001:db8::5"},
+			Caddy:   &caddy.CaddyEntry{Target: "localhost", Port: 80},
+			Status:  diff.StatusSynced,
+		},
+	}
+
+	m, err := Parse([]byte(`
+services:
+  - subdomain: app
+    dns_type: a+aaaa
+    dns_target: "10.0.0.5,2001:DB8:0::5"
+    proxied: false
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	changes, err := Plan(m, testConfig(), entries)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected dual-stack entry to match, got %+v", changes)
+	}
+}
+
+func TestResolveMultiZone(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cloudflare.Zones = []config.ZoneConfig{{ZoneID: "22222222222222222222222222222222", Domain: "example.net"}}
+
+	m, err := Parse([]byte("services:\n  - subdomain: app\n  - subdomain: bar.example.net\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	desired, err := m.Resolve(cfg)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if desired[0].FQDN != "app.example.com" || desired[1].FQDN != "bar.example.net" || desired[1].Subdomain != "bar.example.net" {
+		t.Errorf("Unexpected resolution: %+v", desired)
+	}
+
+	apex, _ := Parse([]byte("services:\n  - subdomain: example.net\n"))
+	if _, err := apex.Resolve(cfg); err == nil {
+		t.Error("Expected error for additional zone apex")
+	}
+}
@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/diff"
+)
+
+// Manifest is the declarative desired state for a profile's entries
+type Manifest struct {
+	// Prune deletes entries (DNS + Caddy) that are not listed in Services
+	Prune    bool      `yaml:"prune,omitempty"`
+	Services []Service `yaml:"services"`
+}
+
+// Service describes one desired entry (DNS record + optional Caddy block)
+// Unset optional fields fall back to the profile defaults, like the add form
+type Service struct {
+	Subdomain string   `yaml:"subdomain"`            // Subdomain or FQDN under the profile domain
+	DNSType   string   `yaml:"dns_type,omitempty"`   // "CNAME" (default), "A", "AAAA" or "A+AAAA"
+	DNSTarget string   `yaml:"dns_target,omitempty"` // CNAME target, record IP, or "IPv4, IPv6" for A+AAAA (default: defaults.cname_target)
+	Proxied   *bool    `yaml:"proxied,omitempty"`    // Cloudflare proxy (default: defaults.proxied)
+	DNSOnly   bool     `yaml:"dns_only,omitempty"`   // DNS record only, no Caddy block
+	Target    string   `yaml:"target,omitempty"`     // Reverse proxy upstream host (default: localhost)
+	Port      int      `yaml:"port,omitempty"`       // Upstream port (default: defaults.port)
+	SSL       *bool    `yaml:"ssl,omitempty"`        // HTTPS upstream (default: defaults.ssl)
+	Snippets  []string `yaml:"snippets,omitempty"`   // Snippet names to import
+
+	// What the Caddy block does: "reverse_proxy" (default), "redirect" or
+	// "static". Without a type, redirect_to makes a redirect and static_root
+	// a static site.
+	Type string `yaml:"type,omitempty"`
+
+	// Reverse proxy: path routes ("<path> <upstream> [strip] [+snippet...]"),
+	// further upstreams load balanced with target, and load balancing
+	// settings as reverse_proxy subdirectives ("lb_policy least_conn")
+	Routes        []string `yaml:"routes,omitempty"`
+	Upstreams     []string `yaml:"upstreams,omitempty"`
+	LoadBalancing []string `yaml:"load_balancing,omitempty"`
+
+	RedirectTo   string `yaml:"redirect_to,omitempty"`   // Redirect: destination URL
+	RedirectCode int    `yaml:"redirect_code,omitempty"` // Redirect: status code (default 301)
+	PreservePath *bool  `yaml:"preserve_path,omitempty"` // Redirect: append the request's path and query (default true)
+	StaticRoot   string `yaml:"static_root,omitempty"`   // Static site: absolute directory served
+	Browse       bool   `yaml:"browse,omitempty"`        // Static site: list directories without an index file
+}
+
+// Desired is a Service with all defaults resolved
+type Desired struct {
+	Subdomain string // Relative to the profile domain (the FQDN for additional zones)
+	FQDN      string
+	DNSType   string
+	DNSTarget string
+	Proxied   bool
+	DNSOnly   bool
+	Target    string
+	Port      int
+	SSL       bool
+	Snippets  []string
+
+	Type          caddy.EntryType
+	Routes        []caddy.PathRoute
+	Upstreams     []string
+	LoadBalancing caddy.LoadBalancing
+	RedirectTo    string
+	RedirectCode  int
+	PreservePath  bool
+	StaticRoot    string
+	Browse        bool
+}
+
+// Action is the kind of change a plan makes to an entry
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Symbol returns the plan prefix for an action
+func (a Action) Symbol() string {
+	switch a {
+	case ActionCreate:
+		return "+"
+	case ActionUpdate:
+		return "~"
+	case ActionDelete:
+		return "-"
+	default:
+		return "?"
+	}
+}
+
+// FieldChange is a single field that differs between current and desired state
+type FieldChange struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// Change is one planned create, update or delete
+type Change struct {
+	Action  Action
+	Domain  string
+	Desired *Desired          // nil for deletes
+	Entry   *diff.SyncedEntry // nil for creates
+	Fields  []FieldChange     // Field-level differences (updates), or the new values (creates)
+}
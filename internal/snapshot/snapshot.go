@@ -0,0 +1,200 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/dnsprovider"
+)
+
+// Suffix is appended to a Caddyfile backup path to name its DNS snapshot.
+// The caddy package treats <backup>.*.json files as companions of the backup.
+const Suffix = ".dns.json"
+
+// RecordTypes are the record types a snapshot holds: the ones LazyProxyFlare manages
+var RecordTypes = []string{"CNAME", "A", "AAAA"}
+
+// Snapshot is the DNS state of a profile's zones at the time a backup was taken
+type Snapshot struct {
+	TakenAt time.Time   `json:"taken_at"`
+	Zones   []ZoneState `json:"zones"`
+}
+
+// ZoneState holds the managed records of one zone
+type ZoneState struct {
+	ZoneID  string                 `json:"zone_id"`
+	Domain  string                 `json:"domain"`
+	Records []cloudflare.DNSRecord `json:"records"`
+}
+
+// PathFor returns the snapshot path for a Caddyfile backup
+func PathFor(backupPath string) string {
+	return backupPath + Suffix
+}
+
+// Take lists the managed records of every zone
+func Take(ctx context.Context, dns dnsprovider.Records, zones []config.ZoneConfig) (*Snapshot, error) {
+	snap := &Snapshot{TakenAt: time.Now()}
+	for _, zone := range zones {
+		// One listing per zone; the types we don't manage are dropped here
+		records, err := dns.ListDNSRecords(ctx, zone.ZoneID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list DNS records for %s: %w", zone.Domain, err)
+		}
+		state := ZoneState{ZoneID: zone.ZoneID, Domain: zone.Domain, Records: []cloudflare.DNSRecord{}}
+		for _, record := range records {
+			if slices.Contains(RecordTypes, record.Type) {
+				state.Records = append(state.Records, record)
+			}
+		}
+		snap.Zones = append(snap.Zones, state)
+	}
+	return snap, nil
+}
+
+// Save writes the snapshot as JSON
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode DNS snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write DNS snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads a snapshot written by Save. A missing file is returned as an
+// error matching os.ErrNotExist.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// zone returns the state of a zone, or nil if the snapshot doesn't cover it
+func (s *Snapshot) zone(zoneID string) *ZoneState {
+	for i := range s.Zones {
+		if s.Zones[i].ZoneID == zoneID {
+			return &s.Zones[i]
+		}
+	}
+	return nil
+}
+
+// Action is what a restore does to one record
+type Action string
+
+const (
+	ActionCreate Action = "create" // Record was deleted since the snapshot
+	ActionUpdate Action = "update" // Record was changed since the snapshot
+	ActionDelete Action = "delete" // Record was created since the snapshot
+)
+
+// Change is one record a restore touches
+type Change struct {
+	Action  Action
+	ZoneID  string
+	Current *cloudflare.DNSRecord // Record as it is now (update, delete)
+	Saved   *cloudflare.DNSRecord // Record as it was in the snapshot (create, update), with the ID to write to
+}
+
+// PlanOptions controls which records a restore touches
+type PlanOptions struct {
+	// DeleteNew deletes records created since the snapshot. Records that
+	// conflict with a restored record (a CNAME and an A/AAAA of the same
+	// name) are always deleted.
+	DeleteNew bool
+
+	// Deletable limits which new records DeleteNew removes (nil: all)
+	Deletable func(cloudflare.DNSRecord) bool
+
+	// Skip leaves records out of the restore entirely (e.g., ignored names)
+	Skip func(cloudflare.DNSRecord) bool
+}
+
+// Plan compares the snapshot with the current records and returns the
+// changes that put the snapshot back. Zones missing from either side are
+// left alone. Records are matched by ID, then by type and name.
+func (s *Snapshot) Plan(current *Snapshot, opts PlanOptions) []Change {
+	skip := func(r cloudflare.DNSRecord) bool { return opts.Skip != nil && opts.Skip(r) }
+
+	var changes []Change
+	for _, now := range current.Zones {
+		saved := s.zone(now.ZoneID)
+		if saved == nil {
+			continue
+		}
+
+		used := make([]bool, len(now.Records))
+		match := func(want cloudflare.DNSRecord) int {
+			best := -1
+			for i, r := range now.Records {
+				if used[i] || r.Type != want.Type || !sameName(r.Name, want.Name) {
+					continue
+				}
+				if r.ID == want.ID || r.Content == want.Content {
+					return i
+				}
+				if best < 0 {
+					best = i
+				}
+			}
+			return best
+		}
+
+		var created []cloudflare.DNSRecord // Records being recreated
+		for _, record := range saved.Records {
+			if skip(record) {
+				continue
+			}
+			i := match(record)
+			if i < 0 {
+				record.ID = ""
+				changes = append(changes, Change{Action: ActionCreate, ZoneID: now.ZoneID, Saved: &record})
+				created = append(created, record)
+				continue
+			}
+			used[i] = true
+			cur := now.Records[i]
+			if cur.Content == record.Content && cur.Proxied == record.Proxied && cur.TTL == record.TTL && cur.Comment == record.Comment {
+				continue
+			}
+			record.ID = cur.ID
+			changes = append(changes, Change{Action: ActionUpdate, ZoneID: now.ZoneID, Current: &cur, Saved: &record})
+		}
+
+		for i, cur := range now.Records {
+			if used[i] || skip(cur) {
+				continue
+			}
+			// A CNAME can't share its name with any other record
+			conflicts := slices.ContainsFunc(created, func(r cloudflare.DNSRecord) bool {
+				return sameName(r.Name, cur.Name) && (r.Type == "CNAME" || cur.Type == "CNAME")
+			})
+			deletable := opts.DeleteNew && (opts.Deletable == nil || opts.Deletable(cur))
+			if conflicts || deletable {
+				changes = append(changes, Change{Action: ActionDelete, ZoneID: now.ZoneID, Current: &cur})
+			}
+		}
+	}
+	return changes
+}
+
+// sameName compares DNS names ignoring case and a trailing dot
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
@@ -0,0 +1,115 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+)
+
+// listDNS serves fixed records per zone
+type listDNS struct {
+	cloudflare.DNSClient
+	records map[string][]cloudflare.DNSRecord
+}
+
+func (f *listDNS) ListDNSRecords(ctx context.Context, zoneID, recordType string) ([]cloudflare.DNSRecord, error) {
+	return f.records[zoneID], nil
+}
+
+func TestTakeSaveLoad(t *testing.T) {
+	dns := &listDNS{records: map[string][]cloudflare.DNSRecord{
+		"zone-a": {
+			{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "example.com", Proxied: true, TTL: 1},
+			{ID: "2", Type: "MX", Name: "example.com", Content: "mail.example.com"},
+			{ID: "3", Type: "A", Name: "nas.example.com", Content: "192.168.1.10", TTL: 300},
+		},
+	}}
+	snap, err := Take(context.Background(), dns, []config.ZoneConfig{{ZoneID: "zone-a", Domain: "example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(snap.Zones[0].Records); got != 2 {
+		t.Fatalf("expected the MX record dropped, got %d records", got)
+	}
+
+	path := PathFor(filepath.Join(t.TempDir(), "Caddyfile.backup.20240101_120000"))
+	if err := snap.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := loaded.Zones[0].Records[1]; r.Content != "192.168.1.10" || r.TTL != 300 {
+		t.Errorf("record not preserved: %+v", r)
+	}
+
+	if _, err := Load(path + ".missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist for a missing snapshot, got %v", err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	saved := &Snapshot{Zones: []ZoneState{{ZoneID: "zone-a", Records: []cloudflare.DNSRecord{
+		{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "example.com", Proxied: true, TTL: 1},
+		{ID: "2", Type: "A", Name: "nas.example.com", Content: "192.168.1.10", TTL: 300},
+		{ID: "3", Type: "CNAME", Name: "gone.example.com", Content: "example.com", TTL: 1},
+		{ID: "4", Type: "CNAME", Name: "retyped.example.com", Content: "example.com", TTL: 1},
+		{ID: "5", Type: "CNAME", Name: "mail.example.com", Content: "mx.example.net", TTL: 1},
+	}}}}
+	current := &Snapshot{Zones: []ZoneState{
+		{ZoneID: "zone-a", Records: []cloudflare.DNSRecord{
+			{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "example.com", Proxied: true, TTL: 1},
+			{ID: "2", Type: "A", Name: "nas.example.com", Content: "192.168.1.20", TTL: 300},
+			{ID: "6", Type: "A", Name: "retyped.example.com", Content: "10.0.0.1", TTL: 1},
+			{ID: "7", Type: "CNAME", Name: "new.example.com", Content: "example.com", TTL: 1, Comment: cloudflare.OwnerMarker},
+			{ID: "8", Type: "CNAME", Name: "manual.example.com", Content: "example.com", TTL: 1},
+		}},
+		{ZoneID: "zone-b", Records: []cloudflare.DNSRecord{ // Added after the backup
+			{ID: "9", Type: "CNAME", Name: "app.example.net", Content: "example.net", TTL: 1},
+		}},
+	}}
+
+	count := func(changes []Change) map[Action]int {
+		counts := map[Action]int{}
+		for _, c := range changes {
+			counts[c.Action]++
+		}
+		return counts
+	}
+
+	skipMail := func(r cloudflare.DNSRecord) bool { return r.Name == "mail.example.com" }
+	changes := saved.Plan(current, PlanOptions{Skip: skipMail})
+	// nas is updated; gone and retyped are recreated; the A record in the way
+	// of retyped's CNAME is deleted even without DeleteNew
+	if got := count(changes); got[ActionUpdate] != 1 || got[ActionCreate] != 2 || got[ActionDelete] != 1 {
+		t.Fatalf("unexpected plan: %v", got)
+	}
+	for _, c := range changes {
+		switch c.Action {
+		case ActionUpdate:
+			if c.Saved.ID != "2" || c.Saved.Content != "192.168.1.10" {
+				t.Errorf("update should write the saved content to the current record: %+v", c.Saved)
+			}
+		case ActionCreate:
+			if c.Saved.ID != "" {
+				t.Errorf("created record should not carry the old ID: %+v", c.Saved)
+			}
+		case ActionDelete:
+			if c.Current.ID != "6" {
+				t.Errorf("expected only the conflicting A record deleted, got %+v", c.Current)
+			}
+		}
+	}
+
+	// DeleteNew removes records created since, limited by Deletable
+	changes = saved.Plan(current, PlanOptions{Skip: skipMail, DeleteNew: true, Deletable: cloudflare.DNSRecord.IsOwned})
+	if got := count(changes); got[ActionDelete] != 2 {
+		t.Errorf("expected the conflicting and the new owned record deleted, got %v", got)
+	}
+}
@@ -0,0 +1,261 @@
+package tunnel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CatchAllService answers requests no ingress rule matches. cloudflared
+// requires the last rule to match everything.
+const CatchAllService = "http_status:404"
+
+// IngressRule routes a hostname of the tunnel to a local service
+type IngressRule struct {
+	Hostname         string
+	Path             string // Optional path regex; LazyProxyFlare writes hostname-only rules
+	Service          string // e.g. https://localhost:443 or http://192.168.1.10:8080
+	OriginServerName string // originRequest.originServerName (TLS SNI sent to the service)
+}
+
+// ruleNode is how a rule is written in config.yml
+type ruleNode struct {
+	Hostname      string         `yaml:"hostname,omitempty"`
+	Path          string         `yaml:"path,omitempty"`
+	Service       string         `yaml:"service"`
+	OriginRequest map[string]any `yaml:"originRequest,omitempty"`
+}
+
+// File is a cloudflared config.yml. It is edited as a YAML node tree so
+// settings and comments outside the changed rules are preserved.
+type File struct {
+	root yaml.Node
+}
+
+// Parse reads a cloudflared config
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+	if err := yaml.Unmarshal(data, &f.root); err != nil {
+		return nil, fmt.Errorf("failed to parse cloudflared config: %w", err)
+	}
+	if len(f.root.Content) == 0 {
+		// Empty file: start a new document
+		f.root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if f.root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cloudflared config is not a YAML mapping")
+	}
+	return f, nil
+}
+
+// Load reads the cloudflared config at path
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cloudflared config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Bytes encodes the config
+func (f *File) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&f.root); err != nil {
+		return nil, fmt.Errorf("failed to encode cloudflared config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ingress returns the ingress sequence, creating it if create is set
+func (f *File) ingress(create bool) *yaml.Node {
+	doc := f.root.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "ingress" {
+			return doc.Content[i+1]
+		}
+	}
+	if !create {
+		return nil
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "ingress"}, seq)
+	return seq
+}
+
+// decodeRule reads one rule node
+func decodeRule(node *yaml.Node) (ruleNode, error) {
+	var rule ruleNode
+	err := node.Decode(&rule)
+	return rule, err
+}
+
+// Rules returns the hostname rules, in order. The catch-all rule is left out.
+func (f *File) Rules() []IngressRule {
+	seq := f.ingress(false)
+	if seq == nil {
+		return nil
+	}
+	var rules []IngressRule
+	for _, node := range seq.Content {
+		raw, err := decodeRule(node)
+		if err != nil || raw.Hostname == "" {
+			continue
+		}
+		rule := IngressRule{Hostname: raw.Hostname, Path: raw.Path, Service: raw.Service}
+		if sni, ok := raw.OriginRequest["originServerName"].(string); ok {
+			rule.OriginServerName = sni
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// SetRule adds a rule, or replaces the service of the existing rule for the
+// same hostname and path (keeping its other settings). New rules go before
+// the catch-all rule, which is added if missing.
+func (f *File) SetRule(rule IngressRule) error {
+	seq := f.ingress(true)
+
+	for i, node := range seq.Content {
+		raw, err := decodeRule(node)
+		if err != nil || !strings.EqualFold(raw.Hostname, rule.Hostname) || raw.Path != rule.Path {
+			continue
+		}
+		raw.Service = rule.Service
+		setOriginServerName(&raw, rule.OriginServerName)
+		updated, err := ruleToNode(raw)
+		if err != nil {
+			return err
+		}
+		seq.Content[i] = updated
+		return nil
+	}
+
+	raw := ruleNode{Hostname: rule.Hostname, Path: rule.Path, Service: rule.Service}
+	setOriginServerName(&raw, rule.OriginServerName)
+	node, err := ruleToNode(raw)
+	if err != nil {
+		return err
+	}
+
+	// Insert before the catch-all rule, adding one if missing
+	at := len(seq.Content)
+	hasCatchAll := false
+	if at > 0 {
+		if last, err := decodeRule(seq.Content[at-1]); err == nil && last.Hostname == "" && last.Path == "" {
+			at--
+			hasCatchAll = true
+		}
+	}
+	seq.Content = slices.Insert(seq.Content, at, node)
+	if !hasCatchAll {
+		catchAll, err := ruleToNode(ruleNode{Service: CatchAllService})
+		if err != nil {
+			return err
+		}
+		seq.Content = append(seq.Content, catchAll)
+	}
+	return nil
+}
+
+// RemoveRule removes every rule for hostname. Returns whether any was found.
+func (f *File) RemoveRule(hostname string) bool {
+	seq := f.ingress(false)
+	if seq == nil {
+		return false
+	}
+	kept := seq.Content[:0]
+	for _, node := range seq.Content {
+		if raw, err := decodeRule(node); err == nil && strings.EqualFold(raw.Hostname, hostname) {
+			continue
+		}
+		kept = append(kept, node)
+	}
+	removed := len(kept) != len(seq.Content)
+	seq.Content = kept
+	return removed
+}
+
+// setOriginServerName sets or clears originRequest.originServerName
+func setOriginServerName(raw *ruleNode, name string) {
+	if name != "" {
+		if raw.OriginRequest == nil {
+			raw.OriginRequest = map[string]any{}
+		}
+		raw.OriginRequest["originServerName"] = name
+	} else if raw.OriginRequest != nil {
+		delete(raw.OriginRequest, "originServerName")
+	}
+}
+
+// ruleToNode encodes a rule as a YAML node
+func ruleToNode(raw ruleNode) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(raw); err != nil {
+		return nil, fmt.Errorf("failed to encode ingress rule: %w", err)
+	}
+	return node, nil
+}
+
+// Edit loads the config at path, applies edit and writes it back with the
+// original permissions. Nothing is written if loading or the edit fails.
+func Edit(path string, edit func(*File) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat cloudflared config: %w", err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(f); err != nil {
+		return err
+	}
+	data, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write cloudflared config: %w", err)
+	}
+	return nil
+}
+
+// Backup copies the config to <path>.bak, replacing the previous backup.
+// Returns the backup path.
+func Backup(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat cloudflared config: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cloudflared config: %w", err)
+	}
+	backupPath := path + ".bak"
+	if err := os.WriteFile(backupPath, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up cloudflared config: %w", err)
+	}
+	return backupPath, nil
+}
+
+// Restart runs the command that makes cloudflared pick up config changes.
+// An empty command does nothing. Returns the command output.
+func Restart(command string) (string, error) {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return "", nil
+	}
+	output, err := exec.Command(parts[0], parts[1:]...).CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("cloudflared restart failed: %s\nCommand: %s\nOutput: %s", err, command, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
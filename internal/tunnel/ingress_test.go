@@ -0,0 +1,97 @@
+package tunnel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `# Managed in part by LazyProxyFlare
+tunnel: 6ff42ae2-765d-4adf-8112-31c55c1551ef
+credentials-file: /etc/cloudflared/creds.json
+ingress:
+  - hostname: grafana.example.com
+    service: http://localhost:3000
+    originRequest:
+      noTLSVerify: true
+  - service: http_status:404
+`
+
+func TestSetRule(t *testing.T) {
+	f, err := Parse([]byte(sampleConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.SetRule(IngressRule{Hostname: "app.example.com", Service: "https://localhost:443", OriginServerName: "app.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	// Replacing keeps the rule's other settings
+	if err := f.SetRule(IngressRule{Hostname: "Grafana.example.com", Service: "http://localhost:3001"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := f.Rules()
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 hostname rules, got %+v", rules)
+	}
+	if rules[0].Service != "http://localhost:3001" || rules[1].OriginServerName != "app.example.com" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	data, err := f.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{"# Managed in part by LazyProxyFlare", "credentials-file:", "noTLSVerify: true"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q preserved in:\n%s", want, out)
+		}
+	}
+	// The catch-all rule stays last
+	if !strings.HasSuffix(strings.TrimSpace(out), "- service: http_status:404") {
+		t.Errorf("catch-all rule is not last:\n%s", out)
+	}
+}
+
+func TestSetRuleAddsCatchAll(t *testing.T) {
+	f, err := Parse([]byte("tunnel: 6ff42ae2-765d-4adf-8112-31c55c1551ef\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetRule(IngressRule{Hostname: "app.example.com", Service: "http://localhost:8080"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := f.Bytes()
+	if !strings.Contains(string(data), CatchAllService) {
+		t.Errorf("expected a catch-all rule to be added:\n%s", data)
+	}
+}
+
+func TestEditRemoveRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	os.WriteFile(path, []byte(sampleConfig), 0640)
+
+	err := Edit(path, func(f *File) error {
+		if !f.RemoveRule("grafana.example.com") {
+			t.Error("expected the rule to be found")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := f.Rules(); len(rules) != 0 {
+		t.Errorf("expected no hostname rules left, got %+v", rules)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("permissions not preserved: %v", info.Mode().Perm())
+	}
+}
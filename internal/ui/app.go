@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"net/netip"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -85,6 +86,30 @@ func isValidIPAddress(ip string) bool {
 	return true
 }
 
+// isValidIPv6Address validates IPv6 address format (no zone identifiers)
+func isValidIPv6Address(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return addr.Is6() && addr.Zone() == ""
+}
+
+// splitDualStackTarget splits an "IPv4, IPv6" dual-stack DNS target into its addresses
+func splitDualStackTarget(target string) (ipv4, ipv6 string) {
+	parts := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for _, part := range parts {
+		if strings.Contains(part, ":") {
+			ipv6 = part
+		} else {
+			ipv4 = part
+		}
+	}
+	return ipv4, ipv6
+}
+
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/snapshot"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -377,15 +379,14 @@ func (m Model) renderConfirmRestoreView() string {
 	b.WriteString("This will:\n")
 	switch m.backup.RestoreScope {
 	case RestoreAll:
-		b.WriteString("  1. Create a new backup of your current Caddyfile\n")
+		b.WriteString("  1. Create a new backup of your current Caddyfile and DNS records\n")
 		b.WriteString("  2. Replace your current Caddyfile with the backup\n")
 		b.WriteString("  3. Validate the restored configuration\n")
 		b.WriteString("  4. Restart Caddy\n")
-		b.WriteString("  5. Update DNS records to match the backup\n")
+		b.WriteString("  5. Apply the DNS changes below\n")
 	case RestoreDNSOnly:
-		b.WriteString("  1. Parse domains from backup Caddyfile\n")
-		b.WriteString("  2. Create/update DNS records for each domain\n")
-		b.WriteString("  3. Leave current Caddyfile unchanged\n")
+		b.WriteString("  1. Apply the DNS changes below\n")
+		b.WriteString("  2. Leave current Caddyfile unchanged\n")
 	case RestoreCaddyOnly:
 		b.WriteString("  1. Create a new backup of your current Caddyfile\n")
 		b.WriteString("  2. Replace your current Caddyfile with the backup\n")
@@ -395,8 +396,15 @@ func (m Model) renderConfirmRestoreView() string {
 	}
 	b.WriteString("\n")
 
+	if m.backup.RestoreScope != RestoreCaddyOnly && m.backup.RestorePlanned {
+		b.WriteString(m.renderRestorePlan())
+		b.WriteString("\n")
+	}
+
 	// Status/Error display
-	if m.loading {
+	if m.loading && m.backup.RestoreScope != RestoreCaddyOnly && !m.backup.RestorePlanned {
+		b.WriteString(StyleInfo.Render("⟳ Comparing the backup's DNS snapshot with current records..."))
+	} else if m.loading {
 		b.WriteString(StyleInfo.Render("⟳ Restoring backup..."))
 	} else if m.err != nil {
 		b.WriteString(StyleError.Render(fmt.Sprintf("✗ Error: %v", m.err)))
@@ -407,12 +415,73 @@ func (m Model) renderConfirmRestoreView() string {
 
 	// Instructions
 	if !m.loading {
-		b.WriteString(StyleDim.Render("Confirm: y  Cancel: n/esc"))
+		if m.backup.RestoreScope != RestoreCaddyOnly {
+			b.WriteString(StyleDim.Render("Confirm: y  Delete new records: space  Cancel: n/esc"))
+		} else {
+			b.WriteString(StyleDim.Render("Confirm: y  Cancel: n/esc"))
+		}
 	}
 
 	return b.String()
 }
 
+// maxRestorePlanLines limits the DNS changes listed in the restore confirmation
+const maxRestorePlanLines = 12
+
+// renderRestorePlan lists the DNS changes of a restore
+func (m Model) renderRestorePlan() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("DNS changes (snapshot from %s):\n", m.backup.RestoreSnapshotAt.Format("2006-01-02 15:04:05")))
+	if len(m.backup.RestorePlan) == 0 {
+		b.WriteString(StyleDim.Render("  DNS records already match the backup"))
+		b.WriteString("\n")
+	}
+	for i, change := range m.backup.RestorePlan {
+		if i == maxRestorePlanLines {
+			b.WriteString(StyleDim.Render(fmt.Sprintf("  ... and %d more", len(m.backup.RestorePlan)-i)))
+			b.WriteString("\n")
+			break
+		}
+		switch change.Action {
+		case snapshot.ActionCreate:
+			r := change.Saved
+			b.WriteString(StyleSuccess.Render(fmt.Sprintf("  + %s %s → %s", r.Type, r.Name, r.Content)))
+		case snapshot.ActionUpdate:
+			b.WriteString(StyleWarning.Render(fmt.Sprintf("  ~ %s %s: %s", change.Saved.Type, change.Saved.Name, describeRecordChange(*change.Current, *change.Saved))))
+		case snapshot.ActionDelete:
+			r := change.Current
+			b.WriteString(StyleError.Render(fmt.Sprintf("  - %s %s → %s", r.Type, r.Name, r.Content)))
+		}
+		b.WriteString("\n")
+	}
+
+	checkbox := "[ ]"
+	if m.backup.RestoreDeleteNew {
+		checkbox = "[x]"
+	}
+	b.WriteString(fmt.Sprintf("\n%s Delete records created since the backup\n", checkbox))
+	return b.String()
+}
+
+// describeRecordChange lists the fields a restore changes on a record
+func describeRecordChange(current, saved cloudflare.DNSRecord) string {
+	var parts []string
+	if current.Content != saved.Content {
+		parts = append(parts, fmt.Sprintf("%s → %s", current.Content, saved.Content))
+	}
+	if current.Proxied != saved.Proxied {
+		parts = append(parts, fmt.Sprintf("proxied %v → %v", current.Proxied, saved.Proxied))
+	}
+	if current.TTL != saved.TTL {
+		parts = append(parts, fmt.Sprintf("TTL %d → %d", current.TTL, saved.TTL))
+	}
+	if current.Comment != saved.Comment {
+		parts = append(parts, fmt.Sprintf("comment %q → %q", current.Comment, saved.Comment))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // renderConfirmCleanupView renders the cleanup old backups confirmation screen
 func (m Model) renderConfirmCleanupView() string {
 	var b strings.Builder
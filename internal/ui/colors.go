@@ -41,6 +41,7 @@ var (
 	// Status Icon Styles
 	StyleIconSynced = lipgloss.NewStyle().Foreground(ColorGreen)
 	StyleIconOrphan = lipgloss.NewStyle().Foreground(ColorOrange)
+	StyleIconDrift  = lipgloss.NewStyle().Foreground(ColorYellow)
 
 	// Selection/Highlight Style
 	StyleSelected = lipgloss.NewStyle().
@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,13 +15,17 @@ import (
 	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
+	"lazyproxyflare/internal/journal"
+	"lazyproxyflare/internal/snapshot"
 )
 
 type restoreBackupMsg struct {
-	success    bool
-	err        error
-	scope      RestoreScope // What was restored (All/DNS/Caddy)
-	backupPath string       // Backup file that was restored
+	success      bool
+	err          error
+	scope        RestoreScope // What was restored (All/DNS/Caddy)
+	backupPath   string       // Backup file that was restored
+	reloadOutput string       // Output of the Caddy reload command (for audit log)
 }
 
 type deleteBackupMsg struct {
@@ -31,156 +39,153 @@ type cleanupBackupsMsg struct {
 	deletedCount int
 }
 
-// restoreBackupCmd restores from backup based on the specified scope
-func restoreBackupCmd(cfg *config.Config, backupPath string, scope RestoreScope, apiToken string) tea.Cmd {
+// backupCaddyfile backs up the Caddyfile together with a snapshot of the
+// profile's DNS records, so restoring the backup can put both back. Without
+// the snapshot the backup is removed and the operation stops before changing
+// anything, rather than leaving a backup that can't restore DNS.
+func backupCaddyfile(ctx context.Context, cfClient dnsprovider.Records, cfg *config.Config) (string, error) {
+	snap, err := snapshot.Take(ctx, cfClient, cfg.AllZones())
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot DNS records for the backup: %w", err)
+	}
+	backupPath, err := caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+	if err != nil {
+		return "", err
+	}
+	if err := snap.Save(snapshot.PathFor(backupPath)); err != nil {
+		os.Remove(backupPath)
+		return "", fmt.Errorf("failed to save the DNS snapshot of the backup: %w", err)
+	}
+	return backupPath, nil
+}
+
+type restorePlanMsg struct {
+	changes []snapshot.Change
+	takenAt time.Time // When the backup's DNS snapshot was taken
+	err     error
+}
+
+// planRestoreCmd works out the DNS changes restoring a backup makes, for
+// review before the restore runs
+func planRestoreCmd(cfg *config.Config, backupPath string, deleteNew bool, apiToken string) tea.Cmd {
 	return func() tea.Msg {
-		// Handle based on restore scope
-		switch scope {
-		case RestoreCaddyOnly:
-			// Only restore Caddyfile
-			err := caddy.RestoreFromBackup(cfg.Caddy.CaddyfilePath, backupPath)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("restore failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
-			}
+		cfClient := dnsprovider.New(cfg, apiToken)
+		saved, changes, err := planDNSRestore(context.Background(), cfClient, cfg, backupPath, deleteNew)
+		if err != nil {
+			return restorePlanMsg{err: err}
+		}
+		return restorePlanMsg{changes: changes, takenAt: saved.TakenAt}
+	}
+}
 
-			// Validate restored Caddyfile
-			err = formatAndValidateCaddyfile(cfg)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("restored Caddyfile validation failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
-			}
+// planDNSRestore compares a backup's DNS snapshot with the current records.
+// Ignored records are left alone, and in owned-only profiles only owned
+// records created since the backup are deleted.
+func planDNSRestore(ctx context.Context, cfClient dnsprovider.Records, cfg *config.Config, backupPath string, deleteNew bool) (*snapshot.Snapshot, []snapshot.Change, error) {
+	saved, err := snapshot.Load(snapshot.PathFor(backupPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("this backup has no DNS snapshot - only the Caddyfile can be restored")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-			// Restart Caddy
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("caddy restart failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
-			}
+	current, err := snapshot.Take(ctx, cfClient, cfg.AllZones())
+	if err != nil {
+		return nil, nil, err
+	}
 
-		case RestoreDNSOnly:
-			// Only restore DNS records from backup
-			err := restoreDNSFromBackup(cfg, backupPath, apiToken)
+	opts := snapshot.PlanOptions{DeleteNew: deleteNew}
+	if ignored := cfg.IgnoreMatcher(); ignored != nil {
+		opts.Skip = func(record cloudflare.DNSRecord) bool {
+			return ignored(record.Name, record.Type)
+		}
+	}
+	if cfg.Cloudflare.OwnedOnly {
+		opts.Deletable = cloudflare.DNSRecord.IsOwned
+	}
+	return saved, saved.Plan(current, opts), nil
+}
+
+// restoreBackupCmd restores from backup based on the specified scope.
+// changes are the DNS changes from planRestoreCmd, as previewed. The current
+// Caddyfile is backed up first and every step is journaled, so a failure
+// undoes the restore.
+func restoreBackupCmd(cfg *config.Config, backupPath string, scope RestoreScope, changes []snapshot.Change, apiToken string) tea.Cmd {
+	return func() tea.Msg {
+		result := restoreBackupMsg{scope: scope, backupPath: backupPath}
+
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "restore", filepath.Base(backupPath))
+		if err != nil {
+			result.err = err
+			return result
+		}
+
+		if scope != RestoreDNSOnly {
+			// Back up the current Caddyfile so the restore can itself be undone
+			currentBackup, err := backupCaddyfile(ctx, cfClient, cfg)
 			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("DNS restore failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
+				result.err = rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup")
+				return result
 			}
+			j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, currentBackup)
 
-		case RestoreAll:
-			// Restore Caddyfile
-			err := caddy.RestoreFromBackup(cfg.Caddy.CaddyfilePath, backupPath)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("Caddyfile restore failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
+			if err := caddy.RestoreFromBackup(cfg.Caddy.CaddyfilePath, backupPath); err != nil {
+				result.err = rollbackWithError(j, cfClient, cfg, err, "Caddyfile restore")
+				return result
 			}
 
 			// Validate restored Caddyfile
-			err = formatAndValidateCaddyfile(cfg)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("restored Caddyfile validation failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
+			if err := formatAndValidateCaddyfile(cfg); err != nil {
+				result.err = rollbackWithError(j, cfClient, cfg, fmt.Errorf("restored Caddyfile validation failed: %w", err), "restore")
+				return result
 			}
 
 			// Restart Caddy
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			result.reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("caddy restart failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
+				result.err = rollbackWithError(j, cfClient, cfg, err, "Caddy reload")
+				return result
 			}
+			j.MarkReloaded()
+		}
 
-			// Restore DNS records
-			err = restoreDNSFromBackup(cfg, backupPath, apiToken)
-			if err != nil {
-				return restoreBackupMsg{
-					success:    false,
-					err:        fmt.Errorf("DNS restore failed: %w", err),
-					scope:      scope,
-					backupPath: backupPath,
-				}
+		if scope != RestoreCaddyOnly {
+			if err := restoreDNS(ctx, cfClient, changes, j); err != nil {
+				result.err = rollbackWithError(j, cfClient, cfg, err, "DNS restore")
+				return result
 			}
 		}
 
-		return restoreBackupMsg{
-			success:    true,
-			scope:      scope,
-			backupPath: backupPath,
-		}
+		j.Commit()
+		result.success = true
+		return result
 	}
 }
 
-// restoreDNSFromBackup parses the backup Caddyfile and creates/updates DNS records
-func restoreDNSFromBackup(cfg *config.Config, backupPath string, apiToken string) error {
-	// Parse the backup Caddyfile to extract domains
-	entries, err := caddy.ParseCaddyfile(backupPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse backup: %w", err)
-	}
-
-	// Initialize Cloudflare client
-	cf := cloudflare.NewClient(apiToken)
-
-	// Create/update DNS records for each domain in the backup
-	for _, entry := range entries {
-		for _, domain := range entry.Domains {
-			// Create DNS record with default settings from config
-			// Determine DNS type based on domain or use default
-			dnsType := "CNAME"
-			content := cfg.Defaults.CNAMETarget
-
-			// Create the DNS record
-			record := cloudflare.DNSRecord{
-				Name:    domain,
-				Type:    dnsType,
-				Content: content,
-				Proxied: cfg.Defaults.Proxied,
-				TTL:     1, // Auto TTL
-			}
-
-			_, err := cf.CreateDNSRecord(cfg.Cloudflare.ZoneID, record)
-			if err != nil {
-				// If record already exists, try to update it
-				// Note: This is a simplified approach - in production you might want to
-				// fetch existing records first and update them
-				return fmt.Errorf("failed to create DNS record for %s: %w", domain, err)
-			}
+// restoreDNS applies the changes of a DNS restore, one batch per zone
+func restoreDNS(ctx context.Context, cfClient dnsprovider.Records, changes []snapshot.Change, j *journal.Journal) error {
+	batch := dnsBatch{}
+	for _, change := range changes {
+		switch change.Action {
+		case snapshot.ActionCreate:
+			batch.create(change.ZoneID, *change.Saved)
+		case snapshot.ActionUpdate:
+			batch.update(change.ZoneID, *change.Current, *change.Saved)
+		case snapshot.ActionDelete:
+			zone := batch.zone(change.ZoneID)
+			zone.Deletes = append(zone.Deletes, *change.Current)
 		}
 	}
-
-	return nil
+	return batch.apply(ctx, cfClient, j)
 }
 
 // deleteBackupCmd deletes a backup file
 func deleteBackupCmd(backupPath string) tea.Cmd {
 	return func() tea.Msg {
-		err := os.Remove(backupPath)
+		err := caddy.RemoveBackup(backupPath)
 		if err != nil {
 			return deleteBackupMsg{
 				success: false,
@@ -238,8 +243,25 @@ func cleanupBackupsCmd(caddyfilePath string, retentionDays int, maxBackups int,
 	}
 }
 
-// refreshDataCmd fetches fresh data from Cloudflare and Caddyfile
-func refreshDataCmd(cfg *config.Config) tea.Cmd {
+// ListManagedRecords lists the CNAME, A and AAAA records of a zone with a
+// single request
+func ListManagedRecords(ctx context.Context, dns dnsprovider.Records, zoneID string) ([]cloudflare.DNSRecord, error) {
+	records, err := dns.ListDNSRecords(ctx, zoneID, "")
+	if err != nil {
+		return nil, err
+	}
+	managed := make([]cloudflare.DNSRecord, 0, len(records))
+	for _, record := range records {
+		if slices.Contains(snapshot.RecordTypes, record.Type) {
+			managed = append(managed, record)
+		}
+	}
+	return managed, nil
+}
+
+// refreshDataCmd fetches fresh data from Cloudflare and Caddyfile.
+// Cancelling ctx abandons the Cloudflare requests.
+func refreshDataCmd(ctx context.Context, cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
 		// Get API token
 		apiToken, err := cfg.GetAPIToken()
@@ -247,37 +269,39 @@ func refreshDataCmd(cfg *config.Config) tea.Cmd {
 			return refreshCompleteMsg{err: fmt.Errorf("failed to get API token: %w", err)}
 		}
 
-		// Parse Caddyfile
-		caddyContent, err := os.ReadFile(cfg.Caddy.CaddyfilePath)
+		// Parse the Caddyfile and the files it imports, with snippets
+		parsed, err := caddy.LoadCaddyfile(cfg.Caddy.CaddyfilePath)
 		if err != nil {
 			// Try local Caddyfile if configured path fails
-			caddyContent, err = os.ReadFile("Caddyfile")
+			parsed, err = caddy.LoadCaddyfile("Caddyfile")
 			if err != nil {
 				return refreshCompleteMsg{err: err}
 			}
 		}
 
-		// Parse with snippets
-		parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
-
-		// Fetch DNS records from Cloudflare
-		cfClient := cloudflare.NewClient(apiToken)
-
-		cnameRecords, err := cfClient.ListDNSRecords(cfg.Cloudflare.ZoneID, "CNAME")
-		if err != nil {
-			return refreshCompleteMsg{err: err}
+		// Fetch DNS records from Cloudflare (every zone in the profile)
+		cfClient := dnsprovider.New(cfg, apiToken)
+		var allDNS []cloudflare.DNSRecord
+		for _, zone := range cfg.AllZones() {
+			records, err := ListManagedRecords(ctx, cfClient, zone.ZoneID)
+			if err != nil {
+				if cfg.IsMultiZone() {
+					err = fmt.Errorf("%s: %w", zone.Domain, err)
+				}
+				return refreshCompleteMsg{err: err}
+			}
+			allDNS = append(allDNS, records...)
 		}
 
-		aRecords, err := cfClient.ListDNSRecords(cfg.Cloudflare.ZoneID, "A")
+		// Read the tunnel ingress rules (tunnel profiles only)
+		opts := CompareOptionsForConfig(cfg)
+		opts.Ingress, err = LoadIngress(cfg)
 		if err != nil {
 			return refreshCompleteMsg{err: err}
 		}
 
-		// Combine all DNS records
-		allDNS := append(cnameRecords, aRecords...)
-
 		// Run diff engine
-		syncedEntries := diff.Compare(allDNS, parsed.Entries)
+		syncedEntries := diff.CompareWithOptions(allDNS, parsed.Entries, opts)
 
 		return refreshCompleteMsg{entries: syncedEntries, snippets: parsed.Snippets, err: nil}
 	}
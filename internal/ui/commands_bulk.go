@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -9,6 +11,9 @@ import (
 	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
+	"lazyproxyflare/internal/journal"
+	"lazyproxyflare/internal/tunnel"
 )
 
 type bulkDeleteMsg struct {
@@ -20,39 +25,51 @@ type bulkDeleteMsg struct {
 	deleteType     string   // "dns", "caddy", or "both" for audit logging
 	deletedDomains []string // Domains that were deleted/synced for audit logging
 	isSync         bool     // true for sync operations, false for delete operations
+	reloadOutput   string   // Output of the Caddy reload command (for audit log)
 }
 
 // bulkDeleteDNSCmd deletes all orphaned DNS records (DNS exists but no Caddy)
+// in one batch per zone; if any batch fails, the records already deleted are recreated
 func bulkDeleteDNSCmd(cfg *config.Config, entries []diff.SyncedEntry, apiToken string) tea.Cmd {
 	return func() tea.Msg {
-		cfClient := cloudflare.NewClient(apiToken)
-		deletedCount := 0
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
 		deletedDomains := []string{}
 
-		// Delete each orphaned DNS record
+		// Collect each orphaned DNS record
+		batch := dnsBatch{}
 		for _, entry := range entries {
 			if entry.Status != diff.StatusOrphanedDNS || entry.DNS == nil {
 				continue
 			}
+			batch.deleteEntry(cfg, entry)
+			deletedDomains = append(deletedDomains, entry.Domain)
+		}
 
-			err := cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, entry.DNS.ID)
-			if err != nil {
-				return bulkDeleteMsg{
-					success:        false,
-					err:            err,
-					count:          deletedCount,
-					errorStep:      fmt.Sprintf("dns_delete_%s", entry.Domain),
-					deleteType:     "dns",
-					deletedDomains: deletedDomains,
-				}
+		j, err := beginJournal(cfg, "bulk delete", describeBatch(deletedDomains))
+		if err != nil {
+			return bulkDeleteMsg{
+				success:    false,
+				err:        err,
+				errorStep:  "journal",
+				deleteType: "dns",
+			}
+		}
+
+		if err := batch.apply(ctx, cfClient, j); err != nil {
+			return bulkDeleteMsg{
+				success:        false,
+				err:            rollbackWithError(j, cfClient, cfg, err, "DNS batch delete"),
+				errorStep:      "dns_delete",
+				deleteType:     "dns",
+				deletedDomains: deletedDomains,
 			}
-			deletedCount++
-			deletedDomains = append(deletedDomains, entry.Domain)
 		}
 
+		j.Commit()
 		return bulkDeleteMsg{
 			success:        true,
-			count:          deletedCount,
+			count:          len(deletedDomains),
 			deleteType:     "dns",
 			deletedDomains: deletedDomains,
 		}
@@ -60,38 +77,55 @@ func bulkDeleteDNSCmd(cfg *config.Config, entries []diff.SyncedEntry, apiToken s
 }
 
 // bulkDeleteCaddyCmd deletes all orphaned Caddy entries (Caddy exists but no DNS)
-func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry) tea.Cmd {
+func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry, apiToken string) tea.Cmd {
 	return func() tea.Msg {
 		var backupPath string
 		var err error
+		var reloadOutput string
 
-		// Step 1: Backup Caddyfile
-		backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+		var orphaned []diff.SyncedEntry
+		var orphanedDomains []string
+		for _, entry := range entries {
+			if entry.Status == diff.StatusOrphanedCaddy && entry.Caddy != nil {
+				orphaned = append(orphaned, entry)
+				orphanedDomains = append(orphanedDomains, entry.Domain)
+			}
+		}
+
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "bulk delete", describeBatch(orphanedDomains))
 		if err != nil {
 			return bulkDeleteMsg{
 				success:    false,
 				err:        err,
+				errorStep:  "journal",
+				deleteType: "caddy",
+			}
+		}
+
+		// Step 1: Backup Caddyfile
+		backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
+		if err != nil {
+			return bulkDeleteMsg{
+				success:    false,
+				err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 				errorStep:  "backup",
 				deleteType: "caddy",
 			}
 		}
+		j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 
 		deletedCount := 0
 		deletedDomains := []string{}
 
 		// Step 2: Remove each orphaned Caddy entry
-		for _, entry := range entries {
-			if entry.Status != diff.StatusOrphanedCaddy || entry.Caddy == nil {
-				continue
-			}
-
+		for _, entry := range orphaned {
 			err = caddy.RemoveEntry(cfg.Caddy.CaddyfilePath, entry.Domain)
 			if err != nil {
-				// Rollback: Restore Caddyfile
-				err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy remove")
 				return bulkDeleteMsg{
 					success:        false,
-					err:            err,
+					err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile remove"),
 					count:          deletedCount,
 					errorStep:      fmt.Sprintf("caddy_remove_%s", entry.Domain),
 					backupPath:     backupPath,
@@ -106,11 +140,9 @@ func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry) tea.Cmd
 		// Step 3: Validate Caddyfile
 		err = formatAndValidateCaddyfile(cfg)
 		if err != nil {
-			// Rollback: Restore Caddyfile
-			err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy validate")
 			return bulkDeleteMsg{
 				success:        false,
-				err:            err,
+				err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 				count:          deletedCount,
 				errorStep:      "caddy_validate",
 				backupPath:     backupPath,
@@ -120,13 +152,11 @@ func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry) tea.Cmd
 		}
 
 		// Step 4: Restart Caddy
-		err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+		reloadOutput, err = reloadCaddy(cfg)
 		if err != nil {
-			// Rollback: Restore Caddyfile
-			err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy restart")
 			return bulkDeleteMsg{
 				success:        false,
-				err:            err,
+				err:            rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 				count:          deletedCount,
 				errorStep:      "caddy_restart",
 				backupPath:     backupPath,
@@ -134,9 +164,12 @@ func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry) tea.Cmd
 				deletedDomains: deletedDomains,
 			}
 		}
+		j.MarkReloaded()
+		j.Commit()
 
 		return bulkDeleteMsg{
 			success:        true,
+			reloadOutput:   reloadOutput,
 			count:          deletedCount,
 			backupPath:     backupPath,
 			deleteType:     "caddy",
@@ -145,19 +178,34 @@ func bulkDeleteCaddyCmd(cfg *config.Config, entries []diff.SyncedEntry) tea.Cmd
 	}
 }
 
-// batchDeleteSelectedCmd deletes all selected entries
+// batchDeleteSelectedCmd deletes all selected entries. The Caddyfile is
+// edited and reloaded first, then the DNS records are deleted in one batch per
+// zone; any failure rolls back every change, so the selection is deleted as a unit.
 func batchDeleteSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, selectedDomains map[string]bool, apiToken string) tea.Cmd {
 	return func() tea.Msg {
 		var backupPath string
 		var err error
-		var dnsDeletedCount, caddyDeletedCount int
+		var reloadOutput string
 		deletedDomains := []string{}
 
-		// Collect selected entries
+		// Collect selected entries, never touching ignored ones
 		var selectedEntries []diff.SyncedEntry
 		for _, entry := range allEntries {
-			if selectedDomains[entry.Domain] {
+			if selectedDomains[entry.Domain] && entry.Status != diff.StatusIgnored {
 				selectedEntries = append(selectedEntries, entry)
+				deletedDomains = append(deletedDomains, entry.Domain)
+			}
+		}
+
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "batch delete", describeBatch(deletedDomains))
+		if err != nil {
+			return bulkDeleteMsg{
+				success:    false,
+				err:        err,
+				errorStep:  "journal",
+				deleteType: "both",
 			}
 		}
 
@@ -171,49 +219,44 @@ func batchDeleteSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, s
 		}
 
 		if needsBackup {
-			backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+			backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
 			if err != nil {
 				return bulkDeleteMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 					errorStep:  "backup",
 					deleteType: "both",
 				}
 			}
+			j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 		}
 
 		// Step 2: Remove Caddy entries
+		caddyModified := false
 		for _, entry := range selectedEntries {
 			if entry.Caddy != nil {
 				err = caddy.RemoveEntry(cfg.Caddy.CaddyfilePath, entry.Domain)
 				if err != nil {
-					if backupPath != "" {
-						err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy remove")
-					}
 					return bulkDeleteMsg{
 						success:        false,
-						err:            err,
-						count:          caddyDeletedCount,
+						err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile remove"),
 						errorStep:      fmt.Sprintf("caddy_remove_%s", entry.Domain),
 						backupPath:     backupPath,
 						deleteType:     "both",
 						deletedDomains: deletedDomains,
 					}
 				}
-				caddyDeletedCount++
-				deletedDomains = append(deletedDomains, entry.Domain)
+				caddyModified = true
 			}
 		}
 
 		// Step 3: Validate and restart Caddy if we modified it
-		if caddyDeletedCount > 0 {
+		if caddyModified {
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
-				err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy validate")
 				return bulkDeleteMsg{
 					success:        false,
-					err:            err,
-					count:          caddyDeletedCount,
+					err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:      "caddy_validate",
 					backupPath:     backupPath,
 					deleteType:     "both",
@@ -221,54 +264,71 @@ func batchDeleteSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, s
 				}
 			}
 
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
-				err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy restart")
 				return bulkDeleteMsg{
 					success:        false,
-					err:            err,
-					count:          caddyDeletedCount,
+					err:            rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:      "caddy_restart",
 					backupPath:     backupPath,
 					deleteType:     "both",
 					deletedDomains: deletedDomains,
 				}
 			}
+			j.MarkReloaded()
 		}
 
-		// Step 4: Delete DNS records
-		cfClient := cloudflare.NewClient(apiToken)
+		// Step 4: Delete DNS records in one batch per zone
+		batch := dnsBatch{}
 		for _, entry := range selectedEntries {
 			if entry.DNS != nil {
-				err = cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, entry.DNS.ID)
-				if err != nil {
-					return bulkDeleteMsg{
-						success:        false,
-						err:            err,
-						count:          dnsDeletedCount + caddyDeletedCount,
-						errorStep:      fmt.Sprintf("dns_delete_%s", entry.Domain),
-						deleteType:     "both",
-						deletedDomains: deletedDomains,
-					}
-				}
-				dnsDeletedCount++
-				// Add domain if not already added (in case it has both DNS and Caddy)
-				alreadyAdded := false
-				for _, d := range deletedDomains {
-					if d == entry.Domain {
-						alreadyAdded = true
-						break
-					}
+				batch.deleteEntry(cfg, entry)
+			}
+		}
+		if err := batch.apply(ctx, cfClient, j); err != nil {
+			// Rollback: Recreate any deleted records, restore the Caddyfile and reload
+			return bulkDeleteMsg{
+				success:        false,
+				err:            rollbackWithError(j, cfClient, cfg, err, "DNS batch delete"),
+				errorStep:      "dns_delete",
+				backupPath:     backupPath,
+				deleteType:     "both",
+				deletedDomains: deletedDomains,
+			}
+		}
+
+		// Step 5: Remove tunnel ingress rules (last: cloudflared restarts)
+		var ingressDomains []string
+		for _, entry := range selectedEntries {
+			if entry.Ingress != nil {
+				ingressDomains = append(ingressDomains, entry.Domain)
+			}
+		}
+		if len(ingressDomains) > 0 && cfg.Tunnel.Enabled() {
+			tunnelOutput, err := applyIngress(cfg, j, func(f *tunnel.File) error {
+				for _, domain := range ingressDomains {
+					f.RemoveRule(domain)
 				}
-				if !alreadyAdded {
-					deletedDomains = append(deletedDomains, entry.Domain)
+				return nil
+			})
+			if err != nil {
+				return bulkDeleteMsg{
+					success:        false,
+					err:            rollbackWithError(j, cfClient, cfg, err, "tunnel ingress"),
+					errorStep:      "tunnel_ingress",
+					backupPath:     backupPath,
+					deleteType:     "both",
+					deletedDomains: deletedDomains,
 				}
 			}
+			reloadOutput = appendOutput(reloadOutput, tunnelOutput)
 		}
 
+		j.Commit()
 		return bulkDeleteMsg{
 			success:        true,
-			count:          dnsDeletedCount + caddyDeletedCount,
+			reloadOutput:   reloadOutput,
+			count:          len(deletedDomains),
 			backupPath:     backupPath,
 			deleteType:     "both",
 			deletedDomains: deletedDomains,
@@ -276,40 +336,60 @@ func batchDeleteSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, s
 	}
 }
 
-// batchSyncSelectedCmd syncs all selected entries by creating missing DNS or Caddy
+// batchSyncSelectedCmd syncs all selected entries by creating missing DNS or
+// Caddy. Caddy blocks are added and reloaded first, then the DNS records are
+// created in one batch per zone; any failure rolls back every change.
 func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, selectedDomains map[string]bool, apiToken string) tea.Cmd {
 	return func() tea.Msg {
 		var backupPath string
 		var err error
-		var syncedCount int
+		var reloadOutput string
 		syncedDomains := []string{}
 
-		// Collect selected entries
+		// Collect selected entries that are missing one side
 		var selectedEntries []diff.SyncedEntry
 		for _, entry := range allEntries {
-			if selectedDomains[entry.Domain] {
+			if !selectedDomains[entry.Domain] {
+				continue
+			}
+			if (entry.Status == diff.StatusOrphanedDNS && entry.DNS != nil) ||
+				(entry.Status == diff.StatusOrphanedCaddy && entry.Caddy != nil) {
 				selectedEntries = append(selectedEntries, entry)
+				syncedDomains = append(syncedDomains, entry.Domain)
 			}
 		}
 
-		// Step 1: Backup Caddyfile (we might add Caddy entries)
-		backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "batch sync", describeBatch(syncedDomains))
 		if err != nil {
 			return bulkDeleteMsg{
 				success:    false,
 				err:        err,
+				errorStep:  "journal",
+				isSync:     true,
+				deleteType: "both",
+			}
+		}
+
+		// Step 1: Backup Caddyfile (we might add Caddy entries)
+		backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
+		if err != nil {
+			return bulkDeleteMsg{
+				success:    false,
+				err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 				errorStep:  "backup",
 				isSync:     true,
 				deleteType: "both",
 			}
 		}
+		j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 
+		// Step 2: Add Caddy entries for DNS-only records; collect DNS records for Caddy-only entries
 		caddyModified := false
-		cfClient := cloudflare.NewClient(apiToken)
-
-		// Step 2: Process each selected entry
+		batch := dnsBatch{}
 		for _, entry := range selectedEntries {
-			if entry.Status == diff.StatusOrphanedDNS && entry.DNS != nil {
+			if entry.Status == diff.StatusOrphanedDNS {
 				// Create Caddy entry
 				caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
 					FQDN:              entry.Domain,
@@ -325,13 +405,11 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 					CustomCaddyConfig: "", // No custom config for batch sync
 				})
 
-				err = caddy.AppendEntry(cfg.Caddy.CaddyfilePath, caddyBlock)
+				err = appendCaddyEntry(cfg, entry.Domain, caddyBlock)
 				if err != nil {
-					err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy remove")
 					return bulkDeleteMsg{
 						success:        false,
-						err:            err,
-						count:          syncedCount,
+						err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile append"),
 						errorStep:      fmt.Sprintf("caddy_append_%s", entry.Domain),
 						backupPath:     backupPath,
 						isSync:         true,
@@ -340,36 +418,9 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 					}
 				}
 				caddyModified = true
-				syncedCount++
-				syncedDomains = append(syncedDomains, entry.Domain)
-
-			} else if entry.Status == diff.StatusOrphanedCaddy && entry.Caddy != nil {
+			} else {
 				// Create DNS record
-				dnsRecord := cloudflare.DNSRecord{
-					Type:    "CNAME",
-					Name:    entry.Domain,
-					Content: cfg.Defaults.CNAMETarget,
-					Proxied: cfg.Defaults.Proxied,
-					TTL:     1,
-				}
-
-				_, err = cfClient.CreateDNSRecord(cfg.Cloudflare.ZoneID, dnsRecord)
-				if err != nil {
-					if caddyModified {
-						err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy remove")
-					}
-					return bulkDeleteMsg{
-						success:        false,
-						err:            err,
-						count:          syncedCount,
-						errorStep:      fmt.Sprintf("dns_create_%s", entry.Domain),
-						isSync:         true,
-						deleteType:     "both",
-						deletedDomains: syncedDomains,
-					}
-				}
-				syncedCount++
-				syncedDomains = append(syncedDomains, entry.Domain)
+				batch.create(cfg.ZoneIDFor(entry.Domain), syncDNSRecord(cfg, entry))
 			}
 		}
 
@@ -377,11 +428,9 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 		if caddyModified {
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
-				err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy validate")
 				return bulkDeleteMsg{
 					success:        false,
-					err:            err,
-					count:          syncedCount,
+					err:            rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:      "caddy_validate",
 					backupPath:     backupPath,
 					isSync:         true,
@@ -390,13 +439,11 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 				}
 			}
 
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
-				err = restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "caddy restart")
 				return bulkDeleteMsg{
 					success:        false,
-					err:            err,
-					count:          syncedCount,
+					err:            rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:      "caddy_restart",
 					backupPath:     backupPath,
 					isSync:         true,
@@ -404,11 +451,31 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 					deletedDomains: syncedDomains,
 				}
 			}
+			j.MarkReloaded()
 		}
 
+		// Step 4: Create DNS records in one batch per zone
+		if err := batch.apply(ctx, cfClient, j); err != nil {
+			if dnsprovider.IsRecordExists(err) {
+				err = fmt.Errorf("%w (records changed since the last refresh; refresh and retry)", err)
+			}
+			// Rollback: Delete any created records, restore the Caddyfile and reload
+			return bulkDeleteMsg{
+				success:        false,
+				err:            rollbackWithError(j, cfClient, cfg, err, "DNS batch create"),
+				errorStep:      "dns_create",
+				backupPath:     backupPath,
+				isSync:         true,
+				deleteType:     "both",
+				deletedDomains: syncedDomains,
+			}
+		}
+
+		j.Commit()
 		return bulkDeleteMsg{
 			success:        true,
-			count:          syncedCount,
+			reloadOutput:   reloadOutput,
+			count:          len(syncedDomains),
 			backupPath:     backupPath,
 			isSync:         true,
 			deleteType:     "both",
@@ -416,3 +483,86 @@ func batchSyncSelectedCmd(cfg *config.Config, allEntries []diff.SyncedEntry, sel
 		}
 	}
 }
+
+// dnsBatch collects the DNS records a bulk operation deletes, updates and
+// creates, keyed by zone ID, so each zone's changes go out in one atomic batch request
+type dnsBatch map[string]*zoneBatch
+
+// zoneBatch is the batch for one zone, with each patched record as it was
+// before the patch so the update can be journaled
+type zoneBatch struct {
+	cloudflare.BatchRequest
+	before []cloudflare.DNSRecord
+}
+
+// zone returns the batch for a zone, creating it on first use
+func (b dnsBatch) zone(zoneID string) *zoneBatch {
+	if b[zoneID] == nil {
+		b[zoneID] = &zoneBatch{}
+	}
+	return b[zoneID]
+}
+
+// deleteEntry queues an entry's DNS record, plus the paired AAAA record of a dual-stack entry
+func (b dnsBatch) deleteEntry(cfg *config.Config, entry diff.SyncedEntry) {
+	for _, record := range []*cloudflare.DNSRecord{entry.DNS, entry.DNSAAAA} {
+		if record != nil {
+			zone := b.zone(recordZoneID(cfg, *record))
+			zone.Deletes = append(zone.Deletes, *record)
+		}
+	}
+}
+
+// create queues a record to create in a zone
+func (b dnsBatch) create(zoneID string, record cloudflare.DNSRecord) {
+	zone := b.zone(zoneID)
+	zone.Posts = append(zone.Posts, record)
+}
+
+// update queues a change to an existing record; after carries the record's ID
+func (b dnsBatch) update(zoneID string, before, after cloudflare.DNSRecord) {
+	zone := b.zone(zoneID)
+	zone.Patches = append(zone.Patches, after)
+	zone.before = append(zone.before, before)
+}
+
+// apply sends each zone's batch in turn, recording every applied change in j
+// so a later failure can undo it
+func (b dnsBatch) apply(ctx context.Context, cfClient dnsprovider.Records, j *journal.Journal) error {
+	zoneIDs := make([]string, 0, len(b))
+	for zoneID := range b {
+		zoneIDs = append(zoneIDs, zoneID)
+	}
+	sort.Strings(zoneIDs)
+
+	for _, zoneID := range zoneIDs {
+		batch := b[zoneID]
+		applied, err := cfClient.BatchDNSRecords(ctx, zoneID, batch.BatchRequest)
+		if applied != nil {
+			for i := range applied.Deletes {
+				j.RecordDNSDelete(zoneID, batch.Deletes[i])
+			}
+			for i, updated := range applied.Patches {
+				j.RecordDNSUpdate(zoneID, batch.before[i], updated)
+			}
+			for _, created := range applied.Posts {
+				j.RecordDNSCreate(zoneID, created)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeBatch summarizes the domains of a bulk operation for its journal
+func describeBatch(domains []string) string {
+	switch len(domains) {
+	case 0:
+		return "no entries"
+	case 1:
+		return domains[0]
+	}
+	return fmt.Sprintf("%s and %d others", domains[0], len(domains)-1)
+}
@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/journal"
+	"lazyproxyflare/internal/snapshot"
+)
+
+// batchOnlyDNS records batch requests; per-record methods are not expected
+type batchOnlyDNS struct {
+	cloudflare.DNSClient
+	batches map[string]cloudflare.BatchRequest
+	failOn  string // Zone ID whose batch fails
+}
+
+func (f *batchOnlyDNS) BatchDNSRecords(ctx context.Context, zoneID string, batch cloudflare.BatchRequest) (*cloudflare.BatchResult, error) {
+	if zoneID == f.failOn {
+		return &cloudflare.BatchResult{}, errors.New("batch failed")
+	}
+	f.batches[zoneID] = batch
+	result := &cloudflare.BatchResult{Deletes: batch.Deletes, Patches: batch.Patches}
+	for _, record := range batch.Posts {
+		record.ID = "new-" + record.Name
+		result.Posts = append(result.Posts, record)
+	}
+	return result, nil
+}
+
+func TestDNSBatchGroupsByZone(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cloudflare.ZoneID = "zone-a"
+	cfg.Domain = "example.com"
+
+	batch := dnsBatch{}
+	batch.deleteEntry(cfg, diff.SyncedEntry{
+		Domain:  "dual.example.com",
+		DNS:     &cloudflare.DNSRecord{ID: "a-1", Type: "A", Name: "dual.example.com", ZoneID: "zone-a"},
+		DNSAAAA: &cloudflare.DNSRecord{ID: "aaaa-1", Type: "AAAA", Name: "dual.example.com", ZoneID: "zone-a"},
+	})
+	batch.deleteEntry(cfg, diff.SyncedEntry{
+		Domain: "other.example.net",
+		DNS:    &cloudflare.DNSRecord{ID: "c-1", Type: "CNAME", Name: "other.example.net", ZoneID: "zone-b"},
+	})
+	batch.create("zone-a", cloudflare.DNSRecord{Type: "CNAME", Name: "new.example.com"})
+
+	j, err := journal.Begin(t.TempDir(), "batch sync", "test", "Caddyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dns := &batchOnlyDNS{batches: map[string]cloudflare.BatchRequest{}}
+	if err := batch.apply(context.Background(), dns, j); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if len(dns.batches) != 2 {
+		t.Fatalf("got %d batch requests, want one per zone", len(dns.batches))
+	}
+	if a := dns.batches["zone-a"]; len(a.Deletes) != 2 || len(a.Posts) != 1 {
+		t.Errorf("zone-a batch: %d deletes, %d posts", len(a.Deletes), len(a.Posts))
+	}
+	if b := dns.batches["zone-b"]; len(b.Deletes) != 1 || len(b.Posts) != 0 {
+		t.Errorf("zone-b batch: %d deletes, %d posts", len(b.Deletes), len(b.Posts))
+	}
+
+	// Every applied change is journaled for rollback
+	if len(j.Steps) != 4 {
+		t.Fatalf("got %d journal steps, want 4", len(j.Steps))
+	}
+	for _, step := range j.Steps {
+		if step.Kind == journal.StepDNSCreate && step.Record.ID != "new-new.example.com" {
+			t.Errorf("created record journaled without its ID: %+v", step.Record)
+		}
+	}
+}
+
+func TestDNSBatchStopsAtFailedZone(t *testing.T) {
+	batch := dnsBatch{}
+	batch.create("zone-a", cloudflare.DNSRecord{Type: "CNAME", Name: "a.example.com"})
+	batch.create("zone-b", cloudflare.DNSRecord{Type: "CNAME", Name: "b.example.net"})
+
+	j, err := journal.Begin(t.TempDir(), "batch sync", "test", "Caddyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dns := &batchOnlyDNS{batches: map[string]cloudflare.BatchRequest{}, failOn: "zone-b"}
+	if err := batch.apply(context.Background(), dns, j); err == nil {
+		t.Fatal("expected error from failed zone")
+	}
+
+	// Zones go out in order; only zone-a was applied and needs undoing
+	if len(j.Steps) != 1 || j.Steps[0].ZoneID != "zone-a" {
+		t.Errorf("expected only zone-a's create journaled, got %+v", j.Steps)
+	}
+}
+
+func TestClaimRecords(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cloudflare.ZoneID = "zone-a"
+	cfg.Domain = "example.com"
+
+	entries := []diff.SyncedEntry{
+		{
+			Domain:  "dual.example.com",
+			DNS:     &cloudflare.DNSRecord{ID: "a-1", Type: "A", Name: "dual.example.com", ZoneID: "zone-a", Comment: "set up by hand"},
+			DNSAAAA: &cloudflare.DNSRecord{ID: "aaaa-1", Type: "AAAA", Name: "dual.example.com", ZoneID: "zone-a", Comment: cloudflare.OwnerMarker},
+		},
+		{Domain: "mail.example.net", DNS: &cloudflare.DNSRecord{ID: "mx-1", Type: "MX", Name: "mail.example.net", ZoneID: "zone-b"}},
+		{Domain: "local.example.com"}, // Caddy only: nothing to claim
+	}
+
+	dns := &batchOnlyDNS{batches: map[string]cloudflare.BatchRequest{}}
+	claimed, err := claimRecords(context.Background(), dns, cfg, entries)
+	if err != nil {
+		t.Fatalf("claimRecords: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0] != "dual.example.com" || claimed[1] != "mail.example.net" {
+		t.Errorf("got claimed domains %v", claimed)
+	}
+
+	// Only unowned records are patched, keeping their existing comment
+	a := dns.batches["zone-a"]
+	if len(a.Patches) != 1 || a.Patches[0].ID != "a-1" || a.Patches[0].Comment != "set up by hand "+cloudflare.OwnerMarker {
+		t.Errorf("zone-a patches: %+v", a.Patches)
+	}
+	if b := dns.batches["zone-b"]; len(b.Patches) != 1 || !b.Patches[0].IsOwned() {
+		t.Errorf("zone-b patches: %+v", b.Patches)
+	}
+
+	if _, err := claimRecords(context.Background(), dns, cfg, entries[2:]); err == nil {
+		t.Error("expected an error when there is nothing to claim")
+	}
+}
+
+func TestRestoreDNSJournalsEveryChange(t *testing.T) {
+	changes := []snapshot.Change{
+		{Action: snapshot.ActionCreate, ZoneID: "zone-a", Saved: &cloudflare.DNSRecord{Type: "CNAME", Name: "gone.example.com"}},
+		{
+			Action:  snapshot.ActionUpdate,
+			ZoneID:  "zone-a",
+			Current: &cloudflare.DNSRecord{ID: "a-1", Type: "A", Name: "nas.example.com", Content: "192.168.1.20"},
+			Saved:   &cloudflare.DNSRecord{ID: "a-1", Type: "A", Name: "nas.example.com", Content: "192.168.1.10"},
+		},
+		{Action: snapshot.ActionDelete, ZoneID: "zone-a", Current: &cloudflare.DNSRecord{ID: "c-1", Type: "CNAME", Name: "new.example.com"}},
+	}
+
+	j, err := journal.Begin(t.TempDir(), "restore", "test", "Caddyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dns := &batchOnlyDNS{batches: map[string]cloudflare.BatchRequest{}}
+	if err := restoreDNS(context.Background(), dns, changes, j); err != nil {
+		t.Fatalf("restoreDNS: %v", err)
+	}
+
+	if a := dns.batches["zone-a"]; a.Len() != 3 {
+		t.Fatalf("expected one batch with all 3 changes, got %+v", a)
+	}
+	if len(j.Steps) != 3 {
+		t.Fatalf("got %d journal steps, want 3", len(j.Steps))
+	}
+	for _, step := range j.Steps {
+		// Updates are undone from the record as it was before the restore
+		if step.Kind == journal.StepDNSUpdate && step.Record.Content != "192.168.1.20" {
+			t.Errorf("update journaled with the wrong record: %+v", step.Record)
+		}
+	}
+}
+
+// listingDNS answers listings with records, or fails them when err is set
+type listingDNS struct {
+	cloudflare.DNSClient
+	records []cloudflare.DNSRecord
+	err     error
+	types   []string // Record type of each listing
+}
+
+func (f *listingDNS) ListDNSRecords(ctx context.Context, zoneID, recordType string) ([]cloudflare.DNSRecord, error) {
+	f.types = append(f.types, recordType)
+	return f.records, f.err
+}
+
+func TestListManagedRecords(t *testing.T) {
+	dns := &listingDNS{records: []cloudflare.DNSRecord{
+		{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "example.com"},
+		{ID: "2", Type: "TXT", Name: "example.com", Content: "v=spf1 -all"},
+		{ID: "3", Type: "A", Name: "db.example.com", Content: "10.0.0.1"},
+		{ID: "4", Type: "AAAA", Name: "db.example.com", Content: "fd00::1"},
+		{ID: "5", Type: "MX", Name: "example.com", Content: "mail.example.com"},
+	}}
+	records, err := ListManagedRecords(context.Background(), dns, "zone-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dns.types) != 1 || dns.types[0] != "" {
+		t.Errorf("expected one listing of every type, got %q", dns.types)
+	}
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	if strings.Join(ids, ",") != "1,3,4" {
+		t.Errorf("expected the CNAME, A and AAAA records, got %v", ids)
+	}
+
+	dns.err = errors.New("unauthorized")
+	if _, err := ListManagedRecords(context.Background(), dns, "zone-a"); err == nil {
+		t.Error("expected the listing error")
+	}
+}
+
+func TestBackupCaddyfileNeedsSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{Domain: "example.com", Cloudflare: config.CloudflareConfig{ZoneID: "zone-a"}}
+	cfg.Caddy.CaddyfilePath = filepath.Join(dir, "Caddyfile")
+	if err := os.WriteFile(cfg.Caddy.CaddyfilePath, []byte("app.example.com {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a snapshot there is no backup and the operation stops
+	if _, err := backupCaddyfile(context.Background(), &listingDNS{err: errors.New("unauthorized")}, cfg); err == nil {
+		t.Fatal("expected the failed snapshot reported")
+	}
+	if backups, _ := filepath.Glob(cfg.Caddy.CaddyfilePath + ".backup.*"); len(backups) != 0 {
+		t.Errorf("expected no backup without a snapshot, got %v", backups)
+	}
+
+	records := []cloudflare.DNSRecord{{ID: "1", Type: "CNAME", Name: "app.example.com", Content: "example.com"}}
+	backupPath, err := backupCaddyfile(context.Background(), &listingDNS{records: records}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := snapshot.Load(snapshot.PathFor(backupPath)); err != nil || len(saved.Zones) != 1 || len(saved.Zones[0].Records) != 1 {
+		t.Errorf("expected the snapshot saved with the backup, got %+v, %v", saved, err)
+	}
+}
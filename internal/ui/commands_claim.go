@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
+)
+
+type claimRecordsMsg struct {
+	success bool
+	err     error
+	domains []string // Domains whose records were claimed (for audit log)
+}
+
+// claimRecordsCmd adopts the DNS records of entries created by hand or by
+// other tools, tagging them as owned so they become candidates for cleanup
+func claimRecordsCmd(cfg *config.Config, entries []diff.SyncedEntry, apiToken string) tea.Cmd {
+	return func() tea.Msg {
+		cfClient := dnsprovider.New(cfg, apiToken)
+		domains, err := claimRecords(context.Background(), cfClient, cfg, entries)
+		return claimRecordsMsg{
+			success: err == nil,
+			err:     err,
+			domains: domains,
+		}
+	}
+}
+
+// claimRecords adds the ownership marker to every unowned record of entries,
+// patching each zone in one batch request. Claiming only touches comments, so
+// it is not journaled: a failure leaves earlier zones claimed and harmless.
+// Returns the domains whose records were claimed.
+func claimRecords(ctx context.Context, cfClient dnsprovider.Records, cfg *config.Config, entries []diff.SyncedEntry) ([]string, error) {
+	if info := dnsprovider.InfoFor(cfg); !info.Comments {
+		return nil, fmt.Errorf("%s records have no comments to carry the ownership marker", info.Name)
+	}
+	batch := dnsBatch{}
+	domains := map[string][]string{} // Zone ID -> domains claimed in it
+	for _, entry := range entries {
+		for _, record := range []*cloudflare.DNSRecord{entry.DNS, entry.DNSAAAA} {
+			if record == nil || record.IsOwned() {
+				continue
+			}
+			zoneID := recordZoneID(cfg, *record)
+			zone := batch.zone(zoneID)
+			zone.Patches = append(zone.Patches, record.Owned())
+			if !slices.Contains(domains[zoneID], entry.Domain) {
+				domains[zoneID] = append(domains[zoneID], entry.Domain)
+			}
+		}
+	}
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("no unclaimed DNS records selected")
+	}
+
+	var claimed []string
+	for _, zoneID := range slices.Sorted(maps.Keys(batch)) {
+		if _, err := cfClient.BatchDNSRecords(ctx, zoneID, batch[zoneID].BatchRequest); err != nil {
+			return claimed, fmt.Errorf("failed to claim records: %w", err)
+		}
+		claimed = append(claimed, domains[zoneID]...)
+	}
+	return claimed, nil
+}
@@ -1,8 +1,9 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,19 +12,22 @@ import (
 	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
+	"lazyproxyflare/internal/journal"
+	"lazyproxyflare/internal/tunnel"
 )
 
-// restoreBackupWithError wraps caddy.RestoreFromBackup with proper error handling
-// Returns an error message indicating whether restore succeeded or failed
-func restoreBackupWithError(caddyfilePath, backupPath string, originalErr error, operation string) error {
-	if restoreErr := caddy.RestoreFromBackup(caddyfilePath, backupPath); restoreErr != nil {
-		return fmt.Errorf("CRITICAL: %s failed AND backup restore failed: %w (original error: %v)", operation, restoreErr, originalErr)
-	}
-	return fmt.Errorf("%s failed (backup restored): %w", operation, originalErr)
-}
-
-// formatAndValidateCaddyfile formats and validates the Caddyfile using config values
+// formatAndValidateCaddyfile formats and validates the Caddyfile using config values.
+// With an admin address configured, validation adapts the Caddyfile through the
+// admin API instead (the API has no formatter, so formatting is skipped).
 func formatAndValidateCaddyfile(cfg *config.Config) error {
+	if cfg.Caddy.AdminAddress != "" {
+		client, err := caddy.NewAdminClient(cfg.Caddy.AdminAddress)
+		if err != nil {
+			return err
+		}
+		return client.ValidateFile(cfg.Caddy.CaddyfilePath)
+	}
 	return caddy.FormatAndValidateCaddyfile(
 		cfg.Caddy.CaddyfilePath,
 		cfg.Caddy.CaddyfileContainerPath,
@@ -34,11 +38,42 @@ func formatAndValidateCaddyfile(cfg *config.Config) error {
 	)
 }
 
+// reloadCaddy applies the Caddyfile on disk to the running Caddy and returns
+// any output worth recording. Uses a graceful /load through the admin API when
+// configured, otherwise runs the profile's reload command.
+func reloadCaddy(cfg *config.Config) (string, error) {
+	if cfg.Caddy.AdminAddress != "" {
+		client, err := caddy.NewAdminClient(cfg.Caddy.AdminAddress)
+		if err != nil {
+			return "", err
+		}
+		return "", client.LoadFile(cfg.Caddy.CaddyfilePath)
+	}
+	return caddy.ReloadCaddy(
+		cfg.Caddy.CaddyfilePath,
+		cfg.Caddy.CaddyfileContainerPath,
+		cfg.Caddy.ContainerName,
+		cfg.Caddy.ComposeFilePath,
+		cfg.Caddy.ReloadCommand,
+	)
+}
+
+// appendCaddyEntry adds the site block of a new entry: to a file of its own
+// when the profile sets site_files_dir, otherwise to the end of the Caddyfile
+func appendCaddyEntry(cfg *config.Config, domain, block string) error {
+	if cfg.Caddy.SiteFilesDir != "" {
+		_, err := caddy.AppendSiteFile(cfg.Caddy.CaddyfilePath, cfg.Caddy.SiteFilesDir, domain, block)
+		return err
+	}
+	return caddy.AppendEntry(cfg.Caddy.CaddyfilePath, block)
+}
+
 // deleteEntryCmd deletes a DNS record and Caddy entry with rollback on failure
 func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScope, apiToken string) tea.Cmd {
 	return func() tea.Msg {
 		var backupPath string
 		var err error
+		var reloadOutput string
 
 		// Determine what to delete based on scope
 		deleteDNS := false
@@ -53,6 +88,8 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 		case DeleteCaddyOnly:
 			deleteCaddy = entry.Caddy != nil
 		}
+		// The ingress rule goes with the DNS record that routes to it
+		deleteIngress := entry.Ingress != nil && cfg.Tunnel.Enabled() && scope != DeleteCaddyOnly
 
 		// Determine entity type for audit logging
 		entityType := "both"
@@ -62,18 +99,33 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 			entityType = "caddy"
 		}
 
+		// Every change is journaled so a later failure can undo the earlier steps
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "delete", entry.Domain)
+		if err != nil {
+			return deleteEntryMsg{
+				success:    false,
+				err:        err,
+				errorStep:  "journal",
+				domain:     entry.Domain,
+				entityType: entityType,
+			}
+		}
+
 		// Step 1: Backup Caddyfile (if deleting Caddy entry)
 		if deleteCaddy {
-			backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+			backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
 			if err != nil {
 				return deleteEntryMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 					errorStep:  "backup",
 					domain:     entry.Domain,
 					entityType: entityType,
 				}
 			}
+			j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 		}
 
 		// Step 2: Remove from Caddyfile (if deleting Caddy)
@@ -82,7 +134,7 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 			if err != nil {
 				return deleteEntryMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile remove"),
 					errorStep:  "caddy_remove",
 					backupPath: backupPath,
 					domain:     entry.Domain,
@@ -96,7 +148,7 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 				// Rollback: Restore Caddyfile
 				return deleteEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:  "caddy_validate",
 					backupPath: backupPath,
 					domain:     entry.Domain,
@@ -105,30 +157,29 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 			}
 
 			// Step 4: Restart Caddy
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
-				// Rollback: Restore Caddyfile
+				// Rollback: Restore Caddyfile and reload it
 				return deleteEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:  "caddy_restart",
 					backupPath: backupPath,
 					domain:     entry.Domain,
 					entityType: entityType,
 				}
 			}
+			j.MarkReloaded()
 		}
 
 		// Step 5: Delete DNS record (if deleting DNS)
 		if deleteDNS {
-			cfClient := cloudflare.NewClient(apiToken)
-			err = cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, entry.DNS.ID)
+			err = deleteEntryDNS(ctx, cfClient, cfg, entry, j)
 			if err != nil {
-				// If Caddy was removed, we're in an inconsistent state
-				// But we can't rollback Caddy at this point (already restarted)
+				// Rollback: Recreate any deleted records, restore the Caddyfile and reload
 				return deleteEntryMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "DNS delete"),
 					errorStep:  "dns_delete",
 					backupPath: backupPath,
 					domain:     entry.Domain,
@@ -137,12 +188,33 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 			}
 		}
 
+		// Step 6: Remove the tunnel ingress rule (last: cloudflared restarts)
+		if deleteIngress {
+			tunnelOutput, err := applyIngress(cfg, j, func(f *tunnel.File) error {
+				f.RemoveRule(entry.Domain)
+				return nil
+			})
+			if err != nil {
+				return deleteEntryMsg{
+					success:    false,
+					err:        rollbackWithError(j, cfClient, cfg, err, "tunnel ingress"),
+					errorStep:  "tunnel_ingress",
+					backupPath: backupPath,
+					domain:     entry.Domain,
+					entityType: entityType,
+				}
+			}
+			reloadOutput = appendOutput(reloadOutput, tunnelOutput)
+		}
+
 		// Success!
+		j.Commit()
 		return deleteEntryMsg{
-			success:    true,
-			backupPath: backupPath,
-			domain:     entry.Domain,
-			entityType: entityType,
+			success:      true,
+			reloadOutput: reloadOutput,
+			backupPath:   backupPath,
+			domain:       entry.Domain,
+			entityType:   entityType,
 		}
 	}
 }
@@ -150,7 +222,6 @@ func deleteEntryCmd(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScop
 // createEntryCmd creates a new DNS record and Caddy entry with rollback on failure
 func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.Cmd {
 	return func() tea.Msg {
-		var dnsRecordIDs []string
 		var backupPath string
 
 		// Parse multiple subdomains (supports newline-separated input)
@@ -166,7 +237,7 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 		}
 
 		// Build FQDNs
-		fqdns := BuildFQDNs(subdomains, cfg.Domain)
+		fqdns := ResolveFQDNs(subdomains, cfg)
 
 		// Parse port
 		port := 80
@@ -174,10 +245,15 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 			fmt.Sscanf(form.ServicePort, "%d", &port)
 		}
 
+		// In direct tunnel mode cloudflared reaches the upstream itself
+		viaTunnel := formViaTunnel(cfg, form)
+		form = directTunnelForm(cfg, form)
+
 		// Step 0: Check for duplicate domains in Caddyfile (only if not DNS-only)
 		var err error
+		var reloadOutput string
 		if !form.DNSOnly {
-			caddyContent, err := os.ReadFile(cfg.Caddy.CaddyfilePath)
+			parsed, err := caddy.LoadCaddyfile(cfg.Caddy.CaddyfilePath)
 			if err != nil {
 				return createEntryMsg{
 					success:   false,
@@ -185,8 +261,6 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 					errorStep: "duplicate_check",
 				}
 			}
-
-			parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
 			for _, fqdn := range fqdns {
 				fqdnLower := strings.ToLower(fqdn)
 				for _, entry := range parsed.Entries {
@@ -211,50 +285,74 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 			}
 		}
 
+		// Every change is journaled so a later failure can undo the earlier steps
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "create", strings.Join(fqdns, ", "))
+		if err != nil {
+			return createEntryMsg{
+				success:   false,
+				err:       err,
+				errorStep: "journal",
+			}
+		}
+
 		// Step 1: Backup Caddyfile (skip if DNS-only mode)
 		if !form.DNSOnly {
-			backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+			backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
 			if err != nil {
 				return createEntryMsg{
 					success:   false,
-					err:       err,
+					err:       rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 					errorStep: "backup",
 				}
 			}
+			j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 		}
 
-		// Step 2: Create DNS records in Cloudflare (one per domain)
-		cfClient := cloudflare.NewClient(apiToken)
-		dnsRecordIDs = []string{}
-
+		// Step 2: Create DNS records in Cloudflare (one per domain, in the zone that matches it)
 		for _, fqdn := range fqdns {
-			dnsRecord := cloudflare.DNSRecord{
-				Type:    form.DNSType,
-				Name:    fqdn,
-				Content: form.DNSTarget,
-				Proxied: form.Proxied,
-				TTL:     1, // Auto
+			// A host route in a wildcard site needs no record when a wildcard record serves it
+			if form.HostRoute {
+				covered, err := hasWildcardRecord(ctx, cfClient, cfg.ZoneIDFor(fqdn), fqdn)
+				if err != nil {
+					return createEntryMsg{
+						success:    false,
+						err:        rollbackWithError(j, cfClient, cfg, fmt.Errorf("failed to look up wildcard record for %s: %w", fqdn, err), "DNS create"),
+						errorStep:  "dns_create",
+						backupPath: backupPath,
+					}
+				}
+				if covered {
+					continue
+				}
 			}
 
-			createdRecord, err := cfClient.CreateDNSRecord(cfg.Cloudflare.ZoneID, dnsRecord)
-			if err != nil {
-				// Rollback: Delete any DNS records already created
-				for _, recordID := range dnsRecordIDs {
-					cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, recordID)
-				}
-				return createEntryMsg{
-					success:    false,
-					err:        fmt.Errorf("failed to create DNS record for %s: %w", fqdn, err),
-					errorStep:  "dns_create",
-					backupPath: backupPath,
+			// Dual-stack entries get both an A and an AAAA record
+			for _, dnsRecord := range dnsRecordsForForm(form, fqdn) {
+				zoneID := cfg.ZoneIDFor(fqdn)
+				createdRecord, err := cfClient.CreateDNSRecord(ctx, zoneID, dnsRecord)
+				if err != nil {
+					// Rollback: Delete any DNS records already created
+					if dnsprovider.IsRecordExists(err) {
+						err = fmt.Errorf("a record for %s already exists in Cloudflare (refresh to see it): %w", fqdn, err)
+					} else {
+						err = fmt.Errorf("failed to create %s record for %s: %w", dnsRecord.Type, fqdn, err)
+					}
+					return createEntryMsg{
+						success:    false,
+						err:        rollbackWithError(j, cfClient, cfg, err, "DNS create"),
+						errorStep:  "dns_create",
+						backupPath: backupPath,
+					}
 				}
+				j.RecordDNSCreate(zoneID, *createdRecord)
 			}
-			dnsRecordIDs = append(dnsRecordIDs, createdRecord.ID)
 		}
 
 		// Step 3: Generate and append Caddy block (skip if DNS-only mode)
 		if !form.DNSOnly {
-			caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
+			input := caddy.GenerateBlockInput{
 				Domains:           fqdns, // Use new Domains field for multi-domain support
 				Target:            form.ReverseProxyTarget,
 				Port:              port,
@@ -266,17 +364,33 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 				AllowedExtIP:      cfg.Defaults.AllowedExternalIP,
 				SelectedSnippets:  getSelectedSnippetNames(form.SelectedSnippets),
 				CustomCaddyConfig: form.CustomCaddyConfig,
-			})
+				Routes:            formPathRoutes(form),
+				Upstreams:         formUpstreams(form),
+				LoadBalancing:     formLoadBalancing(form),
+				ProxyOptions:      form.ProxyOptions,
+
+				Type:                 form.EntryType,
+				RedirectTo:           form.RedirectTo,
+				RedirectCode:         formRedirectCode(form),
+				RedirectPreservePath: form.RedirectPreservePath,
+				StaticRoot:           form.StaticRoot,
+				StaticBrowse:         form.StaticBrowse,
+			}
 
-			err = caddy.AppendEntry(cfg.Caddy.CaddyfilePath, caddyBlock)
+			if form.HostRoute {
+				// Route inside the wildcard site block that serves the domains
+				err = caddy.AddHostRoute(cfg.Caddy.CaddyfilePath, fqdns, caddy.GenerateRouteBody(input))
+			} else {
+				err = appendCaddyEntry(cfg, fqdns[0], caddy.GenerateCaddyBlock(input))
+			}
+			if err == nil && needsCloudflareTrust(form) {
+				err = caddy.TrustCloudflareProxies(cfg.Caddy.CaddyfilePath)
+			}
 			if err != nil {
-				// Rollback: Delete all DNS records
-				for _, recordID := range dnsRecordIDs {
-					cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, recordID)
-				}
+				// Rollback: Restore Caddyfile, delete all DNS records
 				return createEntryMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile append"),
 					errorStep:  "caddy_append",
 					backupPath: backupPath,
 				}
@@ -286,37 +400,48 @@ func createEntryCmd(cfg *config.Config, form AddFormData, apiToken string) tea.C
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile, delete all DNS records
-				for _, recordID := range dnsRecordIDs {
-					cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, recordID)
-				}
 				return createEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:  "caddy_validate",
 					backupPath: backupPath,
 				}
 			}
 
 			// Step 5: Restart Caddy container
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile, delete all DNS records
-				for _, recordID := range dnsRecordIDs {
-					cfClient.DeleteDNSRecord(cfg.Cloudflare.ZoneID, recordID)
-				}
 				return createEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:  "caddy_restart",
 					backupPath: backupPath,
 				}
 			}
+			j.MarkReloaded()
+		}
+
+		// Step 6: Route the domains through the tunnel (last: cloudflared restarts)
+		if viaTunnel {
+			tunnelOutput, err := applyIngress(cfg, j, setIngressRules(cfg, form, fqdns, port))
+			if err != nil {
+				return createEntryMsg{
+					success:    false,
+					err:        rollbackWithError(j, cfClient, cfg, err, "tunnel ingress"),
+					errorStep:  "tunnel_ingress",
+					backupPath: backupPath,
+				}
+			}
+			reloadOutput = appendOutput(reloadOutput, tunnelOutput)
 		}
 
 		// Success!
+		j.Commit()
 		return createEntryMsg{
-			success:    true,
-			backupPath: backupPath,
+			success:      true,
+			reloadOutput: reloadOutput,
+			backupPath:   backupPath,
 			// Note: dnsRecordID field no longer used (we now have multiple)
 		}
 	}
@@ -338,7 +463,7 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 		}
 
 		// Build FQDNs - for update, use first subdomain for primary entry
-		fqdns := BuildFQDNs(subdomains, cfg.Domain)
+		fqdns := ResolveFQDNs(subdomains, cfg)
 		fqdn := fqdns[0] // Primary FQDN for DNS record
 
 		// Parse port
@@ -347,54 +472,45 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 			fmt.Sscanf(form.ServicePort, "%d", &port)
 		}
 
+		// In direct tunnel mode cloudflared reaches the upstream itself
+		viaTunnel := formViaTunnel(cfg, form)
+		form = directTunnelForm(cfg, form)
+
+		// Every change is journaled so a later failure can undo the earlier steps
+		var reloadOutput string
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		j, err := beginJournal(cfg, "update", oldEntry.Domain)
+		if err != nil {
+			return updateEntryMsg{
+				success:   false,
+				err:       err,
+				errorStep: "journal",
+			}
+		}
+
 		// Step 1: Backup Caddyfile (if we're going to modify Caddy)
-		var err error
 		// Backup if: old entry had Caddy, OR we're adding Caddy (switching from DNS-only to full)
 		if oldEntry.Caddy != nil || !form.DNSOnly {
-			backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+			backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
 			if err != nil {
 				return updateEntryMsg{
 					success:   false,
-					err:       err,
+					err:       rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 					errorStep: "backup",
 				}
 			}
+			j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 		}
 
-		// Step 2: Update DNS record in Cloudflare (only if DNS fields changed)
-		cfClient := cloudflare.NewClient(apiToken)
-		var oldDNSRecord cloudflare.DNSRecord
-		dnsUpdated := false
-		if oldEntry.DNS != nil {
-			// Save old DNS values for rollback
-			oldDNSRecord = *oldEntry.DNS
-
-			// Check if DNS fields actually changed
-			dnsChanged := oldEntry.DNS.Type != form.DNSType ||
-				oldEntry.DNS.Content != form.DNSTarget ||
-				oldEntry.DNS.Proxied != form.Proxied ||
-				oldEntry.DNS.Name != fqdn
-
-			if dnsChanged {
-				// Prepare updated DNS record
-				updatedDNS := cloudflare.DNSRecord{
-					Type:    form.DNSType,
-					Name:    fqdn,
-					Content: form.DNSTarget,
-					Proxied: form.Proxied,
-					TTL:     1, // Auto
-				}
-
-				_, err = cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, updatedDNS)
-				if err != nil {
-					return updateEntryMsg{
-						success:    false,
-						err:        err,
-						errorStep:  "dns_update",
-						backupPath: backupPath,
-					}
-				}
-				dnsUpdated = true
+		// Step 2: Update DNS records in Cloudflare (only if DNS fields changed)
+		err = updateDNSRecords(ctx, cfClient, cfg, form, oldEntry, fqdn, j)
+		if err != nil {
+			return updateEntryMsg{
+				success:    false,
+				err:        rollbackWithError(j, cfClient, cfg, err, "DNS update"),
+				errorStep:  "dns_update",
+				backupPath: backupPath,
 			}
 		}
 
@@ -409,12 +525,9 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 			err = caddy.RemoveEntry(cfg.Caddy.CaddyfilePath, oldEntry.Domain)
 			if err != nil {
 				// Rollback DNS if we updated it
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        err,
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile remove"),
 					errorStep:  "caddy_remove",
 					backupPath: backupPath,
 				}
@@ -423,48 +536,27 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 			// Validate and restart after removal
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:  "caddy_validate",
 					backupPath: backupPath,
 				}
 			}
 
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:  "caddy_restart",
 					backupPath: backupPath,
 				}
 			}
+			j.MarkReloaded()
 		} else if oldEntry.Caddy != nil && !form.DNSOnly {
-			// Case 2: Had Caddy and staying in full mode - update Caddy entry
-			// Remove old Caddy block
-			err = caddy.RemoveEntry(cfg.Caddy.CaddyfilePath, oldEntry.Domain)
-			if err != nil {
-				// Rollback DNS if we updated it
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
-				return updateEntryMsg{
-					success:    false,
-					err:        err,
-					errorStep:  "caddy_remove",
-					backupPath: backupPath,
-				}
-			}
-
-			// Generate and append new Caddy block
-			caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
+			// Case 2: Had Caddy and staying in full mode - update Caddy entry in place
+			input := caddy.GenerateBlockInput{
 				FQDN:              fqdn,
 				Target:            form.ReverseProxyTarget,
 				Port:              port,
@@ -476,18 +568,34 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 				AllowedExtIP:      cfg.Defaults.AllowedExternalIP,
 				SelectedSnippets:  getSelectedSnippetNames(form.SelectedSnippets),
 				CustomCaddyConfig: form.CustomCaddyConfig,
-			})
+				Routes:            formPathRoutes(form),
+				Upstreams:         formUpstreams(form),
+				LoadBalancing:     formLoadBalancing(form),
+				ProxyOptions:      form.ProxyOptions,
+
+				Type:                 form.EntryType,
+				RedirectTo:           form.RedirectTo,
+				RedirectCode:         formRedirectCode(form),
+				RedirectPreservePath: form.RedirectPreservePath,
+				StaticRoot:           form.StaticRoot,
+				StaticBrowse:         form.StaticBrowse,
+			}
 
-			err = caddy.AppendEntry(cfg.Caddy.CaddyfilePath, caddyBlock)
+			if oldEntry.Caddy.IsHostRoute() {
+				// Host routes stay in their wildcard site block
+				err = caddy.ReplaceHostRoute(cfg.Caddy.CaddyfilePath, oldEntry.Domain, fqdns, caddy.GenerateRouteBody(input))
+			} else {
+				err = caddy.ReplaceEntry(cfg.Caddy.CaddyfilePath, oldEntry.Domain, caddy.GenerateCaddyBlock(input))
+			}
+			if err == nil && needsCloudflareTrust(form) {
+				err = caddy.TrustCloudflareProxies(cfg.Caddy.CaddyfilePath)
+			}
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile append"),
-					errorStep:  "caddy_append",
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile replace"),
+					errorStep:  "caddy_replace",
 					backupPath: backupPath,
 				}
 			}
@@ -496,31 +604,26 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:  "caddy_validate",
 					backupPath: backupPath,
 				}
 			}
 
 			// Step 5: Restart Caddy
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:  "caddy_restart",
 					backupPath: backupPath,
 				}
 			}
+			j.MarkReloaded()
 		} else if oldEntry.Caddy == nil && !form.DNSOnly {
 			// Case 3: Didn't have Caddy, switching to full mode - add Caddy entry
 			caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
@@ -535,17 +638,28 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 				AllowedExtIP:      cfg.Defaults.AllowedExternalIP,
 				SelectedSnippets:  getSelectedSnippetNames(form.SelectedSnippets),
 				CustomCaddyConfig: form.CustomCaddyConfig,
+				Routes:            formPathRoutes(form),
+				Upstreams:         formUpstreams(form),
+				LoadBalancing:     formLoadBalancing(form),
+				ProxyOptions:      form.ProxyOptions,
+
+				Type:                 form.EntryType,
+				RedirectTo:           form.RedirectTo,
+				RedirectCode:         formRedirectCode(form),
+				RedirectPreservePath: form.RedirectPreservePath,
+				StaticRoot:           form.StaticRoot,
+				StaticBrowse:         form.StaticBrowse,
 			})
 
-			err = caddy.AppendEntry(cfg.Caddy.CaddyfilePath, caddyBlock)
+			err = appendCaddyEntry(cfg, fqdn, caddyBlock)
+			if err == nil && needsCloudflareTrust(form) {
+				err = caddy.TrustCloudflareProxies(cfg.Caddy.CaddyfilePath)
+			}
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile append"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile append"),
 					errorStep:  "caddy_append",
 					backupPath: backupPath,
 				}
@@ -555,38 +669,60 @@ func updateEntryCmd(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEn
 			err = formatAndValidateCaddyfile(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 					errorStep:  "caddy_validate",
 					backupPath: backupPath,
 				}
 			}
 
 			// Restart Caddy
-			err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+			reloadOutput, err = reloadCaddy(cfg)
 			if err != nil {
 				// Rollback: Restore Caddyfile and DNS
-				if dnsUpdated {
-					cfClient.UpdateDNSRecord(cfg.Cloudflare.ZoneID, oldEntry.DNS.ID, oldDNSRecord)
-				}
 				return updateEntryMsg{
 					success:    false,
-					err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+					err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 					errorStep:  "caddy_restart",
 					backupPath: backupPath,
 				}
 			}
+			j.MarkReloaded()
 		}
 		// Case 4: oldEntry.Caddy == nil && form.DNSOnly - do nothing with Caddy
 
+		// Step 6: Update the tunnel ingress (last: cloudflared restarts).
+		// Rules of domains that left the tunnel or were renamed are removed.
+		if cfg.Tunnel.Enabled() && (viaTunnel || oldEntry.Ingress != nil) {
+			tunnelOutput, err := applyIngress(cfg, j, func(f *tunnel.File) error {
+				if oldEntry.Ingress != nil && (!viaTunnel || !slices.ContainsFunc(fqdns, func(d string) bool {
+					return strings.EqualFold(d, oldEntry.Domain)
+				})) {
+					f.RemoveRule(oldEntry.Domain)
+				}
+				if viaTunnel {
+					return setIngressRules(cfg, form, fqdns, port)(f)
+				}
+				return nil
+			})
+			if err != nil {
+				return updateEntryMsg{
+					success:    false,
+					err:        rollbackWithError(j, cfClient, cfg, err, "tunnel ingress"),
+					errorStep:  "tunnel_ingress",
+					backupPath: backupPath,
+				}
+			}
+			reloadOutput = appendOutput(reloadOutput, tunnelOutput)
+		}
+
 		// Success!
+		j.Commit()
 		return updateEntryMsg{
-			success:    true,
-			backupPath: backupPath,
+			success:      true,
+			reloadOutput: reloadOutput,
+			backupPath:   backupPath,
 		}
 	}
 }
@@ -597,7 +733,7 @@ func syncEntryCmd(cfg *config.Config, entry diff.SyncedEntry, apiToken string) t
 		// Determine what to create based on entry status
 		if entry.Status == diff.StatusOrphanedDNS {
 			// DNS exists but Caddy doesn't - create Caddy entry
-			return syncToCaddyCmd(cfg, entry)()
+			return syncToCaddyCmd(cfg, entry, apiToken)()
 		} else if entry.Status == diff.StatusOrphanedCaddy {
 			// Caddy exists but DNS doesn't - create DNS record
 			return syncToDNSCmd(cfg, entry, apiToken)()
@@ -613,7 +749,7 @@ func syncEntryCmd(cfg *config.Config, entry diff.SyncedEntry, apiToken string) t
 }
 
 // syncToCaddyCmd creates a Caddy entry for an orphaned DNS record
-func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry) tea.Cmd {
+func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry, apiToken string) tea.Cmd {
 	return func() tea.Msg {
 		if entry.DNS == nil {
 			return syncEntryMsg{
@@ -627,18 +763,35 @@ func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry) tea.Cmd {
 
 		var backupPath string
 		var err error
+		var reloadOutput string
 
-		// Step 1: Backup Caddyfile
-		backupPath, err = caddy.BackupCaddyfile(cfg.Caddy.CaddyfilePath)
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+
+		// Journal the Caddyfile change so a crash mid-way can be undone (no DNS steps here)
+		j, err := beginJournal(cfg, "sync", entry.Domain)
 		if err != nil {
 			return syncEntryMsg{
 				success:   false,
 				err:       err,
+				errorStep: "journal",
+				domain:    entry.Domain,
+				syncType:  "to_caddy",
+			}
+		}
+
+		// Step 1: Backup Caddyfile
+		backupPath, err = backupCaddyfile(ctx, cfClient, cfg)
+		if err != nil {
+			return syncEntryMsg{
+				success:   false,
+				err:       rollbackWithError(j, cfClient, cfg, err, "Caddyfile backup"),
 				errorStep: "backup",
 				domain:    entry.Domain,
 				syncType:  "to_caddy",
 			}
 		}
+		j.RecordCaddyfile(cfg.Caddy.CaddyfilePath, backupPath)
 
 		// Step 2: Generate Caddy block using defaults from config
 		caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
@@ -656,11 +809,11 @@ func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry) tea.Cmd {
 		})
 
 		// Step 3: Append to Caddyfile
-		err = caddy.AppendEntry(cfg.Caddy.CaddyfilePath, caddyBlock)
+		err = appendCaddyEntry(cfg, entry.Domain, caddyBlock)
 		if err != nil {
 			return syncEntryMsg{
 				success:    false,
-				err:        err,
+				err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile append"),
 				errorStep:  "caddy_append",
 				backupPath: backupPath,
 				domain:     entry.Domain,
@@ -674,7 +827,7 @@ func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry) tea.Cmd {
 			// Rollback: Restore Caddyfile
 			return syncEntryMsg{
 				success:    false,
-				err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddyfile validation"),
+				err:        rollbackWithError(j, cfClient, cfg, err, "Caddyfile validation"),
 				errorStep:  "caddy_validate",
 				backupPath: backupPath,
 				domain:     entry.Domain,
@@ -683,25 +836,28 @@ func syncToCaddyCmd(cfg *config.Config, entry diff.SyncedEntry) tea.Cmd {
 		}
 
 		// Step 5: Restart Caddy
-		err = caddy.RestartCaddy(cfg.Caddy.ContainerName)
+		reloadOutput, err = reloadCaddy(cfg)
 		if err != nil {
 			// Rollback: Restore Caddyfile
 			return syncEntryMsg{
 				success:    false,
-				err:        restoreBackupWithError(cfg.Caddy.CaddyfilePath, backupPath, err, "Caddy restart"),
+				err:        rollbackWithError(j, cfClient, cfg, err, "Caddy reload"),
 				errorStep:  "caddy_restart",
 				backupPath: backupPath,
 				domain:     entry.Domain,
 				syncType:   "to_caddy",
 			}
 		}
+		j.MarkReloaded()
 
 		// Success!
+		j.Commit()
 		return syncEntryMsg{
-			success:    true,
-			backupPath: backupPath,
-			domain:     entry.Domain,
-			syncType:   "to_caddy",
+			success:      true,
+			reloadOutput: reloadOutput,
+			backupPath:   backupPath,
+			domain:       entry.Domain,
+			syncType:     "to_caddy",
 		}
 	}
 }
@@ -720,16 +876,9 @@ func syncToDNSCmd(cfg *config.Config, entry diff.SyncedEntry, apiToken string) t
 		}
 
 		// Create DNS record using defaults from config
-		cfClient := cloudflare.NewClient(apiToken)
-		dnsRecord := cloudflare.DNSRecord{
-			Type:    "CNAME",
-			Name:    entry.Domain,
-			Content: cfg.Defaults.CNAMETarget,
-			Proxied: cfg.Defaults.Proxied,
-			TTL:     1, // Auto
-		}
-
-		createdRecord, err := cfClient.CreateDNSRecord(cfg.Cloudflare.ZoneID, dnsRecord)
+		cfClient := dnsprovider.New(cfg, apiToken)
+		ctx := context.Background()
+		createdRecord, err := cfClient.CreateDNSRecord(ctx, cfg.ZoneIDFor(entry.Domain), syncDNSRecord(cfg, entry))
 		if err != nil {
 			return syncEntryMsg{
 				success:   false,
@@ -749,3 +898,154 @@ func syncToDNSCmd(cfg *config.Config, entry diff.SyncedEntry, apiToken string) t
 		}
 	}
 }
+
+// syncDNSRecord builds the owned CNAME record created for a Caddy entry without DNS.
+// Entries the tunnel already routes point at the tunnel, whose records must be
+// proxied; any other entry gets the configured defaults.
+func syncDNSRecord(cfg *config.Config, entry diff.SyncedEntry) cloudflare.DNSRecord {
+	record := cloudflare.DNSRecord{
+		Type:    "CNAME",
+		Name:    entry.Domain,
+		Content: cfg.Defaults.CNAMETarget,
+		Proxied: cfg.Defaults.Proxied && proxySupported(cfg),
+		TTL:     1, // Auto
+		Comment: cloudflare.OwnerMarker,
+	}
+	if entry.Ingress != nil && cfg.Tunnel.Enabled() {
+		record.Content = cfg.Tunnel.CNAMETarget()
+		record.Proxied = true
+	}
+	return record
+}
+
+// dnsRecordsForForm builds the DNS records for one domain from the form, tagged as owned.
+// Dual-stack (A+AAAA) forms produce an A record followed by an AAAA record.
+func dnsRecordsForForm(form AddFormData, fqdn string) []cloudflare.DNSRecord {
+	if form.DNSType != diff.DualStackType {
+		return []cloudflare.DNSRecord{{
+			Type:    form.DNSType,
+			Name:    fqdn,
+			Content: form.DNSTarget,
+			Proxied: form.Proxied,
+			TTL:     1, // Auto
+			Comment: cloudflare.OwnerMarker,
+		}}
+	}
+
+	ipv4, ipv6 := splitDualStackTarget(form.DNSTarget)
+	return []cloudflare.DNSRecord{
+		{Type: "A", Name: fqdn, Content: ipv4, Proxied: form.Proxied, TTL: 1, Comment: cloudflare.OwnerMarker},
+		{Type: "AAAA", Name: fqdn, Content: ipv6, Proxied: form.Proxied, TTL: 1, Comment: cloudflare.OwnerMarker},
+	}
+}
+
+// hasWildcardRecord reports whether the zone has a wildcard record serving fqdn
+func hasWildcardRecord(ctx context.Context, cfClient dnsprovider.Records, zoneID, fqdn string) (bool, error) {
+	wildcard := diff.WildcardName(strings.ToLower(fqdn))
+	if wildcard == "" {
+		return false, nil
+	}
+	records, err := cfClient.ListDNSRecords(ctx, zoneID, "")
+	if err != nil {
+		return false, err
+	}
+	for _, record := range records {
+		if strings.EqualFold(record.Name, wildcard) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordZoneID returns the zone a record lives in, falling back to routing by name
+func recordZoneID(cfg *config.Config, record cloudflare.DNSRecord) string {
+	if record.ZoneID != "" {
+		return record.ZoneID
+	}
+	return cfg.ZoneIDFor(record.Name)
+}
+
+// deleteEntryDNS deletes an entry's DNS record, plus the paired AAAA record for dual-stack entries.
+// Deleted records are recorded in j (may be nil) so they can be recreated on rollback.
+func deleteEntryDNS(ctx context.Context, cfClient dnsprovider.Records, cfg *config.Config, entry diff.SyncedEntry, j *journal.Journal) error {
+	zoneID := recordZoneID(cfg, *entry.DNS)
+	if err := cfClient.DeleteDNSRecord(ctx, zoneID, entry.DNS.ID); err != nil {
+		return err
+	}
+	j.RecordDNSDelete(zoneID, *entry.DNS)
+	if entry.DNSAAAA != nil {
+		zoneID := recordZoneID(cfg, *entry.DNSAAAA)
+		if err := cfClient.DeleteDNSRecord(ctx, zoneID, entry.DNSAAAA.ID); err != nil {
+			return fmt.Errorf("failed to delete AAAA record: %w", err)
+		}
+		j.RecordDNSDelete(zoneID, *entry.DNSAAAA)
+	}
+	return nil
+}
+
+// keepComment returns desired with the existing record's comment, plus the
+// ownership marker, so an update does not wipe notes made in Cloudflare
+func keepComment(existing, desired cloudflare.DNSRecord) cloudflare.DNSRecord {
+	desired.Comment = existing.Comment
+	return desired.Owned()
+}
+
+// dnsRecordChanged reports whether an existing record differs from the desired one
+func dnsRecordChanged(existing, desired cloudflare.DNSRecord) bool {
+	return existing.Type != desired.Type ||
+		existing.Content != desired.Content ||
+		existing.Proxied != desired.Proxied ||
+		existing.Name != desired.Name
+}
+
+// updateDNSRecords converges an entry's DNS records on the form values.
+// The primary record is updated in place; a paired AAAA record is updated,
+// created or deleted as the entry moves to or from dual-stack.
+// Every change is recorded in j so the caller can roll it back.
+func updateDNSRecords(ctx context.Context, cfClient dnsprovider.Records, cfg *config.Config, form AddFormData, oldEntry diff.SyncedEntry, fqdn string, j *journal.Journal) error {
+	if oldEntry.DNS == nil {
+		return nil
+	}
+
+	// Records can't move between zones; renaming into another zone needs delete + add
+	zoneID := recordZoneID(cfg, *oldEntry.DNS)
+	if cfg.ZoneIDFor(fqdn) != zoneID {
+		return fmt.Errorf("%s belongs to a different zone than %s - delete the entry and add it again", fqdn, oldEntry.Domain)
+	}
+
+	desired := dnsRecordsForForm(form, fqdn)
+
+	// Primary record (CNAME, A or AAAA)
+	if dnsRecordChanged(*oldEntry.DNS, desired[0]) {
+		updated, err := cfClient.UpdateDNSRecord(ctx, zoneID, oldEntry.DNS.ID, keepComment(*oldEntry.DNS, desired[0]))
+		if err != nil {
+			return err
+		}
+		j.RecordDNSUpdate(zoneID, *oldEntry.DNS, *updated)
+	}
+
+	// Paired AAAA record (dual-stack)
+	switch {
+	case len(desired) > 1 && oldEntry.DNSAAAA != nil:
+		if dnsRecordChanged(*oldEntry.DNSAAAA, desired[1]) {
+			updated, err := cfClient.UpdateDNSRecord(ctx, zoneID, oldEntry.DNSAAAA.ID, keepComment(*oldEntry.DNSAAAA, desired[1]))
+			if err != nil {
+				return err
+			}
+			j.RecordDNSUpdate(zoneID, *oldEntry.DNSAAAA, *updated)
+		}
+	case len(desired) > 1:
+		created, err := cfClient.CreateDNSRecord(ctx, zoneID, desired[1])
+		if err != nil {
+			return fmt.Errorf("failed to create AAAA record for %s: %w", fqdn, err)
+		}
+		j.RecordDNSCreate(zoneID, *created)
+	case oldEntry.DNSAAAA != nil:
+		if err := cfClient.DeleteDNSRecord(ctx, zoneID, oldEntry.DNSAAAA.ID); err != nil {
+			return fmt.Errorf("failed to delete AAAA record for %s: %w", fqdn, err)
+		}
+		j.RecordDNSDelete(zoneID, *oldEntry.DNSAAAA)
+	}
+
+	return nil
+}
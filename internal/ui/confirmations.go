@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -37,19 +38,24 @@ func (m Model) renderConfirmDeleteView() string {
 	// Show DNS info if exists
 	if entry.DNS != nil {
 		entryContent.WriteString("\n")
-		entryContent.WriteString("DNS Record (Cloudflare):\n")
+		entryContent.WriteString(fmt.Sprintf("DNS Record (%s):\n", dnsprovider.InfoFor(m.config).Name))
 		entryContent.WriteString(fmt.Sprintf("  Type:     %s\n", entry.DNS.Type))
 		entryContent.WriteString(fmt.Sprintf("  Name:     %s\n", entry.DNS.Name))
 		entryContent.WriteString(fmt.Sprintf("  Content:  %s\n", entry.DNS.Content))
-		entryContent.WriteString(fmt.Sprintf("  Proxied:  %v\n", entry.DNS.Proxied))
+		if proxySupported(m.config) {
+			entryContent.WriteString(fmt.Sprintf("  Proxied:  %v\n", entry.DNS.Proxied))
+		}
 		entryContent.WriteString(fmt.Sprintf("  ID:       %s\n", entry.DNS.ID))
+		if entry.DNSAAAA != nil {
+			entryContent.WriteString(fmt.Sprintf("  AAAA:     %s (ID: %s)\n", entry.DNSAAAA.Content, entry.DNSAAAA.ID))
+		}
 	}
 
 	// Show Caddy info if exists
 	if entry.Caddy != nil {
 		entryContent.WriteString("\n")
 		entryContent.WriteString("Caddy Configuration:\n")
-		entryContent.WriteString(fmt.Sprintf("  Target:   %s:%d\n", entry.Caddy.Target, entry.Caddy.Port))
+		entryContent.WriteString(fmt.Sprintf("  Target:   %s\n", entry.Caddy.Destination()))
 		entryContent.WriteString(fmt.Sprintf("  SSL:      %v\n", entry.Caddy.SSL))
 		if entry.Caddy.IPRestricted {
 			entryContent.WriteString("  Features: IP Restricted\n")
@@ -62,6 +68,13 @@ func (m Model) renderConfirmDeleteView() string {
 		}
 	}
 
+	// The tunnel ingress rule goes with the DNS record
+	if entry.Ingress != nil && m.delete.Scope != DeleteCaddyOnly {
+		entryContent.WriteString("\n")
+		entryContent.WriteString("Tunnel Ingress (cloudflared):\n")
+		entryContent.WriteString(fmt.Sprintf("  Service:  %s\n", entry.Ingress.Service))
+	}
+
 	b.WriteString(entryBox.Render(entryContent.String()))
 	b.WriteString("\n\n")
 
@@ -73,6 +86,8 @@ func (m Model) renderConfirmDeleteView() string {
 		deleteMessage = StyleWarning.Render("⚠ This will delete the DNS record from Cloudflare only")
 	} else if entry.Caddy != nil {
 		deleteMessage = StyleWarning.Render("⚠ This will delete the Caddy entry from Caddyfile only")
+	} else if entry.Ingress != nil {
+		deleteMessage = StyleWarning.Render("⚠ This will remove the ingress rule from the cloudflared config")
 	}
 	b.WriteString(deleteMessage)
 	b.WriteString("\n\n")
@@ -124,13 +139,13 @@ func (m Model) renderConfirmSyncView() string {
 	entryContent.WriteString("Current State:\n")
 
 	if entry.DNS != nil {
-		entryContent.WriteString(fmt.Sprintf("  DNS:   %s → %s\n", entry.DNS.Type, entry.DNS.Content))
+		entryContent.WriteString(fmt.Sprintf("  DNS:   %s → %s\n", entry.DNSType(), entry.DNSContent()))
 	} else {
 		entryContent.WriteString("  DNS:   (missing)\n")
 	}
 
 	if entry.Caddy != nil {
-		entryContent.WriteString(fmt.Sprintf("  Caddy: %s:%d\n", entry.Caddy.Target, entry.Caddy.Port))
+		entryContent.WriteString(fmt.Sprintf("  Caddy: %s\n", entry.Caddy.Destination()))
 	} else {
 		entryContent.WriteString("  Caddy: (missing)\n")
 	}
@@ -146,7 +161,9 @@ func (m Model) renderConfirmSyncView() string {
 		entryContent.WriteString(StyleSuccess.Render("Will create DNS record:\n"))
 		entryContent.WriteString(fmt.Sprintf("  Type:    CNAME\n"))
 		entryContent.WriteString(fmt.Sprintf("  Target:  %s\n", m.config.Defaults.CNAMETarget))
-		entryContent.WriteString(fmt.Sprintf("  Proxied: %v\n", m.config.Defaults.Proxied))
+		if proxySupported(m.config) {
+			entryContent.WriteString(fmt.Sprintf("  Proxied: %v\n", m.config.Defaults.Proxied))
+		}
 		entryContent.WriteString("  (using defaults from config)\n")
 	}
 
@@ -242,9 +259,9 @@ func (m Model) renderConfirmBulkDeleteView() string {
 			break
 		}
 		if m.bulkDelete.Type == "dns" {
-			listContent.WriteString(fmt.Sprintf("%s (DNS: %s → %s)\n", entry.Domain, entry.DNS.Type, entry.DNS.Content))
+			listContent.WriteString(fmt.Sprintf("%s (DNS: %s → %s)\n", entry.Domain, entry.DNSType(), entry.DNSContent()))
 		} else {
-			listContent.WriteString(fmt.Sprintf("%s (Caddy: %s:%d)\n", entry.Domain, entry.Caddy.Target, entry.Caddy.Port))
+			listContent.WriteString(fmt.Sprintf("%s (Caddy: %s)\n", entry.Domain, entry.Caddy.Destination()))
 		}
 	}
 
@@ -303,11 +320,11 @@ func (m Model) renderConfirmBatchDeleteView() string {
 		}
 		details := ""
 		if entry.DNS != nil && entry.Caddy != nil {
-			details = fmt.Sprintf("(DNS: %s → %s, Caddy: %s:%d)", entry.DNS.Type, entry.DNS.Content, entry.Caddy.Target, entry.Caddy.Port)
+			details = fmt.Sprintf("(DNS: %s → %s, Caddy: %s)", entry.DNSType(), entry.DNSContent(), entry.Caddy.Destination())
 		} else if entry.DNS != nil {
-			details = fmt.Sprintf("(DNS: %s → %s)", entry.DNS.Type, entry.DNS.Content)
+			details = fmt.Sprintf("(DNS: %s → %s)", entry.DNSType(), entry.DNSContent())
 		} else if entry.Caddy != nil {
-			details = fmt.Sprintf("(Caddy: %s:%d)", entry.Caddy.Target, entry.Caddy.Port)
+			details = fmt.Sprintf("(Caddy: %s)", entry.Caddy.Destination())
 		}
 		listContent.WriteString(fmt.Sprintf("%s %s\n", entry.Domain, details))
 	}
@@ -429,6 +446,11 @@ func (m Model) renderDeleteScopeView() string {
 		availableScopes = []DeleteScope{DeleteCaddyOnly}
 		b.WriteString(StyleWarning.Render("⚠ This entry only has Caddyfile entry (no DNS record)"))
 		b.WriteString("\n\n")
+	} else if entry.Ingress != nil {
+		// Only a stale tunnel ingress rule is left
+		availableScopes = []DeleteScope{DeleteAll}
+		b.WriteString(StyleWarning.Render("⚠ This entry only has a tunnel ingress rule"))
+		b.WriteString("\n\n")
 	}
 
 	// If there's only one option, auto-select it and show message
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
 	"strings"
 )
@@ -34,6 +35,22 @@ func BuildFQDNs(subdomains []string, baseDomain string) []string {
 	return fqdns
 }
 
+// ResolveFQDNs is BuildFQDNs for a profile with several zones.
+// Entries that already end in one of the profile's zone domains are kept as-is,
+// everything else becomes a subdomain of the primary domain.
+// Example: ["app", "bar.example.net"] -> ["app.example.com", "bar.example.net"]
+func ResolveFQDNs(subdomains []string, cfg *config.Config) []string {
+	fqdns := []string{}
+	for _, subdomain := range subdomains {
+		if _, ok := cfg.ZoneFor(subdomain); ok && cfg.IsMultiZone() {
+			fqdns = append(fqdns, strings.ToLower(strings.TrimSuffix(subdomain, ".")))
+			continue
+		}
+		fqdns = append(fqdns, BuildFQDNs([]string{subdomain}, cfg.Domain)...)
+	}
+	return fqdns
+}
+
 // ValidateSubdomains checks that subdomains are valid and non-duplicate
 func ValidateSubdomains(subdomains []string) error {
 	if len(subdomains) == 0 {
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"lazyproxyflare/internal/config"
+)
+
+// TestResolveFQDNs tests that names in additional zones are kept and the rest use the primary domain
+func TestResolveFQDNs(t *testing.T) {
+	single := headlessTestConfig()
+	multi := headlessTestConfig()
+	multi.Cloudflare.Zones = []config.ZoneConfig{{ZoneID: "22222222222222222222222222222222", Domain: "example.net"}}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		input    []string
+		expected string
+	}{
+		{"Single zone subdomains", single, []string{"app", "api"}, "app.example.com,api.example.com"},
+		{"Single zone keeps legacy behaviour", single, []string{"bar.example.net"}, "bar.example.net.example.com"},
+		{"Additional zone FQDN", multi, []string{"app", "Bar.example.net."}, "app.example.com,bar.example.net"},
+		{"Primary zone FQDN", multi, []string{"app.example.com"}, "app.example.com"},
+		{"Unknown zone becomes subdomain", multi, []string{"app.example.org"}, "app.example.org.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(ResolveFQDNs(tt.input, tt.cfg), ",")
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestNextZoneFilter tests cycling through zones and back to all
+func TestNextZoneFilter(t *testing.T) {
+	zones := []string{"example.com", "Example.net"}
+	got := []string{}
+	current := ""
+	for i := 0; i < 3; i++ {
+		current = nextZoneFilter(current, zones)
+		got = append(got, current)
+	}
+	if strings.Join(got, ",") != "example.com,example.net," {
+		t.Errorf("Unexpected cycle: %q", got)
+	}
+}
@@ -5,10 +5,21 @@ import (
 	"strings"
 
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
+// subdomainPlaceholder explains how subdomain input becomes FQDNs for the profile's zones
+func subdomainPlaceholder(cfg *config.Config) string {
+	if cfg.IsMultiZone() {
+		return "(one per line - subdomain of " + cfg.Domain + ", or full name in " + strings.Join(cfg.ZoneDomains()[1:], ", ") + ")"
+	}
+	return "(one subdomain per line - will become subdomain." + cfg.Domain + ")"
+}
+
 // wrapText wraps text to fit within maxWidth, preserving existing newlines
 func wrapText(text string, maxWidth int) string {
 	if maxWidth <= 0 {
@@ -85,7 +96,7 @@ func (m Model) renderAddFormContent() string {
 	}{
 		label:       "Subdomain(s)",
 		value:       m.addForm.Subdomain,
-		placeholder: "(one subdomain per line - will become subdomain." + m.config.Domain + ")",
+		placeholder: subdomainPlaceholder(m.config),
 		focused:     m.addForm.FocusedField == 0,
 	}
 
@@ -146,6 +157,10 @@ func (m Model) renderAddFormContent() string {
 		dnsTargetPlaceholder = "(target domain for CNAME)"
 	} else if m.addForm.DNSType == "A" {
 		dnsTargetPlaceholder = "(IP address for A record)"
+	} else if m.addForm.DNSType == "AAAA" {
+		dnsTargetPlaceholder = "(IPv6 address for AAAA record)"
+	} else if m.addForm.DNSType == diff.DualStackType {
+		dnsTargetPlaceholder = "(IPv4, IPv6 - e.g. 10.0.0.5, 2001:db8::5)"
 	}
 
 	dnsTarget := struct {
@@ -197,22 +212,40 @@ func (m Model) renderAddFormContent() string {
 	b.WriteString(dnsOnlyStyle.Render(dnsOnlyCheckmark + " DNS Only (skip Caddy configuration)"))
 	b.WriteString("\n\n")
 
-	// Proxied checkbox (field 6) - DNS setting, shown in DNS section
-	proxiedStyle := normalStyle.Copy()
-	if m.addForm.FocusedField == 6 {
-		proxiedStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+	// Proxied checkbox (field 6) - DNS setting, shown in DNS section when the
+	// provider can proxy
+	if proxySupported(m.config) {
+		proxiedStyle := normalStyle.Copy()
+		if m.addForm.FocusedField == 6 {
+			proxiedStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+		}
+		proxiedCheckmark := "[ ]"
+		if m.addForm.Proxied {
+			proxiedCheckmark = "[✓]"
+		}
+		b.WriteString(proxiedStyle.Render(proxiedCheckmark + " Proxy through Cloudflare (orange cloud)"))
+		b.WriteString("\n\n")
 	}
-	proxiedCheckmark := "[ ]"
-	if m.addForm.Proxied {
-		proxiedCheckmark = "[✓]"
+	if warning := ipRestrictionWarning(m.addForm, m.snippets); warning != "" {
+		b.WriteString(StyleWarning.Render("⚠ " + warning))
+		b.WriteString("\n\n")
 	}
-	b.WriteString(proxiedStyle.Render(proxiedCheckmark + " Proxy through Cloudflare (orange cloud)"))
-	b.WriteString("\n\n")
 
 	// Separator for Caddy fields
 	if !m.addForm.DNSOnly {
 		b.WriteString(StyleDim.Render("--- Caddy Configuration ---"))
 		b.WriteString("\n\n")
+
+		// Entry type selector: the fields below depend on it
+		entryTypeStyle := normalStyle.Copy()
+		if m.addForm.FocusedField == m.entryTypeFieldIndex() {
+			entryTypeStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+		}
+		b.WriteString(normalStyle.Render("Entry Type:"))
+		b.WriteString("\n  ")
+		b.WriteString(entryTypeStyle.Render(fmt.Sprintf("< %s %s >", m.addForm.EntryType.Icon(), m.addForm.EntryType)))
+		b.WriteString(StyleDim.Render("  (space to change)"))
+		b.WriteString("\n\n")
 	}
 
 	// Remaining text fields (greyed out if DNS Only is checked)
@@ -238,6 +271,15 @@ func (m Model) renderAddFormContent() string {
 			fieldIndex:  5,
 		},
 	}
+	// Redirects and static sites use fields 4 and 5 for their own settings
+	switch m.addForm.EntryType {
+	case caddy.EntryRedirect:
+		fields[0].label, fields[0].value, fields[0].placeholder = "Redirect To", m.addForm.RedirectTo, "(e.g., https://example.com)"
+		fields[1].label, fields[1].value, fields[1].placeholder = "Status Code", m.addForm.RedirectCode, "(301 permanent, 302 temporary, 303, 307, 308)"
+	case caddy.EntryStatic:
+		fields[0].label, fields[0].value, fields[0].placeholder = "Site Root", m.addForm.StaticRoot, "(directory on the Caddy host, e.g., /srv/www)"
+		fields = fields[:1]
+	}
 
 	// Render text input fields (Caddy-related, greyed out if DNS Only)
 	for _, field := range fields {
@@ -285,6 +327,12 @@ func (m Model) renderAddFormContent() string {
 	}{
 		{"Backend uses HTTPS (upstream service SSL/TLS)", m.addForm.SSL, 7, false},
 	}
+	switch m.addForm.EntryType {
+	case caddy.EntryRedirect:
+		checkboxes[0].label, checkboxes[0].checked = "Keep the request path and query ({uri})", m.addForm.RedirectPreservePath
+	case caddy.EntryStatic:
+		checkboxes[0].label, checkboxes[0].checked = "List directories without an index file (browse)", m.addForm.StaticBrowse
+	}
 
 	for _, cb := range checkboxes {
 		// Skip Caddy-related checkboxes if DNS Only
@@ -408,6 +456,108 @@ func (m Model) renderAddFormContent() string {
 		b.WriteString("\n")
 	}
 
+	// Path routes and load balancing only apply to reverse proxies
+	if !m.addForm.DNSOnly && m.addForm.EntryType == caddy.EntryProxy {
+		// Path routes: other services under paths of the same hostname
+		b.WriteString("\n")
+		b.WriteString(StyleDim.Render("--- Path Routes (Optional) ---"))
+		b.WriteString("\n\n")
+		b.WriteString(StyleDim.Render("One per line: <path> <upstream> [strip] [+snippet]. Other paths use the target above:"))
+		b.WriteString("\n  ")
+
+		pathRoutesStyle := normalStyle.Copy()
+		if m.addForm.FocusedField == m.pathRoutesFieldIndex() {
+			pathRoutesStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+		}
+		pathRoutesDisplay := m.addForm.PathRoutes
+		if pathRoutesDisplay == "" {
+			pathRoutesDisplay = "(e.g., /grafana/* 10.0.0.5:3000 strip +ip_restricted)"
+		}
+		if m.addForm.FocusedField == m.pathRoutesFieldIndex() {
+			pathRoutesDisplay += "_" // Cursor
+		}
+		pathRoutesLines := strings.Split(pathRoutesDisplay, "\n")
+		for i, line := range pathRoutesLines {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(pathRoutesStyle.Render("[" + line + "]"))
+			if i < len(pathRoutesLines)-1 {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+
+		// Load balancing: further upstreams and health checks
+		b.WriteString("\n")
+		b.WriteString(StyleDim.Render("--- Load Balancing (Optional) ---"))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render("Additional Upstreams:"))
+		b.WriteString("\n  ")
+		upstreamsStyle := normalStyle.Copy()
+		if m.addForm.FocusedField == m.upstreamsFieldIndex() {
+			upstreamsStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+		}
+		upstreamsDisplay := m.addForm.Upstreams
+		if m.addForm.FocusedField == m.upstreamsFieldIndex() {
+			upstreamsDisplay += "_" // Cursor
+		}
+		if upstreamsDisplay == "" || upstreamsDisplay == "_" {
+			upstreamsDisplay = "(e.g., 10.0.0.6 10.0.0.7:8080; port defaults to the one above)"
+		}
+		b.WriteString(upstreamsStyle.Render("[" + upstreamsDisplay + "]"))
+		b.WriteString("\n\n")
+
+		b.WriteString(normalStyle.Render("Policy and Health Checks:"))
+		b.WriteString("\n  ")
+		lbStyle := normalStyle.Copy()
+		if m.addForm.FocusedField == m.loadBalancingFieldIndex() {
+			lbStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+		}
+		lbDisplay := m.addForm.LoadBalancing
+		if lbDisplay == "" {
+			lbDisplay = "(e.g., lb_policy least_conn, health_uri /healthz, max_fails 3; one per line)"
+		}
+		if m.addForm.FocusedField == m.loadBalancingFieldIndex() {
+			lbDisplay += "_" // Cursor
+		}
+		lbLines := strings.Split(lbDisplay, "\n")
+		for i, line := range lbLines {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(lbStyle.Render("[" + line + "]"))
+			if i < len(lbLines)-1 {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+		if n := len(m.addForm.ProxyOptions); n > 0 {
+			b.WriteString(StyleDim.Render(fmt.Sprintf("  (%d more reverse_proxy settings kept as written)", n)))
+			b.WriteString("\n")
+		}
+	}
+
+	if !m.addForm.DNSOnly {
+		// Host route option when a wildcard site block already serves the domains
+		if site := m.formWildcardSite(); site != "" && m.currentView == ViewAdd {
+			b.WriteString("\n")
+			b.WriteString(StyleDim.Render("--- Wildcard Site ---"))
+			b.WriteString("\n\n")
+
+			checkmark := "[ ]"
+			if m.addForm.HostRoute {
+				checkmark = "[✓]"
+			}
+			hostRouteStyle := normalStyle.Copy()
+			if m.addForm.FocusedField == m.hostRouteFieldIndex() {
+				hostRouteStyle = selectedStyle.Copy().Reverse(false).Bold(true).Foreground(lipgloss.Color("#00D7FF"))
+			}
+			b.WriteString(hostRouteStyle.Render(fmt.Sprintf("%s Add as host route in %s (no new site block)", checkmark, site)))
+			b.WriteString("\n")
+		}
+	}
+
 	// Instructions
 	b.WriteString("\n")
 	b.WriteString(StyleDim.Render("Navigate: ↑↓/jk  Toggle: space  Preview: enter  Cancel: esc"))
@@ -430,6 +580,75 @@ func (m Model) shouldSuggestSnippet(snippetName string) bool {
 	return false
 }
 
+// pathRoutesFieldIndex returns the field index of the path routes (after custom config)
+func (m Model) pathRoutesFieldIndex() int {
+	return 9 + len(m.snippets)
+}
+
+// upstreamsFieldIndex returns the field index of the additional upstreams (after path routes)
+func (m Model) upstreamsFieldIndex() int {
+	return 10 + len(m.snippets)
+}
+
+// loadBalancingFieldIndex returns the field index of the load balancing settings (after upstreams)
+func (m Model) loadBalancingFieldIndex() int {
+	return 11 + len(m.snippets)
+}
+
+// entryTypeFieldIndex returns the field index of the entry type selector
+// (after load balancing, though shown at the top of the Caddy fields)
+func (m Model) entryTypeFieldIndex() int {
+	return 12 + len(m.snippets)
+}
+
+// hostRouteFieldIndex returns the field index of the host route checkbox (after the entry type)
+func (m Model) hostRouteFieldIndex() int {
+	return 13 + len(m.snippets)
+}
+
+// hostRouteAvailable reports whether the add form offers the host route checkbox
+func (m Model) hostRouteAvailable() bool {
+	return m.currentView == ViewAdd && !m.addForm.DNSOnly && m.formWildcardSite() != ""
+}
+
+// formWildcardSite returns the wildcard site block that serves every domain in
+// the form, so the entry can be added to it as a host route ("" if none does)
+func (m Model) formWildcardSite() string {
+	fqdns := ResolveFQDNs(ParseSubdomains(m.addForm.Subdomain), m.config)
+	if len(fqdns) == 0 {
+		return ""
+	}
+	for _, entry := range m.entries {
+		if entry.Caddy == nil || entry.Caddy.IsHostRoute() {
+			continue
+		}
+		for _, site := range entry.Caddy.Domains {
+			if !caddy.IsWildcardSite(site) {
+				continue
+			}
+			covered := true
+			for _, fqdn := range fqdns {
+				covered = covered && caddy.SiteCovers(site, fqdn)
+			}
+			if covered {
+				return site
+			}
+		}
+	}
+	return ""
+}
+
+// wildcardRecordFor returns the name of the wildcard DNS record serving fqdn, or ""
+func (m Model) wildcardRecordFor(fqdn string) string {
+	wildcard := diff.WildcardName(strings.ToLower(fqdn))
+	for _, entry := range m.entries {
+		if wildcard != "" && entry.DNS != nil && strings.EqualFold(entry.DNS.Name, wildcard) {
+			return entry.DNS.Name
+		}
+	}
+	return ""
+}
+
 // renderEditFormContent renders the edit entry form modal content
 func (m Model) renderEditFormContent() string {
 	// Edit form is identical to add form content
@@ -442,7 +661,7 @@ func (m Model) renderPreviewContent() string {
 
 	// Parse subdomains and build FQDNs
 	subdomains := ParseSubdomains(m.addForm.Subdomain)
-	fqdns := BuildFQDNs(subdomains, m.config.Domain)
+	fqdns := ResolveFQDNs(subdomains, m.config)
 
 	// Parse port
 	port := 80
@@ -469,32 +688,43 @@ func (m Model) renderPreviewContent() string {
 		Padding(0, 1).
 		Width(70)
 
+	dnsInfo := dnsprovider.InfoFor(m.config)
 	dnsContent := strings.Builder{}
-	if len(fqdns) == 1 {
+	if wildcard := m.wildcardRecordFor(fqdns[0]); m.addForm.HostRoute && wildcard != "" {
+		// Host routes in a wildcard site are served by the wildcard record
+		dnsContent.WriteString(StyleInfo.Render(dnsInfo.Name + " DNS Record"))
+		dnsContent.WriteString("\n")
+		dnsContent.WriteString(fmt.Sprintf("  Served by wildcard record %s\n", wildcard))
+		dnsContent.WriteString("  (no new record is created)\n")
+	} else if len(fqdns) == 1 {
 		// Single domain - show traditional format
-		dnsContent.WriteString(StyleInfo.Render("Cloudflare DNS Record"))
+		dnsContent.WriteString(StyleInfo.Render(dnsInfo.Name + " DNS Record"))
 		dnsContent.WriteString("\n")
 		dnsContent.WriteString(fmt.Sprintf("  Type:     %s\n", m.addForm.DNSType))
 		dnsContent.WriteString(fmt.Sprintf("  Name:     %s\n", fqdns[0]))
 		dnsContent.WriteString(fmt.Sprintf("  Target:   %s\n", m.addForm.DNSTarget))
-		if m.addForm.Proxied {
-			dnsContent.WriteString(fmt.Sprintf("  Proxied:  Yes (Orange cloud enabled)\n"))
-		} else {
-			dnsContent.WriteString(fmt.Sprintf("  Proxied:  No (DNS-only)\n"))
+		if dnsInfo.Proxy {
+			if m.addForm.Proxied {
+				dnsContent.WriteString(fmt.Sprintf("  Proxied:  Yes (Orange cloud enabled)\n"))
+			} else {
+				dnsContent.WriteString(fmt.Sprintf("  Proxied:  No (DNS-only)\n"))
+			}
 		}
-		dnsContent.WriteString(fmt.Sprintf("  TTL:      Auto\n"))
+		dnsContent.WriteString(fmt.Sprintf("  TTL:      %s\n", diff.FormatTTL(dnsInfo.DefaultTTL)))
 	} else {
 		// Multiple domains - show list
-		dnsContent.WriteString(StyleInfo.Render(fmt.Sprintf("Cloudflare DNS Records (%d)", len(fqdns))))
+		dnsContent.WriteString(StyleInfo.Render(fmt.Sprintf("%s DNS Records (%d)", dnsInfo.Name, len(fqdns))))
 		dnsContent.WriteString("\n")
 		dnsContent.WriteString(fmt.Sprintf("  Type:     %s\n", m.addForm.DNSType))
 		dnsContent.WriteString(fmt.Sprintf("  Target:   %s\n", m.addForm.DNSTarget))
-		if m.addForm.Proxied {
-			dnsContent.WriteString(fmt.Sprintf("  Proxied:  Yes (Orange cloud enabled)\n"))
-		} else {
-			dnsContent.WriteString(fmt.Sprintf("  Proxied:  No (DNS-only)\n"))
+		if dnsInfo.Proxy {
+			if m.addForm.Proxied {
+				dnsContent.WriteString(fmt.Sprintf("  Proxied:  Yes (Orange cloud enabled)\n"))
+			} else {
+				dnsContent.WriteString(fmt.Sprintf("  Proxied:  No (DNS-only)\n"))
+			}
 		}
-		dnsContent.WriteString(fmt.Sprintf("  TTL:      Auto\n"))
+		dnsContent.WriteString(fmt.Sprintf("  TTL:      %s\n", diff.FormatTTL(dnsInfo.DefaultTTL)))
 		dnsContent.WriteString("\n")
 		dnsContent.WriteString("  Domains:\n")
 		for i, fqdn := range fqdns {
@@ -510,7 +740,8 @@ func (m Model) renderPreviewContent() string {
 	b.WriteString("\n\n")
 
 	// Caddy Block Preview (only if not DNS-only mode)
-	if !m.addForm.DNSOnly {
+	viaTunnel := formViaTunnel(m.config, m.addForm)
+	if !directTunnelForm(m.config, m.addForm).DNSOnly {
 		caddyBox := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(ColorBlue).
@@ -528,11 +759,19 @@ func (m Model) renderPreviewContent() string {
 		}
 
 		caddyContent := strings.Builder{}
-		caddyContent.WriteString(StyleInfo.Render("Caddyfile Entry"))
+		if m.addForm.HostRoute {
+			caddyContent.WriteString(StyleInfo.Render("Host Route in " + m.formWildcardSite()))
+		} else {
+			caddyContent.WriteString(StyleInfo.Render("Caddyfile Entry"))
+		}
 		caddyContent.WriteString("\n")
 
 		// Generate Caddy block (handles both single and multi-domain)
-		caddyBlock := caddy.GenerateCaddyBlock(caddy.GenerateBlockInput{
+		generate := caddy.GenerateCaddyBlock
+		if m.addForm.HostRoute {
+			generate = caddy.GenerateHostRoute
+		}
+		caddyBlock := generate(caddy.GenerateBlockInput{
 			Domains:           fqdns, // Use Domains field for both single and multi-domain
 			Target:            m.addForm.ReverseProxyTarget,
 			Port:              port,
@@ -545,17 +784,54 @@ func (m Model) renderPreviewContent() string {
 			AvailableSnippets: getSnippetNames(m.snippets),
 			SelectedSnippets:  selectedSnippets,
 			CustomCaddyConfig: m.addForm.CustomCaddyConfig,
+			Routes:            formPathRoutes(m.addForm),
+			Upstreams:         formUpstreams(m.addForm),
+			LoadBalancing:     formLoadBalancing(m.addForm),
+			ProxyOptions:      m.addForm.ProxyOptions,
+
+			Type:                 m.addForm.EntryType,
+			RedirectTo:           m.addForm.RedirectTo,
+			RedirectCode:         formRedirectCode(m.addForm),
+			RedirectPreservePath: m.addForm.RedirectPreservePath,
+			StaticRoot:           m.addForm.StaticRoot,
+			StaticBrowse:         m.addForm.StaticBrowse,
 		})
 		caddyContent.WriteString(caddyBlock)
 
 		b.WriteString(caddyBox.Render(caddyContent.String()))
 		b.WriteString("\n\n")
+		if needsCloudflareTrust(m.addForm) {
+			b.WriteString(StyleDim.Render("(Global options: Cloudflare's IP ranges are set as trusted_proxies)"))
+			b.WriteString("\n\n")
+		}
+	} else if viaTunnel {
+		b.WriteString(StyleDim.Render("(Direct tunnel mode: cloudflared reaches the upstream, Caddy configuration will be skipped)"))
+		b.WriteString("\n\n")
 	} else {
 		// DNS-only mode message
 		b.WriteString(StyleDim.Render("(DNS-only mode: Caddy configuration will be skipped)"))
 		b.WriteString("\n\n")
 	}
 
+	// Tunnel ingress preview
+	if viaTunnel {
+		tunnelBox := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(ColorBlue).
+			Padding(0, 1).
+			Width(70)
+
+		tunnelContent := strings.Builder{}
+		tunnelContent.WriteString(StyleInfo.Render("Tunnel Ingress (" + m.config.Tunnel.ConfigPath + ")"))
+		tunnelContent.WriteString("\n")
+		for _, fqdn := range fqdns {
+			rule := ingressRuleFor(m.config, m.addForm, fqdn, port)
+			tunnelContent.WriteString(fmt.Sprintf("  %s → %s\n", rule.Hostname, rule.Service))
+		}
+		b.WriteString(tunnelBox.Render(tunnelContent.String()))
+		b.WriteString("\n\n")
+	}
+
 	// Status display
 	if m.loading {
 		if m.editingEntry != nil {
@@ -0,0 +1,373 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+)
+
+// OperationResult is the outcome of a CRUD operation run outside the TUI
+type OperationResult struct {
+	Success      bool
+	Err          error
+	ErrorStep    string // Which step failed
+	BackupPath   string // Caddyfile backup taken before the change (if any)
+	Domain       string // Primary domain the operation applied to
+	EntityType   string // "dns", "caddy", or "both"
+	SyncType     string // "to_dns" or "to_caddy" (sync only)
+	ReloadOutput string // Output of the Caddy reload command (if any)
+}
+
+// NewAddForm returns an add form pre-filled with the profile defaults
+func NewAddForm(cfg *config.Config) AddFormData {
+	form := AddFormData{
+		Subdomain:          "",
+		DNSType:            "CNAME",
+		DNSTarget:          cfg.Defaults.CNAMETarget,
+		DNSOnly:            false,
+		ReverseProxyTarget: "localhost",
+		ServicePort:        fmt.Sprintf("%d", cfg.Defaults.Port),
+		Proxied:            cfg.Defaults.Proxied && proxySupported(cfg),
+		LANOnly:            false,
+		SSL:                cfg.Defaults.SSL,
+		OAuth:              false,
+		WebSocket:          false,
+		SelectedSnippets:   make(map[string]bool),
+		FocusedField:       0,
+
+		RedirectCode:         "301",
+		RedirectPreservePath: true,
+	}
+	if cfg.Tunnel.Enabled() {
+		// New entries go through the tunnel; tunnel records must be proxied
+		form.DNSTarget = cfg.Tunnel.CNAMETarget()
+		form.Proxied = true
+	}
+	return form
+}
+
+// EditFormFromEntry returns an edit form pre-populated with an existing entry's values
+func EditFormFromEntry(cfg *config.Config, entry diff.SyncedEntry) AddFormData {
+	// For multi-domain entries, populate all domains as newline-separated
+	subdomain := ""
+	if entry.Caddy != nil && len(entry.Caddy.Domains) > 0 {
+		// Multi-domain entry - format all domains as newline-separated subdomains
+		subdomain = GetSubdomainsTextareaValue(entry.Caddy.Domains, cfg.Domain)
+	} else if strings.HasSuffix(entry.Domain, "."+cfg.Domain) {
+		// Single domain (backwards compatibility)
+		subdomain = strings.TrimSuffix(entry.Domain, "."+cfg.Domain)
+	} else {
+		// Entry in another zone of a multi-zone profile - keep the FQDN
+		subdomain = entry.Domain
+	}
+
+	form := NewAddForm(cfg)
+	form.Subdomain = subdomain
+	if entry.DNS != nil {
+		form.DNSType = entry.DNSType()
+		form.DNSTarget = entry.DNSContent()
+		form.Proxied = entry.DNS.Proxied
+	}
+
+	form.DNSOnly = entry.Caddy == nil // If no Caddy config, it's DNS-only
+	if entry.Caddy != nil {
+		form.ReverseProxyTarget = entry.Caddy.Target
+		form.ServicePort = fmt.Sprintf("%d", entry.Caddy.Port)
+		form.SSL = entry.Caddy.SSL
+		form.LANOnly = entry.Caddy.IPRestricted
+		form.OAuth = entry.Caddy.OAuthHeaders
+		form.WebSocket = entry.Caddy.WebSocket
+		form.HostRoute = entry.Caddy.IsHostRoute()
+		form.PathRoutes = caddy.FormatPathRoutes(entry.Caddy.Routes)
+		form.Upstreams = strings.Join(entry.Caddy.Upstreams, " ")
+		form.LoadBalancing = entry.Caddy.LoadBalancing.String()
+		form.ProxyOptions = entry.Caddy.ProxyOptions
+		form.EntryType = entry.Caddy.Type
+		if entry.Caddy.Type == caddy.EntryRedirect {
+			form.RedirectTo = entry.Caddy.RedirectTo
+			form.RedirectCode = strconv.Itoa(entry.Caddy.RedirectCode)
+			form.RedirectPreservePath = entry.Caddy.RedirectPreservePath
+		}
+		form.StaticRoot = entry.Caddy.StaticRoot
+		form.StaticBrowse = entry.Caddy.StaticBrowse
+
+		// Pre-populate selected snippets from entry's imports
+		for _, importName := range entry.Caddy.Imports {
+			form.SelectedSnippets[importName] = true
+		}
+	} else if entry.Ingress != nil && cfg.Tunnel.Direct() {
+		// Direct tunnel entry: the upstream lives in the ingress rule
+		if u, err := url.Parse(entry.Ingress.Service); err == nil && u.Hostname() != "" {
+			form.DNSOnly = false
+			form.ReverseProxyTarget = u.Hostname()
+			form.SSL = u.Scheme == "https"
+			if port := u.Port(); port != "" {
+				form.ServicePort = port
+			}
+		}
+	}
+	return form
+}
+
+// ValidateForm checks the required add/edit form fields before preview or submit
+func ValidateForm(form AddFormData) error {
+	if form.Subdomain == "" {
+		return fmt.Errorf("Subdomain is required")
+	}
+	if form.DNSTarget == "" {
+		return fmt.Errorf("DNS Target is required")
+	}
+
+	// Validate A/AAAA record IP address format
+	switch form.DNSType {
+	case "A":
+		if !isValidIPAddress(form.DNSTarget) {
+			return fmt.Errorf("Invalid IP address format for A record")
+		}
+	case "AAAA":
+		if !isValidIPv6Address(form.DNSTarget) {
+			return fmt.Errorf("Invalid IPv6 address format for AAAA record")
+		}
+	case diff.DualStackType:
+		ipv4, ipv6 := splitDualStackTarget(form.DNSTarget)
+		if !isValidIPAddress(ipv4) || !isValidIPv6Address(ipv6) {
+			return fmt.Errorf("Dual-stack target must be \"IPv4, IPv6\" (e.g. 10.0.0.5, 2001:db8::5)")
+		}
+	}
+
+	// Redirects and static sites have their own fields
+	if !form.DNSOnly {
+		switch form.EntryType {
+		case caddy.EntryRedirect:
+			return validateRedirect(form)
+		case caddy.EntryStatic:
+			return validateStaticSite(form)
+		}
+	}
+
+	// Validate Caddy fields if not DNS-only
+	if !form.DNSOnly && form.ReverseProxyTarget == "" {
+		return fmt.Errorf("Reverse Proxy Target is required (or enable DNS Only)")
+	}
+	if !form.DNSOnly {
+		if _, err := caddy.ParsePathRoutes(form.PathRoutes); err != nil {
+			return fmt.Errorf("Invalid path routes: %w", err)
+		}
+		if _, err := caddy.ParseUpstreams(form.Upstreams); err != nil {
+			return fmt.Errorf("Invalid additional upstreams: %w", err)
+		}
+		if _, err := caddy.ParseLoadBalancing(form.LoadBalancing); err != nil {
+			return fmt.Errorf("Invalid load balancing: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateRedirect checks a redirect form's destination URL and status code
+func validateRedirect(form AddFormData) error {
+	if form.RedirectTo == "" {
+		return fmt.Errorf("Redirect URL is required (or enable DNS Only)")
+	}
+	u, err := url.Parse(form.RedirectTo)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.ContainsAny(form.RedirectTo, " \t") {
+		return fmt.Errorf("Redirect URL must be an absolute http(s) URL (e.g. https://example.com)")
+	}
+	if form.RedirectPreservePath && (u.RawQuery != "" || u.Fragment != "") {
+		return fmt.Errorf("Redirect URL can't have a query when keeping the request path")
+	}
+	if !slices.Contains(caddy.RedirectCodes, formRedirectCode(form)) {
+		return fmt.Errorf("Redirect code must be 301, 302, 303, 307 or 308")
+	}
+	return nil
+}
+
+// validateStaticSite checks a static site form's root directory
+func validateStaticSite(form AddFormData) error {
+	if form.StaticRoot == "" {
+		return fmt.Errorf("Site root directory is required (or enable DNS Only)")
+	}
+	if !strings.HasPrefix(form.StaticRoot, "/") || strings.ContainsAny(form.StaticRoot, " \t") {
+		return fmt.Errorf("Site root must be an absolute path without spaces (e.g. /srv/www)")
+	}
+	return nil
+}
+
+// formRedirectCode returns the form's redirect status code (0 if invalid)
+func formRedirectCode(form AddFormData) int {
+	code, _ := strconv.Atoi(form.RedirectCode)
+	return code
+}
+
+// formPathRoutes returns the form's path routes. ValidateForm has checked
+// them; invalid routes give none.
+func formPathRoutes(form AddFormData) []caddy.PathRoute {
+	routes, _ := caddy.ParsePathRoutes(form.PathRoutes)
+	return routes
+}
+
+// formUpstreams returns the form's additional upstreams (none if invalid)
+func formUpstreams(form AddFormData) []string {
+	upstreams, _ := caddy.ParseUpstreams(form.Upstreams)
+	return upstreams
+}
+
+// formLoadBalancing returns the form's load balancing settings (none if invalid)
+func formLoadBalancing(form AddFormData) caddy.LoadBalancing {
+	lb, _ := caddy.ParseLoadBalancing(form.LoadBalancing)
+	return lb
+}
+
+// needsCloudflareTrust reports whether a form's IP restriction depends on
+// Caddy trusting Cloudflare's proxies: behind the orange cloud every request
+// comes from a Cloudflare address, and only client_ip sees the visitor's
+func needsCloudflareTrust(form AddFormData) bool {
+	return !form.DNSOnly && form.Proxied && (form.LANOnly || form.SelectedSnippets["ip_restricted"])
+}
+
+// ipRestrictionWarning explains how a proxied form's IP restriction behaves,
+// or returns "" when the form has no such combination
+func ipRestrictionWarning(form AddFormData, snippets []caddy.Snippet) string {
+	if !needsCloudflareTrust(form) {
+		return ""
+	}
+	if form.SelectedSnippets["ip_restricted"] {
+		for _, snippet := range snippets {
+			if snippet.Name == "ip_restricted" && strings.Contains(snippet.Content, "remote_ip") && !strings.Contains(snippet.Content, "client_ip") {
+				return "Proxied + ip_restricted: the snippet matches remote_ip, which is\n  always a Cloudflare address here - change it to client_ip"
+			}
+		}
+	}
+	return "Proxied + IP restriction: visitors are checked by their public IP\n  (Cloudflare is added to trusted_proxies); LAN clients only match the\n  LAN subnet when they bypass Cloudflare"
+}
+
+// LoadEntries reads the Caddyfile and DNS records and runs the diff engine
+func LoadEntries(cfg *config.Config) ([]diff.SyncedEntry, []caddy.Snippet, error) {
+	msg := refreshDataCmd(context.Background(), cfg)().(refreshCompleteMsg)
+	if msg.err != nil {
+		return nil, nil, msg.err
+	}
+	return msg.entries, msg.snippets, nil
+}
+
+// FindEntry looks up an entry by FQDN or by subdomain of the profile domain
+func FindEntry(cfg *config.Config, entries []diff.SyncedEntry, name string) (diff.SyncedEntry, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	candidates := []string{name}
+	if !strings.HasSuffix(name, "."+strings.ToLower(cfg.Domain)) && name != strings.ToLower(cfg.Domain) {
+		candidates = append(candidates, name+"."+strings.ToLower(cfg.Domain))
+	}
+
+	for _, candidate := range candidates {
+		for _, entry := range entries {
+			if strings.ToLower(entry.Domain) == candidate {
+				return entry, true
+			}
+			// Also check alternate domains in case of multi-domain blocks
+			if entry.Caddy != nil {
+				for _, domain := range entry.Caddy.Domains {
+					if strings.ToLower(domain) == candidate {
+						return entry, true
+					}
+				}
+			}
+		}
+	}
+	return diff.SyncedEntry{}, false
+}
+
+// RunCreateEntry runs createEntryCmd synchronously (backup, DNS create, Caddy append, validate, restart)
+func RunCreateEntry(cfg *config.Config, form AddFormData, apiToken string) OperationResult {
+	msg := createEntryCmd(cfg, form, apiToken)().(createEntryMsg)
+
+	entityType := "both"
+	if form.DNSOnly {
+		entityType = "dns"
+	}
+	return OperationResult{
+		Success:      msg.success,
+		Err:          msg.err,
+		ErrorStep:    msg.errorStep,
+		BackupPath:   msg.backupPath,
+		ReloadOutput: msg.reloadOutput,
+		Domain:       strings.Join(ResolveFQDNs(ParseSubdomains(form.Subdomain), cfg), ", "),
+		EntityType:   entityType,
+	}
+}
+
+// RunUpdateEntry runs updateEntryCmd synchronously with the same rollback behaviour as the TUI
+func RunUpdateEntry(cfg *config.Config, form AddFormData, oldEntry diff.SyncedEntry, apiToken string) OperationResult {
+	msg := updateEntryCmd(cfg, form, oldEntry, apiToken)().(updateEntryMsg)
+
+	entityType := "both"
+	if form.DNSOnly && oldEntry.Caddy == nil {
+		entityType = "dns"
+	}
+	return OperationResult{
+		Success:      msg.success,
+		Err:          msg.err,
+		ErrorStep:    msg.errorStep,
+		BackupPath:   msg.backupPath,
+		ReloadOutput: msg.reloadOutput,
+		Domain:       oldEntry.Domain,
+		EntityType:   entityType,
+	}
+}
+
+// RunDeleteEntry runs deleteEntryCmd synchronously for the given scope
+func RunDeleteEntry(cfg *config.Config, entry diff.SyncedEntry, scope DeleteScope, apiToken string) OperationResult {
+	msg := deleteEntryCmd(cfg, entry, scope, apiToken)().(deleteEntryMsg)
+	return OperationResult{
+		Success:      msg.success,
+		Err:          msg.err,
+		ErrorStep:    msg.errorStep,
+		BackupPath:   msg.backupPath,
+		ReloadOutput: msg.reloadOutput,
+		Domain:       msg.domain,
+		EntityType:   msg.entityType,
+	}
+}
+
+// RunSyncEntry runs syncEntryCmd synchronously for an orphaned entry
+func RunSyncEntry(cfg *config.Config, entry diff.SyncedEntry, apiToken string) OperationResult {
+	msg := syncEntryCmd(cfg, entry, apiToken)().(syncEntryMsg)
+
+	entityType := "both"
+	if msg.syncType == "to_dns" {
+		entityType = "dns"
+	} else if msg.syncType == "to_caddy" {
+		entityType = "caddy"
+	}
+	return OperationResult{
+		Success:      msg.success,
+		Err:          msg.err,
+		ErrorStep:    msg.errorStep,
+		BackupPath:   msg.backupPath,
+		ReloadOutput: msg.reloadOutput,
+		Domain:       msg.domain,
+		EntityType:   entityType,
+		SyncType:     msg.syncType,
+	}
+}
+
+// RunClaimEntries runs claimRecordsCmd synchronously, tagging the entries' DNS records as owned
+func RunClaimEntries(cfg *config.Config, entries []diff.SyncedEntry, apiToken string) OperationResult {
+	msg := claimRecordsCmd(cfg, entries, apiToken)().(claimRecordsMsg)
+
+	result := OperationResult{
+		Success:    msg.success,
+		Err:        msg.err,
+		Domain:     strings.Join(msg.domains, ", "),
+		EntityType: "dns",
+	}
+	if !msg.success {
+		result.ErrorStep = "dns_claim"
+	}
+	return result
+}
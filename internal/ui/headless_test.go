@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/diff"
+)
+
+func headlessTestConfig() *config.Config {
+	return &config.Config{
+		Domain: "example.com",
+		Defaults: config.DefaultsConfig{
+			CNAMETarget: "home.example.com",
+			Port:        80,
+			Proxied:     true,
+		},
+	}
+}
+
+// TestFindEntry tests lookup by FQDN, subdomain and alternate multi-domain names
+func TestFindEntry(t *testing.T) {
+	cfg := headlessTestConfig()
+	entries := []diff.SyncedEntry{
+		{Domain: "app.example.com", Status: diff.StatusSynced},
+		{
+			Domain: "web.example.com",
+			Status: diff.StatusOrphanedCaddy,
+			Caddy:  &caddy.CaddyEntry{Domain: "web.example.com", Domains: []string{"web.example.com", "www.example.com"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		found    bool
+	}{
+		{"FQDN match", "app.example.com", "app.example.com", true},
+		{"Case-insensitive FQDN", "APP.Example.com", "app.example.com", true},
+		{"Subdomain only", "app", "app.example.com", true},
+		{"Alternate domain in multi-domain block", "www", "web.example.com", true},
+		{"Missing entry", "nope", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found := FindEntry(cfg, entries, tt.query)
+			if found != tt.found {
+				t.Fatalf("Expected found=%v, got %v", tt.found, found)
+			}
+			if entry.Domain != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, entry.Domain)
+			}
+		})
+	}
+}
+
+// TestValidateForm tests required field and A/AAAA record validation
+func TestValidateForm(t *testing.T) {
+	cfg := headlessTestConfig()
+
+	valid := NewAddForm(cfg)
+	valid.Subdomain = "app"
+
+	noSubdomain := NewAddForm(cfg)
+
+	badIP := NewAddForm(cfg)
+	badIP.Subdomain = "app"
+	badIP.DNSType = "A"
+	badIP.DNSTarget = "300.1.1.1"
+
+	noUpstream := NewAddForm(cfg)
+	noUpstream.Subdomain = "app"
+	noUpstream.ReverseProxyTarget = ""
+
+	dnsOnly := noUpstream
+	dnsOnly.DNSOnly = true
+
+	aaaa := valid
+	aaaa.DNSType = "AAAA"
+	aaaa.DNSTarget = "2001:db8::5"
+
+	aaaaWithV4 := aaaa
+	aaaaWithV4.DNSTarget = "10.0.0.5"
+
+	dualStack := valid
+	dualStack.DNSType = diff.DualStackType
+	dualStack.DNSTarget = "10.0.0.5, 2001:db8::5"
+
+	dualStackMissingV6 := dualStack
+	dualStackMissingV6.DNSTarget = "10.0.0.5"
+
+	routes := valid
+	routes.PathRoutes = "/grafana/* grafana:3000 strip\n/prometheus/* prometheus:9090"
+
+	badRoute := valid
+	badRoute.PathRoutes = "grafana grafana:3000"
+
+	loadBalanced := valid
+	loadBalanced.Upstreams = "10.0.0.21, 10.0.0.22:8080"
+	loadBalanced.LoadBalancing = "lb_policy least_conn\nhealth_uri /healthz\nhealth_interval 10s"
+
+	badPolicy := loadBalanced
+	badPolicy.LoadBalancing = "lb_policy fastest"
+
+	// Redirects and static sites need no upstream
+	redirect := noUpstream
+	redirect.EntryType = caddy.EntryRedirect
+	redirect.RedirectTo = "https://example.com"
+
+	relativeRedirect := redirect
+	relativeRedirect.RedirectTo = "example.com"
+
+	badRedirectCode := redirect
+	badRedirectCode.RedirectCode = "200"
+
+	noRedirectURL := redirect
+	noRedirectURL.RedirectTo = ""
+
+	static := noUpstream
+	static.EntryType = caddy.EntryStatic
+	static.StaticRoot = "/srv/www"
+
+	relativeRoot := static
+	relativeRoot.StaticRoot = "www"
+
+	tests := []struct {
+		name    string
+		form    AddFormData
+		wantErr bool
+	}{
+		{"Defaults with subdomain", valid, false},
+		{"Missing subdomain", noSubdomain, true},
+		{"Invalid A record IP", badIP, true},
+		{"Missing upstream", noUpstream, true},
+		{"DNS-only needs no upstream", dnsOnly, false},
+		{"Valid AAAA record", aaaa, false},
+		{"IPv4 address in AAAA record", aaaaWithV4, true},
+		{"Valid dual-stack target", dualStack, false},
+		{"Dual-stack without IPv6", dualStackMissingV6, true},
+		{"Path routes", routes, false},
+		{"Path route without a path", badRoute, true},
+		{"Load balanced upstreams", loadBalanced, false},
+		{"Unknown load balancing policy", badPolicy, true},
+		{"Redirect", redirect, false},
+		{"Redirect without a scheme", relativeRedirect, true},
+		{"Redirect with a non-redirect code", badRedirectCode, true},
+		{"Redirect without a URL", noRedirectURL, true},
+		{"Static site", static, false},
+		{"Static site with a relative root", relativeRoot, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateForm(tt.form)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestEditFormFromEntry tests that edit forms are pre-populated from DNS and Caddy
+func TestEditFormFromEntry(t *testing.T) {
+	cfg := headlessTestConfig()
+	entry := diff.SyncedEntry{
+		Domain: "app.example.com",
+		DNS:    &cloudflare.DNSRecord{Type: "A", Name: "app.example.com", Content: "10.0.0.5", Proxied: false},
+		Caddy: &caddy.CaddyEntry{
+			Domain:  "app.example.com",
+			Target:  "10.0.0.20",
+			Port:    8080,
+			SSL:     true,
+			Imports: []string{"security_headers"},
+			Routes:  []caddy.PathRoute{{Path: "/grafana/*", StripPrefix: true, Target: "10.0.0.21", Port: 3000}},
+
+			Upstreams:     []string{"10.0.0.22:8080"},
+			LoadBalancing: caddy.LoadBalancing{Policy: "round_robin", MaxFails: 2},
+		},
+	}
+
+	form := EditFormFromEntry(cfg, entry)
+	if form.Subdomain != "app" {
+		t.Errorf("Expected subdomain 'app', got %q", form.Subdomain)
+	}
+	if form.DNSType != "A" || form.DNSTarget != "10.0.0.5" || form.Proxied {
+		t.Errorf("DNS fields not populated: %+v", form)
+	}
+	if form.DNSOnly {
+		t.Error("Entry with Caddy block should not be DNS-only")
+	}
+	if form.ReverseProxyTarget != "10.0.0.20" || form.ServicePort != "8080" || !form.SSL {
+		t.Errorf("Caddy fields not populated: %+v", form)
+	}
+	if !form.SelectedSnippets["security_headers"] {
+		t.Error("Expected security_headers snippet to be selected")
+	}
+	if form.PathRoutes != "/grafana/* http://10.0.0.21:3000 strip" {
+		t.Errorf("Path routes not populated: %q", form.PathRoutes)
+	}
+	if form.Upstreams != "10.0.0.22:8080" || form.LoadBalancing != "lb_policy round_robin\nmax_fails 2" {
+		t.Errorf("Load balancing not populated: %q, %q", form.Upstreams, form.LoadBalancing)
+	}
+}
+
+// TestEditFormFromEntryRedirect tests that redirect entries populate the redirect fields
+func TestEditFormFromEntryRedirect(t *testing.T) {
+	cfg := headlessTestConfig()
+	entry := diff.SyncedEntry{
+		Domain: "www.example.com",
+		Caddy: &caddy.CaddyEntry{
+			Domain:       "www.example.com",
+			Type:         caddy.EntryRedirect,
+			Port:         80,
+			RedirectTo:   "https://example.com",
+			RedirectCode: 302,
+		},
+	}
+
+	form := EditFormFromEntry(cfg, entry)
+	if form.EntryType != caddy.EntryRedirect || form.RedirectTo != "https://example.com" || form.RedirectCode != "302" || form.RedirectPreservePath {
+		t.Errorf("Redirect fields not populated: %+v", form)
+	}
+	if err := ValidateForm(form); err != nil {
+		t.Errorf("Expected the edit form to validate, got %v", err)
+	}
+}
+
+// TestEditFormFromEntryDualStack tests that paired A/AAAA records populate a dual-stack form
+func TestEditFormFromEntryDualStack(t *testing.T) {
+	cfg := headlessTestConfig()
+	entry := diff.SyncedEntry{
+		Domain:  "app.example.com",
+		DNS:     &cloudflare.DNSRecord{Type: "A", Name: "app.example.com", Content: "10.0.0.5"},
+		DNSAAAA: &cloudflare.DNSRecord{Type: "AAAA", Name: "app.example.com", Content: "2001:db8::5"},
+	}
+
+	form := EditFormFromEntry(cfg, entry)
+	if form.DNSType != diff.DualStackType || form.DNSTarget != "10.0.0.5, 2001:db8::5" {
+		t.Errorf("Expected dual-stack form, got type %q target %q", form.DNSType, form.DNSTarget)
+	}
+
+	records := dnsRecordsForForm(form, "app.example.com")
+	if len(records) != 2 || records[0].Type != "A" || records[0].Content != "10.0.0.5" ||
+		records[1].Type != "AAAA" || records[1].Content != "2001:db8::5" {
+		t.Errorf("Unexpected records for dual-stack form: %+v", records)
+	}
+}
+
+// TestTunnelForms tests tunnel defaults, ingress rules and editing a direct tunnel entry
+func TestTunnelForms(t *testing.T) {
+	cfg := headlessTestConfig()
+	cfg.Tunnel = config.TunnelConfig{ID: "6FF42AE2-765D-4ADF-8112-31C55C1551EF", ConfigPath: "/etc/cloudflared/config.yml"}
+
+	form := NewAddForm(cfg)
+	if form.DNSTarget != "6ff42ae2-765d-4adf-8112-31c55c1551ef.cfargotunnel.com" || !formViaTunnel(cfg, form) {
+		t.Fatalf("Expected new entries to point at the tunnel, got %q", form.DNSTarget)
+	}
+
+	rule := ingressRuleFor(cfg, form, "app.example.com", 8080)
+	if rule.Service != "https://localhost:443" || rule.OriginServerName != "app.example.com" {
+		t.Errorf("Caddy mode should route to Caddy with the hostname as SNI, got %+v", rule)
+	}
+	if directTunnelForm(cfg, form).DNSOnly {
+		t.Error("Caddy mode should keep the Caddy block")
+	}
+
+	cfg.Tunnel.Mode = config.TunnelModeDirect
+	form.ReverseProxyTarget = "10.0.0.5"
+	rule = ingressRuleFor(cfg, form, "app.example.com", 8080)
+	if rule.Service != "http://10.0.0.5:8080" || rule.OriginServerName != "" {
+		t.Errorf("Direct mode should route to the upstream, got %+v", rule)
+	}
+	if !directTunnelForm(cfg, form).DNSOnly {
+		t.Error("Direct mode should skip the Caddy block")
+	}
+
+	// Editing a direct entry reads the upstream back from its ingress rule
+	entry := diff.SyncedEntry{
+		Domain:  "app.example.com",
+		DNS:     &cloudflare.DNSRecord{Type: "CNAME", Content: cfg.Tunnel.CNAMETarget(), Proxied: true},
+		Ingress: &rule,
+	}
+	edit := EditFormFromEntry(cfg, entry)
+	if edit.DNSOnly || edit.ReverseProxyTarget != "10.0.0.5" || edit.ServicePort != "8080" || edit.SSL {
+		t.Errorf("Unexpected edit form: %+v", edit)
+	}
+
+	// Syncing DNS for a routed entry points at the tunnel, whatever the defaults
+	cfg.Defaults.Proxied = false
+	if record := syncDNSRecord(cfg, diff.SyncedEntry{Domain: "app.example.com", Ingress: &rule}); record.Content != cfg.Tunnel.CNAMETarget() || !record.Proxied {
+		t.Errorf("Expected a proxied tunnel record, got %+v", record)
+	}
+	if record := syncDNSRecord(cfg, diff.SyncedEntry{Domain: "other.example.com"}); record.Content != cfg.Defaults.CNAMETarget {
+		t.Errorf("Expected the default target without ingress, got %+v", record)
+	}
+}
+
+// TestMigrationTrustCloudflare tests that trusting Cloudflare's proxies is
+// on by default only for Cloudflare profiles, and can be turned off
+func TestMigrationTrustCloudflare(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Caddyfile")
+	if err := os.WriteFile(path, []byte("app.example.com {\n\treverse_proxy localhost:8080\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{config: headlessTestConfig()}
+	if err := m.initMigrationWizard(path); err != nil {
+		t.Fatal(err)
+	}
+	m.migration.Data.OptionCursor = 1
+	m, _ = m.handleMigrationOptionsKeyPress("t")
+	m, _ = m.handleMigrationOptionsKeyPress("enter")
+	if m.migration.Data.Options.TrustCloudflare {
+		t.Error("Expected the toggle to turn trusting Cloudflare off")
+	}
+
+	m = Model{config: headlessTestConfig()}
+	m.config.DNS = config.DNSConfig{Provider: config.DNSProviderPowerDNS}
+	if err := m.initMigrationWizard(path); err != nil {
+		t.Fatal(err)
+	}
+	m.migration.Data.OptionCursor = 4
+	m, _ = m.handleMigrationOptionsKeyPress("enter")
+	if m.migration.Data.Options.TrustCloudflare {
+		t.Error("Expected no Cloudflare trust by default for a PowerDNS profile")
+	}
+}
+
+// TestIPRestrictionWarning tests the warning for proxied entries with an IP restriction
+func TestPowerDNSHidesProxied(t *testing.T) {
+	cfg := headlessTestConfig()
+	cfg.DNS = config.DNSConfig{Provider: config.DNSProviderPowerDNS}
+
+	if NewAddForm(cfg).Proxied {
+		t.Error("Expected new entries not proxied without a proxying provider")
+	}
+	opts := CompareOptionsForConfig(cfg)
+	if opts.CheckProxied || opts.TTL != 300 {
+		t.Errorf("Expected no proxied check and the PowerDNS TTL, got %+v", opts)
+	}
+
+	m := Model{config: cfg, currentView: ViewAdd, addForm: AddFormData{FocusedField: 3}}
+	m, _ = m.handleNavigateDown()
+	if m.addForm.FocusedField != m.entryTypeFieldIndex() {
+		t.Errorf("Expected navigation to skip the hidden Proxied field, got field %d", m.addForm.FocusedField)
+	}
+}
+
+// TestStaticSiteNavigation tests that static sites skip the port and the proxy-only fields
+func TestStaticSiteNavigation(t *testing.T) {
+	m := Model{config: headlessTestConfig(), currentView: ViewAdd,
+		addForm: AddFormData{EntryType: caddy.EntryStatic, FocusedField: 4}}
+	m, _ = m.handleNavigateDown()
+	if m.addForm.FocusedField != 7 {
+		t.Errorf("Expected the port skipped, got field %d", m.addForm.FocusedField)
+	}
+
+	m.addForm.FocusedField = 8 + len(m.snippets) // Custom config
+	m, _ = m.handleNavigateDown()
+	if m.addForm.FocusedField != 0 {
+		t.Errorf("Expected path routes and load balancing skipped, got field %d", m.addForm.FocusedField)
+	}
+	m, _ = m.handleNavigateUp()
+	if m.addForm.FocusedField != 8+len(m.snippets) {
+		t.Errorf("Expected to wrap back to custom config, got field %d", m.addForm.FocusedField)
+	}
+}
+
+func TestIPRestrictionWarning(t *testing.T) {
+	form := NewAddForm(headlessTestConfig())
+	form.LANOnly = true
+	if ipRestrictionWarning(form, nil) == "" || !needsCloudflareTrust(form) {
+		t.Error("Expected a warning and trusted proxies for a proxied LAN-only entry")
+	}
+
+	form.Proxied = false
+	if ipRestrictionWarning(form, nil) != "" || needsCloudflareTrust(form) {
+		t.Error("DNS-only (grey cloud) entries see the visitor's address directly")
+	}
+
+	// A snippet still matching remote_ip is called out
+	form.Proxied = true
+	form.LANOnly = false
+	form.SelectedSnippets["ip_restricted"] = true
+	snippets := []caddy.Snippet{{Name: "ip_restricted", Content: "\t@external {\n\t\tnot remote_ip 10.0.0.0/8\n\t}"}}
+	if warning := ipRestrictionWarning(form, snippets); !strings.Contains(warning, "client_ip") {
+		t.Errorf("Expected the remote_ip snippet called out, got %q", warning)
+	}
+}
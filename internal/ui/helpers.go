@@ -1,14 +1,18 @@
 package ui
 
 import (
+	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/dnsprovider"
 )
 
-// getFilteredEntries returns entries filtered by search query, status filter, and DNS type filter,
+// getFilteredEntries returns entries filtered by search query, status filter, DNS type filter and zone,
 // then sorted according to the current sort mode. Also filters by active tab.
 func (m Model) getFilteredEntries() []diff.SyncedEntry {
 	filtered := m.entries
@@ -16,10 +20,10 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 	// Apply tab filter first (filter by which data exists in the entry)
 	switch m.activeTab {
 	case TabCloudflare:
-		// Show only entries with DNS records
+		// Show only entries with DNS records (or tunnel ingress rules)
 		var dnsFiltered []diff.SyncedEntry
 		for _, entry := range filtered {
-			if entry.DNS != nil {
+			if entry.DNS != nil || entry.Ingress != nil {
 				dnsFiltered = append(dnsFiltered, entry)
 			}
 		}
@@ -35,6 +39,17 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 		filtered = caddyFiltered
 	}
 
+	// Ignored entries stay hidden unless filtered for
+	if m.statusFilter != FilterIgnored {
+		var visible []diff.SyncedEntry
+		for _, entry := range filtered {
+			if entry.Status != diff.StatusIgnored {
+				visible = append(visible, entry)
+			}
+		}
+		filtered = visible
+	}
+
 	// Apply status filter
 	if m.statusFilter != FilterAll {
 		var statusFiltered []diff.SyncedEntry
@@ -52,6 +67,22 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 				if entry.Status == diff.StatusOrphanedCaddy {
 					statusFiltered = append(statusFiltered, entry)
 				}
+			case FilterOrphanedIngress:
+				if entry.Status == diff.StatusOrphanedIngress {
+					statusFiltered = append(statusFiltered, entry)
+				}
+			case FilterMismatch:
+				if entry.Status.IsMismatch() {
+					statusFiltered = append(statusFiltered, entry)
+				}
+			case FilterUnmanaged:
+				if entry.Status == diff.StatusUnmanagedDNS {
+					statusFiltered = append(statusFiltered, entry)
+				}
+			case FilterIgnored:
+				if entry.Status == diff.StatusIgnored {
+					statusFiltered = append(statusFiltered, entry)
+				}
 			}
 		}
 		filtered = statusFiltered
@@ -69,7 +100,11 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 						dnsTypeFiltered = append(dnsTypeFiltered, entry)
 					}
 				case DNSTypeA:
-					if entry.DNS.Type == "A" {
+					if entry.HasRecordType("A") {
+						dnsTypeFiltered = append(dnsTypeFiltered, entry)
+					}
+				case DNSTypeAAAA:
+					if entry.HasRecordType("AAAA") {
 						dnsTypeFiltered = append(dnsTypeFiltered, entry)
 					}
 				}
@@ -78,6 +113,17 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 		filtered = dnsTypeFiltered
 	}
 
+	// Apply zone filter (multi-zone profiles)
+	if m.zoneFilter != "" {
+		var zoneFiltered []diff.SyncedEntry
+		for _, entry := range filtered {
+			if entry.Zone == m.zoneFilter {
+				zoneFiltered = append(zoneFiltered, entry)
+			}
+		}
+		filtered = zoneFiltered
+	}
+
 	// Apply search query filter
 	if m.searchQuery != "" {
 		var searchFiltered []diff.SyncedEntry
@@ -98,18 +144,59 @@ func (m Model) getFilteredEntries() []diff.SyncedEntry {
 		})
 	case SortByStatus:
 		sort.Slice(filtered, func(i, j int) bool {
-			// Sort order: Synced (0) < Orphaned DNS (1) < Orphaned Caddy (2)
+			// Sort order: Synced < Orphaned DNS < Orphaned Caddy < Mismatches
 			if filtered[i].Status != filtered[j].Status {
 				return filtered[i].Status < filtered[j].Status
 			}
 			// Within same status, sort alphabetically
 			return filtered[i].Domain < filtered[j].Domain
 		})
+	case SortByZone:
+		sort.Slice(filtered, func(i, j int) bool {
+			// Group by zone, entries outside every zone last
+			if filtered[i].Zone != filtered[j].Zone {
+				if filtered[i].Zone == "" || filtered[j].Zone == "" {
+					return filtered[j].Zone == ""
+				}
+				return filtered[i].Zone < filtered[j].Zone
+			}
+			return filtered[i].Domain < filtered[j].Domain
+		})
 	}
 
 	return filtered
 }
 
+// nextZoneFilter returns the zone after current in zones, wrapping back to "" (all zones)
+func nextZoneFilter(current string, zones []string) string {
+	if current == "" {
+		if len(zones) == 0 {
+			return ""
+		}
+		return strings.ToLower(zones[0])
+	}
+	for i, zone := range zones {
+		if strings.EqualFold(zone, current) && i+1 < len(zones) {
+			return strings.ToLower(zones[i+1])
+		}
+	}
+	return ""
+}
+
+// statusIcon renders the colored list icon for an entry's sync status
+func statusIcon(status diff.SyncStatus) string {
+	switch {
+	case status == diff.StatusSynced:
+		return StyleIconSynced.Render(status.Icon())
+	case status.IsMismatch():
+		return StyleIconDrift.Render(status.Icon())
+	case status == diff.StatusUnmanagedDNS, status == diff.StatusIgnored:
+		return StyleDim.Render(status.Icon())
+	default:
+		return StyleIconOrphan.Render(status.Icon())
+	}
+}
+
 // getSnippetNames extracts just the names from a slice of Snippet structs
 // This is used when passing to the Caddy generator which only needs snippet names
 func getSnippetNames(snippets []caddy.Snippet) []string {
@@ -131,3 +218,41 @@ func getSelectedSnippetNames(selectedSnippets map[string]bool) []string {
 	}
 	return selected
 }
+
+// caddyLocation describes where a block is: its lines, prefixed with the
+// file's path (relative to the Caddyfile) when it is in an imported file
+func caddyLocation(cfg *config.Config, file string, lineStart, lineEnd int) string {
+	lines := fmt.Sprintf("Lines %d-%d", lineStart, lineEnd)
+	if file == "" || cfg == nil || filepath.Clean(file) == filepath.Clean(cfg.Caddy.CaddyfilePath) {
+		return lines
+	}
+	if rel, err := filepath.Rel(filepath.Dir(cfg.Caddy.CaddyfilePath), file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	return file + ", " + strings.ToLower(lines[:1]) + lines[1:]
+}
+
+// proxySupported reports whether the profile's DNS provider can proxy
+// records (Cloudflare's orange cloud). Proxied fields are hidden otherwise.
+func proxySupported(cfg *config.Config) bool {
+	return cfg == nil || dnsprovider.InfoFor(cfg).Proxy
+}
+
+// CompareOptionsForConfig returns the drift-detection expectations for a profile
+// Entries are created with Auto TTL, so anything else is reported as drift
+func CompareOptionsForConfig(cfg *config.Config) diff.CompareOptions {
+	info := dnsprovider.InfoFor(cfg)
+	opts := diff.CompareOptions{
+		CNAMETarget:  cfg.Defaults.CNAMETarget,
+		CheckProxied: info.Proxy,
+		Proxied:      cfg.Defaults.Proxied,
+		TTL:          info.DefaultTTL,
+		Zones:        cfg.ZoneDomains(),
+		OwnedOnly:    cfg.Cloudflare.OwnedOnly,
+		Ignore:       cfg.IgnoreMatcher(),
+	}
+	if cfg.Tunnel.Enabled() {
+		opts.TunnelTarget = cfg.Tunnel.CNAMETarget()
+	}
+	return opts
+}
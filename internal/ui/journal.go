@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/dnsprovider"
+	"lazyproxyflare/internal/journal"
+)
+
+// journalDir returns the directory where operation journals are kept
+func journalDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".config", "lazyproxyflare", "journal")
+}
+
+// beginJournal starts a journal for an operation on the profile's Caddyfile and DNS
+func beginJournal(cfg *config.Config, operation, domain string) (*journal.Journal, error) {
+	return journal.Begin(journalDir(), operation, domain, cfg.Caddy.CaddyfilePath)
+}
+
+// reloadFunc returns a reload callback for journal rollback
+func reloadFunc(cfg *config.Config) func() error {
+	return func() error {
+		_, err := reloadCaddy(cfg)
+		return err
+	}
+}
+
+// rollbackWithError undoes every step recorded in the journal after a failed
+// step and wraps the original error with the outcome
+func rollbackWithError(j *journal.Journal, cfClient dnsprovider.Records, cfg *config.Config, originalErr error, operation string) error {
+	if len(j.Steps) == 0 {
+		// Nothing was changed yet; just discard the journal
+		j.Rollback(context.Background(), cfClient, nil)
+		return originalErr
+	}
+	if rollbackErr := j.Rollback(context.Background(), cfClient, reloadFunc(cfg)); rollbackErr != nil {
+		return fmt.Errorf("CRITICAL: %s failed AND rollback failed: %w (original error: %v)", operation, rollbackErr, originalErr)
+	}
+	return fmt.Errorf("%s failed (changes rolled back): %w", operation, originalErr)
+}
+
+// RecoverJournals finishes or undoes operations on this profile that were
+// interrupted by a crash. Returns a description of each recovered operation.
+func RecoverJournals(cfg *config.Config, apiToken string) ([]string, error) {
+	pending, err := journal.Pending(journalDir())
+	if err != nil {
+		return nil, err
+	}
+
+	cfClient := dnsprovider.New(cfg, apiToken)
+	var recovered []string
+	for _, j := range pending {
+		// Journals from other profiles are recovered when those profiles load,
+		// and operations still running in another process are left alone
+		if j.Caddyfile != cfg.Caddy.CaddyfilePath || !j.Interrupted() {
+			continue
+		}
+		if err := j.Recover(context.Background(), cfClient, reloadFunc(cfg)); err != nil {
+			return recovered, fmt.Errorf("failed to recover interrupted %s: %w", j.Describe(), err)
+		}
+		recovered = append(recovered, j.Describe())
+	}
+	return recovered, nil
+}
+
+type recoverJournalsMsg struct {
+	recovered []string // Descriptions of recovered operations
+	err       error
+}
+
+// recoverJournalsCmd runs crash recovery for the profile before its data is loaded
+func recoverJournalsCmd(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		apiToken, err := cfg.GetAPIToken()
+		if err != nil {
+			return recoverJournalsMsg{err: err}
+		}
+		recovered, err := RecoverJournals(cfg, apiToken)
+		return recoverJournalsMsg{recovered: recovered, err: err}
+	}
+}
@@ -47,6 +47,9 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	case "t":
 		return m.handleDNSTypeFilterCycle()
 
+	case "z":
+		return m.handleZoneFilterCycle()
+
 	case "o":
 		return m.handleSortModeCycle()
 
@@ -108,6 +111,9 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	case "S":
 		return m.handleBatchSyncSelected()
 
+	case "C":
+		return m.handleClaimRecords()
+
 	case "enter":
 		return m.handleEnterKey()
 
@@ -138,7 +138,12 @@ func (m Model) handleConfirmAction() (Model, tea.Cmd) {
 			}
 			return m, bulkDeleteDNSCmd(m.config, m.bulkDelete.Entries, apiToken)
 		} else if m.bulkDelete.Type == "caddy" {
-			return m, bulkDeleteCaddyCmd(m.config, m.bulkDelete.Entries)
+			apiToken, err := m.config.GetAPIToken()
+			if err != nil {
+				m.err = fmt.Errorf("failed to get API token: %w", err)
+				return m, nil
+			}
+			return m, bulkDeleteCaddyCmd(m.config, m.bulkDelete.Entries, apiToken)
 		}
 	}
 	// Confirm batch delete selected (only in confirm batch delete screen)
@@ -173,13 +178,17 @@ func (m Model) handleConfirmAction() (Model, tea.Cmd) {
 	}
 	// Confirm restore backup (only in confirm restore screen)
 	if m.currentView == ViewConfirmRestore && !m.loading {
+		// DNS restores wait for a preview of their changes
+		if m.backup.RestoreScope != RestoreCaddyOnly && !m.backup.RestorePlanned {
+			return m, nil
+		}
 		m.loading = true
 		apiToken, err := m.config.GetAPIToken()
 		if err != nil {
 			m.err = fmt.Errorf("failed to get API token: %w", err)
 			return m, nil
 		}
-		return m, restoreBackupCmd(m.config, m.backup.PreviewPath, m.backup.RestoreScope, apiToken)
+		return m, restoreBackupCmd(m.config, m.backup.PreviewPath, m.backup.RestoreScope, m.backup.RestorePlan, apiToken)
 	}
 	// Confirm cleanup old backups (only in confirm cleanup screen)
 	if m.currentView == ViewConfirmCleanup && !m.loading {
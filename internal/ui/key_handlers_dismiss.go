@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+
 	tea "github.com/charmbracelet/bubbletea"
 
 	snippet_wizard "lazyproxyflare/internal/ui/snippet_wizard"
@@ -162,8 +164,12 @@ func (m Model) handleDismiss() (Model, tea.Cmd) {
 			m.snippetWizardStep = snippet_wizard.StepWelcome
 			m.wizardCursor = 0
 		case snippet_wizard.StepTemplateParams:
-			// Back to template selection
-			m.snippetWizardStep = snippet_wizard.StepTemplateSelection
+			// Back to the previous template, or to template selection
+			if m.snippetWizardData.TemplateIndex > 0 {
+				m.snippetWizardData.TemplateIndex--
+			} else {
+				m.snippetWizardStep = snippet_wizard.StepTemplateSelection
+			}
 			m.wizardCursor = 0
 		case snippet_wizard.StepCustomSnippet:
 			// Back to welcome
@@ -225,6 +231,14 @@ func (m Model) handleDismiss() (Model, tea.Cmd) {
 		m.err = nil // Clear any error
 		return m, nil
 	}
+	// If refreshing the list, cancel the refresh and keep the current data
+	if m.currentView == ViewList && m.loading && m.cancelRefresh != nil {
+		m.cancelRefresh()
+		m.cancelRefresh = nil
+		m.loading = false
+		m.err = fmt.Errorf("refresh cancelled")
+		return m, nil
+	}
 	// If in search mode, exit search mode
 	if m.searching {
 		m.searching = false
@@ -234,10 +248,11 @@ func (m Model) handleDismiss() (Model, tea.Cmd) {
 		return m, nil
 	}
 	// If in list view with any active filters/sort/selections, reset everything
-	if m.currentView == ViewList && (m.searchQuery != "" || m.statusFilter != FilterAll || m.dnsTypeFilter != DNSTypeAll || m.sortMode != SortAlphabetical || len(m.selectedEntries) > 0) {
+	if m.currentView == ViewList && (m.searchQuery != "" || m.statusFilter != FilterAll || m.dnsTypeFilter != DNSTypeAll || m.zoneFilter != "" || m.sortMode != SortAlphabetical || len(m.selectedEntries) > 0) {
 		m.searchQuery = ""
 		m.statusFilter = FilterAll
 		m.dnsTypeFilter = DNSTypeAll
+		m.zoneFilter = ""
 		m.sortMode = SortAlphabetical
 		m.selectedEntries = make(map[string]bool)
 		m.cursor = 0
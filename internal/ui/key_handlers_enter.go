@@ -27,65 +27,7 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 			entry := filteredEntries[m.cursor]
 
 			// Pre-populate form with existing entry values
-			// For multi-domain entries, populate all domains as newline-separated
-			subdomain := ""
-			if entry.Caddy != nil && len(entry.Caddy.Domains) > 0 {
-				// Multi-domain entry - format all domains as newline-separated subdomains
-				subdomain = GetSubdomainsTextareaValue(entry.Caddy.Domains, m.config.Domain)
-			} else if len(entry.Domain) > len(m.config.Domain)+1 {
-				// Single domain (backwards compatibility)
-				subdomain = entry.Domain[:len(entry.Domain)-len(m.config.Domain)-1]
-			}
-
-			dnsType := "CNAME"
-			dnsTarget := m.config.Defaults.CNAMETarget
-			proxied := m.config.Defaults.Proxied
-			if entry.DNS != nil {
-				dnsType = entry.DNS.Type
-				dnsTarget = entry.DNS.Content
-				proxied = entry.DNS.Proxied
-			}
-
-			dnsOnly := entry.Caddy == nil // If no Caddy config, it's DNS-only
-
-			reverseProxyTarget := "localhost"
-			servicePort := fmt.Sprintf("%d", m.config.Defaults.Port)
-			ssl := m.config.Defaults.SSL
-			lanOnly := false
-			oauth := false
-			webSocket := false
-			if entry.Caddy != nil {
-				reverseProxyTarget = entry.Caddy.Target
-				servicePort = fmt.Sprintf("%d", entry.Caddy.Port)
-				ssl = entry.Caddy.SSL
-				lanOnly = entry.Caddy.IPRestricted
-				oauth = entry.Caddy.OAuthHeaders
-				webSocket = entry.Caddy.WebSocket
-			}
-
-			// Pre-populate selected snippets from entry's imports
-			selectedSnippets := make(map[string]bool)
-			if entry.Caddy != nil {
-				for _, importName := range entry.Caddy.Imports {
-					selectedSnippets[importName] = true
-				}
-			}
-
-			m.addForm = AddFormData{
-				Subdomain:          subdomain,
-				DNSType:            dnsType,
-				DNSTarget:          dnsTarget,
-				DNSOnly:            dnsOnly,
-				ReverseProxyTarget: reverseProxyTarget,
-				ServicePort:        servicePort,
-				Proxied:            proxied,
-				LANOnly:            lanOnly,
-				SSL:                ssl,
-				OAuth:              oauth,
-				WebSocket:          webSocket,
-				SelectedSnippets:   selectedSnippets,
-				FocusedField:       0,
-			}
+			m.addForm = EditFormFromEntry(m.config, entry)
 			m.editingEntry = &entry
 			m.currentView = ViewEdit
 			return m, nil
@@ -190,13 +132,16 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 			// Advance to template params or summary
 			m.snippetWizardData.Mode = snippet_wizard.ModeTemplated
 			m.snippetWizardStep = snippet_wizard.StepTemplateParams
+			m.snippetWizardData.TemplateIndex = 0
 			m.wizardCursor = 0
 			return m, nil
 		case snippet_wizard.StepTemplateParams:
-			// Advance to summary
-			m.snippetWizardStep = snippet_wizard.StepSummary
-			m.wizardCursor = 0
-			return m, nil
+			// Check the template's parameters, then configure the next
+			// selected template or advance to summary
+			if m.snippetHashing {
+				return m, nil
+			}
+			return m.commitSnippetTemplateParams()
 		case snippet_wizard.StepIPRestriction:
 			// Guided mode: Advance to security headers
 			m.snippetWizardStep = snippet_wizard.StepSecurityHeaders
@@ -227,10 +172,16 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 		m.currentView = ViewConfirmDelete
 		return m, nil
 	}
-	// Handle Enter in restore scope selection: proceed to confirm
+	// Handle Enter in restore scope selection: proceed to confirm, previewing
+	// the DNS changes first
 	if m.currentView == ViewRestoreScope && !m.loading {
 		m.currentView = ViewConfirmRestore
-		return m, nil
+		m.backup.RestorePlan = nil
+		m.backup.RestorePlanned = false
+		if m.backup.RestoreScope == RestoreCaddyOnly {
+			return m, nil
+		}
+		return m.planRestore()
 	}
 	// In bulk delete menu: select option
 	if m.currentView == ViewBulkDeleteMenu {
@@ -269,29 +220,14 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 	// In add/edit form: validate and go to preview
 	if m.currentView == ViewAdd || m.currentView == ViewEdit {
 		// Validate required fields
-		if m.addForm.Subdomain == "" {
-			m.err = fmt.Errorf("Subdomain is required")
-			return m, nil
-		}
-		if m.addForm.DNSTarget == "" {
-			m.err = fmt.Errorf("DNS Target is required")
+		if err := ValidateForm(m.addForm); err != nil {
+			m.err = err
 			return m, nil
 		}
 
-		// Validate A record IP address format
-		if m.addForm.DNSType == "A" {
-			if !isValidIPAddress(m.addForm.DNSTarget) {
-				m.err = fmt.Errorf("Invalid IP address format for A record")
-				return m, nil
-			}
-		}
-
-		// Validate Caddy fields if not DNS-only
-		if !m.addForm.DNSOnly {
-			if m.addForm.ReverseProxyTarget == "" {
-				m.err = fmt.Errorf("Reverse Proxy Target is required (or enable DNS Only)")
-				return m, nil
-			}
+		// The host route option only applies while a wildcard site serves the domains
+		if m.currentView == ViewAdd {
+			m.addForm.HostRoute = m.addForm.HostRoute && m.hostRouteAvailable()
 		}
 
 		// Clear any previous errors and go to preview
@@ -307,13 +243,17 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 	}
 	// Confirm restore backup (only in confirm restore screen)
 	if m.currentView == ViewConfirmRestore && !m.loading {
+		// DNS restores wait for a preview of their changes
+		if m.backup.RestoreScope != RestoreCaddyOnly && !m.backup.RestorePlanned {
+			return m, nil
+		}
 		m.loading = true
 		apiToken, err := m.config.GetAPIToken()
 		if err != nil {
 			m.err = fmt.Errorf("failed to get API token: %w", err)
 			return m, nil
 		}
-		return m, restoreBackupCmd(m.config, m.backup.PreviewPath, m.backup.RestoreScope, apiToken)
+		return m, restoreBackupCmd(m.config, m.backup.PreviewPath, m.backup.RestoreScope, m.backup.RestorePlan, apiToken)
 	}
 	// Confirm cleanup old backups (only in confirm cleanup screen)
 	if m.currentView == ViewConfirmCleanup && !m.loading {
@@ -332,3 +272,16 @@ func (m Model) handleEnterKey() (Model, tea.Cmd) {
 	}
 	return m, nil
 }
+
+// planRestore previews the DNS changes of the selected restore
+func (m Model) planRestore() (Model, tea.Cmd) {
+	apiToken, err := m.config.GetAPIToken()
+	if err != nil {
+		m.err = fmt.Errorf("failed to get API token: %w", err)
+		return m, nil
+	}
+	m.loading = true
+	m.err = nil
+	m.backup.RestorePlanned = false
+	return m, planRestoreCmd(m.config, m.backup.PreviewPath, m.backup.RestoreDeleteNew, apiToken)
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	tea "github.com/charmbracelet/bubbletea"
 
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/diff"
 	snippet_wizard "lazyproxyflare/internal/ui/snippet_wizard"
 )
 
@@ -42,7 +44,8 @@ func (m Model) handleTextInput(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 	}
 
 	// Handle text input in add/edit form - Other text fields
-	// Text fields are: 2 (DNS Target), 4 (Reverse Proxy), 5 (Service Port)
+	// Text fields are: 2 (DNS Target), 4 (Reverse Proxy), 5 (Service Port);
+	// redirects and static sites keep their own settings in 4 and 5
 	if (m.currentView == ViewAdd || m.currentView == ViewEdit) &&
 		(m.addForm.FocusedField == 2 || m.addForm.FocusedField == 4 || m.addForm.FocusedField == 5) {
 		// Only handle single character input for text fields
@@ -56,6 +59,15 @@ func (m Model) handleTextInput(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 						m.addForm.DNSTarget += char
 						return m, nil, true
 					}
+				} else if m.addForm.DNSType == "AAAA" || m.addForm.DNSType == diff.DualStackType {
+					// AAAA record: hex digits, colons and dots (IPv4-mapped)
+					// Dual-stack also allows ", " between the IPv4 and IPv6 address
+					isHex := (char >= "0" && char <= "9") || (char >= "a" && char <= "f") || (char >= "A" && char <= "F")
+					isSep := m.addForm.DNSType == diff.DualStackType && (char == "," || char == " ")
+					if isHex || char == ":" || char == "." || isSep {
+						m.addForm.DNSTarget += char
+						return m, nil, true
+					}
 				} else {
 					// CNAME: allow domain characters
 					if (char >= "a" && char <= "z") || (char >= "A" && char <= "Z") ||
@@ -64,17 +76,39 @@ func (m Model) handleTextInput(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 						return m, nil, true
 					}
 				}
-			case 4: // Reverse Proxy Target
-				// Allow domain/IP characters
-				if (char >= "a" && char <= "z") || (char >= "A" && char <= "Z") ||
-					(char >= "0" && char <= "9") || char == "-" || char == "." {
-					m.addForm.ReverseProxyTarget += char
-					return m, nil, true
+			case 4: // Reverse Proxy Target, Redirect To or Site Root
+				switch m.addForm.EntryType {
+				case caddy.EntryRedirect:
+					// Allow URL characters (no spaces)
+					if char > " " && char <= "~" {
+						m.addForm.RedirectTo += char
+						return m, nil, true
+					}
+				case caddy.EntryStatic:
+					// Allow path characters (no spaces)
+					if char > " " && char <= "~" {
+						m.addForm.StaticRoot += char
+						return m, nil, true
+					}
+				default:
+					// Allow domain/IP characters
+					if (char >= "a" && char <= "z") || (char >= "A" && char <= "Z") ||
+						(char >= "0" && char <= "9") || char == "-" || char == "." {
+						m.addForm.ReverseProxyTarget += char
+						return m, nil, true
+					}
 				}
-			case 5: // Service Port
+			case 5: // Service Port or redirect Status Code
 				// Allow only numbers
 				if char >= "0" && char <= "9" {
-					m.addForm.ServicePort += char
+					switch m.addForm.EntryType {
+					case caddy.EntryRedirect:
+						m.addForm.RedirectCode += char
+					case caddy.EntryStatic:
+						// No port: file_server serves the site itself
+					default:
+						m.addForm.ServicePort += char
+					}
 					return m, nil, true
 				}
 			}
@@ -100,6 +134,43 @@ func (m Model) handleTextInput(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 		}
 	}
 
+	// Handle text input for the path routes field (multi-line, after custom config)
+	if (m.currentView == ViewAdd || m.currentView == ViewEdit) &&
+		m.addForm.FocusedField == m.pathRoutesFieldIndex() {
+		key := msg.String()
+		// Ctrl+M for newlines, as in custom config
+		if key == "ctrl+m" {
+			m.addForm.PathRoutes += "\n"
+			return m, nil, true
+		}
+		if len(key) == 1 {
+			m.addForm.PathRoutes += key
+			return m, nil, true
+		}
+	}
+
+	// Handle text input for the additional upstreams field
+	if (m.currentView == ViewAdd || m.currentView == ViewEdit) &&
+		m.addForm.FocusedField == m.upstreamsFieldIndex() && len(msg.String()) == 1 {
+		m.addForm.Upstreams += msg.String()
+		return m, nil, true
+	}
+
+	// Handle text input for the load balancing field (multi-line)
+	if (m.currentView == ViewAdd || m.currentView == ViewEdit) &&
+		m.addForm.FocusedField == m.loadBalancingFieldIndex() {
+		key := msg.String()
+		// Ctrl+M for newlines, as in custom config
+		if key == "ctrl+m" {
+			m.addForm.LoadBalancing += "\n"
+			return m, nil, true
+		}
+		if len(key) == 1 {
+			m.addForm.LoadBalancing += key
+			return m, nil, true
+		}
+	}
+
 	// Handle text input in profile edit mode (only when actively editing a field)
 	if m.currentView == ViewProfileEdit && m.profile.EditingField && len(msg.String()) == 1 {
 		char := msg.String()
@@ -192,6 +263,16 @@ func (m Model) handleTextInput(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 		}
 	}
 
+	// Handle text input in the text fields of snippet template parameters
+	if m.currentView == ViewSnippetWizard && m.snippetWizardStep == snippet_wizard.StepTemplateParams {
+		if char := msg.String(); len(char) == 1 && char != " " {
+			if param := snippet_wizard.TemplateTextParam(m.currentSnippetTemplate(), m.wizardCursor); param != "" {
+				m.setSnippetTemplateParamText(m.snippetTemplateParamText(param) + char)
+				return m, nil, true
+			}
+		}
+	}
+
 	// Handle text input in custom snippet wizard step using textinput component
 	if m.currentView == ViewSnippetWizard && m.snippetWizardStep == snippet_wizard.StepCustomSnippet {
 		key := msg.String()
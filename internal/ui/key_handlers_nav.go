@@ -30,8 +30,8 @@ func (m Model) handleNavigateDown() (Model, tea.Cmd) {
 			}
 			return m, nil
 		case snippet_wizard.StepTemplateSelection:
-			// Navigate template selection (15 templates)
-			if m.wizardCursor < 14 {
+			// Navigate template selection (17 templates)
+			if m.wizardCursor < 16 {
 				m.wizardCursor++
 			}
 			return m, nil
@@ -174,7 +174,7 @@ func (m Model) handleNavigateDown() (Model, tea.Cmd) {
 			}
 		} else {
 			// Full mode: cycle through all fields following visual order
-			// Visual order: 0(Subdomain), 1(DNSType), 2(DNSTarget), 3(DNSOnly), 6(Proxied), 4(ReverseProxy), 5(Port), 7(SSL), snippets(8+), custom(8+len)
+			// Visual order: 0(Subdomain), 1(DNSType), 2(DNSTarget), 3(DNSOnly), 6(Proxied), type(12+len), 4(ReverseProxy), 5(Port), 7(SSL), snippets(8+), custom(8+len), routes(9+len), upstreams(10+len), lb(11+len)
 			customConfigFieldIndex := 8 + len(m.snippets)
 			switch m.addForm.FocusedField {
 			case 0:
@@ -186,6 +186,8 @@ func (m Model) handleNavigateDown() (Model, tea.Cmd) {
 			case 3:
 				m.addForm.FocusedField = 6 // Jump to Proxied
 			case 6:
+				m.addForm.FocusedField = m.entryTypeFieldIndex() // Jump to Entry Type
+			case m.entryTypeFieldIndex():
 				m.addForm.FocusedField = 4 // Back to Reverse Proxy Target
 			case 4:
 				m.addForm.FocusedField = 5
@@ -198,17 +200,38 @@ func (m Model) handleNavigateDown() (Model, tea.Cmd) {
 					m.addForm.FocusedField = customConfigFieldIndex // Custom config
 				}
 			default:
-				// Snippets and custom config (8+)
-				if m.addForm.FocusedField >= 8 && m.addForm.FocusedField < customConfigFieldIndex {
-					// In snippets, go to next snippet or custom config
+				// Snippets, custom config, path routes and load balancing (8+)
+				if m.addForm.FocusedField >= 8 && m.addForm.FocusedField < m.loadBalancingFieldIndex() {
+					// Go to the next snippet or text field
 					m.addForm.FocusedField++
-				} else if m.addForm.FocusedField == customConfigFieldIndex {
-					// At custom config, wrap to start
+				} else if m.addForm.FocusedField == m.loadBalancingFieldIndex() && m.hostRouteAvailable() {
+					// At load balancing, go to the host route checkbox
+					m.addForm.FocusedField = m.hostRouteFieldIndex()
+				} else if m.addForm.FocusedField == m.loadBalancingFieldIndex() {
+					// At load balancing, wrap to start
 					m.addForm.FocusedField = 0
 				} else {
 					m.addForm.FocusedField = 0
 				}
 			}
+			// Static sites have no port; only reverse proxies have path
+			// routes and load balancing
+			if m.addForm.FocusedField == 5 && m.addForm.EntryType == caddy.EntryStatic {
+				m.addForm.FocusedField = 7
+			}
+			if m.addForm.FocusedField == m.pathRoutesFieldIndex() && m.addForm.EntryType != caddy.EntryProxy {
+				m.addForm.FocusedField = 0
+				if m.hostRouteAvailable() {
+					m.addForm.FocusedField = m.hostRouteFieldIndex()
+				}
+			}
+		}
+		// The Proxied checkbox is hidden when the DNS provider can't proxy
+		if m.addForm.FocusedField == 6 && !proxySupported(m.config) {
+			m.addForm.FocusedField = m.entryTypeFieldIndex()
+			if m.addForm.DNSOnly {
+				m.addForm.FocusedField = 0
+			}
 		}
 		return m, nil
 	}
@@ -264,7 +287,7 @@ func (m Model) handleNavigateUp() (Model, tea.Cmd) {
 			}
 			return m, nil
 		case snippet_wizard.StepTemplateSelection:
-			// Navigate template selection (15 templates)
+			// Navigate template selection (17 templates)
 			if m.wizardCursor > 0 {
 				m.wizardCursor--
 			}
@@ -374,11 +397,13 @@ func (m Model) handleNavigateUp() (Model, tea.Cmd) {
 			}
 		} else {
 			// Full mode: cycle through all fields following visual order (reverse)
-			// Visual order reverse: custom(8+len), snippets(8+), 7(SSL), 5(Port), 4(ReverseProxy), 6(Proxied), 3(DNSOnly), 2(DNSTarget), 1(DNSType), 0(Subdomain)
-			customConfigFieldIndex := 8 + len(m.snippets)
+			// Visual order reverse: lb(11+len), upstreams(10+len), routes(9+len), custom(8+len), snippets(8+), 7(SSL), 5(Port), 4(ReverseProxy), type(12+len), 6(Proxied), 3(DNSOnly), 2(DNSTarget), 1(DNSType), 0(Subdomain)
 			switch m.addForm.FocusedField {
 			case 0:
-				m.addForm.FocusedField = customConfigFieldIndex // Wrap to custom config
+				m.addForm.FocusedField = m.loadBalancingFieldIndex() // Wrap to load balancing
+				if m.hostRouteAvailable() {
+					m.addForm.FocusedField = m.hostRouteFieldIndex() // Wrap to host route
+				}
 			case 1:
 				m.addForm.FocusedField = 0
 			case 2:
@@ -387,8 +412,12 @@ func (m Model) handleNavigateUp() (Model, tea.Cmd) {
 				m.addForm.FocusedField = 2
 			case 6:
 				m.addForm.FocusedField = 3 // Back to DNS Only
-			case 4:
+			case m.entryTypeFieldIndex():
 				m.addForm.FocusedField = 6 // Back to Proxied
+			case 4:
+				m.addForm.FocusedField = m.entryTypeFieldIndex() // Back to Entry Type
+			case m.hostRouteFieldIndex():
+				m.addForm.FocusedField = m.loadBalancingFieldIndex()
 			case 5:
 				m.addForm.FocusedField = 4
 			case 7:
@@ -396,14 +425,26 @@ func (m Model) handleNavigateUp() (Model, tea.Cmd) {
 			case 8:
 				m.addForm.FocusedField = 7 // Back to SSL
 			default:
-				// Snippets and custom config (8+)
-				if m.addForm.FocusedField > 8 && m.addForm.FocusedField <= customConfigFieldIndex {
-					// Go to previous snippet or SSL
+				// Snippets, custom config, path routes and load balancing (8+)
+				if m.addForm.FocusedField > 8 && m.addForm.FocusedField <= m.loadBalancingFieldIndex() {
+					// Go to the previous snippet or text field
 					m.addForm.FocusedField--
 				} else {
 					m.addForm.FocusedField = 0
 				}
 			}
+			// Static sites have no port; only reverse proxies have path
+			// routes and load balancing
+			if m.addForm.FocusedField == 5 && m.addForm.EntryType == caddy.EntryStatic {
+				m.addForm.FocusedField = 4
+			}
+			if m.addForm.FocusedField == m.loadBalancingFieldIndex() && m.addForm.EntryType != caddy.EntryProxy {
+				m.addForm.FocusedField = 8 + len(m.snippets) // Custom config
+			}
+		}
+		// The Proxied checkbox is hidden when the DNS provider can't proxy
+		if m.addForm.FocusedField == 6 && !proxySupported(m.config) {
+			m.addForm.FocusedField = 3
 		}
 		return m, nil
 	}
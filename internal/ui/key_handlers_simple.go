@@ -38,7 +38,7 @@ func (m Model) handleStatusFilterCycle() (Model, tea.Cmd) {
 		return m, nil
 	}
 	if m.currentView == ViewList && !m.searching && !m.loading {
-		m.statusFilter = (m.statusFilter + 1) % 4
+		m.statusFilter = (m.statusFilter + 1) % 8
 		m.cursor = 0
 		m.scrollOffset = 0
 		return m, nil
@@ -49,7 +49,18 @@ func (m Model) handleStatusFilterCycle() (Model, tea.Cmd) {
 // handleDNSTypeFilterCycle cycles through DNS type filters.
 func (m Model) handleDNSTypeFilterCycle() (Model, tea.Cmd) {
 	if m.currentView == ViewList && !m.searching && !m.loading {
-		m.dnsTypeFilter = (m.dnsTypeFilter + 1) % 3
+		m.dnsTypeFilter = (m.dnsTypeFilter + 1) % 4
+		m.cursor = 0
+		m.scrollOffset = 0
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleZoneFilterCycle cycles through the zones of a multi-zone profile.
+func (m Model) handleZoneFilterCycle() (Model, tea.Cmd) {
+	if m.currentView == ViewList && !m.searching && !m.loading && m.config.IsMultiZone() {
+		m.zoneFilter = nextZoneFilter(m.zoneFilter, m.config.ZoneDomains())
 		m.cursor = 0
 		m.scrollOffset = 0
 		return m, nil
@@ -60,7 +71,7 @@ func (m Model) handleDNSTypeFilterCycle() (Model, tea.Cmd) {
 // handleSortModeCycle cycles through sort modes.
 func (m Model) handleSortModeCycle() (Model, tea.Cmd) {
 	if m.currentView == ViewList && !m.searching && !m.loading {
-		m.sortMode = (m.sortMode + 1) % 2
+		m.sortMode = (m.sortMode + 1) % 3
 		m.cursor = 0
 		m.scrollOffset = 0
 		return m, nil
@@ -71,21 +82,7 @@ func (m Model) handleSortModeCycle() (Model, tea.Cmd) {
 // handleAddEntry opens the add entry form with defaults.
 func (m Model) handleAddEntry() (Model, tea.Cmd) {
 	if m.currentView == ViewList && !m.searching && !m.loading {
-		m.addForm = AddFormData{
-			Subdomain:          "",
-			DNSType:            "CNAME",
-			DNSTarget:          m.config.Defaults.CNAMETarget,
-			DNSOnly:            false,
-			ReverseProxyTarget: "localhost",
-			ServicePort:        fmt.Sprintf("%d", m.config.Defaults.Port),
-			Proxied:            m.config.Defaults.Proxied,
-			LANOnly:            false,
-			SSL:                m.config.Defaults.SSL,
-			OAuth:              false,
-			WebSocket:          false,
-			SelectedSnippets:   make(map[string]bool),
-			FocusedField:       0,
-		}
+		m.addForm = NewAddForm(m.config)
 		m.currentView = ViewAdd
 		return m, nil
 	}
@@ -148,6 +145,36 @@ func (m Model) handleBatchSyncSelected() (Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleClaimRecords tags the DNS records of the selected entries, or the
+// entry under the cursor, as managed by LazyProxyFlare.
+func (m Model) handleClaimRecords() (Model, tea.Cmd) {
+	if m.currentView != ViewList || m.searching || m.loading {
+		return m, nil
+	}
+
+	var entries []diff.SyncedEntry
+	if len(m.selectedEntries) > 0 {
+		for _, entry := range m.entries {
+			if m.selectedEntries[entry.Domain] {
+				entries = append(entries, entry)
+			}
+		}
+	} else if filteredEntries := m.getFilteredEntries(); m.cursor < len(filteredEntries) {
+		entries = append(entries, filteredEntries[m.cursor])
+	}
+	if len(entries) == 0 {
+		return m, nil
+	}
+
+	apiToken, err := m.config.GetAPIToken()
+	if err != nil {
+		m.err = fmt.Errorf("failed to get API token: %w", err)
+		return m, nil
+	}
+	m.loading = true
+	return m, claimRecordsCmd(m.config, entries, apiToken)
+}
+
 // handleSyncEntry opens sync confirmation for an orphaned entry.
 func (m Model) handleSyncEntry() (Model, tea.Cmd) {
 	if m.currentView == ViewList && !m.searching && !m.loading {
@@ -347,6 +374,24 @@ func (m Model) handleBackspaceKey() (Model, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.addForm.FocusedField == m.pathRoutesFieldIndex() {
+			if len(m.addForm.PathRoutes) > 0 {
+				m.addForm.PathRoutes = m.addForm.PathRoutes[:len(m.addForm.PathRoutes)-1]
+			}
+			return m, nil
+		}
+		if m.addForm.FocusedField == m.upstreamsFieldIndex() {
+			if len(m.addForm.Upstreams) > 0 {
+				m.addForm.Upstreams = m.addForm.Upstreams[:len(m.addForm.Upstreams)-1]
+			}
+			return m, nil
+		}
+		if m.addForm.FocusedField == m.loadBalancingFieldIndex() {
+			if len(m.addForm.LoadBalancing) > 0 {
+				m.addForm.LoadBalancing = m.addForm.LoadBalancing[:len(m.addForm.LoadBalancing)-1]
+			}
+			return m, nil
+		}
 
 		// Handle other fields
 		switch m.addForm.FocusedField {
@@ -358,12 +403,27 @@ func (m Model) handleBackspaceKey() (Model, tea.Cmd) {
 			if len(m.addForm.DNSTarget) > 0 {
 				m.addForm.DNSTarget = m.addForm.DNSTarget[:len(m.addForm.DNSTarget)-1]
 			}
-		case 4: // Reverse Proxy Target
-			if len(m.addForm.ReverseProxyTarget) > 0 {
-				m.addForm.ReverseProxyTarget = m.addForm.ReverseProxyTarget[:len(m.addForm.ReverseProxyTarget)-1]
+		case 4: // Reverse Proxy Target, Redirect To or Site Root
+			switch m.addForm.EntryType {
+			case caddy.EntryRedirect:
+				if len(m.addForm.RedirectTo) > 0 {
+					m.addForm.RedirectTo = m.addForm.RedirectTo[:len(m.addForm.RedirectTo)-1]
+				}
+			case caddy.EntryStatic:
+				if len(m.addForm.StaticRoot) > 0 {
+					m.addForm.StaticRoot = m.addForm.StaticRoot[:len(m.addForm.StaticRoot)-1]
+				}
+			default:
+				if len(m.addForm.ReverseProxyTarget) > 0 {
+					m.addForm.ReverseProxyTarget = m.addForm.ReverseProxyTarget[:len(m.addForm.ReverseProxyTarget)-1]
+				}
 			}
-		case 5: // Service Port
-			if len(m.addForm.ServicePort) > 0 {
+		case 5: // Service Port or redirect Status Code
+			if m.addForm.EntryType == caddy.EntryRedirect {
+				if len(m.addForm.RedirectCode) > 0 {
+					m.addForm.RedirectCode = m.addForm.RedirectCode[:len(m.addForm.RedirectCode)-1]
+				}
+			} else if len(m.addForm.ServicePort) > 0 {
 				m.addForm.ServicePort = m.addForm.ServicePort[:len(m.addForm.ServicePort)-1]
 			}
 		}
@@ -394,6 +454,16 @@ func (m Model) handleBackspaceKey() (Model, tea.Cmd) {
 			return m, nil
 		}
 	}
+
+	// Handle backspace in the text fields of snippet template parameters
+	if m.currentView == ViewSnippetWizard && m.snippetWizardStep == snippet_wizard.StepTemplateParams {
+		if param := snippet_wizard.TemplateTextParam(m.currentSnippetTemplate(), m.wizardCursor); param != "" {
+			if text := []rune(m.snippetTemplateParamText(param)); len(text) > 0 {
+				m.setSnippetTemplateParamText(string(text[:len(text)-1]))
+			}
+			return m, nil
+		}
+	}
 	return m, nil
 }
 
@@ -548,6 +618,7 @@ func (m Model) handleRestoreBackup() (Model, tea.Cmd) {
 			m.backup.PreviewPath = backups[m.backup.Cursor].Path
 			m.backup.RestoreScopeCursor = 0    // Reset cursor
 			m.backup.RestoreScope = RestoreAll // Default to restore all
+			m.backup.RestoreDeleteNew = false
 			m.currentView = ViewRestoreScope
 		}
 		return m, nil
@@ -646,7 +717,7 @@ func (m Model) handleRefreshData() (Model, tea.Cmd) {
 	// Refresh data (only from list view, not while searching or loading)
 	if m.currentView == ViewList && !m.searching && !m.loading {
 		m.loading = true
-		return m, refreshDataCmd(m.config)
+		return m.startRefresh()
 	}
 	return m, nil
 }
@@ -26,8 +26,8 @@ func (m Model) handleTabKey() (Model, tea.Cmd) {
 			}
 			return m, nil
 		case snippet_wizard.StepTemplateSelection:
-			// Navigate template list (15 templates)
-			if m.wizardCursor < 14 {
+			// Navigate template list (17 templates)
+			if m.wizardCursor < 16 {
 				m.wizardCursor++
 			}
 			return m, nil
@@ -88,8 +88,11 @@ func (m Model) handleTabKey() (Model, tea.Cmd) {
 				m.addForm.FocusedField = 0
 			}
 		} else {
-			// Full mode: cycle through all fields (0-7 + snippets + custom config)
-			maxFields := 8 + len(m.snippets) + 1
+			// Full mode: cycle through all fields (0-7 + snippets + custom config + path routes + load balancing + entry type + host route)
+			maxFields := 8 + len(m.snippets) + 5
+			if m.hostRouteAvailable() {
+				maxFields++
+			}
 			m.addForm.FocusedField = (m.addForm.FocusedField + 1) % maxFields
 		}
 		return m, nil
@@ -174,8 +177,11 @@ func (m Model) handleShiftTabKey() (Model, tea.Cmd) {
 				m.addForm.FocusedField = 6
 			}
 		} else {
-			// Full mode: cycle backward through all fields (0-7 + snippets + custom config)
-			maxFields := 8 + len(m.snippets) + 1
+			// Full mode: cycle backward through all fields (0-7 + snippets + custom config + path routes + load balancing + entry type + host route)
+			maxFields := 8 + len(m.snippets) + 5
+			if m.hostRouteAvailable() {
+				maxFields++
+			}
 			m.addForm.FocusedField = (m.addForm.FocusedField - 1 + maxFields) % maxFields
 		}
 		return m, nil
@@ -3,6 +3,8 @@ package ui
 import (
 	tea "github.com/charmbracelet/bubbletea"
 
+	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/diff"
 	snippet_wizard "lazyproxyflare/internal/ui/snippet_wizard"
 )
 
@@ -12,6 +14,11 @@ func (m Model) handleSpaceKey() (Model, tea.Cmd) {
 	if m.currentView == ViewProfileEdit {
 		return m.handleProfileEditKeyPress(" ")
 	}
+	// In restore confirmation: toggle deleting records created since the backup
+	if m.currentView == ViewConfirmRestore && !m.loading && m.backup.RestoreScope != RestoreCaddyOnly {
+		m.backup.RestoreDeleteNew = !m.backup.RestoreDeleteNew
+		return m.planRestore()
+	}
 	// In snippet wizard: space toggles checkboxes
 	if m.currentView == ViewSnippetWizard {
 		switch m.snippetWizardStep {
@@ -20,7 +27,7 @@ func (m Model) handleSpaceKey() (Model, tea.Cmd) {
 			// Template order matches template_selection.go view
 			templateKeys := []string{
 				// Security
-				"cors_headers", "rate_limiting", "auth_headers", "ip_restricted", "security_headers",
+				"cors_headers", "rate_limiting", "auth_headers", "forward_auth", "basic_auth", "ip_restricted", "security_headers",
 				// Performance
 				"static_caching", "compression_advanced", "performance",
 				// Backend Integration
@@ -60,10 +67,10 @@ func (m Model) handleSpaceKey() (Model, tea.Cmd) {
 		filteredEntries := m.getFilteredEntries()
 		if m.cursor < len(filteredEntries) {
 			domain := filteredEntries[m.cursor].Domain
-			// Toggle selection
+			// Toggle selection (ignored entries are kept out of batch operations)
 			if m.selectedEntries[domain] {
 				delete(m.selectedEntries, domain)
-			} else {
+			} else if filteredEntries[m.cursor].Status != diff.StatusIgnored {
 				m.selectedEntries[domain] = true
 			}
 		}
@@ -72,19 +79,48 @@ func (m Model) handleSpaceKey() (Model, tea.Cmd) {
 	// In add/edit form: space toggles DNS type and checkboxes
 	if m.currentView == ViewAdd || m.currentView == ViewEdit {
 		switch m.addForm.FocusedField {
-		case 1: // DNS Type toggle
-			if m.addForm.DNSType == "CNAME" {
+		case 1: // DNS Type toggle: CNAME -> A -> AAAA -> A+AAAA
+			switch m.addForm.DNSType {
+			case "CNAME":
 				m.addForm.DNSType = "A"
-			} else {
+			case "A":
+				m.addForm.DNSType = "AAAA"
+			case "AAAA":
+				m.addForm.DNSType = diff.DualStackType
+			default:
 				m.addForm.DNSType = "CNAME"
 			}
 		case 3: // DNS Only checkbox
 			m.addForm.DNSOnly = !m.addForm.DNSOnly
 		case 6: // Proxied checkbox
 			m.addForm.Proxied = !m.addForm.Proxied
-		case 7: // SSL checkbox
-			m.addForm.SSL = !m.addForm.SSL
+		case 7: // SSL checkbox, or the redirect's or static site's option
+			switch m.addForm.EntryType {
+			case caddy.EntryRedirect:
+				m.addForm.RedirectPreservePath = !m.addForm.RedirectPreservePath
+			case caddy.EntryStatic:
+				m.addForm.StaticBrowse = !m.addForm.StaticBrowse
+			default:
+				m.addForm.SSL = !m.addForm.SSL
+			}
 		default:
+			// Entry type toggle: Reverse Proxy -> Redirect -> Static Site
+			if m.addForm.FocusedField == m.entryTypeFieldIndex() {
+				switch m.addForm.EntryType {
+				case caddy.EntryProxy:
+					m.addForm.EntryType = caddy.EntryRedirect
+				case caddy.EntryRedirect:
+					m.addForm.EntryType = caddy.EntryStatic
+				default:
+					m.addForm.EntryType = caddy.EntryProxy
+				}
+				return m, nil
+			}
+			// Host route checkbox follows the entry type
+			if m.addForm.FocusedField == m.hostRouteFieldIndex() && m.hostRouteAvailable() {
+				m.addForm.HostRoute = !m.addForm.HostRoute
+				return m, nil
+			}
 			// Fields 8+ are snippet checkboxes
 			if m.addForm.FocusedField >= 8 {
 				snippetIndex := m.addForm.FocusedField - 8
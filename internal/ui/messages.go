@@ -15,27 +15,30 @@ type refreshCompleteMsg struct {
 }
 
 type createEntryMsg struct {
-	success     bool
-	err         error
-	dnsRecordID string // Track created DNS record for rollback
-	backupPath  string // Track backup path
-	errorStep   string // Which step failed
+	success      bool
+	err          error
+	dnsRecordID  string // Track created DNS record for rollback
+	backupPath   string // Track backup path
+	errorStep    string // Which step failed
+	reloadOutput string // Output of the Caddy reload command (for audit log)
 }
 
 type deleteEntryMsg struct {
-	success    bool
-	err        error
-	backupPath string // Track backup path
-	errorStep  string // Which step failed
-	domain     string // Domain that was deleted (for audit log)
-	entityType string // "dns", "caddy", or "both"
+	success      bool
+	err          error
+	backupPath   string // Track backup path
+	errorStep    string // Which step failed
+	domain       string // Domain that was deleted (for audit log)
+	entityType   string // "dns", "caddy", or "both"
+	reloadOutput string // Output of the Caddy reload command (for audit log)
 }
 
 type updateEntryMsg struct {
-	success    bool
-	err        error
-	backupPath string // Track backup path
-	errorStep  string // Which step failed
+	success      bool
+	err          error
+	backupPath   string // Track backup path
+	errorStep    string // Which step failed
+	reloadOutput string // Output of the Caddy reload command (for audit log)
 }
 
 type editorFinishedMsg struct {
@@ -55,11 +58,12 @@ type importProfileMsg struct {
 }
 
 type syncEntryMsg struct {
-	success     bool
-	err         error
-	backupPath  string // Track backup path (if syncing to Caddy)
-	dnsRecordID string // Track DNS record ID (if syncing to DNS)
-	errorStep   string // Which step failed
-	domain      string // Domain that was synced (for audit log)
-	syncType    string // "to_dns" or "to_caddy"
+	success      bool
+	err          error
+	backupPath   string // Track backup path (if syncing to Caddy)
+	dnsRecordID  string // Track DNS record ID (if syncing to DNS)
+	errorStep    string // Which step failed
+	domain       string // Domain that was synced (for audit log)
+	syncType     string // "to_dns" or "to_caddy"
+	reloadOutput string // Output of the Caddy reload command (for audit log)
 }
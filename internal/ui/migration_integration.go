@@ -88,6 +88,11 @@ func (m Model) handleMigrationOptionsKeyPress(key string) (Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "t":
+		// Toggle trusting Cloudflare's proxies
+		m.migration.Data.TrustCloudflare = !m.migration.Data.TrustCloudflare
+		return m, nil
+
 	case "enter":
 		// Update options based on selection and move to confirm
 		m.updateMigrationOptions()
@@ -152,7 +157,7 @@ func (m Model) handleMigrationCompleteKeyPress(key string) (Model, tea.Cmd) {
 
 	// Reload data to reflect migrated Caddyfile
 	m.loading = true
-	return m, refreshDataCmd(m.config)
+	return m.startRefresh()
 }
 
 // performMigrationCmd executes the migration asynchronously
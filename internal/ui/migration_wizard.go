@@ -28,6 +28,7 @@ type MigrationWizardData struct {
 	Error           error  // Any error during migration
 	InProgress      bool   // Migration is running
 	CaddyfilePath   string // Path to Caddyfile being migrated
+	TrustCloudflare bool   // Trust Cloudflare's proxies in the new Caddyfile (toggled with t)
 }
 
 // renderMigrationWizard renders the appropriate migration wizard screen
@@ -126,6 +127,11 @@ func (m Model) renderMigrationOptions() string {
 	// Archive notice (only show if not "keep existing")
 	if m.migration.Data.OptionCursor != 0 {
 		b.WriteString("\n")
+		trust := "[ ]"
+		if m.migration.Data.TrustCloudflare {
+			trust = "[x]"
+		}
+		b.WriteString(trust + " Trust Cloudflare proxies (trusted_proxies for proxied entries)\n\n")
 		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F9E2AF"))
 		b.WriteString(warningStyle.Render("ℹ Original file will be archived to:"))
 		b.WriteString("\n")
@@ -140,7 +146,7 @@ func (m Model) renderMigrationOptions() string {
 	}
 
 	// Navigation
-	b.WriteString(dimStyle.Render("↑/↓: navigate  Enter: proceed  ESC: cancel"))
+	b.WriteString(dimStyle.Render("↑/↓: navigate  t: trust Cloudflare  Enter: proceed  ESC: cancel"))
 
 	return b.String()
 }
@@ -328,18 +334,24 @@ func (m *Model) initMigrationWizard(caddyfilePath string) error {
 		return fmt.Errorf("failed to parse Caddyfile: %w", err)
 	}
 
+	// Only Cloudflare profiles proxy through Cloudflare by default
+	trustCloudflare := m.config != nil && m.config.DNS.IsCloudflare()
+
 	// Initialize wizard data
 	m.migration.Data = &MigrationWizardData{
 		Step:          MigrationStepOptions,
 		ParsedContent: parsedContent,
 		Options: caddy.MigrationOptions{
-			ImportEntries:  true, // Default: import all
-			ImportSnippets: true,
-			ArchiveOld:     true,
-			FreshTemplate:  false,
+			ImportEntries:   true, // Default: import all
+			ImportSnippets:  true,
+			ArchiveOld:      true,
+			FreshTemplate:   false,
+			TrustCloudflare: trustCloudflare,
 		},
 		OptionCursor:  0,
 		CaddyfilePath: caddyfilePath,
+
+		TrustCloudflare: trustCloudflare,
 	}
 
 	return nil
@@ -351,6 +363,7 @@ func (m *Model) updateMigrationOptions() {
 		return
 	}
 
+	trust := m.migration.Data.TrustCloudflare
 	switch m.migration.Data.OptionCursor {
 	case 0: // Keep existing - no migration
 		m.migration.Data.Options = caddy.MigrationOptions{
@@ -361,31 +374,35 @@ func (m *Model) updateMigrationOptions() {
 		}
 	case 1: // Import all
 		m.migration.Data.Options = caddy.MigrationOptions{
-			ImportEntries:  true,
-			ImportSnippets: true,
-			ArchiveOld:     true,
-			FreshTemplate:  false,
+			ImportEntries:   true,
+			ImportSnippets:  true,
+			ArchiveOld:      true,
+			FreshTemplate:   false,
+			TrustCloudflare: trust,
 		}
 	case 2: // Import entries only
 		m.migration.Data.Options = caddy.MigrationOptions{
-			ImportEntries:  true,
-			ImportSnippets: false,
-			ArchiveOld:     true,
-			FreshTemplate:  false,
+			ImportEntries:   true,
+			ImportSnippets:  false,
+			ArchiveOld:      true,
+			FreshTemplate:   false,
+			TrustCloudflare: trust,
 		}
 	case 3: // Import snippets only
 		m.migration.Data.Options = caddy.MigrationOptions{
-			ImportEntries:  false,
-			ImportSnippets: true,
-			ArchiveOld:     true,
-			FreshTemplate:  false,
+			ImportEntries:   false,
+			ImportSnippets:  true,
+			ArchiveOld:      true,
+			FreshTemplate:   false,
+			TrustCloudflare: trust,
 		}
 	case 4: // Fresh start
 		m.migration.Data.Options = caddy.MigrationOptions{
-			ImportEntries:  false,
-			ImportSnippets: false,
-			ArchiveOld:     true,
-			FreshTemplate:  true,
+			ImportEntries:   false,
+			ImportSnippets:  false,
+			ArchiveOld:      true,
+			FreshTemplate:   true,
+			TrustCloudflare: trust,
 		}
 	}
 }
@@ -1,10 +1,15 @@
 package ui
 
 import (
+	"context"
+	"time"
+
 	"lazyproxyflare/internal/audit"
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 	"lazyproxyflare/internal/diff"
+	"lazyproxyflare/internal/snapshot"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -73,9 +78,9 @@ func (r RestoreScope) String() string {
 func (r RestoreScope) Description() string {
 	switch r {
 	case RestoreAll:
-		return "Restore both Caddyfile configuration and DNS records from backup"
+		return "Restore the Caddyfile and the DNS records snapshotted with it"
 	case RestoreDNSOnly:
-		return "Restore DNS records only, leave current Caddyfile unchanged"
+		return "Restore the DNS records snapshotted with the backup, leave current Caddyfile unchanged"
 	case RestoreCaddyOnly:
 		return "Restore Caddyfile only, leave DNS records unchanged"
 	default:
@@ -128,6 +133,10 @@ const (
 	FilterSynced
 	FilterOrphanedDNS
 	FilterOrphanedCaddy
+	FilterOrphanedIngress
+	FilterMismatch
+	FilterUnmanaged
+	FilterIgnored
 )
 
 // String returns human-readable filter name
@@ -141,6 +150,14 @@ func (f FilterMode) String() string {
 		return "Orphaned DNS"
 	case FilterOrphanedCaddy:
 		return "Orphaned Caddy"
+	case FilterOrphanedIngress:
+		return "Orphaned Ingress"
+	case FilterMismatch:
+		return "Mismatch"
+	case FilterUnmanaged:
+		return "Unmanaged"
+	case FilterIgnored:
+		return "Ignored"
 	default:
 		return "Unknown"
 	}
@@ -153,6 +170,7 @@ const (
 	DNSTypeAll DNSTypeFilter = iota
 	DNSTypeCNAME
 	DNSTypeA
+	DNSTypeAAAA
 )
 
 // String returns human-readable DNS type filter name
@@ -164,6 +182,8 @@ func (d DNSTypeFilter) String() string {
 		return "CNAME"
 	case DNSTypeA:
 		return "A"
+	case DNSTypeAAAA:
+		return "AAAA"
 	default:
 		return "Unknown"
 	}
@@ -175,6 +195,7 @@ type SortMode int
 const (
 	SortAlphabetical SortMode = iota
 	SortByStatus
+	SortByZone
 )
 
 // ActiveTab represents which main view tab is active
@@ -204,6 +225,8 @@ func (s SortMode) String() string {
 		return "Alphabetical"
 	case SortByStatus:
 		return "By Status"
+	case SortByZone:
+		return "By Zone"
 	default:
 		return "Unknown"
 	}
@@ -270,6 +293,10 @@ type BackupState struct {
 	RetentionDays int          // Days to keep backups (for cleanup)
 	RestoreScope      RestoreScope // What to restore (All/DNS/Caddy)
 	RestoreScopeCursor int          // Cursor for restore scope selection (0-2)
+	RestorePlan        []snapshot.Change // DNS changes of the restore, previewed before confirming
+	RestorePlanned     bool              // RestorePlan is up to date with the options below
+	RestoreSnapshotAt  time.Time         // When the backup's DNS snapshot was taken
+	RestoreDeleteNew   bool              // Also delete records created since the backup
 }
 
 // BulkDeleteState holds state for bulk deletion operations
@@ -289,6 +316,7 @@ type ProfileEditData struct {
 	Name          string // Profile name
 	APIToken      string // Cloudflare API token
 	ZoneID        string // Cloudflare zone ID
+	DNSProvider   string // dns.provider (not editable; the Cloudflare fields only apply to Cloudflare)
 	Domain        string // Domain name
 	CaddyfilePath string // Host path to Caddyfile
 	ContainerPath string // Container path to Caddyfile
@@ -310,8 +338,8 @@ type ProfileEditData struct {
 
 type AddFormData struct {
 	Subdomain          string
-	DNSType            string // "CNAME" or "A"
-	DNSTarget          string // CNAME target or A record IP
+	DNSType            string // "CNAME", "A", "AAAA", or "A+AAAA" (dual-stack)
+	DNSTarget          string // CNAME target, record IP, or "IPv4, IPv6" for dual-stack
 	DNSOnly            bool   // If true, create DNS record only (skip Caddy)
 	ReverseProxyTarget string
 	ServicePort        string
@@ -322,7 +350,21 @@ type AddFormData struct {
 	WebSocket          bool
 	SelectedSnippets   map[string]bool // Map of snippet name -> selected
 	CustomCaddyConfig  string          // Custom Caddy directives (one-off features)
-	FocusedField       int             // Which field is currently focused (0-10 + num snippets + custom config)
+	PathRoutes         string          // Services under a path, one per line (see caddy.ParsePathRoutes)
+	Upstreams          string          // Further upstreams load balanced with ReverseProxyTarget (see caddy.ParseUpstreams)
+	LoadBalancing      string          // Load balancing and health check settings, one per line (see caddy.ParseLoadBalancing)
+	ProxyOptions       []string        // Other reverse_proxy subdirectives of the edited entry, kept as written
+	HostRoute          bool            // Add as a host route inside the wildcard site block serving the domains
+	FocusedField       int             // Which field is currently focused (0-10 + num snippets + custom config + path routes + load balancing + entry type + host route)
+
+	// Entry type: redirects and static sites reuse the target, port and SSL
+	// fields (4, 5, 7) for their own settings
+	EntryType            caddy.EntryType // Reverse proxy (default), redirect or static site
+	RedirectTo           string          // Redirect destination URL
+	RedirectCode         string          // Redirect status code (301, 302, 303, 307 or 308)
+	RedirectPreservePath bool            // Append the request's path and query to RedirectTo
+	StaticRoot           string          // Directory served by a static site
+	StaticBrowse         bool            // List directories without an index file
 }
 
 // Model represents the Bubbletea application state
@@ -345,9 +387,13 @@ type Model struct {
 	loading       bool          // Whether data is being refreshed
 	statusFilter  FilterMode    // Current status filter
 	dnsTypeFilter DNSTypeFilter // Current DNS type filter
+	zoneFilter    string        // Zone domain to show ("" = all zones)
 	sortMode      SortMode      // Current sort order
 	activeTab     ActiveTab     // Current main view tab (Cloudflare/Caddy)
 
+	// Refresh in flight; esc on the list view cancels it
+	cancelRefresh context.CancelFunc // nil when no refresh is running
+
 	// Multi-select state
 	selectedEntries map[string]bool // Track selected entries by domain name
 
@@ -378,6 +424,8 @@ type Model struct {
 	wizardCursor          int                    // Cursor for selections in wizard
 	wizardTextInput       textinput.Model        // Text input component for wizard (supports paste)
 	wizardDockerContainers []caddy.DockerContainer // Detected Docker containers for wizard
+	wizardZones            []cloudflare.Zone       // Zones the wizard's API token can access
+	wizardVerifying        bool                    // Token verification and zone listing in flight
 
 	// Snippet panel state
 	snippetPanel SnippetPanelState
@@ -385,6 +433,7 @@ type Model struct {
 	// Snippet wizard state
 	snippetWizardStep SnippetWizardStep // Current snippet wizard step
 	snippetWizardData SnippetWizardData // Data collected during snippet wizard
+	snippetHashing    bool              // basic_auth password being hashed
 
 	// Migration wizard state
 	migration MigrationState
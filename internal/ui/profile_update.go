@@ -6,6 +6,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"lazyproxyflare/internal/caddy"
 	"lazyproxyflare/internal/config"
 )
 
@@ -127,6 +128,7 @@ func (m Model) loadProfile(profileName string) (Model, tea.Cmd) {
 
 	// Convert to legacy config format
 	m.config = config.ProfileToLegacyConfig(profileConfig)
+	caddy.SetContainerPath(m.config.Caddy.CaddyfilePath, m.config.Caddy.CaddyfileContainerPath)
 
 	// Clear current data (will be reloaded)
 	m.entries = nil
@@ -139,6 +141,7 @@ func (m Model) loadProfile(profileName string) (Model, tea.Cmd) {
 	m.searching = false
 	m.statusFilter = FilterAll
 	m.dnsTypeFilter = DNSTypeAll
+	m.zoneFilter = ""
 	m.sortMode = SortAlphabetical
 
 	// Clear selections
@@ -147,9 +150,9 @@ func (m Model) loadProfile(profileName string) (Model, tea.Cmd) {
 	// Return to main view
 	m.currentView = ViewList
 
-	// Set loading and return command to reload data
+	// Set loading; finish or undo interrupted operations before reloading data
 	m.loading = true
-	return m, refreshDataCmd(m.config)
+	return m, recoverJournalsCmd(m.config)
 }
 
 
@@ -186,6 +189,7 @@ func (m Model) startProfileEdit(profileName string) (Model, tea.Cmd) {
 		Name:          profileConfig.Profile.Name,
 		APIToken:      profileConfig.Cloudflare.APIToken,
 		ZoneID:        profileConfig.Cloudflare.ZoneID,
+		DNSProvider:   profileConfig.DNS.ProviderName(),
 		Domain:        profileConfig.Domain,
 		CaddyfilePath: profileConfig.Proxy.Caddy.CaddyfilePath,
 		ContainerPath: profileConfig.Proxy.Caddy.CaddyfileContainerPath,
@@ -362,11 +366,12 @@ func (m Model) saveProfileEdit() (Model, tea.Cmd) {
 		m.err = fmt.Errorf("profile name is required")
 		return m, nil
 	}
-	if data.APIToken == "" {
+	cloudflareDNS := data.DNSProvider == config.DNSProviderCloudflare
+	if cloudflareDNS && data.APIToken == "" {
 		m.err = fmt.Errorf("API token is required")
 		return m, nil
 	}
-	if data.ZoneID == "" {
+	if cloudflareDNS && data.ZoneID == "" {
 		m.err = fmt.Errorf("Zone ID is required")
 		return m, nil
 	}
@@ -441,6 +446,7 @@ func (m Model) saveProfileEdit() (Model, tea.Cmd) {
 	if data.OriginalName == m.profile.CurrentName {
 		m.profile.CurrentName = data.Name
 		m.config = config.ProfileToLegacyConfig(existingProfile)
+		caddy.SetContainerPath(m.config.Caddy.CaddyfilePath, m.config.Caddy.CaddyfileContainerPath)
 		config.SetLastUsedProfile(data.Name)
 	}
 
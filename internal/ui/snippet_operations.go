@@ -32,9 +32,19 @@ func (m Model) createSnippetsFromWizard() (Model, tea.Cmd) {
 	// Convert GeneratedSnippets to snippet strings
 	var snippetsToAdd []string
 	var snippetNames []string
+	generatedNames := make(map[string]bool)
 	for _, gen := range generatedSnippets {
-		// Wrap content in snippet syntax
-		snippetStr := fmt.Sprintf("(%s) {\n%s\n}", gen.Name, gen.Content)
+		if generatedNames[gen.Name] {
+			m.err = fmt.Errorf("two selected snippets are named %s - rename one of them", gen.Name)
+			return m, nil
+		}
+		generatedNames[gen.Name] = true
+
+		// Wrap content in snippet syntax; templates already generate the whole block
+		snippetStr := gen.Content
+		if !strings.HasPrefix(snippetStr, "("+gen.Name+")") {
+			snippetStr = fmt.Sprintf("(%s) {\n%s\n}", gen.Name, gen.Content)
+		}
 		snippetsToAdd = append(snippetsToAdd, snippetStr)
 		snippetNames = append(snippetNames, gen.Name)
 	}
@@ -47,8 +57,8 @@ func (m Model) createSnippetsFromWizard() (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Check for duplicate snippets and filter them out
-	parsed := caddy.ParseCaddyfileWithSnippets(string(content))
+	// Check for duplicate snippets (including those in imported files) and filter them out
+	parsed, _ := caddy.LoadCaddyfile(m.config.Caddy.CaddyfilePath)
 	existingNames := make(map[string]bool)
 	for _, s := range parsed.Snippets {
 		existingNames[s.Name] = true
@@ -118,14 +128,7 @@ func (m Model) createSnippetsFromWizard() (Model, tea.Cmd) {
 	}
 
 	// Validate Caddyfile
-	if err := caddy.FormatAndValidateCaddyfile(
-		m.config.Caddy.CaddyfilePath,
-		m.config.Caddy.CaddyfileContainerPath,
-		m.config.Caddy.ContainerName,
-		m.config.Caddy.DockerMethod,
-		m.config.Caddy.ComposeFilePath,
-		m.config.Caddy.ValidationCommand,
-	); err != nil {
+	if err := formatAndValidateCaddyfile(m.config); err != nil {
 		m.currentView = ViewList
 		// Attempt to restore backup
 		if restoreErr := caddy.RestoreFromBackup(m.config.Caddy.CaddyfilePath, backupPath); restoreErr != nil {
@@ -137,16 +140,14 @@ func (m Model) createSnippetsFromWizard() (Model, tea.Cmd) {
 	}
 
 	// Reload Caddy
-	if err := caddy.RestartCaddy(m.config.Caddy.ContainerName); err != nil {
+	if _, err := reloadCaddy(m.config); err != nil {
 		m.currentView = ViewList
-		m.err = fmt.Errorf("failed to restart Caddy: %w", err)
+		m.err = fmt.Errorf("failed to reload Caddy: %w", err)
 		return m, nil
 	}
 
 	// Reload snippets
-	caddyContent, err := os.ReadFile(m.config.Caddy.CaddyfilePath)
-	if err == nil {
-		parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
+	if parsed, err := caddy.LoadCaddyfile(m.config.Caddy.CaddyfilePath); err == nil {
 		m.snippets = parsed.Snippets
 	}
 
@@ -195,43 +196,6 @@ func (m Model) saveSnippetEdit() (Model, tea.Cmd) {
 	snippet := m.snippets[m.snippetPanel.EditingIndex]
 	newContent := m.snippetPanel.EditTextarea.Value()
 
-	// Read current Caddyfile
-	content, err := os.ReadFile(m.config.Caddy.CaddyfilePath)
-	if err != nil {
-		m.err = fmt.Errorf("failed to read Caddyfile: %w", err)
-		return m, nil
-	}
-
-	// Replace the snippet content in the Caddyfile
-	// We need to find the snippet block and replace it
-	lines := strings.Split(string(content), "\n")
-
-	// Build new Caddyfile content with updated snippet
-	var newCaddyfile strings.Builder
-	for i := 0; i < len(lines); i++ {
-		// Check if this is the start of our snippet (LineStart is 1-indexed)
-		if i+1 == snippet.LineStart {
-			// Write the snippet name line
-			newCaddyfile.WriteString(lines[i])
-			newCaddyfile.WriteString("\n")
-
-			// Write the new content (already includes proper indentation from textarea)
-			newCaddyfile.WriteString(newContent)
-			newCaddyfile.WriteString("\n")
-
-			// Skip the old content lines (until LineEnd-1, as LineEnd is the closing brace)
-			// Then write the closing brace
-			i = snippet.LineEnd - 1 // -1 because LineEnd is 1-indexed and we're 0-indexed
-			if i < len(lines) {
-				newCaddyfile.WriteString(lines[i])
-				newCaddyfile.WriteString("\n")
-			}
-		} else {
-			newCaddyfile.WriteString(lines[i])
-			newCaddyfile.WriteString("\n")
-		}
-	}
-
 	// Backup current Caddyfile
 	backupPath, err := caddy.BackupCaddyfile(m.config.Caddy.CaddyfilePath)
 	if err != nil {
@@ -239,34 +203,19 @@ func (m Model) saveSnippetEdit() (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Get permissions from backup to preserve them
-	backupInfo, err := os.Stat(backupPath)
-	if err != nil {
-		m.err = fmt.Errorf("failed to stat backup: %w", err)
-		return m, nil
-	}
-	backupPerms := backupInfo.Mode().Perm()
-
-	// Write new content with original permissions
-	if err := os.WriteFile(m.config.Caddy.CaddyfilePath, []byte(newCaddyfile.String()), backupPerms); err != nil {
+	// Replace the snippet content, keeping the rest of the file as-is
+	if err := caddy.UpdateSnippet(m.config.Caddy.CaddyfilePath, snippet.Name, newContent); err != nil {
 		// Attempt to restore backup
 		if restoreErr := caddy.RestoreFromBackup(m.config.Caddy.CaddyfilePath, backupPath); restoreErr != nil {
 			m.err = fmt.Errorf("CRITICAL: write failed AND backup restore failed: %w (original error: %v)", restoreErr, err)
 		} else {
-			m.err = fmt.Errorf("failed to write Caddyfile (backup restored): %w", err)
+			m.err = fmt.Errorf("failed to update snippet (backup restored): %w", err)
 		}
 		return m, nil
 	}
 
 	// Validate Caddyfile
-	if err := caddy.FormatAndValidateCaddyfile(
-		m.config.Caddy.CaddyfilePath,
-		m.config.Caddy.CaddyfileContainerPath,
-		m.config.Caddy.ContainerName,
-		m.config.Caddy.DockerMethod,
-		m.config.Caddy.ComposeFilePath,
-		m.config.Caddy.ValidationCommand,
-	); err != nil {
+	if err := formatAndValidateCaddyfile(m.config); err != nil {
 		// Attempt to restore backup
 		if restoreErr := caddy.RestoreFromBackup(m.config.Caddy.CaddyfilePath, backupPath); restoreErr != nil {
 			m.err = fmt.Errorf("CRITICAL: validation failed AND backup restore failed: %w (original error: %v)", restoreErr, err)
@@ -277,15 +226,13 @@ func (m Model) saveSnippetEdit() (Model, tea.Cmd) {
 	}
 
 	// Reload Caddy
-	if err := caddy.RestartCaddy(m.config.Caddy.ContainerName); err != nil {
-		m.err = fmt.Errorf("failed to restart Caddy: %w", err)
+	if _, err := reloadCaddy(m.config); err != nil {
+		m.err = fmt.Errorf("failed to reload Caddy: %w", err)
 		return m, nil
 	}
 
 	// Reload snippets
-	caddyContent, err := os.ReadFile(m.config.Caddy.CaddyfilePath)
-	if err == nil {
-		parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
+	if parsed, err := caddy.LoadCaddyfile(m.config.Caddy.CaddyfilePath); err == nil {
 		m.snippets = parsed.Snippets
 	}
 
@@ -328,39 +275,6 @@ func (m Model) deleteSnippet() (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Read current Caddyfile
-	content, err := os.ReadFile(m.config.Caddy.CaddyfilePath)
-	if err != nil {
-		m.err = fmt.Errorf("failed to read Caddyfile: %w", err)
-		return m, nil
-	}
-
-	// Remove the snippet from the Caddyfile
-	lines := strings.Split(string(content), "\n")
-
-	// Build new Caddyfile content without this snippet
-	var newCaddyfile strings.Builder
-	skipUntilLine := -1
-
-	for i := 0; i < len(lines); i++ {
-		lineNum := i + 1 // Convert to 1-indexed
-
-		// If we're at the snippet start, mark to skip until end
-		if lineNum == snippet.LineStart {
-			skipUntilLine = snippet.LineEnd
-			continue
-		}
-
-		// Skip lines that are part of the snippet
-		if skipUntilLine >= 0 && lineNum <= skipUntilLine {
-			continue
-		}
-
-		// Write non-snippet lines
-		newCaddyfile.WriteString(lines[i])
-		newCaddyfile.WriteString("\n")
-	}
-
 	// Backup current Caddyfile
 	backupPath, err := caddy.BackupCaddyfile(m.config.Caddy.CaddyfilePath)
 	if err != nil {
@@ -368,34 +282,19 @@ func (m Model) deleteSnippet() (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Get permissions from backup to preserve them
-	backupInfo, err := os.Stat(backupPath)
-	if err != nil {
-		m.err = fmt.Errorf("failed to stat backup: %w", err)
-		return m, nil
-	}
-	backupPerms := backupInfo.Mode().Perm()
-
-	// Write new content with original permissions
-	if err := os.WriteFile(m.config.Caddy.CaddyfilePath, []byte(newCaddyfile.String()), backupPerms); err != nil {
+	// Remove the snippet from the Caddyfile
+	if err := caddy.RemoveSnippet(m.config.Caddy.CaddyfilePath, snippet.Name); err != nil {
 		// Attempt to restore backup
 		if restoreErr := caddy.RestoreFromBackup(m.config.Caddy.CaddyfilePath, backupPath); restoreErr != nil {
 			m.err = fmt.Errorf("CRITICAL: write failed AND backup restore failed: %w (original error: %v)", restoreErr, err)
 		} else {
-			m.err = fmt.Errorf("failed to write Caddyfile (backup restored): %w", err)
+			m.err = fmt.Errorf("failed to remove snippet (backup restored): %w", err)
 		}
 		return m, nil
 	}
 
 	// Validate Caddyfile
-	if err := caddy.FormatAndValidateCaddyfile(
-		m.config.Caddy.CaddyfilePath,
-		m.config.Caddy.CaddyfileContainerPath,
-		m.config.Caddy.ContainerName,
-		m.config.Caddy.DockerMethod,
-		m.config.Caddy.ComposeFilePath,
-		m.config.Caddy.ValidationCommand,
-	); err != nil {
+	if err := formatAndValidateCaddyfile(m.config); err != nil {
 		// Attempt to restore backup
 		if restoreErr := caddy.RestoreFromBackup(m.config.Caddy.CaddyfilePath, backupPath); restoreErr != nil {
 			m.err = fmt.Errorf("CRITICAL: validation failed AND backup restore failed: %w (original error: %v)", restoreErr, err)
@@ -406,15 +305,13 @@ func (m Model) deleteSnippet() (Model, tea.Cmd) {
 	}
 
 	// Reload Caddy
-	if err := caddy.RestartCaddy(m.config.Caddy.ContainerName); err != nil {
-		m.err = fmt.Errorf("failed to restart Caddy: %w", err)
+	if _, err := reloadCaddy(m.config); err != nil {
+		m.err = fmt.Errorf("failed to reload Caddy: %w", err)
 		return m, nil
 	}
 
 	// Reload snippets
-	caddyContent, err := os.ReadFile(m.config.Caddy.CaddyfilePath)
-	if err == nil {
-		parsed := caddy.ParseCaddyfileWithSnippets(string(caddyContent))
+	if parsed, err := caddy.LoadCaddyfile(m.config.Caddy.CaddyfilePath); err == nil {
 		m.snippets = parsed.Snippets
 	}
 
@@ -444,22 +341,7 @@ func (m Model) deleteSnippet() (Model, tea.Cmd) {
 
 // getTemplateParamsCursorMax returns the max cursor value for the current template being configured
 func (m Model) getTemplateParamsCursorMax() int {
-	// Find the first selected template to configure (matches render logic)
-	templateKeys := []string{
-		"cors_headers", "rate_limiting", "large_uploads", "extended_timeouts",
-		"static_caching", "compression_advanced", "https_backend", "auth_headers",
-		"websocket_advanced", "custom_headers_inject", "rewrite_rules", "frame_embedding",
-		"ip_restricted", "security_headers", "performance",
-	}
-
-	var currentTemplate string
-	for _, key := range templateKeys {
-		if m.snippetWizardData.SelectedTemplates[key] {
-			currentTemplate = key
-			break
-		}
-	}
-
+	currentTemplate := m.currentSnippetTemplate()
 	if currentTemplate == "" {
 		return 0
 	}
@@ -496,6 +378,10 @@ func (m Model) getTemplateParamsCursorMax() int {
 		return 1 // 2 fields: path pattern, rewrite to
 	case "frame_embedding":
 		return 0 // 1 field: allowed origins
+	case "forward_auth":
+		return 4 // 5 fields: provider, endpoint, uri, copy headers, snippet name
+	case "basic_auth":
+		return 2 // 3 fields: username, password, snippet name
 	default:
 		return 0
 	}
@@ -503,22 +389,7 @@ func (m Model) getTemplateParamsCursorMax() int {
 
 // toggleSnippetTemplateParamCheckbox toggles checkbox parameters in the current template
 func (m *Model) toggleSnippetTemplateParamCheckbox() {
-	// Find the first selected template to configure
-	templateKeys := []string{
-		"cors_headers", "rate_limiting", "large_uploads", "extended_timeouts",
-		"static_caching", "compression_advanced", "https_backend", "auth_headers",
-		"websocket_advanced", "custom_headers_inject", "rewrite_rules", "frame_embedding",
-		"ip_restricted", "security_headers", "performance",
-	}
-
-	var currentTemplate string
-	for _, key := range templateKeys {
-		if m.snippetWizardData.SelectedTemplates[key] {
-			currentTemplate = key
-			break
-		}
-	}
-
+	currentTemplate := m.currentSnippetTemplate()
 	if currentTemplate == "" {
 		return
 	}
@@ -615,7 +486,109 @@ func (m *Model) toggleSnippetTemplateParamCheckbox() {
 				config.Parameters["forward_proto"] = "true"
 			}
 		}
+	case "forward_auth":
+		if m.wizardCursor == 0 { // provider
+			provider, _ := config.Parameters["provider"].(string)
+			config.Parameters["provider"] = snippet_wizard.NextForwardAuthProvider(provider)
+		}
 	}
 
 	m.snippetWizardData.SnippetConfigs[currentTemplate] = config
 }
+
+// snippetTemplateParamText returns the text of a parameter of the template being configured
+func (m Model) snippetTemplateParamText(param string) string {
+	if value, ok := m.snippetWizardData.SnippetConfigs[m.currentSnippetTemplate()].Parameters[param]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// setSnippetTemplateParamText sets the text field at the cursor of the
+// template being configured, validating it like the wizard does
+func (m *Model) setSnippetTemplateParamText(text string) {
+	if m.snippetWizardData.SnippetConfigs == nil {
+		m.snippetWizardData.SnippetConfigs = make(map[string]snippet_wizard.SnippetConfig)
+	}
+	wizard := snippet_wizard.Wizard{
+		State: &snippet_wizard.WizardState{
+			CurrentStep:          snippet_wizard.StepTemplateParams,
+			Data:                 &m.snippetWizardData,
+			Cursor:               m.wizardCursor,
+			TemplatesToConfigure: []string{m.currentSnippetTemplate()},
+		},
+	}
+	wizard.SetText(text)
+}
+
+// snippetPasswordHashedMsg carries the hash of a basic_auth password
+type snippetPasswordHashedMsg struct {
+	templateKey string
+	password    string
+	hash        string
+	err         error
+}
+
+// hashSnippetPasswordCmd hashes a basic_auth password off the update loop,
+// since bcrypt takes about a second at Caddy's cost
+func hashSnippetPasswordCmd(templateKey, password string) tea.Cmd {
+	return func() tea.Msg {
+		hash, err := snippet_wizard.HashPassword(password)
+		return snippetPasswordHashedMsg{templateKey: templateKey, password: password, hash: hash, err: err}
+	}
+}
+
+// commitSnippetTemplateParams checks the parameters of the template being
+// configured, then moves on to the next selected template or the summary.
+// A typed password is hashed first; the template is committed once the
+// hash arrives.
+func (m Model) commitSnippetTemplateParams() (Model, tea.Cmd) {
+	current := m.currentSnippetTemplate()
+	config := m.snippetWizardData.SnippetConfigs[current]
+	if err := snippet_wizard.CheckTemplateParams(current, config); err != nil {
+		m.err = err
+		return m, nil
+	}
+	if snippet_wizard.IsNamedTemplate(current) {
+		name := snippet_wizard.TemplateSnippetName(current, config)
+		for _, snippet := range m.snippets {
+			if snippet.Name == name {
+				m.err = fmt.Errorf("a snippet named %s already exists - enter another snippet name", name)
+				return m, nil
+			}
+		}
+	}
+	if password := snippet_wizard.PendingPassword(config); password != "" {
+		m.err = nil
+		m.snippetHashing = true
+		return m, hashSnippetPasswordCmd(current, password)
+	}
+
+	m.err = nil
+	if m.snippetWizardData.TemplateIndex+1 < len(m.selectedSnippetTemplates()) {
+		m.snippetWizardData.TemplateIndex++
+	} else {
+		m.snippetWizardStep = snippet_wizard.StepSummary
+	}
+	m.wizardCursor = 0
+	return m, nil
+}
+
+// handleSnippetPasswordHashed keeps the hash of a committed password and
+// finishes committing its template, unless the wizard moved on or the
+// password was edited meanwhile
+func (m Model) handleSnippetPasswordHashed(msg snippetPasswordHashedMsg) (Model, tea.Cmd) {
+	m.snippetHashing = false
+	if m.currentView != ViewSnippetWizard || m.snippetWizardStep != snippet_wizard.StepTemplateParams ||
+		m.currentSnippetTemplate() != msg.templateKey {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if !snippet_wizard.SetPasswordHash(m.snippetWizardData.SnippetConfigs[msg.templateKey], msg.password, msg.hash) {
+		return m, nil
+	}
+	return m.commitSnippetTemplateParams()
+}
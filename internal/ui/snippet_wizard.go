@@ -1,8 +1,6 @@
 package ui
 
 import (
-	"fmt"
-
 	snippet_wizard "lazyproxyflare/internal/ui/snippet_wizard"
 	snippet_wizard_views "lazyproxyflare/internal/ui/snippet_wizard/views"
 )
@@ -106,35 +104,47 @@ func (m Model) renderSnippetWizardCustom() string {
 	)
 }
 
-// renderSnippetWizardTemplateParams renders the template parameter configuration screen
-func (m Model) renderSnippetWizardTemplateParams() string {
-	// Find the first selected template to configure
-	// Template order matches the order used in Enter key handling (app.go:2706-2708)
-	templateKeys := []string{
-		"cors_headers", "rate_limiting", "large_uploads", "extended_timeouts",
-		"static_caching", "compression_advanced", "https_backend", "auth_headers",
-		"websocket_advanced", "custom_headers_inject", "rewrite_rules", "frame_embedding",
-		"ip_restricted", "security_headers", "performance",
-	}
+// snippetTemplateConfigOrder is the order the selected templates are configured in
+var snippetTemplateConfigOrder = []string{
+	"cors_headers", "rate_limiting", "large_uploads", "extended_timeouts",
+	"static_caching", "compression_advanced", "https_backend", "auth_headers",
+	"forward_auth", "basic_auth",
+	"websocket_advanced", "custom_headers_inject", "rewrite_rules", "frame_embedding",
+	"ip_restricted", "security_headers", "performance",
+}
 
-	var currentTemplate string
-	for _, key := range templateKeys {
+// selectedSnippetTemplates returns the selected templates in configuration order
+func (m Model) selectedSnippetTemplates() []string {
+	var selected []string
+	for _, key := range snippetTemplateConfigOrder {
 		if m.snippetWizardData.SelectedTemplates[key] {
-			currentTemplate = key
-			break
+			selected = append(selected, key)
 		}
 	}
+	return selected
+}
+
+// currentSnippetTemplate returns the selected template the params step is
+// configuring, "" if none
+func (m Model) currentSnippetTemplate() string {
+	selected := m.selectedSnippetTemplates()
+	if m.snippetWizardData.TemplateIndex < len(selected) {
+		return selected[m.snippetWizardData.TemplateIndex]
+	}
+	return ""
+}
 
+// renderSnippetWizardTemplateParams renders the template parameter configuration screen
+func (m Model) renderSnippetWizardTemplateParams() string {
+	currentTemplate := m.currentSnippetTemplate()
 	if currentTemplate == "" {
 		return "No template selected"
 	}
 
 	// Get parameters for this template
-	params := make(map[string]string)
-	if config, exists := m.snippetWizardData.SnippetConfigs[currentTemplate]; exists {
-		for k, v := range config.Parameters {
-			params[k] = fmt.Sprint(v)
-		}
+	params := snippet_wizard.TemplateViewParams(currentTemplate, m.snippetWizardData.SnippetConfigs[currentTemplate])
+	if m.snippetHashing {
+		params["hashing"] = "true"
 	}
 
 	// Render using the views package
@@ -0,0 +1,128 @@
+package snippet_wizard
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ForwardAuthProvider holds the defaults of an authentication server
+// Caddy's forward_auth can ask before proxying a request
+type ForwardAuthProvider struct {
+	Key         string   // Parameter value (e.g. "authelia")
+	Name        string   // Display name
+	Endpoint    string   // Typical upstream in a Docker network
+	URI         string   // Path of the server's verification endpoint
+	CopyHeaders []string // Identity headers copied into the proxied request
+}
+
+// ForwardAuthProviders are the supported authentication servers, the first
+// being the default
+var ForwardAuthProviders = []ForwardAuthProvider{
+	{
+		Key:         "authelia",
+		Name:        "Authelia",
+		Endpoint:    "authelia:9091",
+		URI:         "/api/authz/forward-auth",
+		CopyHeaders: []string{"Remote-User", "Remote-Groups", "Remote-Email", "Remote-Name"},
+	},
+	{
+		Key:      "authentik",
+		Name:     "Authentik",
+		Endpoint: "authentik-server:9000",
+		URI:      "/outpost.goauthentik.io/auth/caddy",
+		CopyHeaders: []string{
+			"X-Authentik-Username", "X-Authentik-Groups", "X-Authentik-Email", "X-Authentik-Name",
+			"X-Authentik-Uid", "X-Authentik-Jwt", "X-Authentik-Meta-Jwks", "X-Authentik-Meta-Outpost",
+			"X-Authentik-Meta-Provider", "X-Authentik-Meta-App", "X-Authentik-Meta-Version",
+		},
+	},
+	{
+		Key:         "oauth2_proxy",
+		Name:        "oauth2-proxy",
+		Endpoint:    "oauth2-proxy:4180",
+		URI:         "/oauth2/auth",
+		CopyHeaders: []string{"X-Auth-Request-User", "X-Auth-Request-Email", "X-Auth-Request-Groups"},
+	},
+}
+
+// GetForwardAuthProvider returns the provider with the given key, or the
+// default one
+func GetForwardAuthProvider(key string) ForwardAuthProvider {
+	for _, provider := range ForwardAuthProviders {
+		if provider.Key == key {
+			return provider
+		}
+	}
+	return ForwardAuthProviders[0]
+}
+
+// NextForwardAuthProvider returns the key of the provider after key, wrapping around
+func NextForwardAuthProvider(key string) string {
+	key = GetForwardAuthProvider(key).Key
+	for i, provider := range ForwardAuthProviders {
+		if provider.Key == key {
+			return ForwardAuthProviders[(i+1)%len(ForwardAuthProviders)].Key
+		}
+	}
+	return key
+}
+
+// namedTemplates are the templates whose snippet the user names, so that
+// several credential sets or auth servers can sit side by side
+var namedTemplates = map[string]bool{"forward_auth": true, "basic_auth": true}
+
+// IsNamedTemplate reports whether the user names the template's snippet
+func IsNamedTemplate(templateKey string) bool {
+	return namedTemplates[templateKey]
+}
+
+// TemplateSnippetName returns the name of the snippet a template generates:
+// the name typed for a named template, else the template key
+func TemplateSnippetName(templateKey string, config SnippetConfig) string {
+	if namedTemplates[templateKey] {
+		return getStringParam(config.Parameters, "name", templateKey)
+	}
+	return templateKey
+}
+
+// parseHeaderList splits a header list separated by spaces or commas
+func parseHeaderList(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// passwordHashCost is the bcrypt cost of basic_auth passwords, the one
+// "caddy hash-password" uses
+const passwordHashCost = 14
+
+// HashPassword hashes a basic_auth password with bcrypt, as
+// "caddy hash-password" would, without needing the caddy binary
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password is empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// PendingPassword returns the basic_auth password typed but not hashed yet,
+// "" if there is none
+func PendingPassword(config SnippetConfig) string {
+	return getStringParam(config.Parameters, "password", "")
+}
+
+// SetPasswordHash replaces the typed password with its hash, keeping only
+// the hash. It reports false, changing nothing, if the password was edited
+// since it was hashed.
+func SetPasswordHash(config SnippetConfig, password, hash string) bool {
+	if password == "" || PendingPassword(config) != password {
+		return false
+	}
+	config.Parameters["password_hash"] = hash
+	delete(config.Parameters, "password")
+	return true
+}
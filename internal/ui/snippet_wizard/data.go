@@ -193,6 +193,7 @@ type SnippetWizardData struct {
 
 	// Templated mode fields
 	SelectedTemplates map[string]bool // Which templates user selected (key: template name)
+	TemplateIndex     int             // Which selected template the params step configures
 
 	// Custom mode fields
 	CustomSnippetName      string             // User-provided snippet name
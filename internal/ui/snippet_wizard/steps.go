@@ -255,6 +255,8 @@ func (ws *WizardState) buildTemplatesToConfigure() {
 		"https_backend":         true,
 		"performance":           true,
 		"auth_headers":          true,
+		"forward_auth":          true,
+		"basic_auth":            true,
 		"websocket_advanced":    true,
 		"custom_headers_inject": true,
 		"rewrite_rules":         true,
@@ -27,6 +27,16 @@ func GetAvailableTemplates() map[string]struct {
 			Description: "Forward authentication headers (X-Real-IP, X-Forwarded-*)",
 			Category:    "Security",
 		},
+		"forward_auth": {
+			Name:        "Forward Auth (SSO)",
+			Description: "Authelia, Authentik or oauth2-proxy login in front of a site",
+			Category:    "Security",
+		},
+		"basic_auth": {
+			Name:        "Basic Auth",
+			Description: "Username and password, hashed with bcrypt",
+			Category:    "Security",
+		},
 		"ip_restricted": {
 			Name:        "IP Restriction",
 			Description: "Limit access to LAN or specific IPs",
@@ -5,7 +5,8 @@ import (
 	"strings"
 )
 
-// GenerateIPRestrictionSnippet generates the IP restriction snippet content
+// GenerateIPRestrictionSnippet generates the IP restriction snippet content.
+// It matches client_ip, which sees through trusted proxies (Cloudflare).
 func GenerateIPRestrictionSnippet(lanSubnet, externalIP string) string {
 	var b strings.Builder
 
@@ -14,11 +15,11 @@ func GenerateIPRestrictionSnippet(lanSubnet, externalIP string) string {
 
 	// Build the "not" condition
 	if lanSubnet != "" && externalIP != "" {
-		b.WriteString(fmt.Sprintf("\t\tnot remote_ip %s %s\n", lanSubnet, externalIP))
+		b.WriteString(fmt.Sprintf("\t\tnot client_ip %s %s\n", lanSubnet, externalIP))
 	} else if lanSubnet != "" {
-		b.WriteString(fmt.Sprintf("\t\tnot remote_ip %s\n", lanSubnet))
+		b.WriteString(fmt.Sprintf("\t\tnot client_ip %s\n", lanSubnet))
 	} else {
-		b.WriteString("\t\tnot remote_ip 10.0.0.0/8\n") // fallback
+		b.WriteString("\t\tnot client_ip 10.0.0.0/8\n") // fallback
 	}
 
 	b.WriteString("\t}\n")
@@ -327,3 +328,31 @@ func GenerateHTTPSBackendSnippet(skipVerify bool, keepalive int) string {
 	b.WriteString("}")
 	return b.String()
 }
+
+// GenerateForwardAuthSnippet generates a snippet asking an authentication
+// server (Authelia, Authentik, oauth2-proxy) whether to let each request
+// through, copying the identity headers it returns into the proxied request
+func GenerateForwardAuthSnippet(name, endpoint, uri string, copyHeaders []string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("(%s) {\n", name))
+	b.WriteString(fmt.Sprintf("\tforward_auth %s {\n", endpoint))
+	b.WriteString(fmt.Sprintf("\t\turi %s\n", uri))
+	if len(copyHeaders) > 0 {
+		b.WriteString(fmt.Sprintf("\t\tcopy_headers %s\n", strings.Join(copyHeaders, " ")))
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("}")
+	return b.String()
+}
+
+// GenerateBasicAuthSnippet generates an HTTP basic authentication snippet for
+// one user. passwordHash is a bcrypt hash (see HashPassword).
+func GenerateBasicAuthSnippet(name, username, passwordHash string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("(%s) {\n", name))
+	b.WriteString("\tbasic_auth {\n")
+	b.WriteString(fmt.Sprintf("\t\t%s %s\n", username, passwordHash))
+	b.WriteString("\t}\n")
+	b.WriteString("}")
+	return b.String()
+}
@@ -3,6 +3,8 @@ package snippet_wizard
 import (
 	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestGenerateIPRestrictionSnippet(t *testing.T) {
@@ -20,7 +22,7 @@ func TestGenerateIPRestrictionSnippet(t *testing.T) {
 			externalIP: "127.0.0.1/32",
 			wantLAN:    true,
 			wantExt:    true,
-			wantString: "not remote_ip 10.0.0.0/8 127.0.0.1/32",
+			wantString: "not client_ip 10.0.0.0/8 127.0.0.1/32",
 		},
 		{
 			name:       "Only LAN subnet provided",
@@ -28,7 +30,7 @@ func TestGenerateIPRestrictionSnippet(t *testing.T) {
 			externalIP: "",
 			wantLAN:    true,
 			wantExt:    false,
-			wantString: "not remote_ip 192.168.1.0/24",
+			wantString: "not client_ip 192.168.1.0/24",
 		},
 		{
 			name:       "Only external IP provided (uses LAN in fallback)",
@@ -36,7 +38,7 @@ func TestGenerateIPRestrictionSnippet(t *testing.T) {
 			externalIP: "203.0.113.5/32",
 			wantLAN:    false,
 			wantExt:    false,
-			wantString: "not remote_ip 10.0.0.0/8", // fallback to default
+			wantString: "not client_ip 10.0.0.0/8", // fallback to default
 		},
 		{
 			name:       "Neither provided (uses fallback)",
@@ -44,7 +46,7 @@ func TestGenerateIPRestrictionSnippet(t *testing.T) {
 			externalIP: "",
 			wantLAN:    false,
 			wantExt:    false,
-			wantString: "not remote_ip 10.0.0.0/8",
+			wantString: "not client_ip 10.0.0.0/8",
 		},
 	}
 
@@ -88,7 +90,7 @@ func TestGenerateIPRestrictionSnippet(t *testing.T) {
 func TestGenerateIPRestrictionSnippet_UpdateBehavior(t *testing.T) {
 	// Simulate user typing in LAN field first
 	snippet1 := GenerateIPRestrictionSnippet("10.0.0.0/8", "")
-	if !strings.Contains(snippet1, "not remote_ip 10.0.0.0/8") {
+	if !strings.Contains(snippet1, "not client_ip 10.0.0.0/8") {
 		t.Errorf("Step 1: Expected LAN only, got:\n%s", snippet1)
 	}
 	if strings.Contains(snippet1, "127.0.0.1") {
@@ -97,7 +99,7 @@ func TestGenerateIPRestrictionSnippet_UpdateBehavior(t *testing.T) {
 
 	// Simulate user typing in external IP field (should update)
 	snippet2 := GenerateIPRestrictionSnippet("10.0.0.0/8", "127.0.0.1/32")
-	if !strings.Contains(snippet2, "not remote_ip 10.0.0.0/8 127.0.0.1/32") {
+	if !strings.Contains(snippet2, "not client_ip 10.0.0.0/8 127.0.0.1/32") {
 		t.Errorf("Step 2: Expected both IPs, got:\n%s", snippet2)
 	}
 
@@ -109,3 +111,118 @@ func TestGenerateIPRestrictionSnippet_UpdateBehavior(t *testing.T) {
 		t.Errorf("Step 2: External IP not added, got:\n%s", snippet2)
 	}
 }
+
+func TestGenerateForwardAuthSnippet(t *testing.T) {
+	w := &Wizard{State: &WizardState{Data: &SnippetWizardData{
+		Mode:              ModeTemplated,
+		SelectedTemplates: map[string]bool{"forward_auth": true},
+		SnippetConfigs:    map[string]SnippetConfig{"forward_auth": {Parameters: map[string]interface{}{}}},
+	}}}
+	want := "(forward_auth) {\n\tforward_auth authelia:9091 {\n\t\turi /api/authz/forward-auth\n" +
+		"\t\tcopy_headers Remote-User Remote-Groups Remote-Email Remote-Name\n\t}\n}"
+	if got := w.GenerateSnippets()[0].Content; got != want {
+		t.Errorf("unexpected default snippet:\n%s", got)
+	}
+
+	// Switching the provider changes the defaults; typed fields override them
+	w.State.CurrentStep, w.State.TemplatesToConfigure = StepTemplateParams, []string{"forward_auth"}
+	w.ToggleCheckbox()
+	w.State.Cursor = 1
+	w.SetText("https://auth.lan:9443")
+	content := w.GenerateSnippets()[0].Content
+	if !strings.Contains(content, "forward_auth https://auth.lan:9443 {\n\t\turi /outpost.goauthentik.io/auth/caddy\n") ||
+		!strings.Contains(content, "copy_headers X-Authentik-Username X-Authentik-Groups") {
+		t.Errorf("unexpected authentik snippet:\n%s", content)
+	}
+	w.State.Cursor = 3
+	w.SetText("X-User, X-Email")
+	if content := w.GenerateSnippets()[0].Content; !strings.Contains(content, "\t\tcopy_headers X-User X-Email\n") {
+		t.Errorf("copy_headers not overridden:\n%s", content)
+	}
+	w.SetText("X-User X_Bad!")
+	if err := CheckTemplateParams("forward_auth", w.State.Data.SnippetConfigs["forward_auth"]); err == nil {
+		t.Error("expected an invalid header name to be reported")
+	}
+}
+
+func TestBasicAuthSnippetHashesPassword(t *testing.T) {
+	w := &Wizard{State: &WizardState{
+		CurrentStep:          StepTemplateParams,
+		TemplatesToConfigure: []string{"basic_auth"},
+		Data: &SnippetWizardData{
+			Mode:              ModeTemplated,
+			SelectedTemplates: map[string]bool{"basic_auth": true},
+			SnippetConfigs:    map[string]SnippetConfig{},
+		},
+	}}
+	if err := CheckTemplateParams("basic_auth", w.State.Data.SnippetConfigs["basic_auth"]); err == nil {
+		t.Error("expected missing credentials to be reported")
+	}
+	w.SetText("alice")
+	w.State.Cursor = 1
+	w.SetText("correct horse")
+	config := w.State.Data.SnippetConfigs["basic_auth"]
+	if err := CheckTemplateParams("basic_auth", config); err != nil {
+		t.Fatal(err)
+	}
+	if view := TemplateViewParams("basic_auth", config); view["password"] != strings.Repeat("•", 13) {
+		t.Errorf("password not masked for the view: %q", view["password"])
+	}
+
+	// Generating never hashes: nothing is generated until the hash is set
+	if snippets := w.GenerateSnippets(); len(snippets) != 0 {
+		t.Fatalf("expected no snippet before hashing, got %+v", snippets)
+	}
+	password := PendingPassword(config)
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SetPasswordHash(config, "stale", hash) {
+		t.Error("expected a hash of an edited password to be ignored")
+	}
+	if !SetPasswordHash(config, password, hash) || PendingPassword(config) != "" {
+		t.Fatalf("expected only the hash to be kept: %v", config.Parameters)
+	}
+
+	content := w.GenerateSnippets()[0].Content
+	prefix := "(basic_auth) {\n\tbasic_auth {\n\t\talice "
+	if content != prefix+hash+"\n\t}\n}" {
+		t.Fatalf("unexpected snippet:\n%s", content)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("correct horse")); err != nil {
+		t.Errorf("hash doesn't match the password: %v", err)
+	}
+	if view := TemplateViewParams("basic_auth", config); view["password_set"] != "true" || view["password_hash"] != "" {
+		t.Errorf("unexpected view params: %v", view)
+	}
+
+	// A named snippet keeps the credentials apart from other sets
+	w.State.Cursor = 2
+	w.SetText("admin_auth")
+	if gen := w.GenerateSnippets()[0]; gen.Name != "admin_auth" || !strings.HasPrefix(gen.Content, "(admin_auth) {\n") {
+		t.Errorf("snippet name not used: %+v", gen)
+	}
+	w.SetText("admin auth")
+	if err := CheckTemplateParams("basic_auth", w.State.Data.SnippetConfigs["basic_auth"]); err == nil {
+		t.Error("expected an invalid snippet name to be reported")
+	}
+}
+
+func TestRenderSummaryHasNoSideEffects(t *testing.T) {
+	w := &Wizard{State: &WizardState{
+		CurrentStep: StepSummary,
+		Data: &SnippetWizardData{
+			Mode:              ModeTemplated,
+			SelectedTemplates: map[string]bool{"forward_auth": true},
+			SnippetConfigs:    map[string]SnippetConfig{"forward_auth": {Parameters: map[string]interface{}{"name": "sso"}}},
+		},
+	}}
+	w.Render()
+	if w.State.Data.GeneratedSnippets != nil {
+		t.Errorf("Render stored generated snippets: %+v", w.State.Data.GeneratedSnippets)
+	}
+	if gen := w.GenerateSnippets(); len(gen) != 1 || gen[0].Name != "sso" || !strings.HasPrefix(gen[0].Content, "(sso) {\n") {
+		t.Errorf("forward_auth snippet name not used: %+v", gen)
+	}
+}
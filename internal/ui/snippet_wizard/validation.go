@@ -1,8 +1,10 @@
 package snippet_wizard
 
 import (
+	"fmt"
 	"net"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -169,6 +171,96 @@ func ValidateMethods(methods string) ValidationResult {
 	return ValidationResult{Valid: true, Message: "Valid methods"}
 }
 
+// ValidateEndpoint validates a forward_auth upstream (host:port, optionally
+// with http:// or https://)
+func ValidateEndpoint(endpoint string) ValidationResult {
+	if endpoint == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	if host == "" || strings.ContainsAny(host, " /\t") {
+		return ValidationResult{Valid: false, Message: "Invalid endpoint (e.g., authelia:9091)"}
+	}
+	if _, port, found := strings.Cut(host, ":"); found {
+		if num, err := strconv.Atoi(port); err != nil || num < 1 || num > 65535 {
+			return ValidationResult{Valid: false, Message: "Invalid port in endpoint"}
+		}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid endpoint"}
+}
+
+// ValidateURIPath validates a request path (must start with /)
+func ValidateURIPath(uri string) ValidationResult {
+	if uri == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	if !strings.HasPrefix(uri, "/") || strings.ContainsAny(uri, " \t") {
+		return ValidationResult{Valid: false, Message: "Path must start with / (e.g., /api/authz/forward-auth)"}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid path"}
+}
+
+// ValidateHeaderNames validates header names separated by spaces or commas
+func ValidateHeaderNames(headers string) ValidationResult {
+	if headers == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	re := regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	for _, header := range parseHeaderList(headers) {
+		if !re.MatchString(header) {
+			return ValidationResult{Valid: false, Message: "Invalid header name: " + header}
+		}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid headers"}
+}
+
+// ValidateSnippetName validates the name of a snippet, as imported by sites
+func ValidateSnippetName(name string) ValidationResult {
+	if name == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	re := regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	if !re.MatchString(name) {
+		return ValidationResult{Valid: false, Message: "Snippet name can only contain letters, digits, _, . and -"}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid snippet name"}
+}
+
+// ValidateUsername validates a basic_auth user name
+func ValidateUsername(username string) ValidationResult {
+	if username == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	if strings.ContainsAny(username, " \t\"'{}") {
+		return ValidationResult{Valid: false, Message: "User name can't contain spaces, quotes or braces"}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid user name"}
+}
+
+// ValidatePassword validates a basic_auth password; bcrypt only uses the
+// first 72 bytes
+func ValidatePassword(password string) ValidationResult {
+	if password == "" {
+		return ValidationResult{Valid: true, Message: ""}
+	}
+
+	if len(password) > 72 {
+		return ValidationResult{Valid: false, Message: "Password must be at most 72 bytes"}
+	}
+
+	return ValidationResult{Valid: true, Message: "Valid password"}
+}
+
 // GetValidationForField returns the appropriate validation result for a specific field
 func GetValidationForField(templateKey, fieldName, value string) ValidationResult {
 	switch templateKey {
@@ -221,7 +313,52 @@ func GetValidationForField(templateKey, fieldName, value string) ValidationResul
 		if fieldName == "keepalive" {
 			return ValidatePositiveInt(value)
 		}
+
+	case "forward_auth":
+		switch fieldName {
+		case "endpoint":
+			return ValidateEndpoint(value)
+		case "uri":
+			return ValidateURIPath(value)
+		case "copy_headers":
+			return ValidateHeaderNames(value)
+		case "name":
+			return ValidateSnippetName(value)
+		}
+
+	case "basic_auth":
+		switch fieldName {
+		case "username":
+			return ValidateUsername(value)
+		case "password":
+			return ValidatePassword(value)
+		case "name":
+			return ValidateSnippetName(value)
+		}
 	}
 
 	return ValidationResult{Valid: true, Message: ""}
 }
+
+// CheckTemplateParams returns the first problem with a template's parameters:
+// a field that failed validation or a required one left empty
+func CheckTemplateParams(templateKey string, config SnippetConfig) error {
+	fields := make([]string, 0, len(config.ValidationErrors))
+	for field := range config.ValidationErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if len(fields) > 0 {
+		return fmt.Errorf("%s: %s", fields[0], config.ValidationErrors[fields[0]])
+	}
+
+	if templateKey == "basic_auth" {
+		if getStringParam(config.Parameters, "username", "") == "" {
+			return fmt.Errorf("basic_auth: a user name is required")
+		}
+		if getStringParam(config.Parameters, "password", "") == "" && getStringParam(config.Parameters, "password_hash", "") == "" {
+			return fmt.Errorf("basic_auth: a password is required")
+		}
+	}
+	return nil
+}
@@ -45,6 +45,10 @@ func RenderTemplateParams(templateName string, params map[string]string, cursor
 		return renderRewriteRulesParams(params, cursor)
 	case "frame_embedding":
 		return renderFrameEmbeddingParams(params, cursor)
+	case "forward_auth":
+		return renderForwardAuthParams(params, cursor)
+	case "basic_auth":
+		return renderBasicAuthParams(params, cursor)
 	default:
 		// Template doesn't need configuration
 		b.WriteString(StyleInfo.Render("This template uses default configuration."))
@@ -775,3 +779,132 @@ func renderFrameEmbeddingParams(params map[string]string, cursor int) string {
 	b.WriteString(RenderNavigationHint("Enter: continue"))
 	return b.String()
 }
+
+// renderForwardAuthParams expects the chosen provider's name and defaults in
+// provider_name, default_endpoint, default_uri and default_copy_headers
+func renderForwardAuthParams(params map[string]string, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render("Configure: Forward Auth"))
+	b.WriteString("\n\n")
+
+	// Field 0: Provider (cycled with space)
+	if cursor == 0 {
+		b.WriteString(StyleKeybinding.Render("→ Provider:"))
+	} else {
+		b.WriteString(StyleDim.Render("  Provider:"))
+	}
+	b.WriteString(" ")
+	b.WriteString(StyleKeybinding.Render("< " + params["provider_name"] + " >"))
+	b.WriteString("\n")
+	if cursor == 0 {
+		b.WriteString(StyleDim.Render("  Space: Authelia, Authentik or oauth2-proxy"))
+	}
+	b.WriteString("\n\n")
+
+	// Fields 1-4: text fields defaulting to the provider's values
+	fields := []struct {
+		label string
+		key   string
+		hint  string
+	}{
+		{"Endpoint:", "endpoint", "Upstream of the auth server (host:port)"},
+		{"Verify URI:", "uri", "Path the auth server answers forward auth requests on"},
+		{"Copy Headers:", "copy_headers", "Identity headers passed on to the site (space-separated)"},
+		{"Snippet Name:", "name", "Name sites import; name each auth server differently"},
+	}
+	for i, field := range fields {
+		if cursor == i+1 {
+			b.WriteString(StyleKeybinding.Render("→ " + field.label))
+		} else {
+			b.WriteString(StyleDim.Render("  " + field.label))
+		}
+		b.WriteString(" ")
+		if value := params[field.key]; value != "" {
+			b.WriteString(value)
+		} else {
+			b.WriteString(StyleDim.Render(fmt.Sprintf("(default: %s)", params["default_"+field.key])))
+		}
+		b.WriteString("\n")
+		if cursor == i+1 {
+			b.WriteString(StyleDim.Render("  " + field.hint))
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(StyleInfo.Render("Import this snippet in each site to put behind the login"))
+	b.WriteString("\n\n")
+
+	b.WriteString(RenderNavigationHint("Tab: next field", "Space: change provider", "Enter: continue"))
+	return b.String()
+}
+
+// renderBasicAuthParams expects the password masked; password_set marks a
+// password already hashed and hashing one being hashed
+func renderBasicAuthParams(params map[string]string, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render("Configure: Basic Auth"))
+	b.WriteString("\n\n")
+
+	// Field 0: Username
+	if cursor == 0 {
+		b.WriteString(StyleKeybinding.Render("→ Username:"))
+	} else {
+		b.WriteString(StyleDim.Render("  Username:"))
+	}
+	b.WriteString(" ")
+	if username := params["username"]; username != "" {
+		b.WriteString(username)
+	} else {
+		b.WriteString(StyleDim.Render("(required)"))
+	}
+	b.WriteString("\n\n")
+
+	// Field 1: Password (masked)
+	if cursor == 1 {
+		b.WriteString(StyleKeybinding.Render("→ Password:"))
+	} else {
+		b.WriteString(StyleDim.Render("  Password:"))
+	}
+	b.WriteString(" ")
+	switch {
+	case params["hashing"] == "true":
+		b.WriteString(StyleInfo.Render("⟳ Hashing..."))
+	case params["password"] != "":
+		b.WriteString(params["password"])
+	case params["password_set"] == "true":
+		b.WriteString(StyleDim.Render("(hashed - type to replace)"))
+	default:
+		b.WriteString(StyleDim.Render("(required)"))
+	}
+	b.WriteString("\n")
+	if cursor == 1 {
+		b.WriteString(StyleDim.Render("  Hashed with bcrypt; only the hash is written to the Caddyfile"))
+	}
+	b.WriteString("\n\n")
+
+	// Field 2: Snippet name
+	if cursor == 2 {
+		b.WriteString(StyleKeybinding.Render("→ Snippet Name:"))
+	} else {
+		b.WriteString(StyleDim.Render("  Snippet Name:"))
+	}
+	b.WriteString(" ")
+	if name := params["name"]; name != "" {
+		b.WriteString(name)
+	} else {
+		b.WriteString(StyleDim.Render(fmt.Sprintf("(default: %s)", params["default_name"])))
+	}
+	b.WriteString("\n")
+	if cursor == 2 {
+		b.WriteString(StyleDim.Render("  Name sites import; name each set of credentials differently"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(StyleInfo.Render("Import this snippet in each site sharing these credentials"))
+	b.WriteString("\n\n")
+
+	b.WriteString(RenderNavigationHint("Tab: next field", "Enter: continue"))
+	return b.String()
+}
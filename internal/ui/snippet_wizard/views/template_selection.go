@@ -39,6 +39,8 @@ func RenderTemplateSelection(templates map[string]TemplateInfo, cursor int, maxH
 				"cors_headers",
 				"rate_limiting",
 				"auth_headers",
+				"forward_auth",
+				"basic_auth",
 				"ip_restricted",
 				"security_headers",
 			},
@@ -170,13 +170,7 @@ func (w *Wizard) Render() string {
 		// Get current template being configured
 		if w.State.CurrentTemplateIndex < len(w.State.TemplatesToConfigure) {
 			templateKey := w.State.TemplatesToConfigure[w.State.CurrentTemplateIndex]
-			// Get parameters for this template
-			params := make(map[string]string)
-			if config, exists := w.State.Data.SnippetConfigs[templateKey]; exists {
-				for k, v := range config.Parameters {
-					params[k] = fmt.Sprint(v)
-				}
-			}
+			params := TemplateViewParams(templateKey, w.State.Data.SnippetConfigs[templateKey])
 			return views.RenderTemplateParams(templateKey, params, w.State.Cursor)
 		}
 		return "Error: invalid template index"
@@ -188,7 +182,7 @@ func (w *Wizard) Render() string {
 		)
 	case StepSummary:
 		// Generate snippets before showing summary
-		generatedSnippets := w.GenerateSnippets()
+		generatedSnippets := w.generateSnippets()
 
 		// Convert to views.GeneratedSnippet
 		viewSnippets := make([]views.GeneratedSnippet, len(generatedSnippets))
@@ -209,6 +203,34 @@ func (w *Wizard) Render() string {
 	}
 }
 
+// TemplateViewParams returns a template's parameters as RenderTemplateParams
+// shows them: as text, with the forward_auth provider's defaults, the
+// default snippet names and the basic_auth password masked
+func TemplateViewParams(templateKey string, config SnippetConfig) map[string]string {
+	params := make(map[string]string)
+	for k, v := range config.Parameters {
+		params[k] = fmt.Sprint(v)
+	}
+
+	switch templateKey {
+	case "forward_auth":
+		provider := GetForwardAuthProvider(params["provider"])
+		params["provider_name"] = provider.Name
+		params["default_endpoint"] = provider.Endpoint
+		params["default_uri"] = provider.URI
+		params["default_copy_headers"] = strings.Join(provider.CopyHeaders, " ")
+		params["default_name"] = templateKey
+	case "basic_auth":
+		params["default_name"] = templateKey
+		params["password"] = strings.Repeat("•", len([]rune(params["password"])))
+		if params["password_hash"] != "" {
+			params["password_set"] = "true"
+		}
+		delete(params, "password_hash")
+	}
+	return params
+}
+
 // Next advances to the next step
 func (w *Wizard) Next() {
 	// If we're on the welcome screen, save the selected mode
@@ -362,6 +384,10 @@ func (w *Wizard) toggleTemplateParamCheckbox() {
 				config.Parameters["forward_proto"] = "true"
 			}
 		}
+	case "forward_auth":
+		if w.State.Cursor == 0 {
+			config.Parameters["provider"] = NextForwardAuthProvider(getStringParam(config.Parameters, "provider", ""))
+		}
 	}
 
 	w.State.Data.SnippetConfigs[templateKey] = config
@@ -372,7 +398,7 @@ func (w *Wizard) toggleTemplateSelection() {
 	// Build ordered list of template keys (matches view order)
 	templateKeys := []string{
 		// Security
-		"cors_headers", "rate_limiting", "auth_headers", "ip_restricted", "security_headers",
+		"cors_headers", "rate_limiting", "auth_headers", "forward_auth", "basic_auth", "ip_restricted", "security_headers",
 		// Performance
 		"static_caching", "compression_advanced", "performance",
 		// Backend
@@ -410,6 +436,34 @@ func (w *Wizard) SetText(text string) {
 	}
 }
 
+// templateTextParams lists the parameter typed in at each cursor position of
+// a template's params step, "" where the field is a checkbox or an option
+var templateTextParams = map[string][]string{
+	"cors_headers":         {"allowed_origins", "allowed_methods", ""},
+	"rate_limiting":        {"requests_per_second", "burst_size"},
+	"large_uploads":        {"max_size"},
+	"extended_timeouts":    {"read_timeout", "write_timeout", "dial_timeout"},
+	"static_caching":       {"max_age", ""},
+	"ip_restricted":        {"lan_subnet", "external_ip"},
+	"compression_advanced": {"compression_level", "", "", ""},
+	"https_backend":        {"", "keepalive"},
+	"websocket_advanced":   {"upgrade_timeout", "ping_interval"},
+	"rewrite_rules":        {"path_pattern", "rewrite_to"},
+	"frame_embedding":      {"allowed_origins"},
+	"forward_auth":         {"", "endpoint", "uri", "copy_headers", "name"},
+	"basic_auth":           {"username", "password", "name"},
+}
+
+// TemplateTextParam returns the parameter SetText sets at cursor on the
+// template's params step, "" if the field there isn't typed in
+func TemplateTextParam(templateKey string, cursor int) string {
+	params := templateTextParams[templateKey]
+	if cursor < 0 || cursor >= len(params) {
+		return ""
+	}
+	return params[cursor]
+}
+
 // setTemplateParamText sets parameter text for the current template
 func (w *Wizard) setTemplateParamText(text string) {
 	if w.State.CurrentTemplateIndex >= len(w.State.TemplatesToConfigure) {
@@ -508,6 +562,35 @@ func (w *Wizard) setTemplateParamText(text string) {
 			fieldName = "allowed_origins"
 			config.Parameters["allowed_origins"] = text
 		}
+	case "forward_auth":
+		switch w.State.Cursor {
+		case 1:
+			fieldName = "endpoint"
+			config.Parameters["endpoint"] = text
+		case 2:
+			fieldName = "uri"
+			config.Parameters["uri"] = text
+		case 3:
+			fieldName = "copy_headers"
+			config.Parameters["copy_headers"] = text
+		case 4:
+			fieldName = "name"
+			config.Parameters["name"] = text
+		}
+	case "basic_auth":
+		switch w.State.Cursor {
+		case 0:
+			fieldName = "username"
+			config.Parameters["username"] = text
+		case 1:
+			// A new password replaces the hash of the previous one
+			fieldName = "password"
+			config.Parameters["password"] = text
+			delete(config.Parameters, "password_hash")
+		case 2:
+			fieldName = "name"
+			config.Parameters["name"] = text
+		}
 	}
 
 	// Validate the field
@@ -578,7 +661,7 @@ func (w *Wizard) MoveCursorDown() {
 	case StepWelcome:
 		maxCursor = 2 // 3 modes (0, 1, 2)
 	case StepTemplateSelection:
-		maxCursor = 14 // 15 templates (0-14)
+		maxCursor = 16 // 17 templates (0-16)
 	case StepTemplateParams:
 		maxCursor = w.getTemplateParamsCursorMax()
 	case StepIPRestriction:
@@ -632,13 +715,24 @@ func (w *Wizard) getTemplateParamsCursorMax() int {
 		return 1 // 2 fields: path pattern, rewrite to
 	case "frame_embedding":
 		return 0 // 1 field: allowed origins
+	case "forward_auth":
+		return 4 // 5 fields: provider, endpoint, uri, copy headers, snippet name
+	case "basic_auth":
+		return 2 // 3 fields: username, password, snippet name
 	default:
 		return 0
 	}
 }
 
-// GenerateSnippets generates all selected snippets
+// GenerateSnippets generates all selected snippets and keeps them in the wizard data
 func (w *Wizard) GenerateSnippets() []GeneratedSnippet {
+	snippets := w.generateSnippets()
+	w.State.Data.GeneratedSnippets = snippets
+	return snippets
+}
+
+// generateSnippets generates all selected snippets without changing the wizard
+func (w *Wizard) generateSnippets() []GeneratedSnippet {
 	var snippets []GeneratedSnippet
 
 	if w.State.Data.CreateIPRestriction {
@@ -683,7 +777,6 @@ func (w *Wizard) GenerateSnippets() []GeneratedSnippet {
 		snippets = append(snippets, w.generateTemplatedSnippets()...)
 	}
 
-	w.State.Data.GeneratedSnippets = snippets
 	return snippets
 }
 
@@ -790,12 +883,30 @@ func (w *Wizard) generateTemplatedSnippets() []GeneratedSnippet {
 			pathPattern := getStringParam(params, "path_pattern", "/api/*")
 			rewriteTo := getStringParam(params, "rewrite_to", "/new-api{uri}")
 			content = GenerateRewriteRulesSnippet(pathPattern, rewriteTo)
+		case "forward_auth":
+			provider := GetForwardAuthProvider(getStringParam(params, "provider", ""))
+			endpoint := getStringParam(params, "endpoint", provider.Endpoint)
+			uri := getStringParam(params, "uri", provider.URI)
+			copyHeaders := provider.CopyHeaders
+			if text := getStringParam(params, "copy_headers", ""); text != "" {
+				copyHeaders = parseHeaderList(text)
+			}
+			content = GenerateForwardAuthSnippet(TemplateSnippetName(templateKey, config), endpoint, uri, copyHeaders)
+		case "basic_auth":
+			// The password is hashed when its field is committed (see
+			// SetPasswordHash), since hashing is slow on purpose
+			username := getStringParam(params, "username", "")
+			hash := getStringParam(params, "password_hash", "")
+			if username == "" || hash == "" {
+				continue // CheckTemplateParams reports this before the summary
+			}
+			content = GenerateBasicAuthSnippet(TemplateSnippetName(templateKey, config), username, hash)
 		default:
 			continue
 		}
 
 		snippets = append(snippets, GeneratedSnippet{
-			Name:        templateKey,
+			Name:        TemplateSnippetName(templateKey, config),
 			Category:    category,
 			Content:     content,
 			Description: description,
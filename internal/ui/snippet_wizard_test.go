@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+
+	"lazyproxyflare/internal/caddy"
 	snippet_wizard "lazyproxyflare/internal/ui/snippet_wizard"
 )
 
@@ -78,3 +81,88 @@ func TestRenderSnippetWizardTemplateParams(t *testing.T) {
 		})
 	}
 }
+
+func TestSnippetWizardTemplateParamsTyping(t *testing.T) {
+	m := Model{
+		currentView:       ViewSnippetWizard,
+		snippetWizardStep: snippet_wizard.StepTemplateParams,
+		snippetWizardData: SnippetWizardData{
+			Mode:              snippet_wizard.ModeTemplated,
+			SelectedTemplates: map[string]bool{"basic_auth": true, "forward_auth": true},
+			SnippetConfigs:    map[string]snippet_wizard.SnippetConfig{},
+		},
+	}
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		m, _ = m.handleKeyMsg(msg)
+	}
+	typeText := func(text string) {
+		t.Helper()
+		for _, r := range text {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	// forward_auth comes first: space changes the provider, then the endpoint is typed
+	if got := m.currentSnippetTemplate(); got != "forward_auth" {
+		t.Fatalf("expected forward_auth first, got %q", got)
+	}
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	typeText("sso:90")
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	params := m.snippetWizardData.SnippetConfigs["forward_auth"].Parameters
+	if params["provider"] != "authentik" || params["endpoint"] != "sso:9" {
+		t.Errorf("unexpected forward_auth params: %v", params)
+	}
+
+	// Enter moves on to basic_auth, which needs credentials
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.currentSnippetTemplate(); got != "basic_auth" || m.wizardCursor != 0 {
+		t.Fatalf("expected basic_auth next, got %q", got)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.err == nil || m.snippetWizardStep != snippet_wizard.StepTemplateParams {
+		t.Fatal("expected missing credentials to keep the params step")
+	}
+	typeText("bob")
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	typeText("s3cret")
+	if view := m.renderSnippetWizardTemplateParams(); strings.Contains(view, "s3cret") || !strings.Contains(view, "••••••") {
+		t.Errorf("expected the password masked:\n%s", view)
+	}
+
+	// A basic_auth snippet already exists, so the new one needs another name
+	m.snippets = []caddy.Snippet{{Name: "basic_auth"}}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.err == nil || m.snippetHashing {
+		t.Fatal("expected the existing snippet name to be reported")
+	}
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	typeText("admin_auth")
+
+	// The password is hashed by a command; the step is committed once it's back
+	var cmd tea.Cmd
+	m, cmd = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.snippetHashing || m.snippetWizardStep != snippet_wizard.StepTemplateParams {
+		t.Fatalf("expected the password hashed in a command, hashing=%v step %v", m.snippetHashing, m.snippetWizardStep)
+	}
+	if view := m.renderSnippetWizardTemplateParams(); !strings.Contains(view, "Hashing") {
+		t.Errorf("expected hashing shown:\n%s", view)
+	}
+	m, _ = m.handleSnippetPasswordHashed(cmd().(snippetPasswordHashedMsg))
+	if m.err != nil || m.snippetHashing || m.snippetWizardStep != snippet_wizard.StepSummary {
+		t.Errorf("expected the summary, got step %v, err %v", m.snippetWizardStep, m.err)
+	}
+	params = m.snippetWizardData.SnippetConfigs["basic_auth"].Parameters
+	if _, ok := params["password"]; ok || params["password_hash"] == "" || params["name"] != "admin_auth" {
+		t.Errorf("expected only the hash kept: %v", params)
+	}
+
+	// Esc goes back through the templates
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := m.currentSnippetTemplate(); got != "forward_auth" || m.snippetWizardStep != snippet_wizard.StepTemplateParams {
+		t.Errorf("expected forward_auth params again, got %q at step %v", got, m.snippetWizardStep)
+	}
+}
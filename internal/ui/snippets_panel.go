@@ -190,8 +190,8 @@ func (m Model) renderAllSnippetsPanel(height int) string {
 		locationStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#444444")).
 			PaddingLeft(4)
-		b.WriteString(locationStyle.Render(fmt.Sprintf("Lines %d-%d (%d lines)",
-			snippet.LineStart, snippet.LineEnd, snippet.Lines())))
+		b.WriteString(locationStyle.Render(fmt.Sprintf("%s (%d lines)",
+			caddyLocation(m.config, snippet.File, snippet.LineStart, snippet.LineEnd), snippet.Lines())))
 
 		// Add spacing between snippets (except last visible)
 		if i < end-1 {
@@ -339,8 +339,8 @@ func (m Model) renderSnippetDetailView() string {
 
 	// Location in Caddyfile
 	b.WriteString(StyleInfo.Render("Location: "))
-	locationText := fmt.Sprintf("Lines %d-%d (%d lines)",
-		snippet.LineStart, snippet.LineEnd, snippet.Lines())
+	locationText := fmt.Sprintf("%s (%d lines)",
+		caddyLocation(m.config, snippet.File, snippet.LineStart, snippet.LineEnd), snippet.Lines())
 	b.WriteString(StyleDim.Render(locationText))
 	b.WriteString("\n\n")
 
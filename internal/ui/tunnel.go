@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"lazyproxyflare/internal/config"
+	"lazyproxyflare/internal/journal"
+	"lazyproxyflare/internal/tunnel"
+)
+
+// LoadIngress reads the ingress rules of the profile's tunnel (nil without a tunnel)
+func LoadIngress(cfg *config.Config) ([]tunnel.IngressRule, error) {
+	if !cfg.Tunnel.Enabled() {
+		return nil, nil
+	}
+	f, err := tunnel.Load(cfg.Tunnel.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return f.Rules(), nil
+}
+
+// formViaTunnel reports whether a form's DNS record routes through the profile's tunnel
+func formViaTunnel(cfg *config.Config, form AddFormData) bool {
+	return cfg.Tunnel.Enabled() && form.DNSType == "CNAME" &&
+		strings.EqualFold(strings.TrimSuffix(form.DNSTarget, "."), cfg.Tunnel.CNAMETarget())
+}
+
+// ingressRuleFor returns the ingress rule for one domain of a form.
+// In caddy mode the rule points at Caddy with the hostname as SNI, so Caddy
+// serves the matching site; in direct mode it points at the upstream.
+func ingressRuleFor(cfg *config.Config, form AddFormData, fqdn string, port int) tunnel.IngressRule {
+	if cfg.Tunnel.Direct() {
+		scheme := "http"
+		if form.SSL {
+			scheme = "https"
+		}
+		return tunnel.IngressRule{
+			Hostname: fqdn,
+			Service:  scheme + "://" + net.JoinHostPort(form.ReverseProxyTarget, strconv.Itoa(port)),
+		}
+	}
+	rule := tunnel.IngressRule{Hostname: fqdn, Service: cfg.Tunnel.CaddyURL()}
+	if strings.HasPrefix(rule.Service, "https://") {
+		rule.OriginServerName = fqdn
+	}
+	return rule
+}
+
+// setIngressRules returns an edit that points every domain of a form at its service
+func setIngressRules(cfg *config.Config, form AddFormData, fqdns []string, port int) func(*tunnel.File) error {
+	return func(f *tunnel.File) error {
+		for _, fqdn := range fqdns {
+			if err := f.SetRule(ingressRuleFor(cfg, form, fqdn, port)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// applyIngress backs up and journals the cloudflared config, edits it and
+// restarts cloudflared. Callers run it as their last step: the restart can't
+// be undone, so everything else must have succeeded first.
+func applyIngress(cfg *config.Config, j *journal.Journal, edit func(*tunnel.File) error) (string, error) {
+	backupPath, err := tunnel.Backup(cfg.Tunnel.ConfigPath)
+	if err != nil {
+		return "", err
+	}
+	j.RecordFile(cfg.Tunnel.ConfigPath, backupPath)
+	if err := tunnel.Edit(cfg.Tunnel.ConfigPath, edit); err != nil {
+		return "", err
+	}
+	return tunnel.Restart(cfg.Tunnel.RestartCommand)
+}
+
+// directTunnelForm returns the form as it is applied to Caddy: in direct
+// tunnel mode cloudflared reaches the upstream itself, so no Caddy block is
+// written and the upstream fields only feed the ingress rule
+func directTunnelForm(cfg *config.Config, form AddFormData) AddFormData {
+	if cfg.Tunnel.Direct() && formViaTunnel(cfg, form) {
+		form.DNSOnly = true
+	}
+	return form
+}
+
+// appendOutput joins command outputs for the audit log
+func appendOutput(output, more string) string {
+	if more == "" {
+		return output
+	}
+	if output == "" {
+		return more
+	}
+	return fmt.Sprintf("%s\n%s", output, more)
+}
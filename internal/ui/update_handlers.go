@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -12,12 +15,27 @@ import (
 	"lazyproxyflare/internal/config"
 )
 
+// startRefresh starts reloading data, cancelling any refresh still in flight
+func (m Model) startRefresh() (Model, tea.Cmd) {
+	if m.cancelRefresh != nil {
+		m.cancelRefresh()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRefresh = cancel
+	return m, refreshDataCmd(ctx, m.config)
+}
+
 // handleAsyncMsg handles async operation result messages.
 // Returns (model, cmd, handled) where handled indicates if the message was processed.
 func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 	switch msg := msg.(type) {
 	case refreshCompleteMsg:
+		if errors.Is(msg.err, context.Canceled) {
+			// Cancelled from the keyboard; the model was already updated
+			return m, nil, true
+		}
 		m.loading = false
+		m.cancelRefresh = nil
 		if msg.err != nil {
 			m.err = msg.err
 		} else {
@@ -34,6 +52,32 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 		}
 		return m, nil, true
 
+	case recoverJournalsMsg:
+		// Record what crash recovery undid, then load the profile's data
+		if m.audit.Logger != nil {
+			for _, description := range msg.recovered {
+				m.audit.Logger.Log(audit.LogEntry{
+					Operation:  audit.OperationRecover,
+					EntityType: audit.EntityBoth,
+					Domain:     description,
+					Result:     audit.ResultSuccess,
+				})
+			}
+		}
+		if msg.err != nil {
+			m.err = fmt.Errorf("crash recovery: %w", msg.err)
+		}
+		m, cmd := m.startRefresh()
+		return m, cmd, true
+
+	case wizardTokenVerifiedMsg:
+		m2, cmd := m.handleWizardTokenVerified(msg)
+		return m2, cmd, true
+
+	case snippetPasswordHashedMsg:
+		m2, cmd := m.handleSnippetPasswordHashed(msg)
+		return m2, cmd, true
+
 	case exportProfileMsg:
 		if msg.success {
 			m.profile.ExportPath = msg.path
@@ -65,7 +109,8 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 		}
 		// Refresh data after editor closes
 		m.loading = true
-		return m, refreshDataCmd(m.config), true
+		m, cmd := m.startRefresh()
+		return m, cmd, true
 
 	case migrationCompleteMsg:
 		m2, cmd := m.handleMigrationComplete(msg)
@@ -76,7 +121,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 		// Audit log the operation
 		if m.audit.Logger != nil {
-			fqdn := m.addForm.Subdomain + "." + m.config.Domain
+			fqdn := strings.Join(ResolveFQDNs(ParseSubdomains(m.addForm.Subdomain), m.config), ", ")
 			details := map[string]interface{}{
 				"dns_type": m.addForm.DNSType,
 				"target":   m.addForm.DNSTarget,
@@ -84,8 +129,16 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				"dns_only": m.addForm.DNSOnly,
 			}
 			if !m.addForm.DNSOnly {
-				details["reverse_proxy"] = m.addForm.ReverseProxyTarget
-				details["port"] = m.addForm.ServicePort
+				switch m.addForm.EntryType {
+				case caddy.EntryRedirect:
+					details["redirect_to"] = m.addForm.RedirectTo
+					details["redirect_code"] = m.addForm.RedirectCode
+				case caddy.EntryStatic:
+					details["static_root"] = m.addForm.StaticRoot
+				default:
+					details["reverse_proxy"] = m.addForm.ReverseProxyTarget
+					details["port"] = m.addForm.ServicePort
+				}
 			}
 
 			result := audit.ResultSuccess
@@ -104,7 +157,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  audit.OperationCreate,
 				EntityType: entityType,
 				Domain:     fqdn,
-				Details:    details,
+				Details:    withReloadOutput(details, msg.reloadOutput),
 				Result:     result,
 				Error:      errorMsg,
 			})
@@ -115,7 +168,8 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			m.currentView = ViewList
 			m.editingEntry = nil
 			m.err = nil
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - show error message, stay in preview
 			m.err = fmt.Errorf("Failed at %s: %v", msg.errorStep, msg.err)
@@ -127,7 +181,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 
 		// Audit log the operation
 		if m.audit.Logger != nil && m.editingEntry != nil {
-			fqdn := m.addForm.Subdomain + "." + m.config.Domain
+			fqdn := strings.Join(ResolveFQDNs(ParseSubdomains(m.addForm.Subdomain), m.config), ", ")
 			details := map[string]interface{}{
 				"dns_type": m.addForm.DNSType,
 				"target":   m.addForm.DNSTarget,
@@ -135,8 +189,16 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				"dns_only": m.addForm.DNSOnly,
 			}
 			if !m.addForm.DNSOnly {
-				details["reverse_proxy"] = m.addForm.ReverseProxyTarget
-				details["port"] = m.addForm.ServicePort
+				switch m.addForm.EntryType {
+				case caddy.EntryRedirect:
+					details["redirect_to"] = m.addForm.RedirectTo
+					details["redirect_code"] = m.addForm.RedirectCode
+				case caddy.EntryStatic:
+					details["static_root"] = m.addForm.StaticRoot
+				default:
+					details["reverse_proxy"] = m.addForm.ReverseProxyTarget
+					details["port"] = m.addForm.ServicePort
+				}
 			}
 
 			result := audit.ResultSuccess
@@ -160,7 +222,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  audit.OperationUpdate,
 				EntityType: entityType,
 				Domain:     fqdn,
-				Details:    details,
+				Details:    withReloadOutput(details, msg.reloadOutput),
 				Result:     result,
 				Error:      errorMsg,
 			})
@@ -171,7 +233,8 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			m.currentView = ViewList
 			m.editingEntry = nil
 			m.err = nil
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - show error message, stay in preview
 			m.err = fmt.Errorf("Failed at %s: %v", msg.errorStep, msg.err)
@@ -204,6 +267,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  audit.OperationDelete,
 				EntityType: entityType,
 				Domain:     msg.domain,
+				Details:    withReloadOutput(nil, msg.reloadOutput),
 				Result:     result,
 				Error:      errorMsg,
 			})
@@ -213,7 +277,8 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			// Success - return to list view and refresh
 			m.currentView = ViewList
 			m.err = nil
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - show error message, stay in confirm delete
 			m.err = fmt.Errorf("Failed at %s: %v", msg.errorStep, msg.err)
@@ -248,7 +313,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  audit.OperationSync,
 				EntityType: entityType,
 				Domain:     msg.domain,
-				Details:    details,
+				Details:    withReloadOutput(details, msg.reloadOutput),
 				Result:     result,
 				Error:      errorMsg,
 			})
@@ -258,13 +323,43 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			// Success - return to list view and refresh
 			m.currentView = ViewList
 			m.err = nil
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - show error message, stay in confirm sync
 			m.err = fmt.Errorf("Failed at %s: %v", msg.errorStep, msg.err)
 			return m, nil, true
 		}
 
+	case claimRecordsMsg:
+		m.loading = false
+
+		if m.audit.Logger != nil && (len(msg.domains) > 0 || msg.err != nil) {
+			result := audit.ResultSuccess
+			errorMsg := ""
+			if !msg.success {
+				result = audit.ResultFailure
+				errorMsg = msg.err.Error()
+			}
+			m.audit.Logger.Log(audit.LogEntry{
+				Operation:  audit.OperationClaim,
+				EntityType: audit.EntityDNS,
+				Domain:     describeBatch(msg.domains),
+				BatchCount: len(msg.domains),
+				Result:     result,
+				Error:      errorMsg,
+			})
+		}
+
+		if !msg.success {
+			m.err = fmt.Errorf("Claim failed: %v", msg.err)
+			return m, nil, true
+		}
+		m.selectedEntries = make(map[string]bool)
+		m.err = nil
+		m, cmd := m.startRefresh()
+		return m, cmd, true
+
 	case bulkDeleteMsg:
 		m.loading = false
 
@@ -302,6 +397,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  operation,
 				EntityType: entityType,
 				Domain:     domain,
+				Details:    withReloadOutput(nil, msg.reloadOutput),
 				BatchCount: len(msg.deletedDomains),
 				Result:     result,
 				Error:      errorMsg,
@@ -315,7 +411,8 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			m.selectedEntries = make(map[string]bool) // Clear selections after batch operations
 			m.err = nil
 			// Show success message with count
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - show error message with count of entries deleted before failure
 			if msg.count > 0 {
@@ -362,7 +459,7 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 				Operation:  audit.OperationRestore,
 				EntityType: entityType,
 				Domain:     fmt.Sprintf("Backup: %s", backupFilename),
-				Details:    details,
+				Details:    withReloadOutput(details, msg.reloadOutput),
 				Result:     result,
 				Error:      errorMsg,
 			})
@@ -372,13 +469,25 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 			// Success - return to list view and refresh data
 			m.currentView = ViewList
 			m.err = nil
-			return m, refreshDataCmd(m.config), true
+			m, cmd := m.startRefresh()
+			return m, cmd, true
 		} else {
 			// Error - stay in confirm restore view with error
 			m.err = msg.err
 			return m, nil, true
 		}
 
+	case restorePlanMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil, true
+		}
+		m.backup.RestorePlan = msg.changes
+		m.backup.RestoreSnapshotAt = msg.takenAt
+		m.backup.RestorePlanned = true
+		return m, nil, true
+
 	case deleteBackupMsg:
 		m.loading = false
 		if msg.success {
@@ -414,3 +523,15 @@ func (m Model) handleAsyncMsg(msg tea.Msg) (Model, tea.Cmd, bool) {
 		return m, nil, false
 	}
 }
+
+// withReloadOutput adds the Caddy reload command output to audit log details
+func withReloadOutput(details map[string]interface{}, output string) map[string]interface{} {
+	if output == "" {
+		return details
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["reload_output"] = output
+	return details
+}
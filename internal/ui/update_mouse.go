@@ -4,6 +4,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/diff"
 )
 
 // handleMouseMsg handles all mouse input events
@@ -42,7 +43,7 @@ func (m Model) handleMouseMsg(msg tea.MouseMsg) (Model, tea.Cmd) {
 				// Calculate which entry was clicked
 				// Count actual header lines (dynamic based on filters/sorts)
 				headerLines := 0
-				if m.statusFilter != FilterAll || m.dnsTypeFilter != DNSTypeAll || m.searchQuery != "" {
+				if m.statusFilter != FilterAll || m.dnsTypeFilter != DNSTypeAll || m.zoneFilter != "" || m.searchQuery != "" {
 					headerLines++ // Filter line
 				}
 				if m.sortMode != SortAlphabetical {
@@ -62,11 +63,11 @@ func (m Model) handleMouseMsg(msg tea.MouseMsg) (Model, tea.Cmd) {
 						// Account for cursor indicator (2 chars: "→ " or "  ")
 						// Checkbox starts at position 2, spans 4 chars: "[ ] " or "[✓] "
 						if msg.X >= 2 && msg.X <= 7 {
-							// Toggle selection
+							// Toggle selection (ignored entries are kept out of batch operations)
 							domain := filtered[clickedIndex].Domain
 							if m.selectedEntries[domain] {
 								delete(m.selectedEntries, domain)
-							} else {
+							} else if filtered[clickedIndex].Status != diff.StatusIgnored {
 								m.selectedEntries[domain] = true
 							}
 						} else {
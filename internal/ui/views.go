@@ -16,7 +16,11 @@ func (m Model) renderPanelLayout() string {
 	layout := NewPanelLayout(m.width, m.height)
 
 	// Render title bar with tab indicators
-	titleBar := RenderTitleBarWithTabs(m.config.Domain, int(m.activeTab), m.width)
+	titleDomain := m.config.Domain
+	if m.config.IsMultiZone() {
+		titleDomain = fmt.Sprintf("%s +%d", m.config.Domain, len(m.config.AllZones())-1)
+	}
+	titleBar := RenderTitleBarWithTabs(titleDomain, int(m.activeTab), m.width)
 
 	// Render left panel (entry list)
 	leftContent := m.renderLeftPanel(layout.LeftWidth, layout.LeftHeight)
@@ -61,6 +65,17 @@ func (m Model) renderPanelLayout() string {
 // getLeftPanelTitle returns the title for the left panel
 func (m Model) getLeftPanelTitle() string {
 	filteredEntries := m.getFilteredEntries()
+
+	// Ignored entries are hidden; say how many so they are not forgotten
+	ignored := 0
+	for _, entry := range m.entries {
+		if entry.Status == diff.StatusIgnored {
+			ignored++
+		}
+	}
+	if ignored > 0 && m.statusFilter != FilterIgnored {
+		return fmt.Sprintf("Entries (%d, %d ignored)", len(filteredEntries), ignored)
+	}
 	return fmt.Sprintf("Entries (%d)", len(filteredEntries))
 }
 
@@ -160,6 +175,9 @@ func (m Model) renderLeftPanel(width, height int) string {
 	if m.dnsTypeFilter != DNSTypeAll {
 		filterParts = append(filterParts, fmt.Sprintf("Type: %s", m.dnsTypeFilter.String()))
 	}
+	if m.zoneFilter != "" {
+		filterParts = append(filterParts, fmt.Sprintf("Zone: %s", m.zoneFilter))
+	}
 	if m.searchQuery != "" {
 		filterParts = append(filterParts, fmt.Sprintf("Search: \"%s\"", m.searchQuery))
 	}
@@ -203,13 +221,9 @@ func (m Model) renderLeftPanel(width, height int) string {
 			checkbox = StyleDim.Render(checkbox)
 		}
 
-		// Icon based on status
-		var icon string
-		if entry.Status == diff.StatusSynced {
-			icon = StyleIconSynced.Render("✓")
-		} else {
-			icon = StyleIconOrphan.Render("⚠")
-		}
+		// Icons based on status and entry type
+		icon := statusIcon(entry.Status)
+		typeIcon := entryTypeIcon(entry)
 
 		// Domain name - show multi-domain format if applicable
 		var domain string
@@ -220,7 +234,7 @@ func (m Model) renderLeftPanel(width, height int) string {
 		}
 
 		// Truncate if needed
-		maxDomainLen := width - 10 // Account for checkbox, icon, padding
+		maxDomainLen := width - 12 // Account for checkbox, icons, padding
 		if maxDomainLen < 10 {
 			maxDomainLen = 10 // Minimum width
 		}
@@ -232,10 +246,10 @@ func (m Model) renderLeftPanel(width, height int) string {
 		var line string
 		if i == m.cursor {
 			// Add visual cursor indicator and highlight
-			line = StyleHighlight.Render(fmt.Sprintf("→ %s %s %s", checkbox, icon, domain))
+			line = StyleHighlight.Render(fmt.Sprintf("→ %s %s %s %s", checkbox, icon, typeIcon, domain))
 		} else {
 			// Normal line with spacing to align with cursor indicator
-			line = fmt.Sprintf("  %s %s %s", checkbox, icon, domain)
+			line = fmt.Sprintf("  %s %s %s %s", checkbox, icon, typeIcon, domain)
 		}
 
 		b.WriteString(line)
@@ -290,27 +304,69 @@ func (m Model) renderCloudflareDetails(entry diff.SyncedEntry) string {
 		statusLine = StyleWarning.Render("⚠ DNS only (no Caddy config)")
 	} else if entry.Status == diff.StatusOrphanedCaddy {
 		statusLine = StyleDim.Render("○ No DNS record")
+	} else if entry.Status == diff.StatusOrphanedIngress {
+		statusLine = StyleWarning.Render("⚠ Stale tunnel ingress rule (no DNS record or Caddy config)")
+	} else if entry.Status == diff.StatusIgnored {
+		statusLine = StyleDim.Render("· Ignored by the profile's ignore rules")
+	} else if entry.Status == diff.StatusUnmanagedDNS {
+		statusLine = StyleDim.Render("· DNS only, not managed by LazyProxyFlare (press 'C' to claim)")
+	} else if entry.Status.IsMismatch() {
+		statusLine = StyleIconDrift.Render("≠ " + entry.Status.String())
 	}
 	b.WriteString(statusLine)
 	b.WriteString("\n\n")
 
+	// Zone (multi-zone profiles only)
+	if m.config.IsMultiZone() {
+		zone := entry.Zone
+		if zone == "" {
+			zone = StyleWarning.Render("none (no configured zone matches)")
+		}
+		b.WriteString(fmt.Sprintf("  Zone:    %s\n\n", zone))
+	}
+
+	// Field-level drift explanations
+	if len(entry.Mismatches) > 0 {
+		b.WriteString(renderMismatches(entry.Mismatches))
+	}
+
 	// DNS Information
 	if entry.DNS != nil {
 		b.WriteString(StyleInfo.Render("DNS Record"))
 		b.WriteString("\n")
-		b.WriteString(fmt.Sprintf("  Type:    %s\n", StyleKeybinding.Render(entry.DNS.Type)))
+		b.WriteString(fmt.Sprintf("  Type:    %s\n", StyleKeybinding.Render(entry.DNSType())))
 		b.WriteString(fmt.Sprintf("  Target:  %s\n", entry.DNS.Content))
+		if entry.DNSAAAA != nil {
+			b.WriteString(fmt.Sprintf("  IPv6:    %s\n", entry.DNSAAAA.Content))
+		}
 
 		// Proxied status with color
-		proxiedStr := "No"
-		if entry.DNS.Proxied {
-			proxiedStr = StyleSuccess.Render("Yes (CF Proxy)")
+		if proxySupported(m.config) {
+			proxiedStr := "No"
+			if entry.DNS.Proxied {
+				proxiedStr = StyleSuccess.Render("Yes (CF Proxy)")
+			}
+			b.WriteString(fmt.Sprintf("  Proxied: %s\n", proxiedStr))
 		}
-		b.WriteString(fmt.Sprintf("  Proxied: %s\n", proxiedStr))
 
 		// TTL info
-		b.WriteString(fmt.Sprintf("  TTL:     %s\n", "Auto"))
+		b.WriteString(fmt.Sprintf("  TTL:     %s\n", diff.FormatTTL(entry.DNS.TTL)))
+
+		owner := StyleDim.Render("not claimed (press 'C' to claim)")
+		if entry.DNS.IsOwned() || (entry.DNSAAAA != nil && entry.DNSAAAA.IsOwned()) {
+			owner = StyleSuccess.Render("LazyProxyFlare")
+		}
+		b.WriteString(fmt.Sprintf("  Owner:   %s\n", owner))
+		b.WriteString("\n")
+	} else if entry.WildcardDNS != nil {
+		// Served by a wildcard record; it belongs to the wildcard's own row
+		b.WriteString(StyleInfo.Render("DNS Record (wildcard)"))
 		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  Name:    %s\n", StyleKeybinding.Render(entry.WildcardDNS.Name)))
+		b.WriteString(fmt.Sprintf("  Type:    %s\n", entry.WildcardDNS.Type))
+		b.WriteString(fmt.Sprintf("  Target:  %s\n", entry.WildcardDNS.Content))
+		b.WriteString(StyleDim.Render("  No record of its own - edits and deletes leave the wildcard alone"))
+		b.WriteString("\n\n")
 	} else {
 		b.WriteString(StyleDim.Render("No DNS record configured"))
 		b.WriteString("\n")
@@ -318,6 +374,17 @@ func (m Model) renderCloudflareDetails(entry diff.SyncedEntry) string {
 		b.WriteString("\n\n")
 	}
 
+	// Tunnel ingress rule
+	if entry.Ingress != nil {
+		b.WriteString(StyleInfo.Render("Tunnel Ingress"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  Service: %s\n", entry.Ingress.Service))
+		if entry.Ingress.OriginServerName != "" {
+			b.WriteString(fmt.Sprintf("  SNI:     %s\n", entry.Ingress.OriginServerName))
+		}
+		b.WriteString("\n")
+	}
+
 	// Quick summary of Caddy if it exists (for context)
 	if entry.Caddy != nil {
 		b.WriteString(StyleDim.Render("─────────────────────────────"))
@@ -327,7 +394,13 @@ func (m Model) renderCloudflareDetails(entry diff.SyncedEntry) string {
 		if entry.Caddy.SSL {
 			scheme = "https://"
 		}
-		b.WriteString(StyleDim.Render(fmt.Sprintf("%s%s:%d", scheme, entry.Caddy.Target, entry.Caddy.Port)))
+		summary := entry.Caddy.Destination()
+		if entry.Caddy.Type == caddy.EntryProxy {
+			summary = scheme + summary
+		} else {
+			summary = entry.Caddy.Type.String() + " " + summary
+		}
+		b.WriteString(StyleDim.Render(summary))
 		b.WriteString("\n")
 		b.WriteString(StyleDim.Render("(press Tab for Caddy details)"))
 	}
@@ -366,15 +439,51 @@ func (m Model) renderCaddyDetails(entry diff.SyncedEntry) string {
 		return b.String()
 	}
 
-	// Reverse proxy target
-	b.WriteString(StyleInfo.Render("Reverse Proxy"))
+	// Destination by entry type
+	b.WriteString(StyleInfo.Render(entry.Caddy.Type.Icon() + " " + entry.Caddy.Type.String()))
 	b.WriteString("\n")
-	scheme := "http://"
-	if entry.Caddy.SSL {
-		scheme = "https://"
+	switch entry.Caddy.Type {
+	case caddy.EntryRedirect:
+		b.WriteString(fmt.Sprintf("  To:     %s\n", StyleKeybinding.Render(entry.Caddy.RedirectTo)))
+		kind := "temporary"
+		if entry.Caddy.RedirectCode == 301 || entry.Caddy.RedirectCode == 308 {
+			kind = "permanent"
+		}
+		b.WriteString(fmt.Sprintf("  Code:   %d (%s)\n", entry.Caddy.RedirectCode, kind))
+		if entry.Caddy.RedirectPreservePath {
+			b.WriteString("  Path:   kept (request path and query appended)\n")
+		} else {
+			b.WriteString("  Path:   dropped (every request goes to the URL above)\n")
+		}
+	case caddy.EntryStatic:
+		b.WriteString(fmt.Sprintf("  Root:   %s\n", StyleKeybinding.Render(entry.Caddy.StaticRoot)))
+		if entry.Caddy.StaticBrowse {
+			b.WriteString("  Browse: directories without an index are listed\n")
+		}
+	default:
+		scheme := "http://"
+		if entry.Caddy.SSL {
+			scheme = "https://"
+		}
+		targetURL := fmt.Sprintf("%s%s:%d", scheme, entry.Caddy.Target, entry.Caddy.Port)
+		b.WriteString(fmt.Sprintf("  Target: %s\n", StyleKeybinding.Render(targetURL)))
+		for _, upstream := range entry.Caddy.Upstreams {
+			b.WriteString(fmt.Sprintf("          %s\n", StyleKeybinding.Render(scheme+upstream)))
+		}
+		for _, line := range entry.Caddy.LoadBalancing.Directives() {
+			b.WriteString(StyleDim.Render("  "+line) + "\n")
+		}
+	}
+	if entry.Caddy.IsHostRoute() {
+		b.WriteString(fmt.Sprintf("  Route:  %s in %s\n", entry.Caddy.Matcher, entry.Caddy.ParentSite))
+	}
+	for _, route := range entry.Caddy.Routes {
+		strip := ""
+		if route.StripPrefix {
+			strip = StyleDim.Render(" (prefix stripped)")
+		}
+		b.WriteString(fmt.Sprintf("  Path:   %s → %s%s\n", route.Path, StyleKeybinding.Render(route.Upstream()), strip))
 	}
-	targetURL := fmt.Sprintf("%s%s:%d", scheme, entry.Caddy.Target, entry.Caddy.Port)
-	b.WriteString(fmt.Sprintf("  Target: %s\n", StyleKeybinding.Render(targetURL)))
 
 	// Target validity check (basic)
 	b.WriteString("  Status: ")
@@ -382,6 +491,11 @@ func (m Model) renderCaddyDetails(entry diff.SyncedEntry) string {
 	b.WriteString(StyleDim.Render("configured"))
 	b.WriteString("\n\n")
 
+	// Drift against the DNS record (e.g. upstream differs from A record)
+	if len(entry.Mismatches) > 0 {
+		b.WriteString(renderMismatches(entry.Mismatches))
+	}
+
 	// Restrictions
 	if entry.Caddy.IPRestricted {
 		b.WriteString(StyleWarning.Render("⚠ IP Restricted"))
@@ -437,6 +551,29 @@ func (m Model) renderCaddyDetails(entry diff.SyncedEntry) string {
 	return b.String()
 }
 
+// entryTypeIcon returns the list icon of an entry's type; DNS-only entries
+// have no Caddy configuration and get a dot
+func entryTypeIcon(entry diff.SyncedEntry) string {
+	if entry.Caddy == nil {
+		return StyleDim.Render("·")
+	}
+	return entry.Caddy.Type.Icon()
+}
+
+// renderMismatches renders the per-field drift explanations for the details panel
+func renderMismatches(mismatches []diff.FieldMismatch) string {
+	var b strings.Builder
+	b.WriteString(StyleIconDrift.Render("Drift"))
+	b.WriteString("\n")
+	for _, m := range mismatches {
+		b.WriteString(fmt.Sprintf("  %s: %s\n", StyleKeybinding.Render(m.Field), m.Reason))
+		b.WriteString(StyleDim.Render(fmt.Sprintf("    expected %s, got %s", m.Expected, m.Actual)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 // formatKeybinding formats a keybinding with color
 func formatKeybinding(key, description string) string {
 	return fmt.Sprintf("%s:%s", StyleKeybinding.Render(key), description)
@@ -462,11 +599,12 @@ func (m Model) getStatusBarContent() string {
 
 	// Context-sensitive keybindings
 	if len(m.selectedEntries) > 0 {
-		return fmt.Sprintf("Navigate: %s  Select: %s  Batch: %s %s  Clear: %s",
+		return fmt.Sprintf("Navigate: %s  Select: %s  Batch: %s %s %s  Clear: %s",
 			StyleKeybinding.Render("↑↓"),
 			StyleKeybinding.Render("space"),
 			formatKeybinding("X", "delete"),
 			formatKeybinding("S", "sync"),
+			formatKeybinding("C", "claim"),
 			StyleKeybinding.Render("esc"))
 	}
 
@@ -621,6 +759,7 @@ func (m Model) renderHelpPage2() string {
 	right.WriteString(fmt.Sprintf("  %s  Search\n", StyleKeybinding.Render("/")))
 	right.WriteString(fmt.Sprintf("  %s  Status\n", StyleKeybinding.Render("f")))
 	right.WriteString(fmt.Sprintf("  %s  DNS type\n", StyleKeybinding.Render("t")))
+	right.WriteString(fmt.Sprintf("  %s  Zone\n", StyleKeybinding.Render("z")))
 	right.WriteString(fmt.Sprintf("  %s  Sort\n", StyleKeybinding.Render("o")))
 
 	leftBox := lipgloss.NewStyle().Width(28).Render(left.String())
@@ -651,6 +790,7 @@ func (m Model) renderHelpPage3() string {
 	right.WriteString(fmt.Sprintf("  %s  Toggle selection\n", StyleKeybinding.Render("Space")))
 	right.WriteString(fmt.Sprintf("  %s  Delete selected\n", StyleKeybinding.Render("X")))
 	right.WriteString(fmt.Sprintf("  %s  Sync selected\n", StyleKeybinding.Render("S")))
+	right.WriteString(fmt.Sprintf("  %s  Claim DNS records\n", StyleKeybinding.Render("C")))
 	right.WriteString(fmt.Sprintf("  %s  Bulk delete menu\n", StyleKeybinding.Render("D")))
 	right.WriteString(fmt.Sprintf("  %s  Clear selection\n", StyleKeybinding.Render("Esc")))
 
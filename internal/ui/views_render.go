@@ -23,23 +23,36 @@ func (m Model) renderListView() string {
 	displayEntries := m.getFilteredEntries()
 
 	// Summary stats
-	synced, orphanedDNS, orphanedCaddy := 0, 0, 0
+	synced, orphanedDNS, orphanedCaddy, mismatched, unmanaged, orphanedIngress := 0, 0, 0, 0, 0, 0
 	for _, entry := range displayEntries {
-		switch entry.Status {
-		case diff.StatusSynced:
+		switch {
+		case entry.Status == diff.StatusSynced:
 			synced++
-		case diff.StatusOrphanedDNS:
+		case entry.Status == diff.StatusUnmanagedDNS:
+			unmanaged++
+		case entry.Status == diff.StatusOrphanedDNS:
 			orphanedDNS++
-		case diff.StatusOrphanedCaddy:
+		case entry.Status == diff.StatusOrphanedCaddy:
 			orphanedCaddy++
+		case entry.Status == diff.StatusOrphanedIngress:
+			orphanedIngress++
+		case entry.Status.IsMismatch():
+			mismatched++
 		}
 	}
 
-	summary := fmt.Sprintf("%s %d synced  %s %d orphaned (DNS)  %s %d orphaned (Caddy)",
+	summary := fmt.Sprintf("%s %d synced  %s %d orphaned (DNS)  %s %d orphaned (Caddy)  %s %d mismatched",
 		syncedIconStyle.Render("✓"), synced,
 		orphanedIconStyle.Render("⚠"), orphanedDNS,
 		orphanedIconStyle.Render("⚠"), orphanedCaddy,
+		StyleIconDrift.Render("≠"), mismatched,
 	)
+	if orphanedIngress > 0 {
+		summary += fmt.Sprintf("  %s %d orphaned (ingress)", orphanedIconStyle.Render("⚠"), orphanedIngress)
+	}
+	if unmanaged > 0 {
+		summary += fmt.Sprintf("  %s %d unmanaged", StyleDim.Render("·"), unmanaged)
+	}
 	b.WriteString(summary)
 	b.WriteString("\n")
 
@@ -51,6 +64,9 @@ func (m Model) renderListView() string {
 	if m.dnsTypeFilter != DNSTypeAll {
 		filterParts = append(filterParts, fmt.Sprintf("DNS Type: %s", m.dnsTypeFilter.String()))
 	}
+	if m.zoneFilter != "" {
+		filterParts = append(filterParts, fmt.Sprintf("Zone: %s", m.zoneFilter))
+	}
 	if m.searchQuery != "" {
 		filterParts = append(filterParts, fmt.Sprintf("Search: \"%s\"", m.searchQuery))
 	}
@@ -91,12 +107,7 @@ func (m Model) renderListView() string {
 		}
 
 		// Icon based on status
-		icon := entry.Status.Icon()
-		if entry.Status == diff.StatusSynced {
-			icon = syncedIconStyle.Render(icon)
-		} else {
-			icon = orphanedIconStyle.Render(icon)
-		}
+		icon := statusIcon(entry.Status)
 
 		// Domain name
 		domain := entry.Domain
@@ -105,14 +116,14 @@ func (m Model) renderListView() string {
 		var details string
 		if entry.DNS != nil && entry.Caddy != nil {
 			// Both exist - show DNS type and target, plus Caddy target
-			details = fmt.Sprintf("DNS:[%s]%s → Caddy:%s:%d",
-				entry.DNS.Type, entry.DNS.Content, entry.Caddy.Target, entry.Caddy.Port)
+			details = fmt.Sprintf("DNS:[%s]%s → Caddy:%s",
+				entry.DNSType(), entry.DNSContent(), entry.Caddy.Destination())
 		} else if entry.DNS != nil {
 			// Only DNS - show type and target
-			details = fmt.Sprintf("DNS:[%s]%s (no Caddy)", entry.DNS.Type, entry.DNS.Content)
+			details = fmt.Sprintf("DNS:[%s]%s (no Caddy)", entry.DNSType(), entry.DNSContent())
 		} else if entry.Caddy != nil {
 			// Only Caddy
-			details = fmt.Sprintf("Caddy:%s:%d (no DNS)", entry.Caddy.Target, entry.Caddy.Port)
+			details = fmt.Sprintf("Caddy:%s (no DNS)", entry.Caddy.Destination())
 		}
 
 		// Render line
@@ -143,7 +154,7 @@ func (m Model) renderListView() string {
 	var statusBar string
 	if m.loading {
 		// Show loading indicator
-		statusBar = statusBarStyle.Render("⟳ Refreshing data from Cloudflare and Caddyfile...")
+		statusBar = statusBarStyle.Render("⟳ Refreshing data from Cloudflare and Caddyfile...  (esc:cancel)")
 	} else if m.searching {
 		// Show search prompt
 		statusBar = statusBarStyle.Render(
@@ -159,7 +170,7 @@ func (m Model) renderListView() string {
 		// Normal status bar - show batch operations if selections exist
 		if len(m.selectedEntries) > 0 {
 			statusBar = statusBarStyle.Render(
-				"j/k:navigate  space:select  X:batch-delete  S:batch-sync  f:filter  t:type  o:sort  /:search  b:backups  r:refresh  ?:help  q:quit",
+				"j/k:navigate  space:select  X:batch-delete  S:batch-sync  C:claim  f:filter  t:type  o:sort  /:search  b:backups  r:refresh  ?:help  q:quit",
 			)
 		} else {
 			statusBar = statusBarStyle.Render(
@@ -216,6 +227,9 @@ func (m Model) renderDetailsView() string {
 		b.WriteString("\n")
 		b.WriteString(fmt.Sprintf("  Zone ID:  %s\n", entry.DNS.ZoneID))
 		b.WriteString(fmt.Sprintf("  Record ID: %s\n", entry.DNS.ID))
+		if entry.DNSAAAA != nil {
+			b.WriteString(fmt.Sprintf("  AAAA:     %s (Record ID: %s)\n", entry.DNSAAAA.Content, entry.DNSAAAA.ID))
+		}
 		b.WriteString("\n")
 	} else {
 		b.WriteString(orphanedIconStyle.Render("DNS Record: Not found in Cloudflare"))
@@ -265,7 +279,7 @@ func (m Model) renderDetailsView() string {
 		}
 
 		// Location in file
-		b.WriteString(fmt.Sprintf("  Location: Lines %d-%d", entry.Caddy.LineStart, entry.Caddy.LineEnd))
+		b.WriteString("  Location: " + caddyLocation(m.config, entry.Caddy.File, entry.Caddy.LineStart, entry.Caddy.LineEnd))
 		if entry.Caddy.HasMarker {
 			b.WriteString(" (has marker)")
 		}
@@ -529,7 +543,7 @@ func (m Model) renderPreviewScreen() string {
 	b.WriteString("\n\n")
 
 	// Build FQDN
-	fqdn := m.addForm.Subdomain + "." + m.config.Domain
+	fqdn := strings.Join(ResolveFQDNs(ParseSubdomains(m.addForm.Subdomain), m.config), ", ")
 
 	// Parse port
 	port := 80
@@ -588,6 +602,17 @@ func (m Model) renderPreviewScreen() string {
 		AllowedExtIP:      m.config.Defaults.AllowedExternalIP,
 		SelectedSnippets:  getSelectedSnippetNames(m.addForm.SelectedSnippets),
 		CustomCaddyConfig: m.addForm.CustomCaddyConfig,
+		Routes:            formPathRoutes(m.addForm),
+		Upstreams:         formUpstreams(m.addForm),
+		LoadBalancing:     formLoadBalancing(m.addForm),
+		ProxyOptions:      m.addForm.ProxyOptions,
+
+		Type:                 m.addForm.EntryType,
+		RedirectTo:           m.addForm.RedirectTo,
+		RedirectCode:         formRedirectCode(m.addForm),
+		RedirectPreservePath: m.addForm.RedirectPreservePath,
+		StaticRoot:           m.addForm.StaticRoot,
+		StaticBrowse:         m.addForm.StaticBrowse,
 	})
 
 	caddyContent := strings.Builder{}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 )
 
@@ -14,7 +16,7 @@ type WizardStep int
 const (
 	WizardStepWelcome WizardStep = iota
 	WizardStepBasicInfo   // Profile name + Domain
-	WizardStepCloudflare  // API Token + Zone ID
+	WizardStepCloudflare  // API Token + zone picked from the zones it can access
 	WizardStepDockerConfig // Docker method, compose path, container, caddyfile paths
 	WizardStepSummary
 )
@@ -31,7 +33,7 @@ const (
 // Fields for WizardStepCloudflare
 const (
 	FieldAPIToken WizardField = iota
-	FieldZoneID
+	FieldZoneID   // Zone list, or a zone ID typed manually
 )
 
 // Fields for WizardStepCaddyConfig (Step 3: Caddy Configuration)
@@ -82,9 +84,11 @@ type WizardData struct {
 // ToProfileConfig converts wizard data to ProfileConfig
 func (w *WizardData) ToProfileConfig() (*config.ProfileConfig, error) {
 	// Create cloudflare config with plaintext token
+	// New profiles only clean up records they own; existing ones can be claimed
 	cfConfig := config.CloudflareConfig{
-		APIToken: w.APIToken,
-		ZoneID:   w.ZoneID,
+		APIToken:  w.APIToken,
+		ZoneID:    w.ZoneID,
+		OwnedOnly: true,
 	}
 
 	profile := &config.ProfileConfig{
@@ -222,9 +226,7 @@ func ValidateCurrentStep(step WizardStep, data *WizardData) error {
 		if data.ProfileName == "" {
 			return fmt.Errorf("profile name is required")
 		}
-		if data.Domain == "" {
-			return fmt.Errorf("domain is required")
-		}
+		// Domain may be left blank and picked from the token's zones
 	case WizardStepCloudflare:
 		if data.APIToken == "" {
 			return fmt.Errorf("API token is required")
@@ -232,6 +234,9 @@ func ValidateCurrentStep(step WizardStep, data *WizardData) error {
 		if data.ZoneID == "" {
 			return fmt.Errorf("Zone ID is required")
 		}
+		if data.Domain == "" {
+			return fmt.Errorf("domain is required - pick a zone or set it in step 1")
+		}
 	case WizardStepDockerConfig:
 		if data.CaddyfilePath == "" {
 			return fmt.Errorf("Caddyfile path is required")
@@ -248,6 +253,32 @@ func ValidateCurrentStep(step WizardStep, data *WizardData) error {
 	return nil
 }
 
+// zoneIndexForDomain returns the index of the zone serving domain (the
+// longest zone name it equals or ends with), or -1 if none does
+func zoneIndexForDomain(zones []cloudflare.Zone, domain string) int {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	best := -1
+	for i, zone := range zones {
+		name := strings.ToLower(zone.Name)
+		if domain != name && !strings.HasSuffix(domain, "."+name) {
+			continue
+		}
+		if best < 0 || len(name) > len(zones[best].Name) {
+			best = i
+		}
+	}
+	return best
+}
+
+// SelectZone sets the zone ID and domain from a zone picked in the wizard.
+// A domain already inside the zone (e.g., a subdomain) is kept.
+func (w *WizardData) SelectZone(zone cloudflare.Zone) {
+	w.ZoneID = zone.ID
+	if zoneIndexForDomain([]cloudflare.Zone{zone}, w.Domain) < 0 {
+		w.Domain = zone.Name
+	}
+}
+
 // ValidateProfile performs comprehensive validation before saving a new profile
 func ValidateProfile(data *WizardData) error {
 	// 1. Check profile name uniqueness
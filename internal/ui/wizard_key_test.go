@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"lazyproxyflare/internal/cloudflare"
 )
 
 // TestWizardEnterKeyOnWelcome tests that Enter key advances from Welcome step
@@ -139,3 +141,67 @@ func TestWizardEnterKeyFullPath(t *testing.T) {
 		t.Logf("wizardStep: %d", newModel.wizardStep)
 	}
 }
+
+// TestWizardZoneSelection tests picking the zone from the token's zone list
+func TestWizardZoneSelection(t *testing.T) {
+	m := NewModelWithWizard()
+	m.wizardStep = WizardStepCloudflare
+	m.wizardData.CurrentField = FieldAPIToken
+	m.wizardData.APIToken = "token"
+	m.wizardData.Domain = "home.example.net"
+
+	zones := []cloudflare.Zone{
+		{ID: "z1", Name: "example.com", Permissions: []string{"#dns_records:edit"}},
+		{ID: "z2", Name: "example.net", Permissions: []string{"#dns_records:read"}},
+	}
+	m, _ = m.handleWizardTokenVerified(wizardTokenVerifiedMsg{zones: zones})
+
+	// The cursor starts on the zone serving the domain from step 1
+	if m.wizardData.CurrentField != FieldZoneID || m.wizardCursor != 1 {
+		t.Fatalf("expected zone field with cursor 1, got field %d cursor %d", m.wizardData.CurrentField, m.wizardCursor)
+	}
+	if m.isWizardTextInputStep() {
+		t.Error("zone list should not take text input")
+	}
+
+	m, _ = m.handleWizardEnter()
+	if m.wizardStep != WizardStepDockerConfig {
+		t.Fatalf("expected to advance to Docker config, got step %d (err: %v)", m.wizardStep, m.err)
+	}
+	if m.wizardData.ZoneID != "z2" || m.wizardData.Domain != "home.example.net" {
+		t.Errorf("got zone %q domain %q", m.wizardData.ZoneID, m.wizardData.Domain)
+	}
+	if m.wizardZoneWarning() == "" {
+		t.Error("expected a warning for a zone without DNS edit permission")
+	}
+}
+
+// TestWizardZoneSelectionSetsDomain tests that a zone outside the step 1 domain replaces it
+func TestWizardZoneSelectionSetsDomain(t *testing.T) {
+	data := WizardData{}
+	data.SelectZone(cloudflare.Zone{ID: "z1", Name: "example.com"})
+	if data.Domain != "example.com" || data.ZoneID != "z1" {
+		t.Errorf("blank domain: got %q in zone %q", data.Domain, data.ZoneID)
+	}
+
+	data = WizardData{Domain: "other.org"}
+	data.SelectZone(cloudflare.Zone{ID: "z1", Name: "example.com"})
+	if data.Domain != "example.com" {
+		t.Errorf("domain outside the zone: got %q", data.Domain)
+	}
+}
+
+// TestWizardTokenWithoutZoneAccess tests manual zone entry when no zones are listed
+func TestWizardTokenWithoutZoneAccess(t *testing.T) {
+	m := NewModelWithWizard()
+	m.wizardStep = WizardStepCloudflare
+	m.wizardData.APIToken = "token"
+
+	m, _ = m.handleWizardTokenVerified(wizardTokenVerifiedMsg{})
+	if m.wizardData.CurrentField != FieldZoneID || !m.isWizardTextInputStep() {
+		t.Errorf("expected manual zone ID entry, got field %d", m.wizardData.CurrentField)
+	}
+	if m.err == nil {
+		t.Error("expected a hint that zones could not be listed")
+	}
+}
@@ -1,14 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"lazyproxyflare/internal/caddy"
+	"lazyproxyflare/internal/cloudflare"
 	"lazyproxyflare/internal/config"
 )
 
@@ -225,8 +228,13 @@ func (m Model) handleWizardNextField() (Model, tea.Cmd) {
 		}
 
 	case WizardStepCloudflare:
-		if m.wizardData.CurrentField < FieldZoneID {
-			m.wizardData.CurrentField++
+		// Leaving the token field verifies it and loads the zone list
+		if m.wizardData.CurrentField == FieldAPIToken {
+			return m.startWizardTokenVerify()
+		}
+		// Move through the zone list and its manual entry option
+		if len(m.wizardZones) > 0 && m.wizardCursor < len(m.wizardZones) {
+			m.wizardCursor++
 			m.configureTextInputForField()
 		}
 
@@ -289,6 +297,11 @@ func (m Model) handleWizardPrevField() (Model, tea.Cmd) {
 		}
 
 	case WizardStepCloudflare:
+		if m.wizardData.CurrentField == FieldZoneID && len(m.wizardZones) > 0 && m.wizardCursor > 0 {
+			m.wizardCursor--
+			m.configureTextInputForField()
+			return m, nil
+		}
 		if m.wizardData.CurrentField > FieldAPIToken {
 			m.wizardData.CurrentField--
 			m.configureTextInputForField()
@@ -475,7 +488,10 @@ func (m *Model) saveCurrentFieldValue() {
 		case FieldAPIToken:
 			m.wizardData.APIToken = value
 		case FieldZoneID:
-			m.wizardData.ZoneID = value
+			// Only save if in manual entry mode or no zones were listed
+			if len(m.wizardZones) == 0 || m.wizardCursor == len(m.wizardZones) {
+				m.wizardData.ZoneID = value
+			}
 		}
 
 	case WizardStepDockerConfig:
@@ -531,14 +547,19 @@ func (m Model) handleWizardEnter() (Model, tea.Cmd) {
 		return m, nil
 
 	case WizardStepCloudflare:
-		m.saveCurrentFieldValue()
-		// If not on last field, move to next field
-		if m.wizardData.CurrentField < FieldZoneID {
-			m.wizardData.CurrentField++
-			m.configureTextInputForField()
+		if m.wizardVerifying {
 			return m, nil
 		}
-		// On last field - validate and proceed to next step
+		m.saveCurrentFieldValue()
+		// On the token field, verify it and load the zone list
+		if m.wizardData.CurrentField == FieldAPIToken {
+			return m.startWizardTokenVerify()
+		}
+		// Zone picked from the list
+		if len(m.wizardZones) > 0 && m.wizardCursor < len(m.wizardZones) {
+			m.wizardData.SelectZone(m.wizardZones[m.wizardCursor])
+		}
+		// Validate and proceed to next step
 		if err := ValidateCurrentStep(m.wizardStep, &m.wizardData); err != nil {
 			m.err = err
 			return m, nil
@@ -616,6 +637,88 @@ func (m Model) handleWizardEnter() (Model, tea.Cmd) {
 	return m, nil
 }
 
+// wizardTokenVerifiedMsg carries the zones an API token entered in the wizard can access
+type wizardTokenVerifiedMsg struct {
+	zones []cloudflare.Zone
+	err   error
+}
+
+// verifyWizardTokenCmd verifies an API token and lists the zones it can access.
+// A token the verify endpoint rejects is still accepted if it can list zones,
+// since account-owned tokens are verified elsewhere. A token that can verify
+// but not list zones gets an empty list, leaving the zone ID to manual entry.
+func verifyWizardTokenCmd(token string) tea.Cmd {
+	return func() tea.Msg {
+		cfClient := cloudflare.NewClient(token)
+		ctx := context.Background()
+
+		status, verifyErr := cfClient.VerifyToken(ctx)
+		if verifyErr == nil && status.Status != "active" {
+			return wizardTokenVerifiedMsg{err: fmt.Errorf("API token is %s", status.Status)}
+		}
+
+		zones, err := cfClient.ListZones(ctx)
+		if err != nil {
+			if verifyErr != nil {
+				return wizardTokenVerifiedMsg{err: fmt.Errorf("API token rejected: %w", verifyErr)}
+			}
+			if !cloudflare.IsAuthError(err) {
+				return wizardTokenVerifiedMsg{err: fmt.Errorf("failed to list zones: %w", err)}
+			}
+			zones = nil
+		}
+		return wizardTokenVerifiedMsg{zones: zones}
+	}
+}
+
+// startWizardTokenVerify saves the token field and starts verifying it
+func (m Model) startWizardTokenVerify() (Model, tea.Cmd) {
+	m.saveCurrentFieldValue()
+	if m.wizardData.APIToken == "" {
+		m.err = fmt.Errorf("API token is required")
+		return m, nil
+	}
+	m.err = nil
+	m.wizardVerifying = true
+	return m, verifyWizardTokenCmd(m.wizardData.APIToken)
+}
+
+// handleWizardTokenVerified moves to the zone field once the token is verified,
+// with the cursor on the zone serving the domain from step 1
+func (m Model) handleWizardTokenVerified(msg wizardTokenVerifiedMsg) (Model, tea.Cmd) {
+	m.wizardVerifying = false
+	if m.currentView != ViewWizard || m.wizardStep != WizardStepCloudflare {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+
+	m.wizardZones = msg.zones
+	m.wizardData.CurrentField = FieldZoneID
+	m.wizardCursor = 0
+	if len(m.wizardZones) == 0 {
+		m.err = fmt.Errorf("the token can't list zones (needs Zone Read) - enter the zone ID manually")
+	} else if i := zoneIndexForDomain(m.wizardZones, m.wizardData.Domain); i >= 0 {
+		m.wizardCursor = i
+	} else if i := slices.IndexFunc(m.wizardZones, func(z cloudflare.Zone) bool { return z.ID == m.wizardData.ZoneID }); i >= 0 {
+		m.wizardCursor = i
+	}
+	m.configureTextInputForField()
+	return m, nil
+}
+
+// wizardZoneWarning warns when the token can't edit DNS in the chosen zone
+func (m Model) wizardZoneWarning() string {
+	for _, zone := range m.wizardZones {
+		if zone.ID == m.wizardData.ZoneID && !zone.CanEditDNS() {
+			return fmt.Sprintf("The API token lacks DNS edit permission on %s - record changes will fail", zone.Name)
+		}
+	}
+	return ""
+}
+
 // handleWizardSummaryConfirm handles 'y' in summary screen (save profile)
 func (m Model) handleWizardSummaryConfirm() (Model, tea.Cmd) {
 	// Validate profile before proceeding
@@ -654,6 +757,7 @@ func (m Model) handleWizardSummaryConfirm() (Model, tea.Cmd) {
 	// Load the newly created profile
 	m.profile.CurrentName = m.wizardData.ProfileName
 	m.config = config.ProfileToLegacyConfig(profileConfig)
+	caddy.SetContainerPath(m.config.Caddy.CaddyfilePath, m.config.Caddy.CaddyfileContainerPath)
 
 	// Switch to list view
 	m.currentView = ViewList
@@ -669,20 +773,26 @@ func (m Model) handleWizardSummaryConfirm() (Model, tea.Cmd) {
 		if err := m.startMigrationWizard(); err != nil {
 			m.err = fmt.Errorf("failed to start migration wizard: %w", err)
 			m.loading = true
-			return m, refreshDataCmd(m.config)
+			return m.startRefresh()
 		}
 		return m, nil
 	}
 
 	// Proceed to main view and reload data
 	m.loading = true
-	return m, refreshDataCmd(m.config)
+	return m.startRefresh()
 }
 
 // isWizardTextInputStep returns true if current step/field accepts text input
 func (m Model) isWizardTextInputStep() bool {
 	switch m.wizardStep {
-	case WizardStepBasicInfo, WizardStepCloudflare:
+	case WizardStepBasicInfo:
+		return true
+	case WizardStepCloudflare:
+		if m.wizardData.CurrentField == FieldZoneID && len(m.wizardZones) > 0 {
+			// Only text input if "Enter manually" is selected
+			return m.wizardCursor == len(m.wizardZones)
+		}
 		return true
 	case WizardStepDockerConfig:
 		field := m.wizardData.CurrentField
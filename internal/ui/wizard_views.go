@@ -110,9 +110,10 @@ This wizard will set up your profile to manage:
   • Caddy reverse proxy configuration
 
 You'll need:
-  • Cloudflare API token (Zone.DNS Edit permission)
-  • Cloudflare Zone ID (from dashboard)
-  • Docker container name for Caddy`
+  • Cloudflare API token (Zone.DNS Edit + Zone.Zone Read permission)
+  • Docker container name for Caddy
+
+The token is verified and you pick your zone from a list.`
 
 	footer := "Press Enter to continue, ESC to exit"
 	return m.renderWizardModal("Setup Wizard", content, footer)
@@ -164,7 +165,7 @@ func (m Model) renderWizardBasicInfo() string {
 	}
 	b.WriteString("\n")
 	if field == FieldDomain {
-		b.WriteString(StyleDim.Render("  Domain to manage (e.g., example.com)"))
+		b.WriteString(StyleDim.Render("  Domain to manage (e.g., example.com), or blank to pick a zone in the next step"))
 	}
 
 	footer := "Tab/↓: next field  Enter: continue  ESC: cancel"
@@ -206,23 +207,57 @@ func (m Model) renderWizardCloudflare() string {
 	}
 	b.WriteString("\n\n")
 
-	// Zone ID
-	b.WriteString(m.renderFieldLabel("Zone ID", field == FieldZoneID, m.wizardData.ZoneID != ""))
+	if m.wizardVerifying {
+		b.WriteString(StyleInfo.Render("  ⟳ Verifying token and loading zones..."))
+		b.WriteString("\n\n")
+	}
+
+	// Zone (picked from the zones the token can access, or typed manually)
+	b.WriteString(m.renderFieldLabel("Zone", field == FieldZoneID, m.wizardData.ZoneID != ""))
 	b.WriteString("\n")
-	if field == FieldZoneID {
+	if field == FieldZoneID && len(m.wizardZones) > 0 {
+		for i, zone := range m.wizardZones {
+			prefix := "    "
+			radio := "○"
+			if m.wizardData.ZoneID == zone.ID {
+				radio = "●"
+			}
+			line := fmt.Sprintf("%s %s", radio, zone.Name)
+			if i == m.wizardCursor {
+				prefix = "  > "
+				b.WriteString(StyleInfo.Render(prefix + line))
+			} else {
+				b.WriteString(prefix + line)
+			}
+			if zone.Status != "" && zone.Status != "active" {
+				b.WriteString(StyleDim.Render(fmt.Sprintf(" (%s)", zone.Status)))
+			}
+			if !zone.CanEditDNS() {
+				b.WriteString(StyleWarning.Render(" ⚠ no DNS edit permission"))
+			}
+			b.WriteString("\n")
+		}
+		// Manual entry option
+		manualIdx := len(m.wizardZones)
+		if m.wizardCursor == manualIdx {
+			b.WriteString(StyleInfo.Render("  > ○ Enter zone ID manually"))
+			b.WriteString("\n")
+			b.WriteString("  ")
+			b.WriteString(m.wizardTextInput.View())
+		} else {
+			b.WriteString("    ○ Enter zone ID manually")
+		}
+	} else if field == FieldZoneID {
 		b.WriteString("  ")
 		b.WriteString(m.wizardTextInput.View())
+		b.WriteString("\n")
+		b.WriteString(StyleDim.Render("  Find in: Cloudflare Dashboard → Domain → Overview"))
+	} else if m.wizardData.ZoneID != "" {
+		b.WriteString("  " + m.wizardData.ZoneID)
 	} else {
-		if m.wizardData.ZoneID != "" {
-			b.WriteString("  " + m.wizardData.ZoneID)
-		} else {
-			b.WriteString(StyleDim.Render("  (not set)"))
-		}
+		b.WriteString(StyleDim.Render("  (listed after the token is verified)"))
 	}
 	b.WriteString("\n")
-	if field == FieldZoneID {
-		b.WriteString(StyleDim.Render("  Find in: Cloudflare Dashboard → Domain → Overview"))
-	}
 
 	footer := "Tab/↓: next field  Shift+Tab/↑: prev field  Enter: continue  ESC: back"
 	return m.renderWizardModal("Step 2: Cloudflare", b.String(), footer)
@@ -446,6 +481,10 @@ func (m Model) renderWizardSummary() string {
 	}
 	b.WriteString(fmt.Sprintf("  API Token: %s\n", maskedToken))
 	b.WriteString(fmt.Sprintf("  Zone ID: %s\n", m.wizardData.ZoneID))
+	if warning := m.wizardZoneWarning(); warning != "" {
+		b.WriteString(StyleWarning.Render("  ⚠ " + warning))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
 	// Caddy Configuration